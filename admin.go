@@ -3,9 +3,22 @@
 package main
 
 import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"html"
 	"log"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yourusername/matrix-mud/pkg/api"
+	"github.com/yourusername/matrix-mud/pkg/db"
+	"github.com/yourusername/matrix-mud/pkg/logging"
+	"github.com/yourusername/matrix-mud/pkg/moderation"
 )
 
 // adminWorld is a global reference to the world state for admin panel access.
@@ -18,10 +31,32 @@ var adminWorld *World
 //
 // Provides endpoints:
 //
-//	GET /        - Admin dashboard showing connected players and stats
-//	GET /kick    - Forcibly disconnect a player by name
+//	GET /                 - Admin dashboard showing connected players and stats
+//	POST /kick            - Forcibly disconnect a player by name
+//	POST /broadcast       - Send a message to every connected player
+//	POST /teleport        - Move a player to a room
+//	GET /player           - View a player's stats, equipment and inventory
+//	POST /player/edit     - Set a player stat
+//	POST /player/grant    - Grant an item, XP or money
+//	POST /player/revoke   - Revoke an item, XP or money
+//	POST /save            - Save all players and the world
+//	POST /shutdown        - Schedule a shutdown or reboot with countdown
+//	POST /shutdown/cancel - Cancel a scheduled shutdown
+//	POST /copyover        - Hot reboot without dropping connections (Linux)
+//	GET /sanctions        - List active bans, mutes and freezes
+//	POST /sanctions/add   - Issue a ban, mute or freeze
+//	POST /sanctions/lift  - Lift a sanction by ID
+//	GET /audit            - Query the audit log
+//	GET /api-keys         - List REST API keys
+//	POST /api-keys/create - Create a REST API key with scopes
+//	POST /api-keys/revoke - Revoke a REST API key by name
+//	GET /webhooks         - Manage webhooks and the dead-letter queue (see webhooks.go)
+//	GET /profiles         - Tick lag profiles and pprof (see tick_watchdog.go)
+//	GET /recordings       - Session recordings and playback (see recordings.go)
 //
 // All endpoints require HTTP Basic Auth with credentials from Config.
+// State-changing endpoints also require POST with a valid CSRF token, and
+// every action is recorded in the audit log.
 func startAdminServer(w *World) {
 	adminWorld = w
 
@@ -29,6 +64,37 @@ func startAdminServer(w *World) {
 	mux := http.NewServeMux()
 	mux.HandleFunc("/", adminDashboard)
 	mux.HandleFunc("/kick", adminKick)
+	mux.HandleFunc("/broadcast", adminBroadcast)
+	mux.HandleFunc("/teleport", adminTeleport)
+	mux.HandleFunc("/player", adminPlayer)
+	mux.HandleFunc("/player/edit", adminPlayerEdit)
+	mux.HandleFunc("/player/grant", adminPlayerGrant)
+	mux.HandleFunc("/player/revoke", adminPlayerRevoke)
+	mux.HandleFunc("/save", adminSave)
+	mux.HandleFunc("/shutdown", adminShutdown)
+	mux.HandleFunc("/shutdown/cancel", adminShutdownCancel)
+	mux.HandleFunc("/copyover", adminCopyover)
+	mux.HandleFunc("/sanctions", adminSanctions)
+	mux.HandleFunc("/sanctions/add", adminSanctionAdd)
+	mux.HandleFunc("/sanctions/lift", adminSanctionLift)
+	mux.HandleFunc("/audit", adminAudit)
+	mux.HandleFunc("/api-keys", adminAPIKeys)
+	mux.HandleFunc("/api-keys/create", adminAPIKeyCreate)
+	mux.HandleFunc("/api-keys/revoke", adminAPIKeyRevoke)
+	mux.HandleFunc("/webhooks", adminWebhooks)
+	mux.HandleFunc("/webhooks/add", adminWebhookAdd)
+	mux.HandleFunc("/webhooks/toggle", adminWebhookToggle)
+	mux.HandleFunc("/webhooks/remove", adminWebhookRemove)
+	mux.HandleFunc("/webhooks/test", adminWebhookTest)
+	mux.HandleFunc("/webhooks/replay", adminWebhookReplay)
+	mux.HandleFunc("/webhooks/discard", adminWebhookDiscard)
+	registerProfilingRoutes(mux)
+	mux.HandleFunc("/recordings", adminRecordings)
+	mux.HandleFunc("/recordings/start", adminRecordingStart)
+	mux.HandleFunc("/recordings/stop", adminRecordingStop)
+	mux.HandleFunc("/recordings/delete", adminRecordingDelete)
+	mux.HandleFunc("/recordings/play", adminRecordingPlay)
+	mux.HandleFunc("/recordings/file", adminRecordingFile)
 
 	// Use configured bind address (defaults to localhost only)
 	bindAddr := Config.AdminBindAddr
@@ -58,6 +124,59 @@ func checkAdminAuth(w http.ResponseWriter, r *http.Request) bool {
 	return true
 }
 
+// adminCSRFKey signs CSRF tokens for console forms. It is regenerated on every
+// start, so pages rendered before a restart must be reloaded.
+var adminCSRFKey = newCSRFKey()
+
+// newCSRFKey generates a random key for signing CSRF tokens
+func newCSRFKey() []byte {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		log.Fatalf("Failed to generate CSRF key: %v", err)
+	}
+	return key
+}
+
+// csrfToken returns the CSRF token for the configured admin account
+func csrfToken() string {
+	mac := hmac.New(sha256.New, adminCSRFKey)
+	mac.Write([]byte(Config.AdminUser))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// csrfField returns a hidden form input carrying the CSRF token
+func csrfField() string {
+	return `<input type="hidden" name="csrf" value="` + csrfToken() + `">`
+}
+
+// checkAdminPost authenticates a state-changing request. It requires Basic
+// Auth, the POST method and a valid CSRF token, and writes the error response
+// itself when any check fails.
+func checkAdminPost(w http.ResponseWriter, r *http.Request) bool {
+	if !checkAdminAuth(w, r) {
+		return false
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return false
+	}
+	if !hmac.Equal([]byte(r.FormValue("csrf")), []byte(csrfToken())) {
+		http.Error(w, "Invalid CSRF token", http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+// consoleActor names the admin console user in audit entries and sanctions
+func consoleActor() string {
+	return "console:" + Config.AdminUser
+}
+
+// auditConsole records an admin console action in the audit log
+func auditConsole(r *http.Request, action db.AuditAction, details string) {
+	recordAudit(consoleActor(), action, details, remoteIP(r.RemoteAddr))
+}
+
 // adminDashboard renders the main admin interface showing all connected players.
 // Displays player name, current room, HP status, and provides kick buttons.
 // Requires HTTP Basic Auth with credentials from environment variables.
@@ -83,14 +202,46 @@ func adminDashboard(w http.ResponseWriter, r *http.Request) {
 		html += `<div class="warning">⚠️ Using auto-generated admin password. Set ADMIN_PASS environment variable for production.</div>`
 	}
 
-	html += `<h3>Connected Signals</h3>
+	html += `<p><a href="/sanctions" class="btn">SANCTIONS</a> <a href="/audit" class="btn">AUDIT LOG</a> <a href="/api-keys" class="btn">API KEYS</a> <a href="/webhooks" class="btn">WEBHOOKS</a> <a href="/recordings" class="btn">RECORDINGS</a> <a href="/profiles" class="btn">PROFILING</a></p>
+	<p>IRC bridge: ` + ircStatus() + `</p>
+	<p>Simulation seed: ` + seedStatus(adminWorld) + `</p>
+	<h3>Operations</h3>
+	<form method="POST" action="/broadcast">` + csrfField() + `
+		<input name="msg" placeholder="message to all players" size="60">
+		<button type="submit" class="btn">BROADCAST</button>
+	</form>
+	<form method="POST" action="/save">` + csrfField() + `
+		<button type="submit" class="btn">FORCE SAVE</button>
+	</form>`
+
+	if at, reboot, pending := shutdownStatus(); pending {
+		html += fmt.Sprintf(`<p class="warning">%s scheduled for %s (%s remaining)</p>
+	<form method="POST" action="/shutdown/cancel">%s<button type="submit" class="btn">CANCEL</button></form>`,
+			strings.ToUpper(shutdownVerb(reboot)), at.Format("15:04:05"), time.Until(at).Round(time.Second), csrfField())
+	} else {
+		html += `<form method="POST" action="/shutdown">` + csrfField() + `
+		<select name="mode"><option>shutdown</option><option>reboot</option></select>
+		in <input name="delay" value="5m" size="6">
+		<button type="submit" class="btn">SCHEDULE</button>
+	</form>`
+	}
+
+	if copyoverSupported {
+		html += `<form method="POST" action="/copyover">` + csrfField() + `
+		<button type="submit" class="btn">COPYOVER</button>
+	</form>`
+	}
+
+	html += `
+	<h3>Connected Signals</h3>
 	<table>
 		<tr><th>Name</th><th>Room</th><th>HP</th><th>Action</th></tr>`
 
 	adminWorld.mutex.RLock()
 	for client, p := range adminWorld.Players {
-		html += fmt.Sprintf("<tr><td>%s</td><td>%s</td><td>%d/%d</td><td><a href='/kick?name=%s' class='btn'>EJECT</a></td></tr>",
-			p.Name, p.RoomID, p.HP, p.MaxHP, p.Name)
+		html += fmt.Sprintf(`<tr><td><a href="/player?name=%s">%s</a></td><td>%s</td><td>%d/%d</td>
+			<td><form method="POST" action="/kick">%s<input type="hidden" name="name" value="%s"><button type="submit" class="btn">EJECT</button></form></td></tr>`,
+			p.Name, p.Name, p.RoomID, p.HP, p.MaxHP, csrfField(), p.Name)
 		_ = client // unused in loop
 	}
 	adminWorld.mutex.RUnlock()
@@ -100,32 +251,306 @@ func adminDashboard(w http.ResponseWriter, r *http.Request) {
 }
 
 // adminKick forcibly disconnects a player from the server.
-// Takes a "name" query parameter to identify the player to kick.
+// Takes a "name" form field to identify the player to kick.
 // Sends a warning message to the player before closing their connection.
-// Requires HTTP Basic Auth with credentials from environment variables.
+// Requires HTTP Basic Auth, POST and a valid CSRF token.
 func adminKick(w http.ResponseWriter, r *http.Request) {
-	if !checkAdminAuth(w, r) {
+	if !checkAdminPost(w, r) {
 		return
 	}
 
-	targetName := r.URL.Query().Get("name")
+	targetName := r.FormValue("name")
 	if targetName == "" {
 		http.Error(w, "Missing 'name' parameter", http.StatusBadRequest)
 		return
 	}
 
-	adminWorld.mutex.Lock()
-	defer adminWorld.mutex.Unlock()
+	if ejectPlayer(adminWorld, targetName, "\r\n\033[31m[OPERATOR EJECTION]\033[0m\r\n") {
+		log.Printf("Admin kicked player: %s", targetName)
+		auditConsole(r, db.AuditAdminAction, "kick "+targetName)
+		fmt.Fprintf(w, "Ejected %s", targetName)
+		return
+	}
+	http.Error(w, fmt.Sprintf("User %s not found", targetName), http.StatusNotFound)
+}
 
-	for client, p := range adminWorld.Players {
-		if p.Name == targetName {
-			client.Write("\r\n\033[31m[OPERATOR EJECTION]\033[0m\r\n")
-			client.conn.Close()
-			delete(adminWorld.Players, client)
-			log.Printf("Admin kicked player: %s", targetName)
-			fmt.Fprintf(w, "Ejected %s", targetName)
-			return
+// adminSanctions lists active sanctions with forms to issue and lift them.
+// Requires HTTP Basic Auth with credentials from environment variables.
+func adminSanctions(w http.ResponseWriter, r *http.Request) {
+	if !checkAdminAuth(w, r) {
+		return
+	}
+
+	page := `<html><head><title>Construct Sanctions</title>
+	<style>
+		body { background: #111; color: #0f0; font-family: monospace; padding: 20px; }
+		table { border-collapse: collapse; width: 100%; }
+		th, td { border: 1px solid #333; padding: 8px; text-align: left; }
+		th { background: #222; }
+		input, select, button { background: #000; color: #0f0; border: 1px solid #333; font-family: monospace; }
+		.btn { background: #300; color: #fff; }
+	</style>
+	</head><body>
+	<h1>/// SANCTIONS ///</h1>
+	<p><a href="/">&laquo; back</a></p>
+	<h3>Issue Sanction</h3>
+	<form method="POST" action="/sanctions/add">` + csrfField() + `
+		<select name="type"><option>ban</option><option>mute</option><option>freeze</option></select>
+		<select name="kind"><option>account</option><option>ip</option><option>cidr</option></select>
+		<input name="target" placeholder="player, IP or CIDR">
+		<input name="duration" placeholder="30m, 12h, 7d, perm">
+		<input name="reason" placeholder="reason" size="40">
+		<button type="submit" class="btn">ISSUE</button>
+	</form>
+	<h3>Active Sanctions</h3>
+	<table>
+		<tr><th>ID</th><th>Type</th><th>Target</th><th>Duration</th><th>Reason</th><th>Issued By</th><th>Action</th></tr>`
+
+	for _, s := range moderation.GlobalModeration.List() {
+		page += fmt.Sprintf(`<tr><td>%d</td><td>%s</td><td>%s %s</td><td>%s</td><td>%s</td><td>%s</td>
+			<td><form method="POST" action="/sanctions/lift">%s<input type="hidden" name="id" value="%d"><button type="submit" class="btn">LIFT</button></form></td></tr>`,
+			s.ID, s.Type, s.Kind, html.EscapeString(s.Target), s.Describe(),
+			html.EscapeString(s.Reason), html.EscapeString(s.IssuedBy), csrfField(), s.ID)
+	}
+
+	page += `</table></body></html>`
+	w.Write([]byte(page))
+}
+
+// adminSanctionAdd issues a ban, mute or freeze from the admin console.
+// Accepts POST form fields: type, kind, target, duration, reason.
+func adminSanctionAdd(w http.ResponseWriter, r *http.Request) {
+	if !checkAdminPost(w, r) {
+		return
+	}
+
+	duration, err := moderation.ParseDuration(r.FormValue("duration"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rest := []string{}
+	if duration > 0 {
+		rest = append(rest, r.FormValue("duration"))
+	} else {
+		rest = append(rest, "perm")
+	}
+	if reason := strings.TrimSpace(r.FormValue("reason")); reason != "" {
+		rest = append(rest, reason)
+	}
+
+	issuer := consoleActor()
+	typ := moderation.SanctionType(r.FormValue("type"))
+	kind := moderation.TargetKind(r.FormValue("kind"))
+	if _, err := issueSanction(adminWorld, issuer, typ, kind, r.FormValue("target"), rest); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	http.Redirect(w, r, "/sanctions", http.StatusSeeOther)
+}
+
+// adminSanctionLift lifts a sanction by ID from the admin console.
+// Accepts POST form field: id.
+func adminSanctionLift(w http.ResponseWriter, r *http.Request) {
+	if !checkAdminPost(w, r) {
+		return
+	}
+
+	id, err := strconv.ParseInt(r.FormValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid 'id' parameter", http.StatusBadRequest)
+		return
+	}
+
+	s, err := moderation.GlobalModeration.LiftByID(id)
+	if s == nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	action := db.AuditAdminAction
+	if s.Type == moderation.SanctionBan {
+		action = db.AuditUnban
+	}
+	auditConsole(r, action, fmt.Sprintf("lift %s %s %s", s.Type, s.Kind, s.Target))
+	http.Redirect(w, r, "/sanctions", http.StatusSeeOther)
+}
+
+// adminAudit queries the audit log with optional filters.
+// Accepts query parameters: player, action, search, since (e.g. 7d), limit.
+func adminAudit(w http.ResponseWriter, r *http.Request) {
+	if !checkAdminAuth(w, r) {
+		return
+	}
+
+	q := r.URL.Query()
+	var args []string
+	for _, key := range []string{"action", "search", "since", "limit"} {
+		if v := strings.TrimSpace(q.Get(key)); v != "" {
+			args = append(args, key+"="+v)
 		}
 	}
-	http.Error(w, fmt.Sprintf("User %s not found", targetName), http.StatusNotFound)
+	filter, err := parseAuditArgs(args)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	filter.PlayerName = strings.TrimSpace(q.Get("player"))
+	if q.Get("limit") == "" {
+		filter.Limit = 100
+	}
+
+	logs, err := queryAudit(filter)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	page := `<html><head><title>Construct Audit Log</title>
+	<style>
+		body { background: #111; color: #0f0; font-family: monospace; padding: 20px; }
+		table { border-collapse: collapse; width: 100%; }
+		th, td { border: 1px solid #333; padding: 8px; text-align: left; }
+		th { background: #222; }
+		input, button { background: #000; color: #0f0; border: 1px solid #333; font-family: monospace; }
+	</style>
+	</head><body>
+	<h1>/// AUDIT LOG ///</h1>
+	<p><a href="/">&laquo; back</a></p>
+	<form method="GET" action="/audit">`
+	for _, field := range []struct{ name, placeholder string }{
+		{"player", "player"}, {"action", "LOGIN, BAN, TRADE..."}, {"search", "details contain"},
+		{"since", "7d"}, {"limit", "100"},
+	} {
+		page += fmt.Sprintf(`<input name="%s" placeholder="%s" value="%s"> `,
+			field.name, field.placeholder, html.EscapeString(q.Get(field.name)))
+	}
+	page += `<button type="submit">FILTER</button></form>
+	<table>
+		<tr><th>Time</th><th>Action</th><th>Player</th><th>Details</th><th>IP</th></tr>`
+
+	for _, l := range logs {
+		page += fmt.Sprintf("<tr><td>%s</td><td>%s</td><td>%s</td><td>%s</td><td>%s</td></tr>",
+			l.Timestamp.Format("2006-01-02 15:04:05"), html.EscapeString(l.Action),
+			html.EscapeString(l.PlayerName), html.EscapeString(l.Details), html.EscapeString(l.IPAddress))
+	}
+
+	page += `</table></body></html>`
+	w.Write([]byte(page))
+}
+
+// adminAPIKeys lists REST API keys with forms to create and revoke them.
+// A newly created key is shown once, on the page returned by /api-keys/create.
+func adminAPIKeys(w http.ResponseWriter, r *http.Request) {
+	if !checkAdminAuth(w, r) {
+		return
+	}
+	renderAPIKeys(w, nil)
+}
+
+// renderAPIKeys writes the API key page, announcing created if non-nil
+func renderAPIKeys(w http.ResponseWriter, created *api.APIKey) {
+	page := `<html><head><title>Construct API Keys</title>
+	<style>
+		body { background: #111; color: #0f0; font-family: monospace; padding: 20px; }
+		table { border-collapse: collapse; width: 100%; }
+		th, td { border: 1px solid #333; padding: 8px; text-align: left; }
+		th { background: #222; }
+		input, button { background: #000; color: #0f0; border: 1px solid #333; font-family: monospace; }
+		.btn { background: #300; color: #fff; }
+		.new { border: 1px solid #0f0; padding: 10px; }
+	</style>
+	</head><body>
+	<h1>/// API KEYS ///</h1>
+	<p><a href="/">&laquo; back</a></p>`
+
+	if apiServer == nil {
+		page += `<p>The REST API is not running.</p></body></html>`
+		w.Write([]byte(page))
+		return
+	}
+
+	if created != nil {
+		page += fmt.Sprintf(`<p class="new">Created key <b>%s</b>: <code>%s</code><br>Copy it now; it will not be shown again.</p>`,
+			html.EscapeString(created.Name), html.EscapeString(created.Key))
+	}
+
+	page += fmt.Sprintf(`<h3>Create Key</h3>
+	<form method="POST" action="/api-keys/create">%s
+		<input name="name" placeholder="name">
+		<label><input type="checkbox" name="scope" value="%s"> messaging</label>
+		<label><input type="checkbox" name="scope" value="%s"> admin</label>
+		<button type="submit" class="btn">CREATE</button>
+	</form>
+	<p>Every key can read; messaging can send messages to players and channels; admin can do everything.</p>
+	<h3>Keys</h3>
+	<table>
+		<tr><th>Name</th><th>Key</th><th>Scopes</th><th>Created</th><th>Last Used</th><th>Action</th></tr>`,
+		csrfField(), api.ScopeMessaging, api.ScopeAdmin)
+
+	for _, k := range apiServer.ListAPIKeys() {
+		lastUsed := "never"
+		if !k.LastUsed.IsZero() {
+			lastUsed = k.LastUsed.Format("2006-01-02 15:04")
+		}
+		scopes := strings.Join(k.Permissions, ", ")
+		if scopes == "" {
+			scopes = api.ScopeRead
+		}
+		page += fmt.Sprintf(`<tr><td>%s</td><td>%s</td><td>%s</td><td>%s</td><td>%s</td>
+			<td><form method="POST" action="/api-keys/revoke">%s<input type="hidden" name="name" value="%s"><button type="submit" class="btn">REVOKE</button></form></td></tr>`,
+			html.EscapeString(k.Name), html.EscapeString(k.Key), html.EscapeString(scopes),
+			k.CreatedAt.Format("2006-01-02 15:04"), lastUsed, csrfField(), html.EscapeString(k.Name))
+	}
+
+	page += `</table></body></html>`
+	w.Write([]byte(page))
+}
+
+// adminAPIKeyCreate creates a REST API key from the admin console.
+// Accepts POST form fields: name, scope (repeatable).
+func adminAPIKeyCreate(w http.ResponseWriter, r *http.Request) {
+	if !checkAdminPost(w, r) {
+		return
+	}
+	if apiServer == nil {
+		http.Error(w, "REST API is not running", http.StatusServiceUnavailable)
+		return
+	}
+
+	name := strings.TrimSpace(r.FormValue("name"))
+	scopes := append([]string{api.ScopeRead}, r.Form["scope"]...)
+	key, err := apiServer.CreateAPIKey(name, scopes)
+	if key == nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		logging.Error().Err(err).Msg("Failed to save API keys")
+	}
+
+	auditConsole(r, db.AuditAdminAction, fmt.Sprintf("create API key %s (%s)", name, strings.Join(scopes, ", ")))
+	renderAPIKeys(w, key)
+}
+
+// adminAPIKeyRevoke revokes a REST API key by name.
+// Accepts POST form field: name.
+func adminAPIKeyRevoke(w http.ResponseWriter, r *http.Request) {
+	if !checkAdminPost(w, r) {
+		return
+	}
+	if apiServer == nil {
+		http.Error(w, "REST API is not running", http.StatusServiceUnavailable)
+		return
+	}
+
+	name := r.FormValue("name")
+	if err := apiServer.RevokeAPIKey(name); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	auditConsole(r, db.AuditDelete, "revoke API key "+name)
+	http.Redirect(w, r, "/api-keys", http.StatusSeeOther)
 }
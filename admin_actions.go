@@ -0,0 +1,430 @@
+// Package main implements admin console actions on players and the server.
+// Every state-changing handler requires POST with a CSRF token (see
+// checkAdminPost) and writes an audit entry.
+package main
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yourusername/matrix-mud/pkg/db"
+	"github.com/yourusername/matrix-mud/pkg/moderation"
+)
+
+// playerStatFields maps editable stat names to the player fields they control
+var playerStatFields = map[string]func(p *Player) *int{
+	"hp":       func(p *Player) *int { return &p.HP },
+	"maxhp":    func(p *Player) *int { return &p.MaxHP },
+	"mp":       func(p *Player) *int { return &p.MP },
+	"maxmp":    func(p *Player) *int { return &p.MaxMP },
+	"strength": func(p *Player) *int { return &p.Strength },
+	"ac":       func(p *Player) *int { return &p.BaseAC },
+	"level":    func(p *Player) *int { return &p.Level },
+	"xp":       func(p *Player) *int { return &p.XP },
+	"money":    func(p *Player) *int { return &p.Money },
+	"heat":     func(p *Player) *int { return &p.Heat },
+	"crafting": func(p *Player) *int { return &p.CraftingSkill },
+}
+
+// sortedStatFields returns the editable stat names in display order
+func sortedStatFields() []string {
+	fields := make([]string, 0, len(playerStatFields))
+	for name := range playerStatFields {
+		fields = append(fields, name)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// notifyPlayer writes an operator message to a player if they are connected
+func notifyPlayer(p *Player, msg string) {
+	if p.Conn != nil && p.Conn.conn != nil {
+		p.Conn.Write("\r\n" + SystemMsg(msg) + "> ")
+	}
+}
+
+// adminTarget resolves the online player named by the "name" form field.
+// Writes a 400 or 404 response and returns nil if the player is not found.
+func adminTarget(w http.ResponseWriter, r *http.Request) *Player {
+	name := r.FormValue("name")
+	if name == "" {
+		http.Error(w, "Missing 'name' parameter", http.StatusBadRequest)
+		return nil
+	}
+	p := findOnlinePlayer(adminWorld, name)
+	if p == nil {
+		http.Error(w, fmt.Sprintf("User %s not found", name), http.StatusNotFound)
+	}
+	return p
+}
+
+// redirectToPlayer returns to the player detail page after an action
+func redirectToPlayer(w http.ResponseWriter, r *http.Request, p *Player) {
+	http.Redirect(w, r, "/player?name="+p.Name, http.StatusSeeOther)
+}
+
+// adminBroadcast sends an operator message to every connected player.
+// Accepts POST form field: msg.
+func adminBroadcast(w http.ResponseWriter, r *http.Request) {
+	if !checkAdminPost(w, r) {
+		return
+	}
+
+	msg := strings.TrimSpace(r.FormValue("msg"))
+	if msg == "" {
+		http.Error(w, "Missing 'msg' parameter", http.StatusBadRequest)
+		return
+	}
+
+	broadcastAll(adminWorld, "\r\n"+SystemMsg(msg)+"> ")
+	auditConsole(r, db.AuditAdminAction, "broadcast: "+msg)
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
+// adminTeleport moves a player to a room.
+// Accepts POST form fields: name, room.
+func adminTeleport(w http.ResponseWriter, r *http.Request) {
+	if !checkAdminPost(w, r) {
+		return
+	}
+	p := adminTarget(w, r)
+	if p == nil {
+		return
+	}
+
+	room := strings.TrimSpace(r.FormValue("room"))
+	from := p.RoomID
+	if err := adminWorld.Teleport(p, room); err != nil {
+		http.Error(w, fmt.Sprintf("Can't teleport to '%s': %v", room, err), http.StatusBadRequest)
+		return
+	}
+
+	notifyPlayer(p, "You have been moved by the Operators.")
+	auditConsole(r, db.AuditAdminAction, fmt.Sprintf("teleport %s from %s to %s", p.Name, from, room))
+	redirectToPlayer(w, r, p)
+}
+
+// adminPlayer shows a player's stats, equipment and inventory with forms to
+// edit them. Accepts query parameter: name.
+func adminPlayer(w http.ResponseWriter, r *http.Request) {
+	if !checkAdminAuth(w, r) {
+		return
+	}
+	p := adminTarget(w, r)
+	if p == nil {
+		return
+	}
+
+	name := html.EscapeString(p.Name)
+	page := `<html><head><title>Construct Player</title>
+	<style>
+		body { background: #111; color: #0f0; font-family: monospace; padding: 20px; }
+		table { border-collapse: collapse; }
+		th, td { border: 1px solid #333; padding: 8px; text-align: left; }
+		th { background: #222; }
+		input, select, button { background: #000; color: #0f0; border: 1px solid #333; font-family: monospace; }
+		.btn { background: #300; color: #fff; }
+	</style>
+	</head><body>
+	<h1>/// ` + name + ` ///</h1>
+	<p><a href="/">&laquo; back</a></p>`
+
+	hidden := csrfField() + `<input type="hidden" name="name" value="` + name + `">`
+
+	adminWorld.mutex.RLock()
+	page += fmt.Sprintf("<p>Class: %s &nbsp; Room: %s &nbsp; State: %s</p>",
+		html.EscapeString(p.Class), html.EscapeString(p.RoomID), html.EscapeString(p.State))
+
+	page += `<h3>Stats</h3><table><tr><th>Stat</th><th>Value</th><th>Set</th></tr>`
+	for _, field := range sortedStatFields() {
+		page += fmt.Sprintf(`<tr><td>%s</td><td>%d</td><td><form method="POST" action="/player/edit">%s
+			<input type="hidden" name="field" value="%s"><input name="value" size="6"><button type="submit">SET</button></form></td></tr>`,
+			field, *playerStatFields[field](p), hidden, field)
+	}
+	page += `</table>`
+
+	page += `<h3>Equipment</h3><table><tr><th>Slot</th><th>Item</th></tr>`
+	for slot, item := range p.Equipment {
+		page += fmt.Sprintf("<tr><td>%s</td><td>%s (%s)</td></tr>",
+			html.EscapeString(slot), html.EscapeString(item.Name), html.EscapeString(item.ID))
+	}
+	page += `</table>`
+
+	page += `<h3>Inventory</h3><table><tr><th>#</th><th>Item</th><th>Action</th></tr>`
+	for i, item := range p.Inventory {
+		page += fmt.Sprintf(`<tr><td>%d</td><td>%s (%s)</td><td><form method="POST" action="/player/revoke">%s
+			<input type="hidden" name="kind" value="item"><input type="hidden" name="value" value="%s"><input type="hidden" name="count" value="%d">
+			<button type="submit" class="btn">REVOKE</button></form></td></tr>`,
+			i+1, html.EscapeString(item.Name), html.EscapeString(item.ID), hidden, html.EscapeString(item.ID), item.Count())
+	}
+	adminWorld.mutex.RUnlock()
+	page += `</table>`
+
+	page += `<h3>Grant / Revoke</h3>
+	<form method="POST" action="/player/grant">` + hidden + `
+		<select name="kind"><option>item</option><option>xp</option><option>money</option></select>
+		<input name="value" placeholder="item template ID or amount">
+		<button type="submit">GRANT</button>
+	</form>
+	<form method="POST" action="/player/revoke">` + hidden + `
+		<select name="kind"><option>xp</option><option>money</option><option>item</option></select>
+		<input name="value" placeholder="amount or item ID">
+		<input name="count" placeholder="item count (1)">
+		<button type="submit" class="btn">REVOKE</button>
+	</form>
+	<h3>Teleport</h3>
+	<form method="POST" action="/teleport">` + hidden + `
+		<input name="room" placeholder="room ID">
+		<button type="submit">TELEPORT</button>
+	</form>
+	</body></html>`
+
+	w.Write([]byte(page))
+}
+
+// adminPlayerEdit sets a player stat to an exact value.
+// Accepts POST form fields: name, field, value.
+func adminPlayerEdit(w http.ResponseWriter, r *http.Request) {
+	if !checkAdminPost(w, r) {
+		return
+	}
+	p := adminTarget(w, r)
+	if p == nil {
+		return
+	}
+
+	field := strings.ToLower(r.FormValue("field"))
+	stat, ok := playerStatFields[field]
+	if !ok {
+		http.Error(w, fmt.Sprintf("Unknown field '%s'", field), http.StatusBadRequest)
+		return
+	}
+	value, err := strconv.Atoi(strings.TrimSpace(r.FormValue("value")))
+	if err != nil || value < 0 {
+		http.Error(w, "Invalid 'value' parameter", http.StatusBadRequest)
+		return
+	}
+
+	adminWorld.mutex.Lock()
+	old := *stat(p)
+	*stat(p) = value
+	adminWorld.mutex.Unlock()
+
+	auditConsole(r, db.AuditAdminAction, fmt.Sprintf("edit %s %s %d -> %d", p.Name, field, old, value))
+	redirectToPlayer(w, r, p)
+}
+
+// adminPlayerGrant gives a player an item from a template, XP or money.
+// Accepts POST form fields: name, kind (item, xp, money), value.
+func adminPlayerGrant(w http.ResponseWriter, r *http.Request) {
+	if !checkAdminPost(w, r) {
+		return
+	}
+	p := adminTarget(w, r)
+	if p == nil {
+		return
+	}
+
+	kind := strings.ToLower(r.FormValue("kind"))
+	value := strings.TrimSpace(r.FormValue("value"))
+
+	var details string
+	switch kind {
+	case "item":
+		tmpl := adminWorld.GetItemTemplate(value)
+		if tmpl == nil {
+			http.Error(w, fmt.Sprintf("Item template '%s' not found", value), http.StatusBadRequest)
+			return
+		}
+		adminWorld.mutex.Lock()
+		item := adminWorld.newItem(tmpl, 1)
+		p.Inventory = addItem(p.Inventory, item)
+		adminWorld.mutex.Unlock()
+		notifyPlayer(p, fmt.Sprintf("The Operators have granted you %s.", item.Name))
+		details = fmt.Sprintf("grant %s item %s", p.Name, item.TemplateID())
+	case "xp", "money":
+		amount, err := strconv.Atoi(value)
+		if err != nil || amount <= 0 {
+			http.Error(w, "Invalid 'value' parameter", http.StatusBadRequest)
+			return
+		}
+		adminWorld.mutex.Lock()
+		if kind == "xp" {
+			p.XP += amount
+			checkLevelUp(p)
+		} else {
+			p.Money += amount
+		}
+		adminWorld.mutex.Unlock()
+		notifyPlayer(p, fmt.Sprintf("The Operators have granted you %d %s.", amount, kind))
+		details = fmt.Sprintf("grant %s %s %d", p.Name, kind, amount)
+	default:
+		http.Error(w, fmt.Sprintf("Unknown kind '%s'", kind), http.StatusBadRequest)
+		return
+	}
+
+	auditConsole(r, db.AuditAdminAction, details)
+	redirectToPlayer(w, r, p)
+}
+
+// adminPlayerRevoke removes an inventory item, XP or money from a player.
+// Amounts are clamped so stats never go negative.
+// Accepts POST form fields: name, kind (item, xp, money), value.
+func adminPlayerRevoke(w http.ResponseWriter, r *http.Request) {
+	if !checkAdminPost(w, r) {
+		return
+	}
+	p := adminTarget(w, r)
+	if p == nil {
+		return
+	}
+
+	kind := strings.ToLower(r.FormValue("kind"))
+	value := strings.TrimSpace(r.FormValue("value"))
+
+	var details string
+	switch kind {
+	case "item":
+		count := 1
+		if c := r.FormValue("count"); c != "" {
+			n, err := strconv.Atoi(c)
+			if err != nil || n <= 0 {
+				http.Error(w, "Invalid 'count' parameter", http.StatusBadRequest)
+				return
+			}
+			count = n
+		}
+		// Take count items by instance or template ID, splitting a stack
+		// rather than removing all of it
+		adminWorld.mutex.Lock()
+		var picks []pick
+		for _, item := range p.Inventory {
+			if count > 0 && (item.ID == value || item.TemplateID() == value) {
+				take := min(count, item.Count())
+				picks = append(picks, pick{item, take})
+				count -= take
+			}
+		}
+		removed := adminWorld.moveItems(picks, nil, func(item *Item) { p.Inventory = removeItem(p.Inventory, item) }, func(*Item) {})
+		adminWorld.mutex.Unlock()
+		if len(removed) == 0 {
+			http.Error(w, fmt.Sprintf("%s has no item '%s'", p.Name, value), http.StatusNotFound)
+			return
+		}
+		n := 0
+		for _, item := range removed {
+			n += item.Count()
+		}
+		name := removed[0].Name
+		if n > 1 {
+			name = fmt.Sprintf("%dx %s", n, name)
+		}
+		notifyPlayer(p, fmt.Sprintf("The Operators have removed %s from your inventory.", name))
+		details = fmt.Sprintf("revoke %s item %s %d", p.Name, removed[0].TemplateID(), n)
+	case "xp", "money":
+		amount, err := strconv.Atoi(value)
+		if err != nil || amount <= 0 {
+			http.Error(w, "Invalid 'value' parameter", http.StatusBadRequest)
+			return
+		}
+		adminWorld.mutex.Lock()
+		stat := &p.Money
+		if kind == "xp" {
+			stat = &p.XP
+		}
+		if amount > *stat {
+			amount = *stat
+		}
+		*stat -= amount
+		adminWorld.mutex.Unlock()
+		notifyPlayer(p, fmt.Sprintf("The Operators have removed %d %s.", amount, kind))
+		details = fmt.Sprintf("revoke %s %s %d", p.Name, kind, amount)
+	default:
+		http.Error(w, fmt.Sprintf("Unknown kind '%s'", kind), http.StatusBadRequest)
+		return
+	}
+
+	auditConsole(r, db.AuditDelete, details)
+	redirectToPlayer(w, r, p)
+}
+
+// adminSave saves every connected player and the world to disk.
+func adminSave(w http.ResponseWriter, r *http.Request) {
+	if !checkAdminPost(w, r) {
+		return
+	}
+
+	adminWorld.mutex.RLock()
+	players := make([]*Player, 0, len(adminWorld.Players))
+	for _, p := range adminWorld.Players {
+		players = append(players, p)
+	}
+	adminWorld.mutex.RUnlock()
+
+	for _, p := range players {
+		adminWorld.SavePlayer(p)
+	}
+	adminWorld.SaveWorld()
+
+	auditConsole(r, db.AuditAdminAction, fmt.Sprintf("force save (%d players)", len(players)))
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
+// adminShutdown schedules a shutdown or reboot with countdown broadcasts.
+// Accepts POST form fields: delay (e.g. 30s, 5m), mode (shutdown or reboot).
+func adminShutdown(w http.ResponseWriter, r *http.Request) {
+	if !checkAdminPost(w, r) {
+		return
+	}
+
+	delay, err := moderation.ParseDuration(r.FormValue("delay"))
+	if err != nil || delay == 0 {
+		http.Error(w, "Invalid 'delay' parameter (use e.g. 30s, 5m)", http.StatusBadRequest)
+		return
+	}
+	mode := r.FormValue("mode")
+	if mode != "shutdown" && mode != "reboot" {
+		http.Error(w, "Invalid 'mode' parameter", http.StatusBadRequest)
+		return
+	}
+
+	if err := scheduleShutdown(adminWorld, delay, mode == "reboot"); err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+
+	auditConsole(r, db.AuditAdminAction, fmt.Sprintf("schedule %s in %s", mode, delay.Round(time.Second)))
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
+// adminShutdownCancel cancels a scheduled shutdown or reboot.
+func adminShutdownCancel(w http.ResponseWriter, r *http.Request) {
+	if !checkAdminPost(w, r) {
+		return
+	}
+
+	if !cancelShutdown(adminWorld) {
+		http.Error(w, "No shutdown scheduled", http.StatusNotFound)
+		return
+	}
+
+	auditConsole(r, db.AuditAdminAction, "cancel scheduled shutdown")
+	http.Redirect(w, r, "/", http.StatusSeeOther)
+}
+
+// adminCopyover hot reboots the server, keeping telnet sessions connected.
+// On success the process is replaced and no response is sent.
+func adminCopyover(w http.ResponseWriter, r *http.Request) {
+	if !checkAdminPost(w, r) {
+		return
+	}
+
+	if err := startCopyover(adminWorld, consoleActor(), remoteIP(r.RemoteAddr)); err != nil {
+		http.Error(w, "Copyover failed: "+err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourusername/matrix-mud/pkg/db"
+)
+
+// setupAdminActions installs test credentials, an audit log and a small world
+// with one connected player for admin action tests
+func setupAdminActions(t *testing.T) (*Player, *db.AuditRepository) {
+	t.Helper()
+	origUser, origPass, origWorld := Config.AdminUser, Config.AdminPass, adminWorld
+	t.Cleanup(func() {
+		Config.AdminUser, Config.AdminPass, adminWorld = origUser, origPass, origWorld
+	})
+	Config.AdminUser = "testadmin"
+	Config.AdminPass = "testpass"
+
+	p := &Player{Name: "Neo", RoomID: "dojo", HP: 10, MaxHP: 20, Level: 1, Money: 50,
+		Inventory: []*Item{{ID: "phone", Name: "Cell Phone"}}, Equipment: make(map[string]*Item)}
+	adminWorld = &World{
+		Rooms:         map[string]*Room{"dojo": {ID: "dojo"}, "street": {ID: "street"}},
+		Players:       map[*Client]*Player{{}: p},
+		ItemTemplates: map[string]*Item{"katana": {ID: "katana", Name: "Katana"}},
+	}
+	return p, withAuditLog(t)
+}
+
+// adminPost sends an authenticated POST with a valid CSRF token
+func adminPost(handler http.HandlerFunc, form url.Values) *httptest.ResponseRecorder {
+	form.Set("csrf", csrfToken())
+	req := httptest.NewRequest("POST", "/", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth("testadmin", "testpass")
+	w := httptest.NewRecorder()
+	handler(w, req)
+	return w
+}
+
+// TestAdminActionsRequireCSRF verifies state-changing handlers reject GET and bad tokens
+func TestAdminActionsRequireCSRF(t *testing.T) {
+	setupAdminActions(t)
+
+	handlers := map[string]http.HandlerFunc{
+		"broadcast": adminBroadcast, "teleport": adminTeleport, "edit": adminPlayerEdit,
+		"grant": adminPlayerGrant, "revoke": adminPlayerRevoke, "save": adminSave,
+		"shutdown": adminShutdown, "cancel": adminShutdownCancel,
+	}
+	for name, handler := range handlers {
+		req := httptest.NewRequest("GET", "/?name=Neo", nil)
+		req.SetBasicAuth("testadmin", "testpass")
+		w := httptest.NewRecorder()
+		handler(w, req)
+		if w.Code != http.StatusMethodNotAllowed {
+			t.Errorf("%s: expected 405 for GET, got %d", name, w.Code)
+		}
+
+		req = httptest.NewRequest("POST", "/", strings.NewReader("name=Neo&csrf=forged"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.SetBasicAuth("testadmin", "testpass")
+		w = httptest.NewRecorder()
+		handler(w, req)
+		if w.Code != http.StatusForbidden {
+			t.Errorf("%s: expected 403 for forged token, got %d", name, w.Code)
+		}
+	}
+}
+
+// TestAdminTeleport verifies players are moved and the move is audited
+func TestAdminTeleport(t *testing.T) {
+	p, repo := setupAdminActions(t)
+
+	if w := adminPost(adminTeleport, url.Values{"name": {"neo"}, "room": {"nowhere"}}); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for unknown room, got %d", w.Code)
+	}
+	if w := adminPost(adminTeleport, url.Values{"name": {"neo"}, "room": {"street"}}); w.Code != http.StatusSeeOther {
+		t.Fatalf("Expected redirect, got %d", w.Code)
+	}
+	if p.RoomID != "street" {
+		t.Errorf("Player should be in street, got %s", p.RoomID)
+	}
+
+	logs, _ := repo.Find(db.AuditFilter{PlayerName: "console:testadmin"})
+	if len(logs) != 1 || !strings.Contains(logs[0].Details, "teleport Neo from dojo to street") {
+		t.Errorf("Teleport not audited: %+v", logs)
+	}
+}
+
+// TestAdminPlayerEdit verifies stats can be set and invalid input rejected
+func TestAdminPlayerEdit(t *testing.T) {
+	p, repo := setupAdminActions(t)
+
+	if w := adminPost(adminPlayerEdit, url.Values{"name": {"neo"}, "field": {"charisma"}, "value": {"5"}}); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for unknown field, got %d", w.Code)
+	}
+	if w := adminPost(adminPlayerEdit, url.Values{"name": {"neo"}, "field": {"hp"}, "value": {"-1"}}); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for negative value, got %d", w.Code)
+	}
+	if w := adminPost(adminPlayerEdit, url.Values{"name": {"smith"}, "field": {"hp"}, "value": {"5"}}); w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for offline player, got %d", w.Code)
+	}
+
+	adminPost(adminPlayerEdit, url.Values{"name": {"neo"}, "field": {"hp"}, "value": {"20"}})
+	if p.HP != 20 {
+		t.Errorf("HP should be 20, got %d", p.HP)
+	}
+	if logs, _ := repo.Find(db.AuditFilter{Query: "edit Neo hp 10 -> 20"}); len(logs) != 1 {
+		t.Error("Edit not audited")
+	}
+}
+
+// TestAdminPlayerGrantRevoke verifies items, XP and money can be granted and revoked
+func TestAdminPlayerGrantRevoke(t *testing.T) {
+	p, repo := setupAdminActions(t)
+
+	if w := adminPost(adminPlayerGrant, url.Values{"name": {"neo"}, "kind": {"item"}, "value": {"spoon"}}); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for unknown template, got %d", w.Code)
+	}
+	adminPost(adminPlayerGrant, url.Values{"name": {"neo"}, "kind": {"item"}, "value": {"katana"}})
+	adminPost(adminPlayerGrant, url.Values{"name": {"neo"}, "kind": {"money"}, "value": {"100"}})
+	adminPost(adminPlayerGrant, url.Values{"name": {"neo"}, "kind": {"xp"}, "value": {"150"}})
+
+	if len(p.Inventory) != 2 || p.Inventory[1].TemplateID() != "katana" {
+		t.Errorf("Katana not granted: %+v", p.Inventory)
+	}
+	if p.Money != 150 {
+		t.Errorf("Money should be 150, got %d", p.Money)
+	}
+	if p.Level != 2 {
+		t.Errorf("XP grant should level up to 2, got %d", p.Level)
+	}
+
+	adminPost(adminPlayerRevoke, url.Values{"name": {"neo"}, "kind": {"item"}, "value": {"phone"}})
+	adminPost(adminPlayerRevoke, url.Values{"name": {"neo"}, "kind": {"money"}, "value": {"1000"}})
+	if len(p.Inventory) != 1 || p.Inventory[0].TemplateID() != "katana" {
+		t.Errorf("Phone not revoked: %+v", p.Inventory)
+	}
+	if p.Money != 0 {
+		t.Errorf("Money revoke should clamp to 0, got %d", p.Money)
+	}
+	if w := adminPost(adminPlayerRevoke, url.Values{"name": {"neo"}, "kind": {"item"}, "value": {"phone"}}); w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for missing item, got %d", w.Code)
+	}
+
+	// Revoking from a stack takes only the count asked for
+	p.Inventory = append(p.Inventory, adminWorld.newItem(&Item{ID: "credchip", Name: "Credchip", Type: "material"}, 500))
+	adminPost(adminPlayerRevoke, url.Values{"name": {"neo"}, "kind": {"item"}, "value": {"credchip"}})
+	adminPost(adminPlayerRevoke, url.Values{"name": {"neo"}, "kind": {"item"}, "value": {"credchip"}, "count": {"9"}})
+	if len(p.Inventory) != 2 || p.Inventory[1].Count() != 490 {
+		t.Errorf("Revoke should split the stack: %+v", p.Inventory)
+	}
+	if w := adminPost(adminPlayerRevoke, url.Values{"name": {"neo"}, "kind": {"item"}, "value": {"credchip"}, "count": {"0"}}); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a zero count, got %d", w.Code)
+	}
+
+	logs, _ := repo.Find(db.AuditFilter{PlayerName: "console:testadmin"})
+	if len(logs) != 7 {
+		t.Fatalf("Expected 7 audited actions, got %d", len(logs))
+	}
+	found := false
+	for _, l := range logs {
+		found = found || strings.Contains(l.Details, "revoke Neo item credchip 9")
+	}
+	if !found {
+		t.Error("Audit entry should record the quantity revoked")
+	}
+}
+
+// TestAdminPlayerPage verifies the player detail page renders with CSRF tokens
+func TestAdminPlayerPage(t *testing.T) {
+	setupAdminActions(t)
+
+	req := httptest.NewRequest("GET", "/player?name=neo", nil)
+	req.SetBasicAuth("testadmin", "testpass")
+	w := httptest.NewRecorder()
+	adminPlayer(w, req)
+
+	body := w.Body.String()
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", w.Code)
+	}
+	for _, want := range []string{"Cell Phone", "maxhp", csrfToken()} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Player page missing %q", want)
+		}
+	}
+}
+
+// TestScheduleShutdown verifies the countdown signals main and can be canceled
+func TestScheduleShutdown(t *testing.T) {
+	w := &World{Players: make(map[*Client]*Player)}
+
+	if err := scheduleShutdown(w, time.Hour, false); err != nil {
+		t.Fatalf("scheduleShutdown failed: %v", err)
+	}
+	if _, _, pending := shutdownStatus(); !pending {
+		t.Error("Shutdown should be pending")
+	}
+	if err := scheduleShutdown(w, time.Hour, false); err == nil {
+		t.Error("Second schedule should be rejected")
+	}
+	if !cancelShutdown(w) {
+		t.Error("Cancel should succeed")
+	}
+	if cancelShutdown(w) {
+		t.Error("Second cancel should report nothing pending")
+	}
+
+	if err := scheduleShutdown(w, 20*time.Millisecond, true); err != nil {
+		t.Fatalf("scheduleShutdown failed: %v", err)
+	}
+	select {
+	case reboot := <-shutdownRequests:
+		if !reboot {
+			t.Error("Expected a reboot request")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Countdown did not signal shutdown")
+	}
+	if _, _, pending := shutdownStatus(); pending {
+		t.Error("Nothing should be pending after the countdown completes")
+	}
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"strings"
 	"testing"
 )
@@ -112,29 +113,43 @@ func TestAdminKick(t *testing.T) {
 	// Set up adminWorld
 	adminWorld = NewWorld()
 
+	kick := func(form url.Values, auth bool) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/kick", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if auth {
+			req.SetBasicAuth("admin", "pass")
+		}
+		w := httptest.NewRecorder()
+		adminKick(w, req)
+		return w
+	}
+
 	// Test without auth
+	if w := kick(url.Values{"name": {"test"}, "csrf": {csrfToken()}}, false); w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without auth, got %d", w.Code)
+	}
+
+	// Test GET is rejected
 	req := httptest.NewRequest("GET", "/kick?name=test", nil)
+	req.SetBasicAuth("admin", "pass")
 	w := httptest.NewRecorder()
 	adminKick(w, req)
-	if w.Code != http.StatusUnauthorized {
-		t.Errorf("Expected 401 without auth, got %d", w.Code)
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405 for GET, got %d", w.Code)
+	}
+
+	// Test missing CSRF token
+	if w := kick(url.Values{"name": {"test"}}, true); w.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 without CSRF token, got %d", w.Code)
 	}
 
 	// Test with auth but missing name
-	req = httptest.NewRequest("GET", "/kick", nil)
-	req.SetBasicAuth("admin", "pass")
-	w = httptest.NewRecorder()
-	adminKick(w, req)
-	if w.Code != http.StatusBadRequest {
+	if w := kick(url.Values{"csrf": {csrfToken()}}, true); w.Code != http.StatusBadRequest {
 		t.Errorf("Expected 400 for missing name, got %d", w.Code)
 	}
 
 	// Test with auth but nonexistent player
-	req = httptest.NewRequest("GET", "/kick?name=nonexistent", nil)
-	req.SetBasicAuth("admin", "pass")
-	w = httptest.NewRecorder()
-	adminKick(w, req)
-	if w.Code != http.StatusNotFound {
+	if w := kick(url.Values{"name": {"nonexistent"}, "csrf": {csrfToken()}}, true); w.Code != http.StatusNotFound {
 		t.Errorf("Expected 404 for nonexistent player, got %d", w.Code)
 	}
 }
@@ -0,0 +1,370 @@
+// Package main serves the public REST API (pkg/api) on its own port. The API
+// reads from the live world through worldReadModel, which takes the world
+// lock for every query and copies what it needs into API types.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yourusername/matrix-mud/pkg/achievements"
+	"github.com/yourusername/matrix-mud/pkg/api"
+	"github.com/yourusername/matrix-mud/pkg/chat"
+	"github.com/yourusername/matrix-mud/pkg/events"
+	"github.com/yourusername/matrix-mud/pkg/leaderboard"
+	"github.com/yourusername/matrix-mud/pkg/logging"
+	"github.com/yourusername/matrix-mud/pkg/validation"
+)
+
+// apiServer is the running REST API, used by the admin console to manage keys
+var apiServer *api.Server
+
+// apiLeaderboardStats maps API leaderboard categories to tracked stats
+var apiLeaderboardStats = map[string]leaderboard.StatType{
+	"level":        leaderboard.StatLevel,
+	"money":        leaderboard.StatMoney,
+	"kills":        leaderboard.StatKills,
+	"achievements": leaderboard.StatAchievements,
+	"pvp":          leaderboard.StatPvPWins,
+}
+
+// worldReadModel implements api.ReadModel over the live world
+type worldReadModel struct {
+	world   *World
+	started time.Time
+}
+
+// newAPIServer builds the API server with keys from keysFile
+func newAPIServer(w *World, keysFile string) (*api.Server, error) {
+	keys, err := api.LoadAPIKeys(keysFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var origins []string
+	for _, o := range strings.Split(Config.AllowedOrigins, ",") {
+		if o = strings.TrimSpace(o); o != "" {
+			origins = append(origins, o)
+		}
+	}
+
+	s := api.NewServer(&api.Config{
+		BindAddr:    ":" + Config.APIPort,
+		APIKeys:     keys,
+		CORSOrigins: origins,
+		KeysFile:    keysFile,
+	}, Version)
+	s.UseReadModel(&worldReadModel{world: w, started: time.Now()})
+	s.Events = events.GlobalStream
+	s.Journal = eventJournal
+	return s, nil
+}
+
+// startAPIServer serves the REST API until the process exits
+func startAPIServer(w *World) {
+	s, err := newAPIServer(w, Config.APIKeysFile)
+	if err != nil {
+		logging.Error().Err(err).Str("path", Config.APIKeysFile).Msg("Failed to load API keys, REST API disabled")
+		return
+	}
+	apiServer = s
+
+	if len(s.ListAPIKeys()) == 0 {
+		logging.Warn().Msg("No API keys configured; create one from the admin console")
+	}
+	logging.Info().Str("port", Config.APIPort).Msg("REST API active")
+	if err := s.Start(); err != nil {
+		logging.Error().Err(err).Msg("REST API stopped")
+	}
+}
+
+// Status reports server-wide counts
+func (m *worldReadModel) Status() *api.ServerStatus {
+	m.world.mutex.RLock()
+	online := len(m.world.Players)
+	rooms := len(m.world.Rooms)
+	npcs := 0
+	for _, room := range m.world.Rooms {
+		npcs += len(room.NPCMap)
+	}
+	m.world.mutex.RUnlock()
+
+	saved, _ := filepath.Glob("data/players/*.json")
+	return &api.ServerStatus{
+		Status:        "online",
+		Version:       Version,
+		Uptime:        time.Since(m.started).Round(time.Second).String(),
+		PlayersOnline: online,
+		TotalPlayers:  len(saved),
+		TotalRooms:    rooms,
+		TotalNPCs:     npcs,
+		StartedAt:     m.started,
+	}
+}
+
+// OnlinePlayers lists connected players by name
+func (m *worldReadModel) OnlinePlayers() []api.PlayerInfo {
+	m.world.mutex.RLock()
+	players := make([]api.PlayerInfo, 0, len(m.world.Players))
+	now := m.world.now()
+	for _, p := range m.world.Players {
+		info := playerInfo(p, true)
+		info.Effects = p.Effects.Names(now)
+		players = append(players, info)
+	}
+	m.world.mutex.RUnlock()
+
+	sort.Slice(players, func(i, j int) bool { return players[i].Name < players[j].Name })
+	return players
+}
+
+// Player returns an online player, or the saved record of an offline one
+func (m *worldReadModel) Player(name string) *api.PlayerInfo {
+	if p := m.onlinePlayer(name); p != nil {
+		m.world.mutex.RLock()
+		defer m.world.mutex.RUnlock()
+		info := playerInfo(p, true)
+		info.Effects = p.Effects.Names(m.world.now())
+		return &info
+	}
+
+	p, lastSeen := loadSavedPlayer(name)
+	if p == nil {
+		return nil
+	}
+	info := playerInfo(p, false)
+	info.LastSeen = lastSeen
+	return &info
+}
+
+// Inventory lists what a player is carrying
+func (m *worldReadModel) Inventory(name string) []api.ItemInfo {
+	p := m.onlinePlayer(name)
+	if p != nil {
+		m.world.mutex.RLock()
+		defer m.world.mutex.RUnlock()
+	} else if p, _ = loadSavedPlayer(name); p == nil {
+		return []api.ItemInfo{}
+	}
+
+	items := make([]api.ItemInfo, 0, len(p.Inventory))
+	for _, item := range p.Inventory {
+		items = append(items, itemInfo(item))
+	}
+	return items
+}
+
+// Rooms lists every room by ID
+func (m *worldReadModel) Rooms() []api.RoomInfo {
+	m.world.mutex.RLock()
+	rooms := make([]api.RoomInfo, 0, len(m.world.Rooms))
+	for _, room := range m.world.Rooms {
+		rooms = append(rooms, m.roomInfo(room))
+	}
+	m.world.mutex.RUnlock()
+
+	sort.Slice(rooms, func(i, j int) bool { return rooms[i].ID < rooms[j].ID })
+	return rooms
+}
+
+// Room returns a single room
+func (m *worldReadModel) Room(id string) *api.RoomInfo {
+	m.world.mutex.RLock()
+	defer m.world.mutex.RUnlock()
+
+	room, ok := m.world.Rooms[id]
+	if !ok {
+		return nil
+	}
+	info := m.roomInfo(room)
+	return &info
+}
+
+// NPCs lists every living NPC with its current room
+func (m *worldReadModel) NPCs() []api.NPCInfo {
+	m.world.mutex.RLock()
+	var npcs []api.NPCInfo
+	for _, room := range m.world.Rooms {
+		for _, npc := range room.NPCMap {
+			npcs = append(npcs, api.NPCInfo{
+				ID:       npc.ID,
+				Name:     npc.Name,
+				HP:       npc.HP,
+				MaxHP:    npc.MaxHP,
+				RoomID:   room.ID,
+				Hostile:  npc.Aggro || npc.IsAgent,
+				Merchant: npc.Vendor,
+			})
+		}
+	}
+	m.world.mutex.RUnlock()
+
+	sort.Slice(npcs, func(i, j int) bool {
+		if npcs[i].RoomID != npcs[j].RoomID {
+			return npcs[i].RoomID < npcs[j].RoomID
+		}
+		return npcs[i].ID < npcs[j].ID
+	})
+	return npcs
+}
+
+// Items lists the item templates
+func (m *worldReadModel) Items() []api.ItemInfo {
+	m.world.mutex.RLock()
+	items := make([]api.ItemInfo, 0, len(m.world.ItemTemplates))
+	for _, item := range m.world.ItemTemplates {
+		items = append(items, itemInfo(item))
+	}
+	m.world.mutex.RUnlock()
+
+	sort.Slice(items, func(i, j int) bool { return items[i].ID < items[j].ID })
+	return items
+}
+
+// Leaderboard returns the top players for a category. Any tracked stat
+// name is accepted alongside the advertised categories.
+func (m *worldReadModel) Leaderboard(category string, limit int) []api.LeaderboardEntry {
+	stat, ok := apiLeaderboardStats[category]
+	if !ok {
+		stat = leaderboard.StatType(category)
+	}
+
+	board := leaderboard.GlobalLeaderboard.GetLeaderboard(stat, limit)
+	entries := make([]api.LeaderboardEntry, 0, len(board))
+	for _, e := range board {
+		entries = append(entries, api.LeaderboardEntry{
+			Rank:  e.Rank,
+			Name:  e.Name,
+			Value: e.Value,
+			Title: achievements.GlobalAchievements.GetTitle(e.Name),
+		})
+	}
+	return entries
+}
+
+// SendMessage delivers a system message to an online player
+func (m *worldReadModel) SendMessage(player, message string) error {
+	if ok, reason := validation.ValidateMessage(message); !ok {
+		return fmt.Errorf("%s", reason)
+	}
+	p := m.onlinePlayer(player)
+	if p == nil || p.Conn == nil {
+		return fmt.Errorf("player %s is not online", player)
+	}
+	p.Conn.Write("\r\n" + SystemMsg(message) + "> ")
+	return nil
+}
+
+// SendChannelMessage posts a system message to every member of a chat channel
+func (m *worldReadModel) SendChannelMessage(channel, message string) error {
+	if ok, reason := validation.ValidateMessage(message); !ok {
+		return fmt.Errorf("%s", reason)
+	}
+	ch := chat.GlobalChat.GetChannel(channel)
+	if ch == nil {
+		return fmt.Errorf("unknown channel %s", channel)
+	}
+
+	msg := chat.FormatMessage(chat.Message{
+		Sender:    "OPERATOR",
+		Content:   message,
+		Timestamp: time.Now(),
+	}, ch.Name)
+	broadcastChatMessage(m.world, msg, chat.GlobalChat.GetChannelMembers(channel))
+	return nil
+}
+
+// onlinePlayer finds a connected player by name, ignoring case
+func (m *worldReadModel) onlinePlayer(name string) *Player {
+	m.world.mutex.RLock()
+	defer m.world.mutex.RUnlock()
+	for _, p := range m.world.Players {
+		if strings.EqualFold(p.Name, name) {
+			return p
+		}
+	}
+	return nil
+}
+
+// roomInfo copies a room; the caller holds the world lock
+func (m *worldReadModel) roomInfo(room *Room) api.RoomInfo {
+	info := api.RoomInfo{ID: room.ID, Name: room.ID, Description: room.Description}
+	for dir, exit := range room.Exits {
+		if !exit.Hidden {
+			info.Exits = append(info.Exits, dir)
+		}
+	}
+	for _, npc := range room.NPCMap {
+		info.NPCs = append(info.NPCs, npc.Name)
+	}
+	for _, item := range room.ItemMap {
+		info.Items = append(info.Items, item.Name)
+	}
+	sort.Strings(info.Exits)
+	sort.Strings(info.NPCs)
+	sort.Strings(info.Items)
+
+	for _, p := range m.world.Players {
+		if p.RoomID == room.ID {
+			info.PlayerCount++
+		}
+	}
+	return info
+}
+
+// loadSavedPlayer reads an offline player's save file and its modification time
+func loadSavedPlayer(name string) (*Player, time.Time) {
+	if !validation.ValidateUsername(name) {
+		return nil, time.Time{}
+	}
+	path := "data/players/" + strings.ToLower(name) + ".json"
+	stat, err := os.Stat(path)
+	if err != nil {
+		return nil, time.Time{}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, time.Time{}
+	}
+	var p Player
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, time.Time{}
+	}
+	return &p, stat.ModTime()
+}
+
+// playerInfo copies a player's public profile
+func playerInfo(p *Player, online bool) api.PlayerInfo {
+	return api.PlayerInfo{
+		Name:   p.Name,
+		Class:  p.Class,
+		Level:  p.Level,
+		XP:     p.XP,
+		HP:     p.HP,
+		MaxHP:  p.MaxHP,
+		MP:     p.MP,
+		MaxMP:  p.MaxMP,
+		Money:  p.Money,
+		RoomID: p.RoomID,
+		Title:  achievements.GlobalAchievements.GetTitle(p.Name),
+		Online: online,
+	}
+}
+
+// itemInfo copies an item
+func itemInfo(item *Item) api.ItemInfo {
+	return api.ItemInfo{
+		ID:          item.ID,
+		Name:        item.Name,
+		Type:        item.Type,
+		Description: item.Description,
+		Value:       item.Value,
+		Damage:      item.Damage,
+		Armor:       item.AC,
+	}
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/matrix-mud/pkg/api"
+	"github.com/yourusername/matrix-mud/pkg/chat"
+	"github.com/yourusername/matrix-mud/pkg/db"
+)
+
+// setupAPIServer builds an API server over a small world with one connected
+// player and returns it with the player's connection and an admin key
+func setupAPIServer(t *testing.T) (*api.Server, *mockConn, string) {
+	t.Helper()
+	conn := newMockConn("")
+	client := &Client{conn: conn, reader: bufio.NewReader(conn)}
+	p := &Player{Name: "Neo", RoomID: "dojo", Level: 3, Conn: client,
+		Inventory: []*Item{{ID: "phone", Name: "Cell Phone"}}, Equipment: make(map[string]*Item)}
+	w := &World{
+		Rooms: map[string]*Room{
+			"dojo": {ID: "dojo", Description: "A training program.", Exits: map[string]*Exit{"north": {To: "street"}},
+				NPCMap:  map[string]*NPC{"morpheus": {ID: "morpheus", Name: "Morpheus", HP: 50, MaxHP: 50}},
+				ItemMap: map[string]*Item{}},
+			"street": {ID: "street", Exits: map[string]*Exit{"south": {To: "dojo"}}, NPCMap: map[string]*NPC{}, ItemMap: map[string]*Item{}},
+		},
+		Players:       map[*Client]*Player{client: p},
+		ItemTemplates: map[string]*Item{"katana": {ID: "katana", Name: "Katana", Damage: 8}},
+	}
+
+	s, err := newAPIServer(w, filepath.Join(t.TempDir(), "api_keys.json"))
+	if err != nil {
+		t.Fatalf("newAPIServer failed: %v", err)
+	}
+	key, err := s.CreateAPIKey("ops", []string{api.ScopeAdmin})
+	if err != nil {
+		t.Fatalf("CreateAPIKey failed: %v", err)
+	}
+	return s, conn, key.Key
+}
+
+// apiGet performs an authenticated API request and decodes the data field
+func apiGet(t *testing.T, s *api.Server, key, target string, data interface{}) int {
+	t.Helper()
+	req := httptest.NewRequest("GET", target, nil)
+	req.Header.Set("X-API-Key", key)
+	w := httptest.NewRecorder()
+	s.Handler().ServeHTTP(w, req)
+	json.NewDecoder(w.Body).Decode(&struct {
+		Data interface{} `json:"data"`
+	}{data})
+	return w.Code
+}
+
+// TestAPIReadModel verifies the API serves live world data
+func TestAPIReadModel(t *testing.T) {
+	s, _, key := setupAPIServer(t)
+
+	var players []api.PlayerInfo
+	if code := apiGet(t, s, key, "/api/players", &players); code != http.StatusOK || len(players) != 1 || players[0].Name != "Neo" || !players[0].Online {
+		t.Errorf("Players: status %d, %+v", code, players)
+	}
+
+	var room api.RoomInfo
+	apiGet(t, s, key, "/api/world/rooms/dojo", &room)
+	if room.PlayerCount != 1 || len(room.NPCs) != 1 || room.NPCs[0] != "Morpheus" || len(room.Exits) != 1 {
+		t.Errorf("Room not read from world: %+v", room)
+	}
+
+	var npcs []api.NPCInfo
+	apiGet(t, s, key, "/api/world/npcs", &npcs)
+	if len(npcs) != 1 || npcs[0].RoomID != "dojo" {
+		t.Errorf("NPCs: %+v", npcs)
+	}
+
+	var items []api.ItemInfo
+	apiGet(t, s, key, "/api/world/items", &items)
+	if len(items) != 1 || items[0].Damage != 8 {
+		t.Errorf("Items: %+v", items)
+	}
+
+	apiGet(t, s, key, "/api/players/neo/inventory", &items)
+	if len(items) != 1 || items[0].ID != "phone" {
+		t.Errorf("Inventory: %+v", items)
+	}
+
+	if p, _ := loadSavedPlayer("../world"); p != nil {
+		t.Error("Invalid player names should not be looked up on disk")
+	}
+}
+
+// TestAPIMessages verifies messages reach online players and channel members
+func TestAPIMessages(t *testing.T) {
+	s, conn, key := setupAPIServer(t)
+
+	post := func(body map[string]string) int {
+		data, _ := json.Marshal(body)
+		req := httptest.NewRequest("POST", "/api/messages", bytes.NewReader(data))
+		req.Header.Set("X-API-Key", key)
+		w := httptest.NewRecorder()
+		s.Handler().ServeHTTP(w, req)
+		return w.Code
+	}
+
+	if code := post(map[string]string{"player": "neo", "message": "Follow the white rabbit"}); code != http.StatusOK {
+		t.Fatalf("Direct message: status %d", code)
+	}
+	if !strings.Contains(conn.output(), "Follow the white rabbit") {
+		t.Errorf("Message not delivered: %q", conn.output())
+	}
+	if code := post(map[string]string{"player": "smith", "message": "Hello"}); code != http.StatusBadRequest {
+		t.Errorf("Offline player: status %d, want 400", code)
+	}
+
+	chat.GlobalChat.JoinChannel("Neo", "global")
+	t.Cleanup(func() { chat.GlobalChat.LeaveChannel("Neo", "global") })
+	if code := post(map[string]string{"channel": "global", "message": "Knock knock"}); code != http.StatusOK {
+		t.Fatalf("Channel message: status %d", code)
+	}
+	if !strings.Contains(conn.output(), "Knock knock") {
+		t.Errorf("Channel message not delivered: %q", conn.output())
+	}
+	if code := post(map[string]string{"channel": "nowhere", "message": "Hello"}); code != http.StatusBadRequest {
+		t.Errorf("Unknown channel: status %d, want 400", code)
+	}
+}
+
+// TestAdminAPIKeys verifies keys can be created and revoked from the console
+func TestAdminAPIKeys(t *testing.T) {
+	_, repo := setupAdminActions(t)
+	s, _, _ := setupAPIServer(t)
+	orig := apiServer
+	apiServer = s
+	t.Cleanup(func() { apiServer = orig })
+
+	w := adminPost(adminAPIKeyCreate, url.Values{"name": {"discord"}, "scope": {"messaging"}})
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "will not be shown again") {
+		t.Fatalf("Create: status %d", w.Code)
+	}
+	if w := adminPost(adminAPIKeyCreate, url.Values{"name": {"discord"}}); w.Code != http.StatusBadRequest {
+		t.Errorf("Duplicate name: status %d, want 400", w.Code)
+	}
+
+	var created *api.APIKey
+	for _, k := range s.ListAPIKeys() {
+		if k.Name == "discord" {
+			created = k
+		}
+	}
+	if created == nil || strings.Join(created.Permissions, ",") != "read,messaging" {
+		t.Fatalf("Key not created with scopes: %+v", created)
+	}
+
+	if w := adminPost(adminAPIKeyRevoke, url.Values{"name": {"discord"}}); w.Code != http.StatusSeeOther {
+		t.Errorf("Revoke: status %d", w.Code)
+	}
+	if len(s.ListAPIKeys()) != 1 {
+		t.Error("Key should be revoked")
+	}
+
+	if logs, _ := repo.Find(db.AuditFilter{Query: "API key discord"}); len(logs) != 2 {
+		t.Errorf("Expected create and revoke audited, got %d", len(logs))
+	}
+}
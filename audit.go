@@ -0,0 +1,221 @@
+// Package main wires the persistent audit log into game and admin actions.
+// Entries are written to the SQLite audit_log table managed by pkg/db.
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yourusername/matrix-mud/pkg/db"
+	"github.com/yourusername/matrix-mud/pkg/logging"
+	"github.com/yourusername/matrix-mud/pkg/moderation"
+	"github.com/yourusername/matrix-mud/pkg/trade"
+)
+
+// auditLog is the global audit repository. Nil when auditing is unavailable,
+// in which case recordAudit is a no-op.
+var auditLog *db.AuditRepository
+
+// initAuditLog opens the audit database at path and applies migrations.
+// Failures are logged and leave auditing disabled rather than stopping the server.
+func initAuditLog(path string) {
+	database, err := db.New(db.Config{DSN: path})
+	if err != nil {
+		logging.Error().Err(err).Str("path", path).Msg("Audit log unavailable")
+		return
+	}
+	if err := database.RunMigrations(); err != nil {
+		logging.Error().Err(err).Str("path", path).Msg("Audit log migrations failed")
+		database.Close()
+		return
+	}
+	auditLog = db.NewAuditRepository(database)
+	logging.Info().Str("path", path).Msg("Audit log enabled")
+}
+
+// recordAudit writes an audit entry for a player or staff action.
+// The ip argument may be empty when the action is not tied to a connection.
+func recordAudit(playerName string, action db.AuditAction, details, ip string) {
+	if auditLog == nil {
+		return
+	}
+	err := auditLog.Log(&db.AuditEntry{
+		PlayerName: playerName,
+		Action:     action,
+		Details:    details,
+		IPAddress:  ip,
+	})
+	if err != nil {
+		logging.Warn().Err(err).Str("action", string(action)).Msg("Failed to write audit entry")
+	}
+}
+
+// largeTransferThreshold is the amount of money at or above which a
+// transfer is flagged in the audit log
+const largeTransferThreshold = 1000
+
+// clientIP returns the remote address of a client for audit entries, or "".
+// Web client sessions give the browser's address.
+func clientIP(c *Client) string {
+	if c == nil || c.conn == nil {
+		return ""
+	}
+	return browserAddr(c.conn.RemoteAddr().String())
+}
+
+// auditMoney records a money transfer if it meets the large-transfer threshold
+func auditMoney(playerName string, action db.AuditAction, amount int, details, ip string) {
+	if amount < largeTransferThreshold {
+		return
+	}
+	recordAudit(playerName, action, fmt.Sprintf("%s [large transfer: %d]", details, amount), ip)
+}
+
+// describeTradeOffer summarizes one side of a trade for the audit log
+func describeTradeOffer(offer trade.TradeOffer) string {
+	var parts []string
+	for _, item := range offer.Items {
+		parts = append(parts, fmt.Sprintf("%dx %s", item.Quantity, item.Name))
+	}
+	if offer.Money > 0 {
+		parts = append(parts, fmt.Sprintf("%d bits", offer.Money))
+	}
+	if len(parts) == 0 {
+		return "nothing"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// auditTrade records a completed trade and flags large money offers
+func auditTrade(t *trade.Trade, ip string) {
+	details := fmt.Sprintf("trade %s: %s gave %s; %s gave %s", t.ID,
+		t.Initiator, describeTradeOffer(t.InitiatorOffer),
+		t.Target, describeTradeOffer(t.TargetOffer))
+	recordAudit(t.Initiator, db.AuditTrade, details, ip)
+	recordAudit(t.Target, db.AuditTrade, details, "")
+	auditMoney(t.Initiator, db.AuditWarning, t.InitiatorOffer.Money, "trade "+t.ID+" to "+t.Target, ip)
+	auditMoney(t.Target, db.AuditWarning, t.TargetOffer.Money, "trade "+t.ID+" to "+t.Initiator, "")
+}
+
+// auditAuctionSale records a completed auction sale
+func auditAuctionSale(listing *trade.AuctionListing, ip string) {
+	if listing == nil {
+		return
+	}
+	details := fmt.Sprintf("auction %s: %dx %s sold by %s to %s for %d",
+		listing.ID, listing.Quantity, listing.ItemName, listing.SellerName, listing.CurrentBidder, listing.CurrentBid)
+	recordAudit(listing.CurrentBidder, db.AuditPurchase, details, ip)
+	recordAudit(listing.SellerName, db.AuditSale, details, "")
+	auditMoney(listing.CurrentBidder, db.AuditWarning, listing.CurrentBid, "auction "+listing.ID+" to "+listing.SellerName, ip)
+}
+
+// settleExpiredAuctions ends the auctions past their expiry. One with a
+// winning bid is a sale, audited and announced as a buyout is.
+func settleExpiredAuctions(m *trade.Manager) {
+	for _, expired := range m.ProcessExpiredAuctions() {
+		if expired.HasBid {
+			auditAuctionSale(expired.Listing, "")
+			publishAuctionSale(expired.Listing)
+		}
+	}
+}
+
+// auditBuild records a builder command and its outcome. Deletions are
+// logged as item destruction; everything else is an admin action.
+func auditBuild(p *Player, cmd, arg, result, ip string) {
+	action := db.AuditAdminAction
+	if cmd == "delete" || cmd == "del" {
+		action = db.AuditDelete
+	}
+	recordAudit(p.Name, action, fmt.Sprintf("build %s %s in %s: %s", cmd, arg, p.RoomID, result), ip)
+}
+
+// queryAudit runs a filtered audit query, returning an error when auditing is disabled
+func queryAudit(filter db.AuditFilter) ([]*db.AuditLog, error) {
+	if auditLog == nil {
+		return nil, fmt.Errorf("audit log is not enabled")
+	}
+	return auditLog.Find(filter)
+}
+
+// parseAuditArgs builds a filter from command arguments:
+// [player] [action=<ACTION>] [search=<text>] [since=<duration>] [limit=<n>]
+func parseAuditArgs(args []string) (db.AuditFilter, error) {
+	filter := db.AuditFilter{Limit: 20}
+	for _, arg := range args {
+		key, value, found := strings.Cut(arg, "=")
+		if !found {
+			if filter.PlayerName != "" {
+				return filter, fmt.Errorf("unexpected argument '%s'", arg)
+			}
+			filter.PlayerName = arg
+			continue
+		}
+		switch strings.ToLower(key) {
+		case "action":
+			filter.Action = db.AuditAction(strings.ToUpper(value))
+		case "search":
+			filter.Query = value
+		case "since":
+			d, err := moderation.ParseDuration(value)
+			if err != nil || d == 0 {
+				return filter, fmt.Errorf("invalid since '%s' (use e.g. 30m, 12h, 7d)", value)
+			}
+			filter.Since = time.Now().Add(-d)
+		case "limit":
+			n, err := strconv.Atoi(value)
+			if err != nil || n <= 0 || n > 200 {
+				return filter, fmt.Errorf("invalid limit '%s' (1-200)", value)
+			}
+			filter.Limit = n
+		default:
+			return filter, fmt.Errorf("unknown filter '%s'", key)
+		}
+	}
+	return filter, nil
+}
+
+// formatAuditLogs renders audit entries for the in-game audit command
+func formatAuditLogs(logs []*db.AuditLog) string {
+	if len(logs) == 0 {
+		return "No audit entries found.\r\n"
+	}
+
+	var sb strings.Builder
+	sb.WriteString("=== AUDIT LOG ===\r\n")
+	for _, l := range logs {
+		sb.WriteString(fmt.Sprintf("  %s %s%-8s%s %-12s %s",
+			l.Timestamp.Format("2006-01-02 15:04:05"), Cyan, l.Action, Reset, l.PlayerName, l.Details))
+		if l.IPAddress != "" {
+			sb.WriteString(" (" + l.IPAddress + ")")
+		}
+		sb.WriteString("\r\n")
+	}
+	return sb.String()
+}
+
+// handleAuditCommand lets staff query the audit log in game:
+// audit <player> [action=<ACTION>] [search=<text>] [since=<duration>] [limit=<n>]
+func handleAuditCommand(admin *Player, args []string) string {
+	if !isAdmin(admin) {
+		return "Unknown.\r\n"
+	}
+	if len(args) == 0 {
+		return "Usage: audit <player|*> [action=<ACTION>] [search=<text>] [since=7d] [limit=20]\r\n"
+	}
+	if args[0] == "*" {
+		args = args[1:]
+	}
+
+	filter, err := parseAuditArgs(args)
+	if err != nil {
+		return Red + err.Error() + Reset + "\r\n"
+	}
+	logs, err := queryAudit(filter)
+	if err != nil {
+		return Red + err.Error() + Reset + "\r\n"
+	}
+	return formatAuditLogs(logs)
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourusername/matrix-mud/pkg/db"
+	"github.com/yourusername/matrix-mud/pkg/trade"
+)
+
+// withAuditLog installs an in-memory audit log for a test
+func withAuditLog(t *testing.T) *db.AuditRepository {
+	t.Helper()
+	database, err := db.NewMemory()
+	if err != nil {
+		t.Fatalf("NewMemory failed: %v", err)
+	}
+	if err := database.RunMigrations(); err != nil {
+		t.Fatalf("RunMigrations failed: %v", err)
+	}
+	orig := auditLog
+	auditLog = db.NewAuditRepository(database)
+	t.Cleanup(func() {
+		auditLog = orig
+		database.Close()
+	})
+	return auditLog
+}
+
+// TestRecordAuditDisabled verifies recording is a no-op without a database
+func TestRecordAuditDisabled(t *testing.T) {
+	orig := auditLog
+	auditLog = nil
+	defer func() { auditLog = orig }()
+
+	recordAudit("neo", db.AuditLogin, "login", "") // Must not panic
+	if _, err := queryAudit(db.AuditFilter{}); err == nil {
+		t.Error("Query should fail when auditing is disabled")
+	}
+}
+
+// TestAuditMoneyThreshold verifies only large transfers are flagged
+func TestAuditMoneyThreshold(t *testing.T) {
+	repo := withAuditLog(t)
+
+	auditMoney("neo", db.AuditSale, largeTransferThreshold-1, "small", "")
+	auditMoney("neo", db.AuditSale, largeTransferThreshold, "large", "")
+
+	logs, _ := repo.Find(db.AuditFilter{PlayerName: "neo"})
+	if len(logs) != 1 || !strings.Contains(logs[0].Details, "large") {
+		t.Errorf("Expected only the large transfer, got %+v", logs)
+	}
+}
+
+// TestAuditTrade verifies both sides of a trade are recorded
+func TestAuditTrade(t *testing.T) {
+	repo := withAuditLog(t)
+
+	tr := &trade.Trade{
+		ID:             "t1",
+		Initiator:      "Neo",
+		Target:         "Trinity",
+		InitiatorOffer: trade.TradeOffer{Items: []trade.TradeItem{{ItemID: "deck", Name: "Cyberdeck", Quantity: 1}}},
+		TargetOffer:    trade.TradeOffer{Money: 5000},
+	}
+	auditTrade(tr, "10.0.0.1")
+
+	logs, _ := repo.Find(db.AuditFilter{Action: db.AuditTrade})
+	if len(logs) != 2 {
+		t.Fatalf("Expected 2 trade entries, got %d", len(logs))
+	}
+	if !strings.Contains(logs[0].Details, "1x Cyberdeck") || !strings.Contains(logs[0].Details, "5000 bits") {
+		t.Errorf("Trade details incomplete: %s", logs[0].Details)
+	}
+	if warnings, _ := repo.Find(db.AuditFilter{PlayerName: "Trinity", Action: db.AuditWarning}); len(warnings) != 1 {
+		t.Errorf("Expected large transfer warning for Trinity, got %d", len(warnings))
+	}
+}
+
+// TestSettleExpiredAuctions verifies an auction won by bid is audited
+func TestSettleExpiredAuctions(t *testing.T) {
+	repo := withAuditLog(t)
+	m := trade.NewManager()
+	sold, _ := m.CreateListing("Neo", "deck", "Cyberdeck", 1, 100, 200, time.Millisecond, "general")
+	m.PlaceBid("Trinity", sold.ID, 150)
+	m.CreateListing("Neo", "spoon", "Spoon", 1, 100, 200, time.Millisecond, "general")
+	time.Sleep(10 * time.Millisecond)
+
+	settleExpiredAuctions(m)
+	if logs, _ := repo.Find(db.AuditFilter{PlayerName: "Trinity", Action: db.AuditPurchase}); len(logs) != 1 || !strings.Contains(logs[0].Details, "1x Cyberdeck sold by Neo to Trinity for 150") {
+		t.Errorf("Expected the winning bid to be audited, got %+v", logs)
+	}
+	if logs, _ := repo.Find(db.AuditFilter{PlayerName: "Neo", Action: db.AuditSale}); len(logs) != 1 {
+		t.Errorf("Expected one sale for the seller, got %d", len(logs))
+	}
+}
+
+// TestParseAuditArgs verifies filter parsing
+func TestParseAuditArgs(t *testing.T) {
+	f, err := parseAuditArgs([]string{"neo", "action=trade", "search=deck", "since=7d", "limit=5"})
+	if err != nil {
+		t.Fatalf("parseAuditArgs failed: %v", err)
+	}
+	if f.PlayerName != "neo" || f.Action != db.AuditTrade || f.Query != "deck" || f.Limit != 5 || f.Since.IsZero() {
+		t.Errorf("Unexpected filter: %+v", f)
+	}
+
+	for _, bad := range [][]string{
+		{"neo", "smith"},
+		{"since=soon"},
+		{"limit=0"},
+		{"color=red"},
+	} {
+		if _, err := parseAuditArgs(bad); err == nil {
+			t.Errorf("parseAuditArgs(%v) should fail", bad)
+		}
+	}
+}
+
+// TestHandleAuditCommand verifies staff-only access and filtering
+func TestHandleAuditCommand(t *testing.T) {
+	withModeration(t, "morpheus")
+	withAuditLog(t)
+
+	recordAudit("neo", db.AuditLogin, "login", "10.0.0.1")
+	recordAudit("neo", db.AuditDelete, "build delete phone in lobby: Deleted Phone", "")
+	recordAudit("smith", db.AuditLogin, "login", "")
+
+	if got := handleAuditCommand(&Player{Name: "neo"}, []string{"neo"}); got != "Unknown.\r\n" {
+		t.Errorf("Non-admin should get Unknown, got %q", got)
+	}
+
+	admin := &Player{Name: "Morpheus"}
+	if got := handleAuditCommand(admin, nil); !strings.Contains(got, "Usage") {
+		t.Errorf("Expected usage, got %q", got)
+	}
+
+	got := handleAuditCommand(admin, []string{"neo", "action=delete"})
+	if !strings.Contains(got, "Deleted Phone") || strings.Contains(got, "LOGIN") {
+		t.Errorf("Filter by action failed: %q", got)
+	}
+
+	got = handleAuditCommand(admin, []string{"*", "action=login"})
+	if !strings.Contains(got, "neo") || !strings.Contains(got, "smith") {
+		t.Errorf("Wildcard query failed: %q", got)
+	}
+}
+
+// TestAdminAudit verifies the console audit page
+func TestAdminAudit(t *testing.T) {
+	withAuditLog(t)
+	origUser, origPass := Config.AdminUser, Config.AdminPass
+	defer func() { Config.AdminUser, Config.AdminPass = origUser, origPass }()
+	Config.AdminUser = "testadmin"
+	Config.AdminPass = "testpass"
+
+	recordAudit("neo", db.AuditLogin, "login <script>", "10.0.0.1")
+	recordAudit("smith", db.AuditLogin, "login", "")
+
+	req := httptest.NewRequest("GET", "/audit?player=neo", nil)
+	req.SetBasicAuth("testadmin", "testpass")
+	w := httptest.NewRecorder()
+	adminAudit(w, req)
+
+	body := w.Body.String()
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", w.Code)
+	}
+	if !strings.Contains(body, "&lt;script&gt;") || strings.Contains(body, "<script>") {
+		t.Error("Details should be HTML-escaped")
+	}
+	if strings.Contains(body, "smith") {
+		t.Error("Player filter should exclude other players")
+	}
+
+	req = httptest.NewRequest("GET", "/audit?limit=abc", nil)
+	req.SetBasicAuth("testadmin", "testpass")
+	w = httptest.NewRecorder()
+	adminAudit(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Invalid limit should 400, got %d", w.Code)
+	}
+}
@@ -5,9 +5,15 @@ package main
 
 import (
 	"fmt"
-	"math/rand"
+	"slices"
+	"sort"
 	"strings"
 	"time"
+
+	"github.com/yourusername/matrix-mud/pkg/effects"
+	"github.com/yourusername/matrix-mud/pkg/game"
+	"github.com/yourusername/matrix-mud/pkg/skills"
+	"github.com/yourusername/matrix-mud/pkg/world"
 )
 
 // Heat thresholds for Agent spawning
@@ -41,8 +47,8 @@ func (w *World) TakePill(p *Player, pillColor string) string {
 
 	// Check room items
 	for _, item := range room.Items {
-		if (pillColor == "red" && item.ID == "red_pill") ||
-			(pillColor == "blue" && item.ID == "blue_pill") {
+		if (pillColor == "red" && item.TemplateID() == "red_pill") ||
+			(pillColor == "blue" && item.TemplateID() == "blue_pill") {
 			hasPill = true
 			pillItem = item
 			break
@@ -52,8 +58,8 @@ func (w *World) TakePill(p *Player, pillColor string) string {
 	// Check inventory
 	if !hasPill {
 		for _, item := range p.Inventory {
-			if (pillColor == "red" && item.ID == "red_pill") ||
-				(pillColor == "blue" && item.ID == "blue_pill") {
+			if (pillColor == "red" && item.TemplateID() == "red_pill") ||
+				(pillColor == "blue" && item.TemplateID() == "blue_pill") {
 				hasPill = true
 				pillItem = item
 				break
@@ -68,8 +74,7 @@ func (w *World) TakePill(p *Player, pillColor string) string {
 	switch pillColor {
 	case "red":
 		// Remove the pill
-		w.removeItemFromRoom(room, pillItem)
-		w.removeItemFromInventory(p, pillItem)
+		w.swallowPill(room, p, pillItem)
 
 		p.Awakened = true
 
@@ -96,8 +101,7 @@ Type 'abilities' to see your new awakened powers.`, Reset)
 
 	case "blue":
 		// Remove the pill
-		w.removeItemFromRoom(room, pillItem)
-		w.removeItemFromInventory(p, pillItem)
+		w.swallowPill(room, p, pillItem)
 
 		return fmt.Sprintf("%s%s%s\r\n\r\n%s%s%s\r\n",
 			Cyan, "You swallow the blue pill.", Reset,
@@ -108,6 +112,14 @@ Type 'abilities' to see your new awakened powers.`, Reset)
 	}
 }
 
+// swallowPill takes one pill from a stack, removing the item with the last
+func (w *World) swallowPill(room *Room, p *Player, pill *Item) {
+	if w.take(pick{pill, 1}) == pill {
+		w.removeItemFromRoom(room, pill)
+		w.removeItemFromInventory(p, pill)
+	}
+}
+
 // removeItemFromRoom removes an item from a room's item list
 func (w *World) removeItemFromRoom(room *Room, item *Item) {
 	for i, roomItem := range room.Items {
@@ -139,19 +151,14 @@ func (w *World) ShowAbilities(p *Player) string {
 
 	// Class abilities
 	sb.WriteString(White + "Class: " + p.Class + Reset + "\r\n")
-	switch p.Class {
-	case "Hacker":
-		sb.WriteString("  glitch <target> - Disrupt an enemy's code (5s cooldown)\r\n")
-		sb.WriteString("  patch - Heal yourself by rewriting your code (15s cooldown)\r\n")
-		sb.WriteString("  overflow <target> - Massive damage attack (30s cooldown)\r\n")
-	case "Rebel":
-		sb.WriteString("  smash <target> - Powerful physical attack (3s cooldown)\r\n")
-		sb.WriteString("  fortify - Increase your defenses (20s cooldown)\r\n")
-		sb.WriteString("  rampage - Attack all enemies in room (45s cooldown)\r\n")
-	case "Operator":
-		sb.WriteString("  strike <target> - Quick precision attack (4s cooldown)\r\n")
-		sb.WriteString("  vanish - Become temporarily invisible (30s cooldown)\r\n")
-		sb.WriteString("  assassinate <target> - High damage sneak attack (60s cooldown)\r\n")
+	for _, s := range skills.GlobalSkills.ForClass(p.Class) {
+		line := fmt.Sprintf("  %s - %s (%s cooldown)", s.ID, s.Description, s.CooldownDuration())
+		if p.Level < s.Level {
+			line += fmt.Sprintf(" [level %d]", s.Level)
+		} else if !knowsSkill(p, s) {
+			line += " [learn]"
+		}
+		sb.WriteString(line + "\r\n")
 	}
 
 	// Awakened abilities
@@ -173,6 +180,8 @@ func (w *World) SeeCode(p *Player) string {
 		return "You stare intently but see only the surface of things.\r\n"
 	}
 
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
 	room := w.Rooms[p.RoomID]
 	if room == nil {
 		return "Error: Location not found in the Matrix.\r\n"
@@ -184,6 +193,15 @@ func (w *World) SeeCode(p *Player) string {
 	// Show room data
 	sb.WriteString(fmt.Sprintf("Room ID: %s%s%s\r\n", Cyan, room.ID, Reset))
 	sb.WriteString(fmt.Sprintf("Exits: %d connections\r\n", len(room.Exits)))
+	found := revealHidden(p, room)
+	dirs := make([]string, 0, len(room.Exits))
+	for dir := range room.Exits {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+	for _, dir := range dirs {
+		sb.WriteString("  " + exitCode(dir, room.Exits[dir], slices.Contains(found, dir)) + "\r\n")
+	}
 
 	// Reveal hidden items
 	if len(room.Items) > 0 {
@@ -273,13 +291,13 @@ func (w *World) DecayHeat() {
 // maybeSpawnAgent potentially spawns an Agent to hunt a high-heat player
 func (w *World) maybeSpawnAgent(p *Player) {
 	// 20% chance per check when heat is high
-	if rand.Intn(100) > 20 {
+	if w.rng().Intn(100) > 20 {
 		return
 	}
 
 	room := w.Rooms[p.RoomID]
-	if room == nil {
-		return
+	if room == nil || room.HasFlag(world.FlagSafe) {
+		return // Agents can't reach into safe rooms
 	}
 
 	// Check if there's already an Agent in the room
@@ -291,8 +309,8 @@ func (w *World) maybeSpawnAgent(p *Player) {
 
 	// Find an adjacent room to spawn the Agent
 	var spawnRoom *Room
-	for _, exitID := range room.Exits {
-		if r := w.Rooms[exitID]; r != nil {
+	for _, exit := range room.Exits {
+		if r := w.Rooms[exit.To]; r != nil && !r.HasFlag(world.FlagSafe) {
 			spawnRoom = r
 			break
 		}
@@ -304,7 +322,7 @@ func (w *World) maybeSpawnAgent(p *Player) {
 
 	// Create the Agent
 	agent := &NPC{
-		ID:           fmt.Sprintf("agent_%d", time.Now().UnixNano()),
+		ID:           fmt.Sprintf("agent_%d", w.now().UnixNano()),
 		Name:         "Agent Smith",
 		Description:  "A man in a dark suit and sunglasses. His movements are unnaturally precise.",
 		RoomID:       spawnRoom.ID,
@@ -315,6 +333,7 @@ func (w *World) maybeSpawnAgent(p *Player) {
 		XP:           200,
 		Aggro:        true,
 		IsAgent:      true,
+		Kind:         game.KindAgent,
 		TargetPlayer: p.Name,
 		OriginalRoom: spawnRoom.ID,
 	}
@@ -367,16 +386,22 @@ func (w *World) AgentAI() {
 				continue
 			}
 
+			// Rooted and stunned Agents stay put
+			if npc.Effects.Controlled(effects.Root, w.now()) || npc.Effects.Controlled(effects.Stun, w.now()) {
+				continue
+			}
+
 			// Move toward target (pathfinding - simple: check if adjacent)
 			currentRoom := w.Rooms[npc.RoomID]
 			if currentRoom == nil {
 				continue
 			}
 
-			for dir, exitID := range currentRoom.Exits {
-				if exitID == target.RoomID {
+			// Agents rewrite doors and walls as they please
+			for dir, exit := range currentRoom.Exits {
+				if exit.To == target.RoomID && !w.Rooms[exit.To].HasFlag(world.FlagSafe) {
 					// Move to target's room
-					w.moveNPC(npc, currentRoom, w.Rooms[exitID], dir)
+					w.moveNPC(npc, currentRoom, w.Rooms[exit.To], dir)
 					break
 				}
 			}
@@ -240,8 +240,8 @@ func TestMoveNPC(t *testing.T) {
 
 	// Find a connected room
 	var toRoomID string
-	for _, exitRoom := range fromRoom.Exits {
-		toRoomID = exitRoom
+	for _, exit := range fromRoom.Exits {
+		toRoomID = exit.To
 		break
 	}
 
@@ -0,0 +1,161 @@
+// Package main builds pkg/game combatants from world players and NPCs, so
+// melee in the world, instances and arenas all fight by the engine's
+// rules (game.Attack). Armor counts from every equipment slot, Awakened
+// players dodge, armed players parry, and weapons attack at their speed.
+//
+// Damage is typed (kinetic, code, EMP, psychic). NPCs resist by kind and
+// players by the armor they wear; EMP shorts out machines, and resistance
+// to an effect's type can ward it off.
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/yourusername/matrix-mud/pkg/effects"
+	"github.com/yourusername/matrix-mud/pkg/game"
+)
+
+const (
+	AwakenedDodge = 10 // Percent chance for an Awakened player to dodge
+	WeaponParry   = 5  // Percent chance to parry with a weapon in hand
+
+	DisabledEffect = "disabled" // Effect EMP puts on machines
+)
+
+// playerCombatant describes a player for the combat engine at now
+func (w *World) playerCombatant(p *Player, now time.Time) game.Combatant {
+	c := game.Combatant{Name: p.Name, Strength: p.Strength}
+	var pieces []int
+	for _, item := range p.Equipment {
+		if item != nil {
+			pieces = append(pieces, item.AC)
+		}
+	}
+	acMod, bonus := p.Effects.Modifiers(now)
+	c.AC = game.TotalAC(p.BaseAC, pieces...) + acMod
+	c.Bonus = bonus
+	if weapon, ok := p.Equipment["hand"]; ok && weapon != nil {
+		c.Damage = weapon.Damage
+		c.DamageType = weapon.DamageType
+		c.Parry = WeaponParry
+	}
+	c.Resist = playerResist(p)
+	if p.Awakened {
+		c.Dodge = AwakenedDodge
+	}
+	return c
+}
+
+// npcCombatant describes an NPC for the combat engine at now
+func (w *World) npcCombatant(npc *NPC, now time.Time) game.Combatant {
+	acMod, bonus := npc.Effects.Modifiers(now)
+	c := game.NPCCombatant(npc.Name, npc.Damage, npc.AC+acMod)
+	c.Bonus = bonus
+	c.DamageType = npc.DamageType
+	c.Resist = npcResist(npc)
+	return c
+}
+
+// npcResist returns an NPC's resistances: its kind's plus its own
+func npcResist(npc *NPC) map[string]int {
+	return game.MergeResist(game.KindResist[npc.Kind], npc.Resist)
+}
+
+// playerResist returns the resistances granted by a player's equipment
+func playerResist(p *Player) map[string]int {
+	var tables []map[string]int
+	for _, item := range p.Equipment {
+		if item != nil {
+			tables = append(tables, item.Resist)
+		}
+	}
+	return game.MergeResist(tables...)
+}
+
+// resistsEffect rolls whether resistance wards off an effect. The chance
+// is the resistance to the effect's damage type; untyped effects always land.
+func (w *World) resistsEffect(def *effects.Def, resist map[string]int) bool {
+	pct := resist[def.Type]
+	return def.Type != "" && pct > 0 && w.rng().Intn(100) < pct
+}
+
+// afflictNPC applies a hostile effect to an NPC unless it resists, and
+// describes the outcome, e.g. "Agent Smith is Feared." or "Agent Smith
+// resists Feared."
+func (w *World) afflictNPC(npc *NPC, def *effects.Def, now time.Time, source string) string {
+	if w.resistsEffect(def, npcResist(npc)) {
+		return fmt.Sprintf("%s resists %s.", npc.Name, def.Name)
+	}
+	npc.Effects.Apply(def, now, source)
+	return fmt.Sprintf("%s is %s.", npc.Name, def.Name)
+}
+
+// disableNPC shuts down a living machine hit by EMP and describes it. It
+// returns "" if the damage type does not disable the NPC.
+func (w *World) disableNPC(npc *NPC, damageType string, now time.Time, source string) string {
+	def := effects.GlobalEffects.Get(DisabledEffect)
+	if def == nil || npc.HP <= 0 || !game.Disables(npc.Kind, damageType) {
+		return ""
+	}
+	npc.Effects.Apply(def, now, source)
+	return fmt.Sprintf("%s shorts out!", npc.Name)
+}
+
+// throwItem hits an NPC with a thrown consumable such as an EMP grenade,
+// dealing the item's Value as damage of its type
+func (w *World) throwItem(p *Player, item *Item, npc *NPC, room *Room) string {
+	now := w.now()
+	damage := game.Resist(item.Value, item.DamageType, npcResist(npc))
+	npc.HP -= damage
+	w.addThreat(npc, p, damage)
+	msg := fmt.Sprintf("You throw %s at %s for %d damage!", ColorizeItem(item), npc.Name, damage)
+	if disabled := w.disableNPC(npc, item.DamageType, now, p.Name); disabled != "" {
+		msg += " " + disabled
+	}
+	if npc.HP <= 0 {
+		msg += "\r\n" + w.killNPC(p, npc, room)
+	}
+	return msg
+}
+
+// weaponSpeed returns the speed of the player's weapon, 0 if unarmed
+func weaponSpeed(p *Player) int {
+	if weapon, ok := p.Equipment["hand"]; ok && weapon != nil {
+		return weapon.Speed
+	}
+	return 0
+}
+
+// attackerOf returns a living NPC in the player's room that is attacking
+// them, or nil
+func (w *World) attackerOf(p *Player) *NPC {
+	room := w.Rooms[p.RoomID]
+	if room == nil {
+		return nil
+	}
+	for _, npc := range room.NPCMap {
+		if npc.CombatTarget == p.Name && !npc.IsDead && npc.HP > 0 {
+			return npc
+		}
+	}
+	return nil
+}
+
+// missMessage describes an attack that did no damage, e.g. "You swing at
+// Agent Smith but miss." or "Agent Smith attacks you but you dodge!"
+func missMessage(result game.CombatResult, attacker, defender string) string {
+	switch {
+	case result.Dodged && defender == "you":
+		return fmt.Sprintf("%s attacks you but you dodge!", attacker)
+	case result.Dodged:
+		return fmt.Sprintf("%s dodges your attack!", defender)
+	case result.Parried && defender == "you":
+		return fmt.Sprintf("%s attacks you but you parry!", attacker)
+	case result.Parried:
+		return fmt.Sprintf("%s parries your attack!", defender)
+	case attacker == "You":
+		return fmt.Sprintf("You swing at %s but miss.", defender)
+	}
+	return fmt.Sprintf("%s attacks %s but misses.", attacker, defender)
+}
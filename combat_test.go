@@ -0,0 +1,151 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourusername/matrix-mud/pkg/effects"
+	"github.com/yourusername/matrix-mud/pkg/game"
+)
+
+func TestPlayerCombatant(t *testing.T) {
+	w, tank, _, clock := setupFight(t, 1)
+	tank.Equipment["body"] = &Item{ID: "coat", AC: 3}
+	tank.Equipment["head"] = &Item{ID: "shades", AC: 1}
+	tank.Equipment["feet"] = &Item{ID: "boots", AC: 2}
+	tank.Equipment["hand"] = &Item{ID: "katana", Damage: 5, Speed: 150}
+	tank.Awakened = true
+	tank.Effects.Apply(effectDef(t, "fortified"), clock.Now(), "")
+
+	c := w.playerCombatant(tank, clock.Now())
+	fortified := effectDef(t, "fortified").AC
+	if c.AC != 10+3+1+2+fortified || c.Damage != 5 || c.Dodge != AwakenedDodge || c.Parry != WeaponParry {
+		t.Errorf("combatant = %+v", c)
+	}
+}
+
+func TestWeaponSpeedAndFlee(t *testing.T) {
+	count := func(speed int) int {
+		w, tank, npcs, clock := setupFight(t, 1)
+		npcs[0].HP, npcs[0].MaxHP = 10000, 10000
+		tank.Equipment["hand"] = &Item{ID: "blade", Damage: 1, Speed: speed}
+		w.StartCombat(tank, "agent_a")
+		for i := 0; i < 60; i++ {
+			clock.Advance(500 * time.Millisecond)
+			w.Update()
+		}
+		out := tank.Conn.conn.(*mockConn).output()
+		return strings.Count(out, "You hit") + strings.Count(out, "CRITICAL HIT") + strings.Count(out, "You swing") + strings.Count(out, "parries your")
+	}
+	if fast, slow := count(150), count(75); fast <= slow {
+		t.Errorf("fast weapon attacked %d times, slow weapon %d", fast, slow)
+	}
+
+	// Fleeing an NPC that is attacking you can fail; anything else stops at once
+	w, tank, npcs, _ := setupFight(t, 1)
+	if got := w.StopCombat(tank); got != "Stopped." {
+		t.Errorf("stop outside combat = %q", got)
+	}
+	fled, blocked := 0, 0
+	for i := 0; i < 20; i++ {
+		w.StartCombat(tank, "agent_a")
+		switch got := w.StopCombat(tank); {
+		case got == "Stopped.":
+			fled++
+		case strings.Contains(got, "Agent A cuts you off!") && tank.State == "COMBAT":
+			blocked++
+		default:
+			t.Fatalf("flee = %q, state %s", got, tank.State)
+		}
+	}
+	if fled == 0 || blocked == 0 {
+		t.Errorf("flee rolls: fled %d, blocked %d", fled, blocked)
+	}
+	if _, ok := npcs[0].Threat["Neo"]; ok {
+		t.Error("fleeing should leave the threat table")
+	}
+}
+
+func TestDamageTypesAndResistance(t *testing.T) {
+	w, tank, npcs, clock := setupFight(t, 1)
+	sentinel := npcs[0]
+	sentinel.Kind = game.KindMachine
+	tank.Equipment["head"] = &Item{ID: "shades", Resist: map[string]int{game.DamagePsychic: 25}}
+	tank.Equipment["body"] = &Item{ID: "vest", Resist: map[string]int{game.DamageKinetic: 10, game.DamagePsychic: 5}}
+	tank.Equipment["hand"] = &Item{ID: "blade", Damage: 5, DamageType: game.DamageCode}
+	if c := w.playerCombatant(tank, clock.Now()); c.DamageType != game.DamageCode || c.Resist[game.DamagePsychic] != 30 || c.Resist[game.DamageKinetic] != 10 {
+		t.Errorf("player combatant = %+v", c)
+	}
+	if c := w.npcCombatant(sentinel, clock.Now()); c.Resist[game.DamageEMP] != -100 {
+		t.Errorf("machine resist = %v", c.Resist)
+	}
+
+	// EMP doubles against machines and shorts them out
+	grenade := &Item{ID: "emp_grenade", Name: "EMP Grenade", Type: "consumable", Effect: "damage", Value: 15, DamageType: game.DamageEMP}
+	tank.Inventory = []*Item{grenade}
+	if got := w.UseItem(tank, "grenade"); !strings.Contains(got, "Attack something first") || len(tank.Inventory) != 1 {
+		t.Errorf("grenade without a target = %q", got)
+	}
+	w.StartCombat(tank, "agent_a")
+	if got := w.UseItem(tank, "grenade"); !strings.Contains(got, "for 30 damage! Agent A shorts out!") {
+		t.Errorf("grenade = %q", got)
+	}
+	if sentinel.HP != 10 || !sentinel.Effects.Controlled(effects.Stun, clock.Now()) {
+		t.Errorf("sentinel HP %d, effects %s", sentinel.HP, sentinel.Effects.String(clock.Now()))
+	}
+
+	// Machines have no mind to frighten
+	if got := w.afflictNPC(sentinel, effectDef(t, "feared"), clock.Now(), "Neo"); got != "Agent A resists Feared." {
+		t.Errorf("fear on a machine = %q", got)
+	}
+	sentinel.Kind = ""
+	if got := w.afflictNPC(sentinel, effectDef(t, "feared"), clock.Now(), "Neo"); got != "Agent A is Feared." {
+		t.Errorf("fear on a human = %q", got)
+	}
+
+	// Code effects tick harder on programs
+	tick := effects.Tick{Def: effectDef(t, "corrupted"), HP: -2, MP: -3}
+	if got := resistTick(tick, game.KindResist[game.KindProgram]); got.HP != -3 || got.MP != -3 {
+		t.Errorf("corruption on a program = %+v", got)
+	}
+
+	sentinel.Kind = game.KindAgent
+	if got := w.Look(tank, "agent a"); !strings.Contains(got, "Kind: agent") || !strings.Contains(got, "Resists: kinetic 25%, weak to code 50%, psychic 50%") {
+		t.Errorf("look npc = %q", got)
+	}
+	tank.Inventory = []*Item{{ID: "vest", Name: "Vest", AC: 3, Resist: map[string]int{game.DamageKinetic: 10}}}
+	if got := w.Look(tank, "vest"); !strings.Contains(got, "Resists: kinetic 10%") {
+		t.Errorf("look item = %q", got)
+	}
+}
+
+func TestLootAffinity(t *testing.T) {
+	w, _, _, _ := setupFight(t, 0)
+	w.ItemTemplates = map[string]*Item{
+		"katana": {ID: "katana", Name: "Katana", Damage: 5, Slot: "hand"},
+		"coat":   {ID: "coat", Name: "Coat", AC: 2, Slot: "body"},
+	}
+	typed, resistant := 0, 0
+	for i := 0; i < 500; i++ {
+		weapon, armor := w.GenerateLoot("katana"), w.GenerateLoot("coat")
+		if weapon.DamageType != "" {
+			typed++
+			if weapon.Rarity < 2 || weapon.DamageType == game.DamageKinetic {
+				t.Fatalf("rarity %d weapon rolled %s", weapon.Rarity, weapon.DamageType)
+			}
+		}
+		for _, pct := range armor.Resist {
+			resistant++
+			if (armor.Rarity == 2 && pct != RareResist) || (armor.Rarity == 3 && pct != LegendaryResist) || armor.Rarity < 2 {
+				t.Fatalf("rarity %d armor rolled %v", armor.Rarity, armor.Resist)
+			}
+		}
+	}
+	if typed == 0 || resistant == 0 {
+		t.Errorf("no affinities in 500 rolls: %d typed weapons, %d resistant armor", typed, resistant)
+	}
+	if w.ItemTemplates["coat"].Resist != nil || w.ItemTemplates["katana"].DamageType != "" {
+		t.Error("GenerateLoot changed the template")
+	}
+}
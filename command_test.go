@@ -301,12 +301,8 @@ func TestTeleport(t *testing.T) {
 		RoomID: "loading_program",
 	}
 
-	result := world.Teleport(player, "dojo")
-	if player.RoomID != "dojo" {
-		t.Errorf("Teleport failed: room=%q, want dojo", player.RoomID)
-	}
-	if result == "" {
-		t.Error("Teleport should return message")
+	if err := world.Teleport(player, "dojo"); err != nil || player.RoomID != "dojo" {
+		t.Errorf("Teleport failed: room=%q, want dojo (%v)", player.RoomID, err)
 	}
 }
 
@@ -319,12 +315,12 @@ func TestTeleportInvalid(t *testing.T) {
 		RoomID: "loading_program",
 	}
 
-	result := world.Teleport(player, "nonexistent_room_xyz")
+	err := world.Teleport(player, "nonexistent_room_xyz")
 	if player.RoomID != "loading_program" {
 		t.Error("Invalid teleport should not change room")
 	}
-	if result == "" {
-		t.Error("Invalid teleport should return message")
+	if err == nil {
+		t.Error("Invalid teleport should return an error")
 	}
 }
 
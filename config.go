@@ -41,28 +41,96 @@ var Config = struct {
 	TelnetPort string
 	WebPort    string
 	AdminPort  string
+	APIPort    string // REST API for external tools and bots
 
 	// Admin credentials - MUST be set via environment in production
 	AdminUser string
 	AdminPass string
 
+	// In-game staff - comma-separated player names allowed to use admin commands
+	AdminPlayers string
+
 	// Security settings
 	AdminBindAddr  string // Default: localhost only
 	AllowedOrigins string // Comma-separated list, or "*" for development
 
+	// Persistence settings
+	SanctionsFile string // Bans, mutes and freezes
+	AuditDBPath   string // SQLite database holding the audit log
+	CopyoverFile  string // Session hand-off file written during a copyover
+	APIKeysFile   string // REST API keys and their scopes
+
+	// Webhook settings
+	WebhooksFile   string // Webhook definitions, editable from the admin console
+	WebhookDLQFile string // Events that exhausted their delivery retries
+
+	// IRC bridge settings; the bridge is off unless IRCServer is set
+	IRCServer   string // host:port
+	IRCTLS      bool   // Connect with TLS
+	IRCNick     string // Bot nickname
+	IRCPassword string // Server password, if required
+	IRCChannels string // Linked channels: global=#construct,trade=#construct-trade
+
+	// Session recording settings
+	RecordingDir       string // asciicast files of recorded sessions
+	RecordingRetention string // Age at which recordings are deleted (30d, perm)
+
+	// Tick watchdog settings
+	TickSlowThreshold    string // Ticks running longer are logged with a phase breakdown
+	TickProfileThreshold string // Lag that triggers automatic profiles ("off" disables)
+	ProfileDir           string // Where automatic profiles are written
+
+	// Simulation settings
+	SimSeed string // Seed for combat, loot and generation rolls; empty picks one at startup
+
+	// Event journal settings
+	EventLogDir       string // Directory of JSONL event segments
+	EventLogRetention string // Age at which old segments are deleted (30d, perm)
+
+	// Death penalty settings
+	DeathXPDebt     string // Percent of a level's XP owed after dying
+	DeathDurability string // Durability each equipped item loses on death
+	CorpseDecay     string // How long a player corpse holds their belongings
+	NewbieLevel     string // Players below this level keep their belongings and owe no XP
+
 	// Logging settings
 	LogLevel  string // debug, info, warn, error
 	LogPretty bool   // true for console, false for JSON
 }{
-	TelnetPort:     getEnv("TELNET_PORT", "2323"),
-	WebPort:        getEnv("WEB_PORT", "8080"),
-	AdminPort:      getEnv("ADMIN_PORT", "9090"),
-	AdminUser:      getEnv("ADMIN_USER", "admin"),
-	AdminPass:      getEnvOrGenerate("ADMIN_PASS"),
-	AdminBindAddr:  getEnv("ADMIN_BIND_ADDR", "127.0.0.1:9090"),
-	AllowedOrigins: getEnv("ALLOWED_ORIGINS", "*"),
-	LogLevel:       getEnv("LOG_LEVEL", "info"),
-	LogPretty:      getEnv("LOG_PRETTY", "true") == "true",
+	TelnetPort:           getEnv("TELNET_PORT", "2323"),
+	WebPort:              getEnv("WEB_PORT", "8080"),
+	AdminPort:            getEnv("ADMIN_PORT", "9090"),
+	APIPort:              getEnv("API_PORT", "8081"),
+	AdminUser:            getEnv("ADMIN_USER", "admin"),
+	AdminPass:            getEnvOrGenerate("ADMIN_PASS"),
+	AdminPlayers:         getEnv("ADMIN_PLAYERS", ""),
+	AdminBindAddr:        getEnv("ADMIN_BIND_ADDR", "127.0.0.1:9090"),
+	AllowedOrigins:       getEnv("ALLOWED_ORIGINS", "*"),
+	SanctionsFile:        getEnv("SANCTIONS_FILE", "data/sanctions.json"),
+	AuditDBPath:          getEnv("AUDIT_DB_PATH", "data/audit.db"),
+	CopyoverFile:         getEnv("COPYOVER_FILE", "data/copyover.json"),
+	APIKeysFile:          getEnv("API_KEYS_FILE", "data/api_keys.json"),
+	WebhooksFile:         getEnv("WEBHOOKS_FILE", "data/webhooks.json"),
+	WebhookDLQFile:       getEnv("WEBHOOK_DLQ_FILE", "data/webhook_dlq.json"),
+	IRCServer:            getEnv("IRC_SERVER", ""),
+	IRCTLS:               getEnv("IRC_TLS", "true") == "true",
+	IRCNick:              getEnv("IRC_NICK", "construct"),
+	IRCPassword:          getEnv("IRC_PASSWORD", ""),
+	IRCChannels:          getEnv("IRC_CHANNELS", "global=#construct"),
+	RecordingDir:         getEnv("RECORDING_DIR", "data/recordings"),
+	RecordingRetention:   getEnv("RECORDING_RETENTION", "30d"),
+	TickSlowThreshold:    getEnv("TICK_SLOW_THRESHOLD", "250ms"),
+	TickProfileThreshold: getEnv("TICK_PROFILE_THRESHOLD", "2s"),
+	ProfileDir:           getEnv("PROFILE_DIR", "data/profiles"),
+	SimSeed:              getEnv("SIM_SEED", ""),
+	EventLogDir:          getEnv("EVENT_LOG_DIR", "data/events"),
+	EventLogRetention:    getEnv("EVENT_LOG_RETENTION", "30d"),
+	DeathXPDebt:          getEnv("DEATH_XP_DEBT", "10"),
+	DeathDurability:      getEnv("DEATH_DURABILITY", "10"),
+	CorpseDecay:          getEnv("CORPSE_DECAY", "30m"),
+	NewbieLevel:          getEnv("NEWBIE_LEVEL", "5"),
+	LogLevel:             getEnv("LOG_LEVEL", "info"),
+	LogPretty:            getEnv("LOG_PRETTY", "true") == "true",
 }
 
 // getEnv retrieves an environment variable or returns the fallback value.
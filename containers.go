@@ -0,0 +1,311 @@
+// Package main handles containers: items that hold other items, such as
+// backpacks, data caches, footlockers fixed in rooms and corpses:
+//
+//	put <item> in <container>    - Store items in a container
+//	get <item> from <container>  - Take items out of a container
+//	look in <container>          - See what a container holds
+//	open/close <container>       - Open or close a container or door
+//	lock/unlock <container>      - Lock or unlock a container or door with its key
+//
+// Containers hold up to Capacity stacks weighing up to MaxWeight, and can
+// be nested. A carried container takes one inventory slot however much it
+// holds, so a backpack adds capacity.
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/yourusername/matrix-mud/pkg/game"
+)
+
+const ContainerType = "container" // Item.Type of a bag, chest or cache
+
+// IsContainer reports whether an item holds other items
+func (i *Item) IsContainer() bool {
+	return i.Type == ContainerType || i.Type == CorpseType
+}
+
+// unitWeight returns the weight of one item, not counting what it holds
+func (i *Item) unitWeight() int {
+	return max(i.Weight, 1)
+}
+
+// contentsWeight returns the weight of everything inside an item
+func (i *Item) contentsWeight() int {
+	total := 0
+	for _, c := range i.Contents {
+		total += c.unitWeight()*c.Count() + c.contentsWeight()
+	}
+	return total
+}
+
+// contains reports whether other is this item or is somewhere inside it
+func (i *Item) contains(other *Item) bool {
+	if i == other {
+		return true
+	}
+	for _, c := range i.Contents {
+		if c.contains(other) {
+			return true
+		}
+	}
+	return false
+}
+
+// refuses returns why a container cannot take n of an item, or ""
+func (i *Item) refuses(item *Item, n int) string {
+	joins := false
+	for _, have := range i.Contents {
+		joins = joins || have.stacksWith(item)
+	}
+	switch {
+	case item.contains(i):
+		return fmt.Sprintf("You can't put the %s inside itself.", item.Name)
+	case i.Capacity > 0 && len(i.Contents) >= i.Capacity && !joins:
+		return fmt.Sprintf("The %s is full.", i.Name)
+	case i.MaxWeight > 0 && i.contentsWeight()+item.unitWeight()*n+item.contentsWeight() > i.MaxWeight:
+		return fmt.Sprintf("The %s can't hold that much weight.", i.Name)
+	}
+	return ""
+}
+
+// findContainer finds a container the player carries, or one in their room
+func (w *World) findContainer(p *Player, name string) *Item {
+	for _, item := range p.Inventory {
+		if item.IsContainer() && item.matches(name) {
+			return item
+		}
+	}
+	if room := w.Rooms[p.RoomID]; room != nil {
+		for _, item := range roomItems(room) {
+			if item.IsContainer() && item.matches(name) {
+				return item
+			}
+		}
+	}
+	return nil
+}
+
+// reachInto returns why a player cannot reach into a container, or ""
+func reachInto(p *Player, c *Item) string {
+	if c.Closed {
+		return fmt.Sprintf("The %s is closed.", c.Name)
+	}
+	if c.Type == CorpseType && c.Owner != "" && !strings.EqualFold(c.Owner, p.Name) {
+		return fmt.Sprintf("That is %s's corpse. Only they can recover it.", c.Owner)
+	}
+	return ""
+}
+
+// findKey returns the key a player carries for a lock, or nil
+func findKey(p *Player, key string) *Item {
+	if key == "" {
+		return nil
+	}
+	for _, item := range p.Inventory {
+		if item.TemplateID() == key {
+			return item
+		}
+	}
+	for _, item := range p.Equipment {
+		if item != nil && item.TemplateID() == key {
+			return item
+		}
+	}
+	return nil
+}
+
+// containerDetails describes what a container holds and how full it is
+func containerDetails(c *Item) string {
+	if c.Closed {
+		if c.Locked {
+			return "It is closed and locked.\r\n"
+		}
+		return "It is closed.\r\n"
+	}
+	desc := "It is empty.\r\n"
+	if len(c.Contents) > 0 {
+		desc = "It holds: " + itemList(c.Contents) + "\r\n"
+	}
+	if c.Capacity > 0 {
+		desc += fmt.Sprintf("Space: %d/%d\r\n", len(c.Contents), c.Capacity)
+	}
+	if c.MaxWeight > 0 {
+		desc += fmt.Sprintf("Weight: %d/%d\r\n", c.contentsWeight(), c.MaxWeight)
+	}
+	return desc
+}
+
+// lookIn describes what a container holds. The world lock must be held.
+func (w *World) lookIn(p *Player, name string) string {
+	c := w.findContainer(p, name)
+	if c == nil {
+		return "You don't see that container."
+	}
+	return fmt.Sprintf("\r\n%s:\r\n%s", ColorizeItem(c), containerDetails(c))
+}
+
+// PutItem puts items the player carries into a container, e.g.
+// "put 5 trash in backpack"
+func (w *World) PutItem(p *Player, itemName, containerName string) string {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	c := w.findContainer(p, containerName)
+	if c == nil {
+		return "You don't see that container."
+	}
+	if c.Type == CorpseType {
+		return "You can't put things in a corpse."
+	}
+	if msg := reachInto(p, c); msg != "" {
+		return msg
+	}
+	items := p.Inventory
+	if _, all, _ := game.ParseQuantity(itemName); all {
+		items = removeItem(append([]*Item(nil), items...), c)
+	}
+	picks, ok := pickItems(items, itemName)
+	if len(picks) == 0 {
+		return "You don't have that."
+	}
+	if !ok {
+		return "You don't have that many."
+	}
+	refused := ""
+	moved := w.moveItems(picks, func(pk pick) bool {
+		refused = c.refuses(pk.item, pk.n)
+		return refused == ""
+	},
+		func(item *Item) { p.Inventory = removeItem(p.Inventory, item) },
+		func(item *Item) { c.Contents = addItem(c.Contents, item) })
+	if len(moved) == 0 {
+		return refused
+	}
+	msg := fmt.Sprintf("You put %s in the %s.", itemList(moved), c.Name)
+	if refused != "" {
+		msg += " " + refused
+	}
+	return msg
+}
+
+// GetFrom takes items out of a container, e.g. "get all from footlocker"
+func (w *World) GetFrom(p *Player, itemName, containerName string) string {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	c := w.findContainer(p, containerName)
+	if c == nil {
+		return "You don't see that container."
+	}
+	if msg := reachInto(p, c); msg != "" {
+		return msg
+	}
+	return w.takeOut(p, c, itemName)
+}
+
+// takeOut moves items from an open container into the player's inventory,
+// as many as fit. An emptied corpse crumbles. The world lock must be held.
+func (w *World) takeOut(p *Player, c *Item, itemName string) string {
+	if len(c.Contents) == 0 {
+		return fmt.Sprintf("The %s is empty.", c.Name)
+	}
+	picks, ok := pickItems(c.Contents, itemName)
+	if len(picks) == 0 {
+		return fmt.Sprintf("The %s doesn't hold that.", c.Name)
+	}
+	if !ok {
+		return fmt.Sprintf("The %s doesn't hold that many.", c.Name)
+	}
+	moved := w.moveItems(picks, func(pk pick) bool { return canCarry(p, pk.item) },
+		func(item *Item) { c.Contents = removeItem(c.Contents, item) },
+		func(item *Item) { p.Inventory = addItem(p.Inventory, item) })
+	if len(moved) == 0 {
+		return fmt.Sprintf("Your inventory is full (max %d items).", MaxInventorySize)
+	}
+	msg := fmt.Sprintf("You take %s from the %s.", itemList(moved), c.Name)
+	if len(moved) < len(picks) {
+		msg += fmt.Sprintf(" Your inventory is full; %d items remain.", len(c.Contents))
+	}
+	if room := w.Rooms[p.RoomID]; c.Type == CorpseType && len(c.Contents) == 0 && room != nil {
+		delete(room.ItemMap, c.ID)
+		msg += fmt.Sprintf(" The %s crumbles away.", c.Name)
+	}
+	return msg
+}
+
+// OpenTarget opens a closed door (see doors.go) or container
+func (w *World) OpenTarget(p *Player, name string) string {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if dir, door := w.findDoor(p, name); door != nil {
+		return w.openDoor(p, dir, door)
+	}
+	c := w.findContainer(p, name)
+	switch {
+	case c == nil:
+		return "You don't see that here."
+	case !c.Closable:
+		return fmt.Sprintf("The %s doesn't open.", c.Name)
+	case !c.Closed:
+		return fmt.Sprintf("The %s is already open.", c.Name)
+	case c.Locked:
+		return fmt.Sprintf("The %s is locked.", c.Name)
+	}
+	c.Closed = false
+	return fmt.Sprintf("You open the %s.", c.Name)
+}
+
+// CloseTarget closes an open door or container
+func (w *World) CloseTarget(p *Player, name string) string {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if dir, door := w.findDoor(p, name); door != nil {
+		return w.closeDoor(p, dir, door)
+	}
+	c := w.findContainer(p, name)
+	switch {
+	case c == nil:
+		return "You don't see that here."
+	case !c.Closable:
+		return fmt.Sprintf("The %s doesn't close.", c.Name)
+	case c.Closed:
+		return fmt.Sprintf("The %s is already closed.", c.Name)
+	}
+	c.Closed = true
+	return fmt.Sprintf("You close the %s.", c.Name)
+}
+
+// LockTarget locks or unlocks a closed door or container with the key it
+// names
+func (w *World) LockTarget(p *Player, name string, lock bool) string {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if dir, door := w.findDoor(p, name); door != nil {
+		return w.lockDoor(p, dir, door, lock)
+	}
+	c := w.findContainer(p, name)
+	if c == nil {
+		return "You don't see that here."
+	}
+	if c.Key == "" {
+		return fmt.Sprintf("The %s has no lock.", c.Name)
+	}
+	if c.Locked == lock {
+		if lock {
+			return fmt.Sprintf("The %s is already locked.", c.Name)
+		}
+		return fmt.Sprintf("The %s is not locked.", c.Name)
+	}
+	if !c.Closed {
+		return fmt.Sprintf("Close the %s first.", c.Name)
+	}
+	key := findKey(p, c.Key)
+	if key == nil {
+		return "You don't have the key."
+	}
+	c.Locked = lock
+	if lock {
+		return fmt.Sprintf("You lock the %s with %s.", c.Name, ColorizeItem(key))
+	}
+	return fmt.Sprintf("You unlock the %s with %s.", c.Name, ColorizeItem(key))
+}
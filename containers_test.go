@@ -0,0 +1,146 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// setupContainers adds a backpack, a locked footlocker in the dojo and its key
+func setupContainers(t *testing.T) (*World, *Player) {
+	t.Helper()
+	w, p := setupItems(t)
+	w.ItemTemplates["backpack"] = &Item{ID: "backpack", Name: "Utility Backpack", Type: ContainerType, Weight: 2, Capacity: 3, MaxWeight: 20, Price: 150}
+	w.ItemTemplates["footlocker"] = &Item{ID: "footlocker", Name: "Armory Footlocker", Type: ContainerType, Fixed: true, Closable: true, Closed: true, Locked: true, Key: "footlocker_key"}
+	w.ItemTemplates["footlocker_key"] = &Item{ID: "footlocker_key", Name: "Footlocker Key", Type: "key"}
+	footlocker := w.newItem(w.ItemTemplates["footlocker"], 1)
+	footlocker.Contents = []*Item{w.newItem(w.ItemTemplates["health_vial"], 2)}
+	w.Rooms["dojo"].ItemMap[footlocker.ID] = footlocker
+	p.Inventory = []*Item{w.newItem(w.ItemTemplates["backpack"], 1), w.newItem(w.ItemTemplates["trash"], 10)}
+	return w, p
+}
+
+func TestPutAndGetFrom(t *testing.T) {
+	w, p := setupContainers(t)
+	bag := p.Inventory[0]
+
+	if got := w.PutItem(p, "5 trash", "backpack"); got != "You put "+White+"Digital Trash (x5)"+Reset+" in the Utility Backpack." {
+		t.Errorf("put 5 trash = %q", got)
+	}
+	w.PutItem(p, "2 trash", "backpack")
+	if len(bag.Contents) != 1 || bag.Contents[0].Count() != 7 || p.Inventory[1].Count() != 3 {
+		t.Errorf("trash should stack inside the bag: %+v", bag.Contents)
+	}
+	if got := w.PutItem(p, "backpack", "backpack"); !strings.Contains(got, "inside itself") {
+		t.Errorf("put a bag in itself = %q", got)
+	}
+	if got := w.Look(p, "in backpack"); !strings.Contains(got, "Digital Trash (x7)") || !strings.Contains(got, "Space: 1/3") || !strings.Contains(got, "Weight: 7/20") {
+		t.Errorf("look in backpack = %q", got)
+	}
+
+	// Capacity and weight limits
+	p.Inventory = append(p.Inventory, w.newItem(w.ItemTemplates["katana"], 1), w.newItem(w.ItemTemplates["phone"], 1))
+	w.PutItem(p, "katana", "backpack")
+	w.PutItem(p, "phone", "backpack")
+	if got := w.PutItem(p, "health", "backpack"); got != "You don't have that." {
+		t.Errorf("put missing item = %q", got)
+	}
+	p.Inventory = append(p.Inventory, w.newItem(w.ItemTemplates["health_vial"], 1))
+	if got := w.PutItem(p, "health", "backpack"); got != "The Utility Backpack is full." {
+		t.Errorf("put into a full bag = %q", got)
+	}
+	w.GetFrom(p, "katana", "backpack")
+	heavy := &Item{ID: "anvil", Name: "Anvil", Weight: 15}
+	p.Inventory = append(p.Inventory, heavy)
+	if got := w.PutItem(p, "anvil", "backpack"); !strings.Contains(got, "can't hold that much weight") {
+		t.Errorf("put an anvil = %q", got)
+	}
+
+	if got := w.GetFrom(p, "all.trash", "backpack"); !strings.Contains(got, "You take") || p.Inventory[1].Count() != 10 {
+		t.Errorf("get all.trash from backpack = %q", got)
+	}
+	if got := w.SellItem(p, "backpack"); got != "Empty the Utility Backpack first." {
+		t.Errorf("sell a full bag = %q", got)
+	}
+
+	// Bags nest, but never inside themselves
+	pouch := &Item{ID: "pouch", Name: "Pouch", Type: ContainerType}
+	bag.Contents = append(bag.Contents, pouch)
+	p.Inventory = append(p.Inventory, &Item{ID: "sack", Name: "Sack", Type: ContainerType, Contents: []*Item{bag}})
+	p.Inventory = removeItem(p.Inventory, bag)
+	if got := w.PutItem(p, "sack", "sack"); !strings.Contains(got, "inside itself") {
+		t.Errorf("put sack in sack = %q", got)
+	}
+}
+
+func TestLockedFootlocker(t *testing.T) {
+	w, p := setupContainers(t)
+
+	if got := w.GetItem(p, "footlocker"); got != "The Armory Footlocker won't budge." {
+		t.Errorf("get footlocker = %q", got)
+	}
+	if got := w.GetFrom(p, "all", "footlocker"); got != "The Armory Footlocker is closed." {
+		t.Errorf("get from closed footlocker = %q", got)
+	}
+	if got := w.OpenTarget(p, "footlocker"); got != "The Armory Footlocker is locked." {
+		t.Errorf("open locked footlocker = %q", got)
+	}
+	if got := w.LockTarget(p, "footlocker", false); got != "You don't have the key." {
+		t.Errorf("unlock without key = %q", got)
+	}
+	p.Inventory = append(p.Inventory, w.newItem(w.ItemTemplates["footlocker_key"], 1))
+	if got := w.LockTarget(p, "footlocker", false); !strings.Contains(got, "You unlock the Armory Footlocker") {
+		t.Errorf("unlock = %q", got)
+	}
+	if got := w.OpenTarget(p, "footlocker"); got != "You open the Armory Footlocker." {
+		t.Errorf("open = %q", got)
+	}
+	if got := w.LockTarget(p, "footlocker", true); got != "Close the Armory Footlocker first." {
+		t.Errorf("lock while open = %q", got)
+	}
+	if got := w.GetFrom(p, "all", "footlocker"); !strings.Contains(got, "Health Vial (x2)") {
+		t.Errorf("get all from footlocker = %q", got)
+	}
+	if got := w.Look(p, "footlocker"); !strings.Contains(got, "It is empty.") {
+		t.Errorf("look footlocker = %q", got)
+	}
+	w.CloseTarget(p, "footlocker")
+	if got := w.LockTarget(p, "footlocker", true); !strings.Contains(got, "You lock") {
+		t.Errorf("lock = %q", got)
+	}
+	if got := w.Look(p, "in footlocker"); !strings.Contains(got, "closed and locked") {
+		t.Errorf("look in locked footlocker = %q", got)
+	}
+}
+
+func TestContainersPersist(t *testing.T) {
+	w, p := setupContainers(t)
+	w.PutItem(p, "all.trash", "backpack")
+	p.Bank = []*Item{{ID: "cache", Name: "Data Cache", Type: ContainerType, Closable: true, Closed: true,
+		Contents: []*Item{{ID: "pill", Name: "Red Pill", Type: "consumable", Quantity: 2}}}}
+	room := &Room{ID: "dojo", Items: roomItems(w.Rooms["dojo"])}
+
+	data, err := json.Marshal(struct {
+		P *Player
+		R *Room
+	}{p, room})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var loaded struct {
+		P *Player
+		R *Room
+	}
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		t.Fatal(err)
+	}
+	if bag := loaded.P.Inventory[0]; len(bag.Contents) != 1 || bag.Contents[0].Count() != 10 {
+		t.Errorf("backpack contents lost: %+v", bag)
+	}
+	if cache := loaded.P.Bank[0]; !cache.Closed || cache.Contents[0].Count() != 2 {
+		t.Errorf("banked cache lost: %+v", cache)
+	}
+	if locker := loaded.R.Items[0]; !locker.Locked || !locker.Fixed || len(locker.Contents) != 1 {
+		t.Errorf("footlocker lost: %+v", locker)
+	}
+}
@@ -7,10 +7,8 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
-	"time"
 
 	"github.com/yourusername/matrix-mud/pkg/dialogue"
-	"github.com/yourusername/matrix-mud/pkg/events"
 	"github.com/yourusername/matrix-mud/pkg/instance"
 	"github.com/yourusername/matrix-mud/pkg/quest"
 )
@@ -127,15 +125,15 @@ func handleDialogueAction(world *World, player *Player, action *dialogue.ChoiceA
 	case "give_item":
 		// Give item to player
 		if item := world.GetItemTemplate(action.Target); item != nil {
-			newItem := *item
-			newItem.ID = fmt.Sprintf("%s_%d", item.ID, len(player.Inventory))
-			player.Inventory = append(player.Inventory, &newItem)
+			player.Inventory = addItem(player.Inventory, world.newItem(item, 1))
 		}
 	case "take_item":
 		// Remove item from player
-		for i, item := range player.Inventory {
-			if item.ID == action.Target || strings.Contains(item.ID, action.Target) {
-				player.Inventory = append(player.Inventory[:i], player.Inventory[i+1:]...)
+		for _, item := range player.Inventory {
+			if item.TemplateID() == action.Target || strings.Contains(item.ID, action.Target) {
+				if world.take(pick{item, 1}) == item {
+					player.Inventory = removeItem(player.Inventory, item)
+				}
 				break
 			}
 		}
@@ -225,9 +223,7 @@ func HandleInstanceCommand(world *World, player *Player, args string) string {
 		}
 		for _, itemID := range rewards.Items {
 			if item := world.GetItemTemplate(itemID); item != nil {
-				newItem := *item
-				newItem.ID = fmt.Sprintf("%s_%d", item.ID, len(player.Inventory))
-				player.Inventory = append(player.Inventory, &newItem)
+				player.Inventory = addItem(player.Inventory, world.newItem(item, 1))
 				sb.WriteString(fmt.Sprintf("  +%s\r\n", item.Name))
 			}
 		}
@@ -277,13 +273,11 @@ func HandleInstanceAttack(world *World, player *Player, target string) (string,
 		return "", false // Not in instance, use normal combat
 	}
 
-	// Calculate player damage
-	damage := player.Strength
-	if player.Equipment != nil && player.Equipment["hand"] != nil {
-		damage += player.Equipment["hand"].Damage
-	}
+	world.mutex.RLock()
+	attacker := world.playerCombatant(player, world.now())
+	world.mutex.RUnlock()
 
-	result, ok := instance.GlobalInstance.AttackInInstance(player.Name, target, damage)
+	result, ok := instance.GlobalInstance.AttackInInstance(world.rng(), player.Name, target, attacker)
 	return result + "\r\n", ok
 }
 
@@ -310,16 +304,7 @@ func IsInInstance(playerName string) bool {
 func checkLevelUp(player *Player) {
 	xpForLevel := player.Level * 100
 	for player.XP >= xpForLevel {
-		player.Level++
-		player.MaxHP += 10
-		player.HP = player.MaxHP
-
-		// Emit level up event
-		events.GlobalEventBus.Publish(&events.Event{
-			Type:       events.EventPlayerLevelUp,
-			Timestamp:  time.Now(),
-			PlayerName: player.Name,
-		})
+		levelUp(player)
 		xpForLevel = player.Level * 100
 	}
 }
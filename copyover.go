@@ -0,0 +1,171 @@
+// Package main implements copyover, a hot reboot that replaces the server
+// binary without dropping telnet connections. The running process saves all
+// state, records each connected session alongside its socket descriptor, and
+// execs the new binary, which inherits the listener and client sockets and
+// re-attaches each one to its player. Copyover is only available on Linux;
+// see copyover_linux.go and copyover_other.go.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+
+	"github.com/yourusername/matrix-mud/pkg/db"
+	"github.com/yourusername/matrix-mud/pkg/logging"
+)
+
+// copyoverEnv names the environment variable that points a freshly exec'd
+// server at the session hand-off file
+const copyoverEnv = "MATRIX_COPYOVER"
+
+// copyoverSession describes one connected player handed to the new process
+type copyoverSession struct {
+	FD     uintptr `json:"fd"`
+	Name   string  `json:"name"`
+	RoomID string  `json:"room_id"`
+	State  string  `json:"state"`
+	Target string  `json:"target,omitempty"`
+}
+
+// copyoverState is the hand-off written before exec and read on startup
+type copyoverState struct {
+	ListenerFD uintptr           `json:"listener_fd"`
+	Sessions   []copyoverSession `json:"sessions"`
+}
+
+// telnetListener is the active telnet listener, inherited across copyovers
+var telnetListener net.Listener
+
+// writeCopyoverState saves the hand-off file
+func writeCopyoverState(path string, state *copyoverState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600) // Owner read/write only
+}
+
+// readCopyoverState loads and removes the hand-off file
+func readCopyoverState(path string) (*copyoverState, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read copyover state: %w", err)
+	}
+	os.Remove(path)
+
+	var state copyoverState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse copyover state: %w", err)
+	}
+	return &state, nil
+}
+
+// listenTelnet opens the telnet listener. After a copyover it adopts the
+// inherited listener instead and returns the sessions to re-attach.
+func listenTelnet(addr string) (net.Listener, *copyoverState, error) {
+	path := os.Getenv(copyoverEnv)
+	if path == "" {
+		l, err := net.Listen("tcp", addr)
+		return l, nil, err
+	}
+	os.Unsetenv(copyoverEnv)
+
+	state, err := readCopyoverState(path)
+	if err != nil {
+		logging.Error().Err(err).Msg("Copyover state unavailable, starting fresh")
+		l, err := net.Listen("tcp", addr)
+		return l, nil, err
+	}
+
+	l, err := inheritListener(state)
+	if err != nil {
+		logging.Error().Err(err).Msg("Inherited listener unusable, starting fresh")
+		l, err := net.Listen("tcp", addr)
+		return l, nil, err
+	}
+	return l, state, nil
+}
+
+// resumeCopyoverSessions re-attaches inherited sockets to their players and
+// starts a command loop for each. Sessions that cannot be restored are closed.
+func resumeCopyoverSessions(ctx context.Context, world *World, state *copyoverState, slots chan struct{}) {
+	if state == nil {
+		return
+	}
+
+	resumed := 0
+	for _, s := range state.Sessions {
+		client, err := inheritClient(s.FD)
+		if err != nil {
+			logging.Error().Err(err).Str("player", s.Name).Msg("Copyover session lost")
+			continue
+		}
+
+		player := world.LoadPlayer(s.Name, client)
+		if _, ok := world.Rooms[s.RoomID]; ok {
+			player.RoomID = s.RoomID
+		}
+		player.State = s.State
+		player.Target = s.Target
+
+		select {
+		case slots <- struct{}{}:
+		default:
+			client.Write("Server full. Please try again later.\r\n")
+			client.conn.Close()
+			continue
+		}
+
+		go func(c *Client, p *Player) {
+			defer func() { <-slots }()
+			defer c.conn.Close()
+			runSession(ctx, c, world, p, true)
+		}(client, player)
+		resumed++
+	}
+
+	logging.Info().Int("sessions", resumed).Int("expected", len(state.Sessions)).Msg("Copyover complete")
+}
+
+// saveForCopyover writes every connected player and the world to disk
+func saveForCopyover(w *World) {
+	w.mutex.RLock()
+	players := make([]*Player, 0, len(w.Players))
+	for _, p := range w.Players {
+		players = append(players, p)
+	}
+	w.mutex.RUnlock()
+
+	for _, p := range players {
+		w.SavePlayer(p)
+	}
+	w.SaveWorld()
+}
+
+// startCopyover audits and performs a copyover on behalf of an admin.
+// It only returns if the copyover failed.
+func startCopyover(w *World, by, ip string) error {
+	recordAudit(by, db.AuditAdminAction, "copyover", ip)
+	logging.Info().Str("by", by).Msg("Copyover initiated")
+
+	if err := copyover(w); err != nil {
+		logging.Error().Err(err).Msg("Copyover failed")
+		recordAudit(by, db.AuditWarning, "copyover failed: "+err.Error(), ip)
+		return err
+	}
+	return nil
+}
+
+// handleCopyoverCommand lets staff trigger a copyover in game
+func handleCopyoverCommand(w *World, admin *Player) string {
+	if !isAdmin(admin) {
+		return "Unknown.\r\n"
+	}
+	if err := startCopyover(w, admin.Name, clientIP(admin.Conn)); err != nil {
+		return fmt.Sprintf("%sCopyover failed: %s%s\r\n", Red, err, Reset)
+	}
+	return ""
+}
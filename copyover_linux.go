@@ -0,0 +1,139 @@
+//go:build linux
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"os"
+	"runtime"
+	"syscall"
+)
+
+// copyoverSupported reports whether this platform can hot reboot
+const copyoverSupported = true
+
+// inheritableFile duplicates a socket's descriptor and clears close-on-exec
+// so it survives exec. The returned file must stay referenced until exec,
+// or its finalizer will close the descriptor.
+func inheritableFile(c interface{ File() (*os.File, error) }) (*os.File, error) {
+	f, err := c.File()
+	if err != nil {
+		return nil, err
+	}
+	if _, _, errno := syscall.Syscall(syscall.SYS_FCNTL, f.Fd(), syscall.F_SETFD, 0); errno != 0 {
+		f.Close()
+		return nil, errno
+	}
+	return f, nil
+}
+
+// exportCopyover prepares the listener and every connected session for
+// inheritance. Players are notified before their socket is handed over.
+func exportCopyover(listener net.Listener, w *World) (*copyoverState, []*os.File, error) {
+	tcpListener, ok := listener.(*net.TCPListener)
+	if !ok {
+		return nil, nil, fmt.Errorf("listener is not TCP")
+	}
+	lf, err := inheritableFile(tcpListener)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to export listener: %w", err)
+	}
+
+	state := &copyoverState{ListenerFD: lf.Fd()}
+	files := []*os.File{lf}
+
+	w.mutex.RLock()
+	defer w.mutex.RUnlock()
+
+	for client, p := range w.Players {
+		tcp, ok := client.conn.(*net.TCPConn)
+		if !ok {
+			continue
+		}
+		f, err := inheritableFile(tcp)
+		if err != nil {
+			client.Write("\r\n" + SystemMsg("The Matrix is reloading. Your session could not be carried over; please reconnect."))
+			continue
+		}
+		files = append(files, f)
+		state.Sessions = append(state.Sessions, copyoverSession{
+			FD:     f.Fd(),
+			Name:   p.Name,
+			RoomID: p.RoomID,
+			State:  p.State,
+			Target: p.Target,
+		})
+		client.Write("\r\n" + SystemMsg("The Matrix is reloading. Hold still..."))
+	}
+	return state, files, nil
+}
+
+// copyover saves all state and execs the current binary with the listener
+// and client sockets inherited. It only returns if exec failed.
+func copyover(w *World) error {
+	if telnetListener == nil {
+		return fmt.Errorf("telnet listener not running")
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("cannot locate server binary: %w", err)
+	}
+
+	saveForCopyover(w)
+
+	state, files, err := exportCopyover(telnetListener, w)
+	if err != nil {
+		return err
+	}
+	closeFiles := func() {
+		// Fd() switched the shared sockets to blocking mode; restore them
+		// for the runtime poller before releasing the duplicates.
+		syscall.SetNonblock(int(state.ListenerFD), true)
+		for _, sess := range state.Sessions {
+			syscall.SetNonblock(int(sess.FD), true)
+		}
+		for _, f := range files {
+			f.Close()
+		}
+	}
+
+	if err := writeCopyoverState(Config.CopyoverFile, state); err != nil {
+		closeFiles()
+		return fmt.Errorf("failed to write copyover state: %w", err)
+	}
+
+	env := append(os.Environ(), copyoverEnv+"="+Config.CopyoverFile)
+	err = syscall.Exec(exe, os.Args, env)
+
+	// Exec only returns on failure
+	runtime.KeepAlive(files)
+	closeFiles()
+	os.Remove(Config.CopyoverFile)
+	return fmt.Errorf("exec failed: %w", err)
+}
+
+// inheritListener adopts the telnet listener from the previous process
+func inheritListener(state *copyoverState) (net.Listener, error) {
+	f := os.NewFile(state.ListenerFD, "telnet-listener")
+	if f == nil {
+		return nil, fmt.Errorf("invalid listener descriptor %d", state.ListenerFD)
+	}
+	defer f.Close() // FileListener holds its own duplicate
+	return net.FileListener(f)
+}
+
+// inheritClient adopts a client socket from the previous process
+func inheritClient(fd uintptr) (*Client, error) {
+	f := os.NewFile(fd, "telnet-client")
+	if f == nil {
+		return nil, fmt.Errorf("invalid client descriptor %d", fd)
+	}
+	defer f.Close() // FileConn holds its own duplicate
+	conn, err := net.FileConn(f)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{conn: conn, reader: bufio.NewReader(conn)}, nil
+}
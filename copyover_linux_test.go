@@ -0,0 +1,85 @@
+//go:build linux
+
+package main
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+// TestCopyoverExportInherit hands a listener and a live client socket through
+// exportCopyover and re-adopts them in process, as the exec'd server would
+func TestCopyoverExportInherit(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	defer listener.Close()
+
+	remote, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer remote.Close()
+	server, err := listener.Accept()
+	if err != nil {
+		t.Fatalf("Accept failed: %v", err)
+	}
+	defer server.Close()
+
+	client := &Client{conn: server, reader: bufio.NewReader(server)}
+	w := &World{Players: map[*Client]*Player{
+		client: {Name: "Neo", RoomID: "dojo", State: "COMBAT", Target: "agent"},
+	}}
+
+	state, files, err := exportCopyover(listener, w)
+	if err != nil {
+		t.Fatalf("exportCopyover failed: %v", err)
+	}
+	if len(state.Sessions) != 1 || state.Sessions[0].Name != "Neo" || state.Sessions[0].State != "COMBAT" {
+		t.Fatalf("Unexpected sessions: %+v", state.Sessions)
+	}
+
+	reader := bufio.NewReader(remote)
+	if line, _ := reader.ReadString('\n'); line == "" {
+		t.Error("Player should be warned before the handover")
+	}
+	reader.ReadString('\n')
+
+	// The exec'd process owns the exported descriptors outright. In process,
+	// the exported files still hold them, so inherit duplicates instead.
+	for _, fd := range []*uintptr{&state.ListenerFD, &state.Sessions[0].FD} {
+		dup, err := syscall.Dup(int(*fd))
+		if err != nil {
+			t.Fatalf("Dup failed: %v", err)
+		}
+		*fd = uintptr(dup)
+	}
+	for _, f := range files {
+		f.Close()
+	}
+
+	inheritedListener, err := inheritListener(state)
+	if err != nil {
+		t.Fatalf("inheritListener failed: %v", err)
+	}
+	defer inheritedListener.Close()
+	if inheritedListener.Addr().String() != listener.Addr().String() {
+		t.Errorf("Inherited listener on %s, want %s", inheritedListener.Addr(), listener.Addr())
+	}
+
+	inherited, err := inheritClient(state.Sessions[0].FD)
+	if err != nil {
+		t.Fatalf("inheritClient failed: %v", err)
+	}
+	defer inherited.conn.Close()
+
+	inherited.Write("reloaded\r\n")
+	line, err := reader.ReadString('\n')
+	if err != nil || !strings.Contains(line, "reloaded") {
+		t.Errorf("Inherited socket should reach the client, got %q (%v)", line, err)
+	}
+}
@@ -0,0 +1,27 @@
+//go:build !linux
+
+package main
+
+import (
+	"fmt"
+	"net"
+	"runtime"
+)
+
+// copyoverSupported reports whether this platform can hot reboot
+const copyoverSupported = false
+
+// copyover is only implemented on Linux
+func copyover(w *World) error {
+	return fmt.Errorf("copyover is not supported on %s", runtime.GOOS)
+}
+
+// inheritListener is only implemented on Linux
+func inheritListener(state *copyoverState) (net.Listener, error) {
+	return nil, fmt.Errorf("copyover is not supported on %s", runtime.GOOS)
+}
+
+// inheritClient is only implemented on Linux
+func inheritClient(fd uintptr) (*Client, error) {
+	return nil, fmt.Errorf("copyover is not supported on %s", runtime.GOOS)
+}
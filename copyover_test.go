@@ -0,0 +1,67 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// TestCopyoverStateRoundTrip verifies the hand-off file is read once and removed
+func TestCopyoverStateRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "copyover.json")
+	state := &copyoverState{
+		ListenerFD: 3,
+		Sessions:   []copyoverSession{{FD: 7, Name: "Neo", RoomID: "dojo", State: "COMBAT", Target: "agent"}},
+	}
+
+	if err := writeCopyoverState(path, state); err != nil {
+		t.Fatalf("writeCopyoverState failed: %v", err)
+	}
+	got, err := readCopyoverState(path)
+	if err != nil {
+		t.Fatalf("readCopyoverState failed: %v", err)
+	}
+	if got.ListenerFD != 3 || len(got.Sessions) != 1 || got.Sessions[0] != state.Sessions[0] {
+		t.Errorf("State mismatch: %+v", got)
+	}
+	if _, err := readCopyoverState(path); err == nil {
+		t.Error("Hand-off file should be removed after reading")
+	}
+}
+
+// TestListenTelnetFresh verifies a normal start opens a new listener
+func TestListenTelnetFresh(t *testing.T) {
+	t.Setenv(copyoverEnv, "")
+
+	l, state, err := listenTelnet("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listenTelnet failed: %v", err)
+	}
+	defer l.Close()
+	if state != nil {
+		t.Error("Fresh start should not return copyover state")
+	}
+}
+
+// TestListenTelnetMissingState verifies a bad hand-off falls back to a fresh listener
+func TestListenTelnetMissingState(t *testing.T) {
+	t.Setenv(copyoverEnv, filepath.Join(t.TempDir(), "missing.json"))
+
+	l, state, err := listenTelnet("127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listenTelnet failed: %v", err)
+	}
+	defer l.Close()
+	if state != nil {
+		t.Error("Missing hand-off should start fresh")
+	}
+}
+
+// TestCopyoverCommandRequiresAdmin verifies players cannot trigger a copyover
+func TestCopyoverCommandRequiresAdmin(t *testing.T) {
+	withModeration(t, "morpheus")
+	w := &World{Players: make(map[*Client]*Player)}
+
+	if got := handleCopyoverCommand(w, &Player{Name: "neo"}); got != "Unknown.\r\n" {
+		t.Errorf("Non-admin should get Unknown, got %q", got)
+	}
+}
@@ -0,0 +1,257 @@
+// Package main handles player and NPC death. A dead player becomes a ghost
+// beside their corpse, which holds everything they carried:
+//
+//	loot [corpse]  - Take what a corpse holds (your own, or an NPC's)
+//	release        - Leave your corpse and return to life at a Zion respawn point
+//
+// Operators can also reboot a ghost where it stands with the Reboot skill.
+// Dying costs XP debt, repaid from half of the XP earned afterwards, and
+// equipment durability. Players below the newbie level keep their
+// belongings and owe no XP. Corpses decay, taking their contents with them.
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yourusername/matrix-mud/pkg/logging"
+)
+
+const (
+	CorpseType      = "corpse"          // Item.Type of a corpse
+	NPCCorpseDecay  = 5 * time.Minute   // How long an NPC corpse lasts
+	DefaultRespawn  = "loading_program" // Respawn point if no room is marked as one
+	XPPerLevel      = 1000              // XP needed per level, see gainXP
+	ResurrectDivide = 2                 // A resurrected player returns with 1/2 HP and MP
+)
+
+// ghostCommands are the commands a ghost may use
+var ghostCommands = map[string]bool{
+	"look": true, "l": true, "score": true, "sc": true,
+	"inv": true, "i": true, "who": true, "time": true,
+	"help": true, "?": true, "quit": true, "release": true,
+	"say": true, "gossip": true, "chat": true, "tell": true, "whisper": true, "t": true,
+	"north": true, "n": true, "south": true, "s": true, "east": true, "e": true,
+	"west": true, "w": true, "up": true, "u": true, "down": true, "dn": true,
+}
+
+// deathSettings is the death penalty, read from Config
+type deathSettings struct {
+	xpDebt      int           // Percent of a level's XP owed
+	durability  int           // Durability lost by each equipped item
+	corpseDecay time.Duration // How long a player corpse lasts
+	newbieLevel int           // Players below this level die lightly
+}
+
+// loadDeathSettings parses the death penalty settings, falling back to the
+// defaults on invalid values
+func loadDeathSettings() deathSettings {
+	number := func(name, value string, fallback int) int {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			logging.Warn().Str(name, value).Int("default", fallback).Msg("Invalid death penalty setting, using default")
+			return fallback
+		}
+		return n
+	}
+	s := deathSettings{
+		xpDebt:      number("DEATH_XP_DEBT", Config.DeathXPDebt, 10),
+		durability:  number("DEATH_DURABILITY", Config.DeathDurability, 10),
+		newbieLevel: number("NEWBIE_LEVEL", Config.NewbieLevel, 5),
+		corpseDecay: 30 * time.Minute,
+	}
+	if d, err := time.ParseDuration(Config.CorpseDecay); err == nil && d > 0 {
+		s.corpseDecay = d
+	} else {
+		logging.Warn().Str("CORPSE_DECAY", Config.CorpseDecay).Msg("Invalid corpse decay, using 30m")
+	}
+	return s
+}
+
+// ghostBlocksCommand returns a message if a ghost cannot use cmd, or ""
+func ghostBlocksCommand(w *World, p *Player, cmd string) string {
+	w.mutex.RLock()
+	defer w.mutex.RUnlock()
+	if !p.Ghost || ghostCommands[cmd] {
+		return ""
+	}
+	return fmt.Sprintf("%sYou are a ghost. Type 'release' to return to life, or wait for an Operator to reboot you.%s\r\n", Cyan, Reset)
+}
+
+// playerDeath turns a player who has died into a ghost beside their corpse,
+// applies the death penalty and returns the message to show them. cause
+// names the killer. The world lock must be held.
+func (w *World) playerDeath(p *Player, cause string) string {
+	w.dropThreat(p)
+	settings := loadDeathSettings()
+	newbie := p.Level < settings.newbieLevel
+	p.HP, p.State, p.Target, p.Ghost = 0, "IDLE", "", true
+	p.Effects.Clear()
+	publishPlayerDeath(p, cause)
+
+	msg := "*** YOU HAVE DIED ***"
+	durability := settings.durability
+	if newbie {
+		durability /= 2
+	}
+	w.degradeEquipment(p, durability)
+	var contents []*Item
+	if newbie {
+		msg += "\r\nYou are new to the Matrix: you keep your belongings and owe no XP."
+	} else {
+		contents, p.Inventory = p.Inventory, make([]*Item, 0)
+		debt := p.Level * XPPerLevel * settings.xpDebt / 100
+		if p.XPDebt+debt > p.Level*XPPerLevel {
+			debt = p.Level*XPPerLevel - p.XPDebt
+		}
+		p.XPDebt += debt
+		msg += fmt.Sprintf("\r\nYou owe %d XP. Half of the XP you earn repays it.", p.XPDebt)
+	}
+	if room := w.Rooms[p.RoomID]; room != nil {
+		corpse := w.makeCorpse(strings.ToLower(p.Name), p.Name, contents, settings.corpseDecay)
+		corpse.Owner = p.Name
+		room.ItemMap[corpse.ID] = corpse
+		w.Broadcast(room.ID, p, fmt.Sprintf("\r\n%s has died.\r\n> ", p.Name))
+		if len(contents) > 0 {
+			msg += fmt.Sprintf("\r\nYour corpse holds your belongings for %s. Recover them with 'loot'.", settings.corpseDecay)
+		}
+	}
+	return msg + "\r\nYou are a ghost. Type 'release' to return to life in Zion, or wait for an Operator to reboot you."
+}
+
+// makeCorpse creates the corpse of name holding contents. id keeps corpse
+// IDs unique in a room.
+func (w *World) makeCorpse(id, name string, contents []*Item, decay time.Duration) *Item {
+	now := w.now()
+	return &Item{
+		ID:          fmt.Sprintf("corpse_%s_%d", id, now.UnixNano()),
+		Name:        "corpse of " + name,
+		Description: fmt.Sprintf("The lifeless shell of %s, flickering as the Matrix reclaims it.", name),
+		Type:        CorpseType,
+		Contents:    contents,
+		Decays:      now.Add(decay),
+	}
+}
+
+// resurrect brings a ghost back to life with part of their HP and MP and
+// returns the message to show them
+func (w *World) resurrect(p *Player) string {
+	p.Ghost = false
+	p.HP = max(1, p.MaxHP/ResurrectDivide)
+	p.MP = p.MaxMP / ResurrectDivide
+	p.State = "IDLE"
+	return fmt.Sprintf("%sYour signal locks on. You are alive again.%s", Green, Reset)
+}
+
+// respawnRoom returns the room released ghosts return to: the first room
+// marked as a respawn point, or the loading program
+func (w *World) respawnRoom() string {
+	var ids []string
+	for id, room := range w.Rooms {
+		if room.Respawn {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return DefaultRespawn
+	}
+	sort.Strings(ids)
+	return ids[0]
+}
+
+// Release returns a ghost to life at the respawn point, leaving their corpse
+// where they fell
+func (w *World) Release(p *Player) string {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if !p.Ghost {
+		return "You are not dead."
+	}
+	from := p.RoomID
+	p.RoomID = w.respawnRoom()
+	msg := "You let go of your corpse. The Zion operators pull your signal home...\r\n" + w.resurrect(p)
+	w.Broadcast(from, p, fmt.Sprintf("\r\nThe ghost of %s fades away.\r\n> ", p.Name))
+	w.playerEntered(p, from)
+	return msg
+}
+
+// Loot takes everything a corpse holds that fits in the player's inventory,
+// like "get all from corpse". With no target it searches the first corpse
+// in the room they may loot. Player corpses may only be looted by their
+// owner; an emptied corpse crumbles.
+func (w *World) Loot(p *Player, target string) string {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	room := w.Rooms[p.RoomID]
+	if room == nil {
+		return "There is no corpse here."
+	}
+	var corpse *Item
+	if target != "" {
+		if item := findItemInMap(room.ItemMap, target); item != nil && item.Type == CorpseType {
+			corpse = item
+		}
+	} else {
+		for _, item := range corpsesIn(room) {
+			if len(item.Contents) > 0 && (item.Owner == "" || strings.EqualFold(item.Owner, p.Name)) {
+				corpse = item
+				break
+			}
+		}
+	}
+	if corpse == nil {
+		return "There is no corpse here to loot."
+	}
+	if msg := reachInto(p, corpse); msg != "" {
+		return msg
+	}
+	return w.takeOut(p, corpse, "all")
+}
+
+// corpsesIn returns the corpses in a room, the soonest to decay first
+func corpsesIn(room *Room) []*Item {
+	var corpses []*Item
+	for _, item := range room.ItemMap {
+		if item.Type == CorpseType {
+			corpses = append(corpses, item)
+		}
+	}
+	sort.Slice(corpses, func(i, j int) bool {
+		if !corpses[i].Decays.Equal(corpses[j].Decays) {
+			return corpses[i].Decays.Before(corpses[j].Decays)
+		}
+		return corpses[i].ID < corpses[j].ID
+	})
+	return corpses
+}
+
+// decayCorpses removes corpses whose time is up, with their contents. The
+// world lock must be held.
+func (w *World) decayCorpses(now time.Time) {
+	for _, room := range w.Rooms {
+		for id, item := range room.ItemMap {
+			if item.Type == CorpseType && !item.Decays.IsZero() && now.After(item.Decays) {
+				delete(room.ItemMap, id)
+				w.Broadcast(room.ID, nil, fmt.Sprintf("\r\nThe %s dissolves into stray code.\r\n> ", item.Name))
+			}
+		}
+	}
+}
+
+// repayXPDebt takes half of an XP gain, up to the debt, to repay a
+// player's XP debt. It returns the XP left to gain and a message, "" if
+// the player owes nothing.
+func repayXPDebt(p *Player, xp int) (int, string) {
+	if p.XPDebt <= 0 || xp <= 0 {
+		return xp, ""
+	}
+	repay := min(max(xp/2, 1), p.XPDebt)
+	p.XPDebt -= repay
+	if p.XPDebt == 0 {
+		return xp - repay, fmt.Sprintf("\r\n%d XP repays your debt. You are clear.", repay)
+	}
+	return xp - repay, fmt.Sprintf("\r\n%d XP repays your debt (%d left).", repay, p.XPDebt)
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPlayerDeathPenalty(t *testing.T) {
+	w, tank, _, _ := setupFight(t, 0)
+	w.Rooms["street"] = &Room{ID: "street", Exits: map[string]*Exit{}, ItemMap: map[string]*Item{}, NPCMap: map[string]*NPC{}, Respawn: true}
+
+	// Newbies keep their belongings and owe nothing
+	tank.Level = 2
+	tank.Inventory = []*Item{{ID: "phone", Name: "Nokia Phone"}}
+	tank.Equipment["hand"] = &Item{ID: "katana", Name: "Katana", Durability: 50, MaxDurability: 50}
+	out := w.playerDeath(tank, "Agent A")
+	if !tank.Ghost || tank.HP != 0 || tank.XPDebt != 0 || len(tank.Inventory) != 1 || tank.Equipment["hand"].Durability != 45 {
+		t.Errorf("newbie death: ghost %v, HP %d, debt %d, inventory %d, durability %d", tank.Ghost, tank.HP, tank.XPDebt, len(tank.Inventory), tank.Equipment["hand"].Durability)
+	}
+	if !strings.Contains(out, "you keep your belongings") {
+		t.Errorf("newbie death = %q", out)
+	}
+	if got := w.Release(tank); !strings.Contains(got, "alive again") || tank.Ghost || tank.RoomID != "street" || tank.HP != tank.MaxHP/2 {
+		t.Errorf("release = %q: ghost %v, room %s, HP %d", got, tank.Ghost, tank.RoomID, tank.HP)
+	}
+
+	// Veterans leave everything on their corpse and owe XP
+	tank.RoomID, tank.Level = "dojo", 6
+	w.Rooms["dojo"].ItemMap = map[string]*Item{}
+	out = w.playerDeath(tank, "Agent A")
+	if tank.XPDebt != 600 || len(tank.Inventory) != 0 || tank.Equipment["hand"].Durability != 35 {
+		t.Errorf("veteran death: debt %d, inventory %d, durability %d", tank.XPDebt, len(tank.Inventory), tank.Equipment["hand"].Durability)
+	}
+	if !strings.Contains(out, "You owe 600 XP.") || !strings.Contains(out, "Recover them with 'loot'.") {
+		t.Errorf("veteran death = %q", out)
+	}
+	if got := ghostBlocksCommand(w, tank, "kill"); !strings.Contains(got, "You are a ghost") {
+		t.Errorf("ghost kill = %q", got)
+	}
+	if got := ghostBlocksCommand(w, tank, "north"); got != "" {
+		t.Errorf("ghost move = %q", got)
+	}
+	if got := w.Look(tank, "corpse"); !strings.Contains(got, "It holds: ") || !strings.Contains(got, "Nokia Phone") {
+		t.Errorf("look corpse = %q", got)
+	}
+
+	// Only the owner can recover a player corpse, and it cannot be carried
+	thief := addFighter(w, "Cypher", "Rebel", 12)
+	if got := w.Loot(thief, "corpse"); got != "That is Neo's corpse. Only they can recover it." {
+		t.Errorf("thief loot = %q", got)
+	}
+	if got := w.GetItem(thief, "corpse"); !strings.Contains(got, "You can't carry") {
+		t.Errorf("get corpse = %q", got)
+	}
+	w.Release(tank)
+	tank.RoomID = "dojo"
+	if got := w.Loot(tank, ""); !strings.Contains(got, "You take") || !strings.Contains(got, "crumbles away") || len(tank.Inventory) != 1 {
+		t.Errorf("loot = %q, inventory %d", got, len(tank.Inventory))
+	}
+	if len(corpsesIn(w.Rooms["dojo"])) != 0 {
+		t.Error("the emptied corpse should be gone")
+	}
+
+	// Half of each XP gain repays the debt
+	if got := w.gainXP(tank, 100); !strings.Contains(got, "50 XP repays your debt (550 left).") || tank.XP != 50 {
+		t.Errorf("gainXP = %q, XP %d", got, tank.XP)
+	}
+}
+
+func TestRebootAndCorpseDecay(t *testing.T) {
+	w, tank, npcs, clock := setupFight(t, 1)
+	trinity := addFighter(w, "Trinity", "Operator", 12)
+	trinity.Skills = map[string]int{"reboot": MaxProficiency}
+
+	if got := w.CastSkill(trinity, "reboot", "neo"); got != "Neo is not dead." {
+		t.Errorf("reboot the living = %q", got)
+	}
+	w.playerDeath(tank, "Agent A")
+	trinity.Skills["patch"] = MaxProficiency
+	if got := w.CastSkill(trinity, "patch", "neo"); !strings.Contains(got, "Neo is a ghost") {
+		t.Errorf("patch a ghost = %q", got)
+	}
+	if got := w.CastSkill(trinity, "reboot", "neo"); !strings.Contains(got, "You reboot Neo's signal.") {
+		t.Fatalf("reboot = %q", got)
+	}
+	if tank.Ghost || tank.RoomID != "dojo" || tank.HP != tank.MaxHP/2 {
+		t.Errorf("rebooted: ghost %v, room %s, HP %d", tank.Ghost, tank.RoomID, tank.HP)
+	}
+	if got := tank.Conn.conn.(*mockConn).output(); !strings.Contains(got, "You are alive again.") {
+		t.Errorf("rebooted player saw %q", got)
+	}
+
+	// NPC corpses hold unshared loot and decay
+	w.ItemTemplates = map[string]*Item{"baton": {ID: "baton", Name: "Police Baton", Slot: "hand", Damage: 3}}
+	npcs[0].Loot = []string{"baton"}
+	w.killNPC(tank, npcs[0], w.Rooms["dojo"])
+	corpses := corpsesIn(w.Rooms["dojo"])
+	if len(corpses) != 2 || corpses[0].Name != "corpse of Agent A" || len(corpses[0].Contents) != 1 {
+		t.Fatalf("corpses = %+v", corpses)
+	}
+	clock.Advance(NPCCorpseDecay + time.Second)
+	w.decayCorpses(clock.Now())
+	if len(corpsesIn(w.Rooms["dojo"])) != 1 {
+		t.Errorf("the NPC corpse should have decayed, the player corpse should remain")
+	}
+}
@@ -0,0 +1,400 @@
+// Package main handles exits between rooms: doors, locks, keys and hidden
+// passages:
+//
+//	open/close <door>    - Open or close a door, by direction or name
+//	lock/unlock <door>   - Lock or unlock a door with its key
+//	pick <door>          - Pick a door's lock (Rebels are best at it)
+//	hack <door>          - Hack a door's lock for MP (Hackers are best at it)
+//	search               - Search the room for hidden exits
+//	edit exit <dir> ...  - Builders: set an exit's door, lock and flags
+//
+// A door stands in an exit and in the exit back the other way, so opening
+// or locking it from one side changes both. One-way exits have no way back.
+// Hidden exits can't be seen or used until a player finds them by searching
+// or with the awakened see_code.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/yourusername/matrix-mud/pkg/game"
+)
+
+// HackCost is the MP spent on each attempt to hack a lock
+const HackCost = 5
+
+// HackHeat is the heat a failed hack draws from the Agents
+const HackHeat = 5
+
+// Exit leads from a room to another. World files may save it as just the
+// room ID it leads to.
+type Exit struct {
+	To     string `json:"to"`
+	Door   string `json:"door,omitempty"` // Name of the door in the way, e.g. "blast door"
+	Closed bool   `json:"closed,omitempty"`
+	Locked bool   `json:"locked,omitempty"`
+	Key    string `json:"key,omitempty"`     // Template ID of the key that locks it
+	Pick   int    `json:"pick,omitempty"`    // Difficulty to pick the lock, 0 = can't be picked
+	Hack   int    `json:"hack,omitempty"`    // Difficulty to hack the lock, 0 = can't be hacked
+	Hidden bool   `json:"hidden,omitempty"`  // Unseen until found by search or see_code
+	OneWay bool   `json:"one_way,omitempty"` // Its door is not shared with an exit back
+}
+
+// MarshalJSON saves an exit with nothing but a destination as its room ID,
+// so worlds without doors keep the old format
+func (e *Exit) MarshalJSON() ([]byte, error) {
+	if *e == (Exit{To: e.To}) {
+		return json.Marshal(e.To)
+	}
+	type exit Exit
+	return json.Marshal((*exit)(e))
+}
+
+// UnmarshalJSON loads an exit saved as a room ID or as an object
+func (e *Exit) UnmarshalJSON(data []byte) error {
+	var to string
+	if err := json.Unmarshal(data, &to); err == nil {
+		*e = Exit{To: to}
+		return nil
+	}
+	type exit Exit
+	return json.Unmarshal(data, (*exit)(e))
+}
+
+// exitKey names an exit in Player.RevealedExits
+func exitKey(roomID, dir string) string {
+	return roomID + ":" + dir
+}
+
+// seesExit reports whether a player can see and use an exit: it isn't
+// hidden, or they have found it
+func seesExit(p *Player, roomID, dir string, e *Exit) bool {
+	return !e.Hidden || slices.Contains(p.RevealedExits, exitKey(roomID, dir))
+}
+
+// visibleExits lists the directions out of a room a player can see, sorted
+func visibleExits(p *Player, room *Room) []string {
+	dirs := make([]string, 0, len(room.Exits))
+	for dir, e := range room.Exits {
+		if seesExit(p, room.ID, dir, e) {
+			dirs = append(dirs, dir)
+		}
+	}
+	sort.Strings(dirs)
+	return dirs
+}
+
+// exitLabel describes an exit in a room's exit list
+func exitLabel(dir string, e *Exit) string {
+	if e.Closed {
+		return fmt.Sprintf("%s: closed %s", dir, e.Door)
+	}
+	return dir
+}
+
+// exitCode describes an exit to see_code: where it leads and its door, lock
+// and flags. found marks a hidden exit just revealed.
+func exitCode(dir string, e *Exit, found bool) string {
+	code := fmt.Sprintf("%s -> %s", dir, e.To)
+	if e.Door != "" {
+		code += fmt.Sprintf(" through a %s, %s", e.Door, doorState(e))
+	}
+	var tags []string
+	if e.Key != "" {
+		tags = append(tags, "key: "+e.Key)
+	}
+	if e.Pick > 0 {
+		tags = append(tags, fmt.Sprintf("pick %d", e.Pick))
+	}
+	if e.Hack > 0 {
+		tags = append(tags, fmt.Sprintf("hack %d", e.Hack))
+	}
+	if e.OneWay {
+		tags = append(tags, "one-way")
+	}
+	if found {
+		tags = append(tags, Yellow+"HIDDEN EXIT REVEALED"+Reset)
+	} else if e.Hidden {
+		tags = append(tags, "hidden")
+	}
+	if len(tags) > 0 {
+		code += " [" + strings.Join(tags, ", ") + "]"
+	}
+	return code
+}
+
+// revealHidden reveals a room's hidden exits to a player and returns the
+// directions they had not found yet
+func revealHidden(p *Player, room *Room) []string {
+	var found []string
+	for dir, e := range room.Exits {
+		if !seesExit(p, room.ID, dir, e) {
+			p.RevealedExits = append(p.RevealedExits, exitKey(room.ID, dir))
+			found = append(found, dir)
+		}
+	}
+	sort.Strings(found)
+	return found
+}
+
+// findDoor finds a door the player can see in their room, by direction or
+// by name. The world lock must be held.
+func (w *World) findDoor(p *Player, name string) (string, *Exit) {
+	room := w.Rooms[p.RoomID]
+	if room == nil || name == "" {
+		return "", nil
+	}
+	for _, dir := range visibleExits(p, room) {
+		e := room.Exits[dir]
+		if e.Door != "" && (dir == name || strings.Contains(strings.ToLower(e.Door), name)) {
+			return dir, e
+		}
+	}
+	return "", nil
+}
+
+// pairedExit returns the exit back through the same door as a room's exit
+// in dir: the reverse direction of the room it leads to, if that leads back.
+// One-way exits have none.
+func (w *World) pairedExit(from *Room, dir string) *Exit {
+	e := from.Exits[dir]
+	if e == nil || e.OneWay {
+		return nil
+	}
+	to := w.Rooms[e.To]
+	if to == nil {
+		return nil
+	}
+	back := to.Exits[getReverseDir(dir)]
+	if back == nil || back.To != from.ID || back.OneWay {
+		return nil
+	}
+	return back
+}
+
+// setDoor changes the door in an exit on both of its sides, and tells the
+// room on the far side what happened to it. The world lock must be held.
+func (w *World) setDoor(room *Room, dir string, change func(*Exit), news string) {
+	e := room.Exits[dir]
+	change(e)
+	if back := w.pairedExit(room, dir); back != nil {
+		change(back)
+		if news != "" {
+			w.Broadcast(e.To, nil, fmt.Sprintf("\r\n%sThe %s %s.%s\r\n> ", White, back.Door, news, Green))
+		}
+	}
+}
+
+// doorState describes whether a door is open, closed or locked
+func doorState(e *Exit) string {
+	switch {
+	case e.Locked:
+		return "closed and locked"
+	case e.Closed:
+		return "closed"
+	}
+	return "open"
+}
+
+// doorDetails describes a door a player looks at
+func doorDetails(dir string, e *Exit) string {
+	return fmt.Sprintf("\r\nA %s leads %s. It is %s.\r\n", e.Door, dir, doorState(e))
+}
+
+// openDoor opens a door. The world lock must be held.
+func (w *World) openDoor(p *Player, dir string, e *Exit) string {
+	switch {
+	case !e.Closed:
+		return fmt.Sprintf("The %s is already open.", e.Door)
+	case e.Locked:
+		return fmt.Sprintf("The %s is locked.", e.Door)
+	}
+	w.setDoor(w.Rooms[p.RoomID], dir, func(e *Exit) { e.Closed = false }, "opens")
+	return fmt.Sprintf("You open the %s.", e.Door)
+}
+
+// closeDoor closes a door. The world lock must be held.
+func (w *World) closeDoor(p *Player, dir string, e *Exit) string {
+	if e.Closed {
+		return fmt.Sprintf("The %s is already closed.", e.Door)
+	}
+	w.setDoor(w.Rooms[p.RoomID], dir, func(e *Exit) { e.Closed = true }, "closes")
+	return fmt.Sprintf("You close the %s.", e.Door)
+}
+
+// lockDoor locks or unlocks a closed door with its key. The world lock must
+// be held.
+func (w *World) lockDoor(p *Player, dir string, e *Exit, lock bool) string {
+	if e.Key == "" && e.Pick == 0 && e.Hack == 0 {
+		return fmt.Sprintf("The %s has no lock.", e.Door)
+	}
+	if e.Locked == lock {
+		if lock {
+			return fmt.Sprintf("The %s is already locked.", e.Door)
+		}
+		return fmt.Sprintf("The %s is not locked.", e.Door)
+	}
+	if !e.Closed {
+		return fmt.Sprintf("Close the %s first.", e.Door)
+	}
+	key := findKey(p, e.Key)
+	if key == nil {
+		return "You don't have the key."
+	}
+	w.setDoor(w.Rooms[p.RoomID], dir, func(e *Exit) { e.Locked = lock }, "clicks")
+	if lock {
+		return fmt.Sprintf("You lock the %s with %s.", e.Door, ColorizeItem(key))
+	}
+	return fmt.Sprintf("You unlock the %s with %s.", e.Door, ColorizeItem(key))
+}
+
+// PickLock tries to pick a locked door's lock. Rebels are best at it.
+func (w *World) PickLock(p *Player, name string) string {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if msg := w.controlBlocked(p, "pick"); msg != "" {
+		return msg
+	}
+	dir, e := w.findDoor(p, name)
+	switch {
+	case e == nil:
+		return "You don't see that door."
+	case !e.Locked:
+		return fmt.Sprintf("The %s is not locked.", e.Door)
+	case e.Pick == 0:
+		return fmt.Sprintf("The lock on the %s can't be picked.", e.Door)
+	}
+	if !game.UnlockCheck(w.rng(), game.LockSkill(p.Level, p.Class == "Rebel"), e.Pick) {
+		return fmt.Sprintf("You fail to pick the lock on the %s.", e.Door)
+	}
+	w.setDoor(w.Rooms[p.RoomID], dir, func(e *Exit) { e.Locked = false }, "clicks")
+	return fmt.Sprintf("You pick the lock on the %s.", e.Door)
+}
+
+// HackLock tries to hack a locked door's electronic lock for HackCost MP.
+// Hackers are best at it, and a failed hack draws the Agents' attention.
+func (w *World) HackLock(p *Player, name string) string {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if msg := w.controlBlocked(p, "cast"); msg != "" {
+		return msg
+	}
+	dir, e := w.findDoor(p, name)
+	switch {
+	case e == nil:
+		return "You don't see that door."
+	case !e.Locked:
+		return fmt.Sprintf("The %s is not locked.", e.Door)
+	case e.Hack == 0:
+		return fmt.Sprintf("The lock on the %s has no code to hack.", e.Door)
+	case p.MP < HackCost:
+		return fmt.Sprintf("You need %d MP to hack a lock.", HackCost)
+	}
+	p.MP -= HackCost
+	if !game.UnlockCheck(w.rng(), game.LockSkill(p.Level, p.Class == "Hacker"), e.Hack) {
+		w.AddHeat(p, HackHeat)
+		return fmt.Sprintf("Your hack on the %s's lock fails, tripping an alarm.", e.Door)
+	}
+	w.setDoor(w.Rooms[p.RoomID], dir, func(e *Exit) { e.Locked = false }, "clicks")
+	return fmt.Sprintf("You rewrite the code of the %s's lock. It unlocks.", e.Door)
+}
+
+// Search looks for hidden exits in the player's room
+func (w *World) Search(p *Player) string {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	room := w.Rooms[p.RoomID]
+	if room == nil {
+		return "There is nothing here to search."
+	}
+	found := revealHidden(p, room)
+	if len(found) == 0 {
+		return "You search the area but find nothing hidden."
+	}
+	return fmt.Sprintf("You find a hidden exit leading %s!", strings.Join(found, ", "))
+}
+
+// isBuilder reports whether a player may edit exits, room flags and
+// environments, and so sees flags in look. These change the rules of play,
+// so only the players listed in ADMIN_PLAYERS hold them.
+func isBuilder(p *Player) bool {
+	return isAdmin(p)
+}
+
+// EditExit sets part of an exit out of the builder's room, e.g.
+// "edit exit north door blast door". Door settings apply to both sides of
+// the door. The world lock must be held.
+func (w *World) EditExit(p *Player, dir, field, value string) string {
+	if !isBuilder(p) {
+		return "Unknown."
+	}
+	room := w.Rooms[p.RoomID]
+	if room == nil {
+		return "You can't do that here."
+	}
+	e := room.Exits[dir]
+	if e == nil {
+		return fmt.Sprintf("There is no exit %s.", dir)
+	}
+	on := value == "on" || value == "yes" || value == "true"
+	switch field {
+	case "door":
+		if value == "" || value == "none" {
+			w.setDoor(room, dir, func(e *Exit) { *e = Exit{To: e.To, Hidden: e.Hidden, OneWay: e.OneWay} }, "")
+			return fmt.Sprintf("Removed the door %s.", dir)
+		}
+		w.setDoor(room, dir, func(e *Exit) { e.Door, e.Closed = value, true }, "")
+		return fmt.Sprintf("Added a closed %s %s.", value, dir)
+	case "state":
+		if e.Door == "" {
+			return fmt.Sprintf("There is no door %s.", dir)
+		}
+		closed, locked := value != "open", value == "locked"
+		if value != "open" && value != "closed" && value != "locked" {
+			return "Usage: edit exit [dir] state [open|closed|locked]"
+		}
+		w.setDoor(room, dir, func(e *Exit) { e.Closed, e.Locked = closed, locked }, "")
+		return fmt.Sprintf("The %s %s is now %s.", e.Door, dir, value)
+	case "key":
+		key := value
+		if key == "none" {
+			key = ""
+		} else if w.ItemTemplates[key] == nil {
+			return fmt.Sprintf("No item template '%s'.", key)
+		}
+		w.setDoor(room, dir, func(e *Exit) { e.Key = key }, "")
+		return fmt.Sprintf("Exit %s key set to '%s'.", dir, key)
+	case "pick", "hack":
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return fmt.Sprintf("Usage: edit exit [dir] %s [difficulty]", field)
+		}
+		w.setDoor(room, dir, func(e *Exit) {
+			if field == "pick" {
+				e.Pick = n
+			} else {
+				e.Hack = n
+			}
+		}, "")
+		return fmt.Sprintf("Exit %s %s difficulty set to %d.", dir, field, n)
+	case "hidden":
+		e.Hidden = on
+		return fmt.Sprintf("Exit %s hidden: %t.", dir, on)
+	case "oneway":
+		back, to := w.pairedExit(room, dir), w.Rooms[e.To]
+		reverse := getReverseDir(dir)
+		if on && back != nil {
+			delete(to.Exits, reverse)
+		} else if !on && to != nil && to.Exits[reverse] == nil {
+			to.Exits[reverse] = &Exit{To: room.ID}
+		}
+		e.OneWay = on
+		return fmt.Sprintf("Exit %s one-way: %t.", dir, on)
+	}
+	return "Usage: edit exit [dir] [door|state|key|pick|hack|hidden|oneway] [value]"
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// setupDoors adds a vault north of the dojo behind a locked door, and a
+// hidden one-way exit down into a cellar
+func setupDoors(t *testing.T) (*World, *Player) {
+	t.Helper()
+	w, p := setupItems(t)
+	w.ItemTemplates["vault_key"] = &Item{ID: "vault_key", Name: "Vault Key", Type: "key"}
+	door := Exit{Door: "vault door", Closed: true, Locked: true, Key: "vault_key", Pick: 1, Hack: 100}
+	north, south := door, door
+	north.To, south.To = "vault", "dojo"
+	w.Rooms["dojo"].Exits["north"] = &north
+	w.Rooms["dojo"].Exits["down"] = &Exit{To: "cellar", Hidden: true, OneWay: true}
+	w.Rooms["vault"] = &Room{ID: "vault", Description: "A vault.", Exits: map[string]*Exit{"south": &south}, ItemMap: map[string]*Item{}, NPCMap: map[string]*NPC{}}
+	w.Rooms["cellar"] = &Room{ID: "cellar", Description: "A cellar.", Exits: map[string]*Exit{}, ItemMap: map[string]*Item{}, NPCMap: map[string]*NPC{}}
+	return w, p
+}
+
+func TestExitJSON(t *testing.T) {
+	var room Room
+	if err := json.Unmarshal([]byte(`{"ID": "dojo", "Exits": {"north": "street", "down": {"to": "cellar", "hidden": true}}}`), &room); err != nil {
+		t.Fatal(err)
+	}
+	if e := room.Exits["north"]; e == nil || *e != (Exit{To: "street"}) {
+		t.Errorf("old-style exit = %+v", e)
+	}
+	if e := room.Exits["down"]; e == nil || e.To != "cellar" || !e.Hidden {
+		t.Errorf("exit object = %+v", e)
+	}
+
+	data, err := json.Marshal(room.Exits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(data); got != `{"down":{"to":"cellar","hidden":true},"north":"street"}` {
+		t.Errorf("saved exits = %s", got)
+	}
+}
+
+func TestDoorsAndKeys(t *testing.T) {
+	w, p := setupDoors(t)
+	back := w.Rooms["vault"].Exits["south"]
+
+	if got := w.MovePlayer(p, "north"); got != "The vault door is closed." || p.RoomID != "dojo" {
+		t.Errorf("walk into a closed door = %q", got)
+	}
+	if got := w.Look(p, ""); !strings.Contains(got, "[north: closed vault door]") {
+		t.Errorf("look should show the closed door: %q", got)
+	}
+	if got := w.OpenTarget(p, "north"); got != "The vault door is locked." {
+		t.Errorf("open a locked door = %q", got)
+	}
+	if got := w.LockTarget(p, "vault", false); got != "You don't have the key." {
+		t.Errorf("unlock without the key = %q", got)
+	}
+
+	p.Inventory = append(p.Inventory, w.newItem(w.ItemTemplates["vault_key"], 1))
+	if got := w.LockTarget(p, "vault", false); !strings.Contains(got, "You unlock the vault door") || back.Locked {
+		t.Errorf("unlock should unlock both sides: %q, far side locked %t", got, back.Locked)
+	}
+	w.OpenTarget(p, "north")
+	if back.Closed {
+		t.Error("opening the door should open it from the vault too")
+	}
+	if got := w.MovePlayer(p, "north"); got != "You move to vault." {
+		t.Errorf("walk through the open door = %q", got)
+	}
+	if got := w.CloseTarget(p, "south"); got != "You close the vault door." || !w.Rooms["dojo"].Exits["north"].Closed {
+		t.Errorf("close from the vault = %q", got)
+	}
+	if got := w.LockTarget(p, "door", true); !strings.Contains(got, "You lock the vault door") || !w.Rooms["dojo"].Exits["north"].Locked {
+		t.Errorf("lock from the vault = %q", got)
+	}
+	if got := w.Look(p, "south"); !strings.Contains(got, "closed and locked") {
+		t.Errorf("look at the door = %q", got)
+	}
+}
+
+func TestPickAndHackLocks(t *testing.T) {
+	w, p := setupDoors(t)
+	door := w.Rooms["dojo"].Exits["north"]
+
+	// Difficulty 100 is beyond any roll at level 4
+	if got := w.HackLock(p, "north"); !strings.Contains(got, "fails") || p.MP != 100-HackCost || !door.Locked {
+		t.Errorf("failed hack = %q, MP %d", got, p.MP)
+	}
+	p.MP = HackCost - 1
+	if got := w.HackLock(p, "north"); !strings.Contains(got, "MP to hack") {
+		t.Errorf("hack without MP = %q", got)
+	}
+
+	// Difficulty 1 always picks
+	if got := w.PickLock(p, "vault door"); got != "You pick the lock on the vault door." || door.Locked || w.Rooms["vault"].Exits["south"].Locked {
+		t.Errorf("pick = %q", got)
+	}
+	if got := w.PickLock(p, "north"); got != "The vault door is not locked." {
+		t.Errorf("pick an unlocked door = %q", got)
+	}
+	door.Locked, door.Pick = true, 0
+	if got := w.PickLock(p, "north"); !strings.Contains(got, "can't be picked") {
+		t.Errorf("pick an unpickable lock = %q", got)
+	}
+}
+
+func TestHiddenExits(t *testing.T) {
+	w, p := setupDoors(t)
+
+	if got := w.Look(p, ""); strings.Contains(got, "[down]") {
+		t.Errorf("hidden exit should not show: %q", got)
+	}
+	if got := w.MovePlayer(p, "down"); got != "No exit." {
+		t.Errorf("walk through an unfound exit = %q", got)
+	}
+	if got := w.Search(p); got != "You find a hidden exit leading down!" {
+		t.Errorf("search = %q", got)
+	}
+	if got := w.Search(p); got != "You search the area but find nothing hidden." {
+		t.Errorf("second search = %q", got)
+	}
+	if got := w.Look(p, ""); !strings.Contains(got, "[down]") {
+		t.Errorf("found exit should show: %q", got)
+	}
+	if got := w.MovePlayer(p, "down"); got != "You move to cellar." {
+		t.Errorf("walk through a found exit = %q", got)
+	}
+
+	// The awakened see hidden exits, and what guards every exit
+	trinity := &Player{Name: "Trinity", RoomID: "dojo", Awakened: true}
+	if got := w.SeeCode(trinity); !strings.Contains(got, "down -> cellar [one-way, ") || !strings.Contains(got, "HIDDEN EXIT REVEALED") ||
+		!strings.Contains(got, "north -> vault through a vault door, closed and locked [key: vault_key, pick 1, hack 100]") {
+		t.Errorf("see_code = %q", got)
+	}
+	if !seesExit(trinity, "dojo", "down", w.Rooms["dojo"].Exits["down"]) {
+		t.Error("see_code should reveal the hidden exit")
+	}
+}
+
+func TestDigAndEditExits(t *testing.T) {
+	w, p := setupDoors(t)
+
+	// Players can't undo a lock by editing the exit
+	withModeration(t, "")
+	if got := w.EditRoom(p, "exit", "north state open"); got != "Unknown." || !w.Rooms["dojo"].Exits["north"].Locked {
+		t.Errorf("edit exit as a player = %q", got)
+	}
+	withModeration(t, "Neo")
+
+	if got := w.Dig(p, "down", "Wine Cellar"); got != "Exit exists." {
+		t.Errorf("dig over a hidden exit = %q", got)
+	}
+	w.Dig(p, "port", "Gun Deck")
+	if e := w.Rooms["gun_deck"].Exits["starboard"]; e == nil || e.To != "dojo" {
+		t.Errorf("dig port should pair with starboard: %+v", w.Rooms["gun_deck"].Exits)
+	}
+
+	if got := w.EditRoom(p, "exit", "port door blast door"); got != "Added a closed blast door port." {
+		t.Errorf("edit exit door = %q", got)
+	}
+	w.EditRoom(p, "exit", "port key vault_key")
+	w.EditRoom(p, "exit", "port state locked")
+	if e := w.Rooms["gun_deck"].Exits["starboard"]; e.Door != "blast door" || !e.Locked || e.Key != "vault_key" {
+		t.Errorf("door edits should apply to both sides: %+v", e)
+	}
+	if got := w.EditRoom(p, "exit", "port key nothing"); got != "No item template 'nothing'." {
+		t.Errorf("edit exit key = %q", got)
+	}
+
+	w.EditRoom(p, "exit", "port oneway on")
+	if _, ok := w.Rooms["gun_deck"].Exits["starboard"]; ok || !w.Rooms["dojo"].Exits["port"].OneWay {
+		t.Error("a one-way exit should have no way back")
+	}
+	w.EditRoom(p, "exit", "port oneway off")
+	if e := w.Rooms["gun_deck"].Exits["starboard"]; e == nil || e.To != "dojo" {
+		t.Error("a two-way exit should lead back")
+	}
+	if got := w.EditRoom(p, "exit", "west door gate"); got != "There is no exit west." {
+		t.Errorf("edit a missing exit = %q", got)
+	}
+}
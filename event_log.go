@@ -0,0 +1,233 @@
+// Package main keeps a durable journal of every event published on the
+// event bus and provides the "events" subcommand for reading it offline:
+//
+//	matrix-mud events tail [-after N] [-n 20] [-f] [-types combat.*] [-player neo] [-room dojo]
+//	matrix-mud events export [-from 2024-01-01] [-to 24h]
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yourusername/matrix-mud/pkg/events"
+	"github.com/yourusername/matrix-mud/pkg/logging"
+)
+
+// eventJournal is the open event journal, or nil if it failed to open
+var eventJournal *events.Journal
+
+// eventTailInterval is how often "events tail -f" polls for new events
+const eventTailInterval = time.Second
+
+// openEventJournal opens the journal from Config and starts recording bus events
+func openEventJournal() {
+	journal, err := newEventJournal(false)
+	if err != nil {
+		logging.Error().Err(err).Str("dir", Config.EventLogDir).Msg("Failed to open event journal")
+		return
+	}
+	journal.Attach(events.GlobalEventBus)
+	eventJournal = journal
+}
+
+// newEventJournal opens the journal configured by EVENT_LOG_DIR and EVENT_LOG_RETENTION.
+// A retention of "perm" keeps every segment.
+func newEventJournal(readOnly bool) (*events.Journal, error) {
+	var retention time.Duration
+	if s := strings.ToLower(strings.TrimSpace(Config.EventLogRetention)); s != "" && s != "perm" {
+		var err error
+		if retention, err = parseEventAge(s); err != nil {
+			return nil, fmt.Errorf("EVENT_LOG_RETENTION: %w", err)
+		}
+	}
+	return events.OpenJournal(events.JournalConfig{
+		Dir:       Config.EventLogDir,
+		Retention: retention,
+		ReadOnly:  readOnly,
+	})
+}
+
+// runEventsCommand implements "matrix-mud events ..." and returns the exit code
+func runEventsCommand(args []string, out, errOut io.Writer) int {
+	usage := "usage: matrix-mud events tail|export [flags]"
+	if len(args) == 0 {
+		fmt.Fprintln(errOut, usage)
+		return 2
+	}
+
+	journal, err := newEventJournal(true)
+	if err != nil {
+		fmt.Fprintln(errOut, err)
+		return 1
+	}
+	defer journal.Close()
+
+	switch args[0] {
+	case "tail":
+		return tailEvents(journal, args[1:], out, errOut)
+	case "export":
+		return exportEvents(journal, args[1:], out, errOut)
+	default:
+		fmt.Fprintln(errOut, usage)
+		return 2
+	}
+}
+
+// tailEvents prints the newest events, optionally following new ones
+func tailEvents(journal *events.Journal, args []string, out, errOut io.Writer) int {
+	fs := flag.NewFlagSet("events tail", flag.ContinueOnError)
+	fs.SetOutput(errOut)
+	after := fs.Uint64("after", 0, "print events after this cursor instead of the last -n")
+	n := fs.Int("n", 20, "number of recent events to print")
+	follow := fs.Bool("f", false, "keep printing new events")
+	types := fs.String("types", "", "comma-separated event types; category.* matches a category")
+	player := fs.String("player", "", "only events for this player")
+	room := fs.String("room", "", "only events in this room")
+	if fs.Parse(args) != nil {
+		return 2
+	}
+
+	filter := events.StreamFilter{Player: *player, Room: *room}
+	for _, t := range strings.Split(*types, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			filter.Types = append(filter.Types, t)
+		}
+	}
+
+	cursor := *after
+	if cursor == 0 && *n > 0 {
+		start, err := recentCursor(journal, *n, filter)
+		if err != nil {
+			fmt.Fprintln(errOut, err)
+			return 1
+		}
+		cursor = start
+	}
+
+	for {
+		page, err := journal.Read(cursor, 1000, filter)
+		if err != nil {
+			fmt.Fprintln(errOut, err)
+			return 1
+		}
+		for _, se := range page {
+			fmt.Fprintln(out, formatJournalEvent(se))
+			cursor = se.Seq
+		}
+		if len(page) == 1000 {
+			continue
+		}
+		if !*follow {
+			return 0
+		}
+		time.Sleep(eventTailInterval)
+	}
+}
+
+// recentCursor returns the cursor just before the last n events matching filter
+func recentCursor(journal *events.Journal, n int, filter events.StreamFilter) (uint64, error) {
+	var recent []uint64
+	err := journal.Range(time.Time{}, time.Time{}, func(se events.StreamEvent) error {
+		if filter.Match(se.Event) {
+			recent = append(recent, se.Seq)
+			if len(recent) > n {
+				recent = recent[1:]
+			}
+		}
+		return nil
+	})
+	if err != nil || len(recent) == 0 {
+		return 0, err
+	}
+	return recent[0] - 1, nil
+}
+
+// exportEvents writes events in a time range as JSON Lines
+func exportEvents(journal *events.Journal, args []string, out, errOut io.Writer) int {
+	fs := flag.NewFlagSet("events export", flag.ContinueOnError)
+	fs.SetOutput(errOut)
+	fromArg := fs.String("from", "", "start time: RFC 3339, YYYY-MM-DD, or an age such as 24h or 7d")
+	toArg := fs.String("to", "", "end time (exclusive), same formats as -from")
+	if fs.Parse(args) != nil {
+		return 2
+	}
+
+	from, err := parseEventTime(*fromArg)
+	if err != nil {
+		fmt.Fprintln(errOut, "-from:", err)
+		return 2
+	}
+	to, err := parseEventTime(*toArg)
+	if err != nil {
+		fmt.Fprintln(errOut, "-to:", err)
+		return 2
+	}
+
+	enc := json.NewEncoder(out)
+	if err := journal.Range(from, to, func(se events.StreamEvent) error {
+		return enc.Encode(se)
+	}); err != nil {
+		fmt.Fprintln(errOut, err)
+		return 1
+	}
+	return 0
+}
+
+// parseEventTime accepts RFC 3339, a date, or an age relative to now.
+// An empty string is the zero time, leaving the range open.
+func parseEventTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", s, time.Local); err == nil {
+		return t, nil
+	}
+	d, err := parseEventAge(s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q", s)
+	}
+	return time.Now().Add(-d), nil
+}
+
+// parseEventAge parses a positive age: a Go duration such as "90m" or "12h",
+// or a whole number of days such as "30d"
+func parseEventAge(s string) (time.Duration, error) {
+	var d time.Duration
+	var err error
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		var n int
+		n, err = strconv.Atoi(days)
+		d = time.Duration(n) * 24 * time.Hour
+	} else {
+		d, err = time.ParseDuration(s)
+	}
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid age %q", s)
+	}
+	return d, nil
+}
+
+// formatJournalEvent renders one event as a line for the terminal
+func formatJournalEvent(se events.StreamEvent) string {
+	e := se.Event
+	line := fmt.Sprintf("%d %s %-20s", se.Seq, e.Timestamp.Format("2006-01-02 15:04:05"), e.Type)
+	if e.PlayerName != "" {
+		line += " player=" + e.PlayerName
+	}
+	if e.RoomID != "" {
+		line += " room=" + e.RoomID
+	}
+	if len(e.Data) > 0 {
+		data, _ := json.Marshal(e.Data)
+		line += " " + string(data)
+	}
+	return line
+}
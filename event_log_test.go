@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourusername/matrix-mud/pkg/events"
+)
+
+// withEventLog points the journal at a temp directory holding the given events
+func withEventLog(t *testing.T, evs ...*events.Event) {
+	t.Helper()
+	origDir, origRetention := Config.EventLogDir, Config.EventLogRetention
+	t.Cleanup(func() { Config.EventLogDir, Config.EventLogRetention = origDir, origRetention })
+	Config.EventLogDir = t.TempDir()
+	Config.EventLogRetention = "perm"
+
+	journal, err := newEventJournal(false)
+	if err != nil {
+		t.Fatalf("newEventJournal failed: %v", err)
+	}
+	defer journal.Close()
+	for _, e := range evs {
+		journal.Append(e)
+	}
+}
+
+// TestEventsTailCommand verifies tail prints the last matching events
+func TestEventsTailCommand(t *testing.T) {
+	withEventLog(t,
+		events.NewEvent(events.EventNPCKill).WithPlayer("Neo", 0),
+		events.NewEvent(events.EventPlayerJoin).WithPlayer("Trinity", 0),
+		events.NewEvent(events.EventNPCKill).WithPlayer("Trinity", 0).WithData("npc_name", "Agent Smith"),
+		events.NewEvent(events.EventNPCKill).WithPlayer("Neo", 0).WithRoom("dojo"),
+	)
+
+	var out, errOut bytes.Buffer
+	if code := runEventsCommand([]string{"tail", "-n", "2", "-types", "combat.*"}, &out, &errOut); code != 0 {
+		t.Fatalf("tail exited %d: %s", code, errOut.String())
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "3 ") || !strings.Contains(lines[0], `"npc_name":"Agent Smith"`) ||
+		!strings.Contains(lines[1], "room=dojo") {
+		t.Errorf("Unexpected tail output:\n%s", out.String())
+	}
+
+	out.Reset()
+	runEventsCommand([]string{"tail", "-after", "1", "-player", "trinity"}, &out, &errOut)
+	if got := strings.Count(out.String(), "player=Trinity"); got != 2 {
+		t.Errorf("Expected 2 Trinity events after cursor 1, got:\n%s", out.String())
+	}
+}
+
+// TestEventsExportCommand verifies export writes a time range as JSON Lines
+func TestEventsExportCommand(t *testing.T) {
+	old := events.NewEvent(events.EventAuctionSold)
+	old.Timestamp = time.Now().Add(-48 * time.Hour)
+	withEventLog(t, old, events.NewEvent(events.EventAuctionSold), events.NewEvent(events.EventPlayerLevelUp))
+
+	var out, errOut bytes.Buffer
+	if code := runEventsCommand([]string{"export", "-from", "24h"}, &out, &errOut); code != 0 {
+		t.Fatalf("export exited %d: %s", code, errOut.String())
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], `{"seq":2,`) {
+		t.Errorf("Unexpected export output:\n%s", out.String())
+	}
+
+	if code := runEventsCommand([]string{"export", "-from", "yesterday"}, &out, &errOut); code != 2 {
+		t.Errorf("Invalid time should exit 2, got %d", code)
+	}
+	if code := runEventsCommand(nil, &out, &errOut); code != 2 {
+		t.Errorf("Missing subcommand should exit 2, got %d", code)
+	}
+}
+
+// TestParseEventAge verifies ages in Go units and whole days
+func TestParseEventAge(t *testing.T) {
+	for s, want := range map[string]time.Duration{"90m": 90 * time.Minute, "12h": 12 * time.Hour, "30d": 30 * 24 * time.Hour} {
+		if got, err := parseEventAge(s); err != nil || got != want {
+			t.Errorf("parseEventAge(%q) = %v, %v; want %v", s, got, err, want)
+		}
+	}
+	for _, s := range []string{"", "perm", "0d", "-1h", "1.5d", "soon"} {
+		if _, err := parseEventAge(s); err == nil {
+			t.Errorf("parseEventAge(%q) should fail", s)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+// Package main publishes notable game moments to the event bus, which feeds
+// the live event stream and external integrations.
+package main
+
+import (
+	"github.com/yourusername/matrix-mud/pkg/achievements"
+	"github.com/yourusername/matrix-mud/pkg/events"
+	"github.com/yourusername/matrix-mud/pkg/trade"
+)
+
+// publishPlayerJoin announces a player entering the Matrix
+func publishPlayerJoin(p *Player) {
+	events.Publish(events.NewEvent(events.EventPlayerJoin).
+		WithPlayer(p.Name, 0).
+		WithRoom(p.RoomID).
+		WithData("level", p.Level).
+		WithData("class", p.Class))
+}
+
+// publishPlayerLeave announces a player leaving the Matrix
+func publishPlayerLeave(p *Player) {
+	events.Publish(events.NewEvent(events.EventPlayerLeave).
+		WithPlayer(p.Name, 0).
+		WithRoom(p.RoomID))
+}
+
+// publishLevelUp announces a player reaching a new level
+func publishLevelUp(p *Player) {
+	events.Publish(events.NewEvent(events.EventPlayerLevelUp).
+		WithPlayer(p.Name, 0).
+		WithRoom(p.RoomID).
+		WithData("level", p.Level))
+}
+
+// publishNPCKill announces a player killing an NPC
+func publishNPCKill(p *Player, npc *NPC) {
+	events.Publish(events.NewEvent(events.EventNPCKill).
+		WithPlayer(p.Name, 0).
+		WithRoom(p.RoomID).
+		WithData("npc_id", npc.ID).
+		WithData("npc_name", npc.Name).
+		WithData("xp", npc.XP))
+}
+
+// publishPlayerDeath announces a player dying; cause names the killer
+func publishPlayerDeath(p *Player, cause string) {
+	events.Publish(events.NewEvent(events.EventPlayerDeath).
+		WithPlayer(p.Name, 0).
+		WithRoom(p.RoomID).
+		WithData("level", p.Level).
+		WithData("cause", cause))
+}
+
+// publishAchievement announces an unlocked achievement
+func publishAchievement(p *Player, ach *achievements.Achievement) {
+	events.Publish(events.NewEvent(events.EventAchievement).
+		WithPlayer(p.Name, 0).
+		WithRoom(p.RoomID).
+		WithData("achievement_id", string(ach.ID)).
+		WithData("achievement", ach.Name).
+		WithData("points", ach.Points))
+}
+
+// publishAuctionSale announces a completed auction
+func publishAuctionSale(listing *trade.AuctionListing) {
+	if listing == nil {
+		return
+	}
+	events.Publish(events.NewEvent(events.EventAuctionSold).
+		WithPlayer(listing.CurrentBidder, 0).
+		WithData("listing_id", listing.ID).
+		WithData("item", listing.ItemName).
+		WithData("quantity", listing.Quantity).
+		WithData("price", listing.CurrentBid).
+		WithData("seller", listing.SellerName))
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/yourusername/matrix-mud/pkg/events"
+	"github.com/yourusername/matrix-mud/pkg/trade"
+)
+
+// TestGameEventsReachStream verifies published game moments reach stream subscribers
+func TestGameEventsReachStream(t *testing.T) {
+	events.GlobalEventBus.Start()
+	events.GlobalStream.Attach(events.GlobalEventBus)
+	sub := events.GlobalStream.Subscribe(events.StreamFilter{Player: "Neo"}, 0, 10)
+	defer sub.Close()
+
+	p := &Player{Name: "Neo", RoomID: "dojo", Level: 2}
+	publishNPCKill(p, &NPC{ID: "agent_smith", Name: "Agent Smith", XP: 100})
+	publishLevelUp(p)
+	publishAuctionSale(&trade.AuctionListing{ID: "a1", ItemName: "Katana", CurrentBidder: "Neo", CurrentBid: 500})
+
+	want := []events.EventType{events.EventNPCKill, events.EventPlayerLevelUp, events.EventAuctionSold}
+	got := map[events.EventType]*events.Event{}
+	for len(got) < len(want) {
+		select {
+		case se := <-sub.C:
+			got[se.Event.Type] = se.Event
+		case <-time.After(time.Second):
+			t.Fatalf("Only received %d of %d events", len(got), len(want))
+		}
+	}
+
+	if kill := got[events.EventNPCKill]; kill.RoomID != "dojo" || kill.Data["npc_name"] != "Agent Smith" {
+		t.Errorf("Kill event = %+v", kill)
+	}
+	if sale := got[events.EventAuctionSold]; sale.Data["price"] != 500 {
+		t.Errorf("Sale event = %+v", sale)
+	}
+}
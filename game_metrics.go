@@ -0,0 +1,70 @@
+// Package main feeds game state into the Prometheus metrics served on
+// /metrics: players per room and area, instances, arenas, auctions, money,
+// and event bus queue wait.
+package main
+
+import (
+	"strings"
+	"time"
+
+	"github.com/yourusername/matrix-mud/pkg/events"
+	"github.com/yourusername/matrix-mud/pkg/help"
+	"github.com/yourusername/matrix-mud/pkg/instance"
+	"github.com/yourusername/matrix-mud/pkg/metrics"
+	"github.com/yourusername/matrix-mud/pkg/pvp"
+	"github.com/yourusername/matrix-mud/pkg/trade"
+)
+
+// registerGameMetrics starts collecting world gauges on each scrape
+func registerGameMetrics(w *World) {
+	events.GlobalEventBus.ObserveQueueWait(metrics.ObserveEventQueueWait)
+	metrics.RegisterCollector(func() { collectGameMetrics(w) })
+}
+
+// collectGameMetrics refreshes the gauges derived from world state
+func collectGameMetrics(w *World) {
+	rooms := make(map[string]float64)
+	areas := make(map[string]float64)
+	var money int64
+
+	w.mutex.RLock()
+	for _, p := range w.Players {
+		if p == nil {
+			continue
+		}
+		rooms[p.RoomID]++
+		areas[roomArea(p.RoomID)]++
+		money += int64(p.Money)
+	}
+	w.mutex.RUnlock()
+
+	metrics.SetPlayerLocations(rooms, areas)
+	metrics.SetActivity(
+		int64(instance.GlobalInstance.ActiveCount()),
+		int64(pvp.GlobalPvP.ActiveArenas()),
+		int64(trade.GlobalTrade.ActiveListings()),
+		money,
+	)
+}
+
+// roomArea groups rooms by the prefix of their ID, so "zion_docks" and
+// "zion_temple" are both in "zion" and generated "city_<seed>_x_y" blocks
+// are all in "city"
+func roomArea(roomID string) string {
+	area, _, _ := strings.Cut(roomID, "_")
+	return area
+}
+
+// commandLabel maps typed input to its canonical command for the latency
+// histogram. Unknown commands share one label so typos cannot create series.
+func commandLabel(cmd string) string {
+	if entry := help.GetHelp(cmd); entry != nil {
+		return entry.Command
+	}
+	return metrics.OtherLabel
+}
+
+// observeSave records a save duration; use as defer observeSave(kind, time.Now())
+func observeSave(kind string, start time.Time) {
+	metrics.ObserveSave(kind, time.Since(start))
+}
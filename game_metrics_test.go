@@ -0,0 +1,54 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/yourusername/matrix-mud/pkg/metrics"
+)
+
+func TestRoomArea(t *testing.T) {
+	cases := map[string]string{
+		"zion_docks":      "zion",
+		"city_42_3_7":     "city",
+		"loading_program": "loading",
+		"dojo":            "dojo",
+	}
+	for room, want := range cases {
+		if got := roomArea(room); got != want {
+			t.Errorf("roomArea(%q) = %q, want %q", room, got, want)
+		}
+	}
+}
+
+func TestCommandLabel(t *testing.T) {
+	if got := commandLabel("look"); got != "look" {
+		t.Errorf("commandLabel(look) = %q", got)
+	}
+	if got := commandLabel("xyzzy-not-a-command"); got != metrics.OtherLabel {
+		t.Errorf("unknown command label = %q, want %q", got, metrics.OtherLabel)
+	}
+}
+
+func TestCollectGameMetrics(t *testing.T) {
+	w := &World{Players: map[*Client]*Player{
+		{}: {Name: "Neo", RoomID: "zion_docks", Money: 100},
+		{}: {Name: "Trinity", RoomID: "zion_docks", Money: 50},
+		{}: {Name: "Morpheus", RoomID: "zion_temple", Money: 25},
+		{}: {Name: "Tank", RoomID: "dojo"},
+	}}
+
+	collectGameMetrics(w)
+
+	if got := metrics.RoomPlayers.Get("zion_docks"); got != 2 {
+		t.Errorf("players in zion_docks = %v, want 2", got)
+	}
+	if got := metrics.AreaPlayers.Get("zion"); got != 3 {
+		t.Errorf("players in zion = %v, want 3", got)
+	}
+	if got := metrics.AreaPlayers.Get("dojo"); got != 1 {
+		t.Errorf("players in dojo = %v, want 1", got)
+	}
+	if got := metrics.M.MoneyInCirculation; got != 175 {
+		t.Errorf("money in circulation = %d, want 175", got)
+	}
+}
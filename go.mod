@@ -4,12 +4,14 @@ go 1.24.0
 
 require github.com/gorilla/websocket v1.5.3
 
-require golang.org/x/crypto v0.45.0
+require (
+	github.com/mattn/go-sqlite3 v1.14.32
+	github.com/rs/zerolog v1.34.0
+	golang.org/x/crypto v0.45.0
+)
 
 require (
 	github.com/mattn/go-colorable v0.1.13 // indirect
 	github.com/mattn/go-isatty v0.0.19 // indirect
-	github.com/mattn/go-sqlite3 v1.14.32 // indirect
-	github.com/rs/zerolog v1.34.0 // indirect
 	golang.org/x/sys v0.38.0 // indirect
 )
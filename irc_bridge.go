@@ -0,0 +1,94 @@
+// Package main links chat channels to IRC when IRC_SERVER is set. Players'
+// messages on linked channels are relayed to IRC as "<Name> text", and IRC
+// messages appear in game as "[IRC] nick". Staff silence an IRC user with
+// "mute irc:<nick>", or a channel moderator can mute "[IRC] <nick>".
+package main
+
+import (
+	"html"
+	"strings"
+
+	"github.com/yourusername/matrix-mud/pkg/chat"
+	"github.com/yourusername/matrix-mud/pkg/irc"
+	"github.com/yourusername/matrix-mud/pkg/logging"
+	"github.com/yourusername/matrix-mud/pkg/moderation"
+)
+
+// ircBridge is the running IRC bridge, or nil if IRC is not configured
+var ircBridge *irc.Bridge
+
+// startIRCBridge connects the configured chat channels to IRC
+func startIRCBridge(w *World) {
+	if Config.IRCServer == "" {
+		return
+	}
+	channels, err := irc.ParseChannels(Config.IRCChannels)
+	if err != nil {
+		logging.Error().Err(err).Msg("IRC bridge disabled: bad IRC_CHANNELS")
+		return
+	}
+	for id := range channels {
+		if chat.GlobalChat.GetChannel(id) == nil {
+			logging.Error().Str("channel", id).Msg("IRC bridge disabled: unknown chat channel in IRC_CHANNELS")
+			return
+		}
+	}
+	if len(channels) == 0 {
+		logging.Warn().Msg("IRC bridge disabled: IRC_CHANNELS is empty")
+		return
+	}
+
+	bridge := irc.NewBridge(irc.Config{
+		Server:   Config.IRCServer,
+		TLS:      Config.IRCTLS,
+		Nick:     Config.IRCNick,
+		Password: Config.IRCPassword,
+		Channels: channels,
+	}, func(m irc.Message) {
+		relayFromIRC(w, m)
+	})
+	chat.GlobalChat.OnMessage(func(m chat.Message) {
+		bridge.Relay(m.Channel, m.Sender, m.Content)
+	})
+	bridge.Start()
+	ircBridge = bridge
+	logging.Info().Str("server", Config.IRCServer).Str("channels", Config.IRCChannels).Msg("IRC bridge started")
+}
+
+// ircSender is how an IRC user is named in game chat
+func ircSender(nick string) string {
+	return "[IRC] " + nick
+}
+
+// relayFromIRC delivers an IRC message to the linked channel's members,
+// unless the nick is muted server-wide or in that channel
+func relayFromIRC(w *World, m irc.Message) {
+	if moderation.GlobalModeration.IsMuted("irc:"+m.Nick) != nil {
+		return
+	}
+	msg, recipients, err := chat.GlobalChat.RelayMessage(ircSender(m.Nick), m.Channel, m.Text)
+	if err != nil {
+		return
+	}
+	channel := chat.GlobalChat.GetChannel(m.Channel)
+	broadcastChatMessage(w, chat.FormatMessage(msg, channel.Name), recipients)
+}
+
+// stopIRCBridge disconnects from IRC during shutdown
+func stopIRCBridge() {
+	if ircBridge != nil {
+		ircBridge.Stop()
+	}
+}
+
+// ircStatus describes the bridge for the admin dashboard, HTML-escaped
+func ircStatus() string {
+	switch {
+	case ircBridge == nil:
+		return "off"
+	case ircBridge.Connected():
+		return html.EscapeString("connected to " + Config.IRCServer + " (" + strings.ReplaceAll(Config.IRCChannels, ",", ", ") + ")")
+	default:
+		return html.EscapeString("reconnecting to " + Config.IRCServer)
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourusername/matrix-mud/pkg/chat"
+	"github.com/yourusername/matrix-mud/pkg/irc"
+	"github.com/yourusername/matrix-mud/pkg/moderation"
+)
+
+// setupIRCWorld swaps in a fresh chat manager and a world with Neo online
+// in the global channel
+func setupIRCWorld(t *testing.T) (*World, *mockConn) {
+	t.Helper()
+	withModeration(t, "")
+	origChat := chat.GlobalChat
+	chat.GlobalChat = chat.NewManager()
+	t.Cleanup(func() { chat.GlobalChat = origChat })
+
+	conn := newMockConn("")
+	client := &Client{conn: conn, reader: bufio.NewReader(conn)}
+	p := &Player{Name: "Neo", RoomID: "dojo", Conn: client}
+	chat.GlobalChat.JoinChannel("Neo", "global")
+	return &World{Players: map[*Client]*Player{client: p}}, conn
+}
+
+// TestRelayFromIRC verifies IRC messages reach channel members filtered and
+// that muted nicks are dropped
+func TestRelayFromIRC(t *testing.T) {
+	w, conn := setupIRCWorld(t)
+
+	relayFromIRC(w, irc.Message{Channel: "global", Nick: "tank", Text: "holy shit it works"})
+	if out := conn.output(); !strings.Contains(out, "[Global] [IRC] tank: holy **** it works") {
+		t.Errorf("Output = %q", out)
+	}
+
+	moderation.GlobalModeration.Add(moderation.SanctionMute, moderation.TargetAccount, "irc:tank", "", "test", 0)
+	before := conn.output()
+	relayFromIRC(w, irc.Message{Channel: "global", Nick: "Tank", Text: "still here"})
+	if conn.output() != before {
+		t.Error("Muted IRC nick should not be relayed")
+	}
+
+	relayFromIRC(w, irc.Message{Channel: "global", Nick: "dozer", Text: "hi"})
+	chat.GlobalChat.AddModerator("global", "Morpheus")
+	if err := chat.GlobalChat.MutePlayer("Morpheus", ircSender("dozer"), "global", time.Minute); err != nil {
+		t.Fatalf("Channel mute of IRC user failed: %v", err)
+	}
+	before = conn.output()
+	relayFromIRC(w, irc.Message{Channel: "global", Nick: "dozer", Text: "hello?"})
+	if conn.output() != before {
+		t.Error("Channel-muted IRC nick should not be relayed")
+	}
+}
+
+// TestIRCBridgeEndToEnd links the global channel to a fake IRC server
+func TestIRCBridgeEndToEnd(t *testing.T) {
+	w, conn := setupIRCWorld(t)
+	chat.GlobalChat.JoinChannel("Trinity", "global")
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	origBridge := ircBridge
+	origServer, origTLS, origChannels := Config.IRCServer, Config.IRCTLS, Config.IRCChannels
+	Config.IRCServer, Config.IRCTLS, Config.IRCChannels = ln.Addr().String(), false, "global=#construct"
+	t.Cleanup(func() {
+		stopIRCBridge()
+		ircBridge = origBridge
+		Config.IRCServer, Config.IRCTLS, Config.IRCChannels = origServer, origTLS, origChannels
+	})
+
+	startIRCBridge(w)
+	if ircBridge == nil {
+		t.Fatal("Bridge not started")
+	}
+
+	ln.(*net.TCPListener).SetDeadline(time.Now().Add(3 * time.Second))
+	server, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+	server.SetReadDeadline(time.Now().Add(3 * time.Second))
+	reader := bufio.NewReader(server)
+	expect := func(prefix string) string {
+		t.Helper()
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				t.Fatalf("waiting for %q: %v", prefix, err)
+			}
+			if strings.HasPrefix(line, prefix) {
+				return strings.TrimSpace(line)
+			}
+		}
+	}
+
+	expect("USER")
+	fmt.Fprintf(server, ":irc.test 001 %s :Welcome\r\n", Config.IRCNick)
+	expect("JOIN #construct")
+
+	// Game to IRC
+	if _, err := chat.GlobalChat.SendMessage("Trinity", "global", "dodge this"); err != nil {
+		t.Fatal(err)
+	}
+	if line := expect("PRIVMSG"); line != "PRIVMSG #construct :<Trinity> dodge this" {
+		t.Errorf("Relayed line = %q", line)
+	}
+
+	// IRC to game
+	fmt.Fprintf(server, ":tank!t@zion PRIVMSG #construct :operator here\r\n")
+	// The bridge writes to the connection under the world lock
+	output := func() string {
+		w.mutex.Lock()
+		defer w.mutex.Unlock()
+		return conn.output()
+	}
+	deadline := time.Now().Add(3 * time.Second)
+	for !strings.Contains(output(), "[IRC] tank: operator here") {
+		if time.Now().After(deadline) {
+			t.Fatalf("IRC message not delivered: %q", output())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if !strings.HasPrefix(ircStatus(), "connected to") {
+		t.Errorf("ircStatus = %q", ircStatus())
+	}
+}
@@ -0,0 +1,234 @@
+// Package main handles item instances. Every item is an instance of a
+// template in data/items.json with its own unique ID. Consumables and
+// materials stack, so a pile of trash takes a single inventory slot, while
+// gear stays unique. Item commands take a quantity:
+//
+//	get 5 trash      - Pick up five trash
+//	drop all.trash   - Drop every trash you carry
+//	sell all         - Sell everything in your backpack
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync/atomic"
+
+	"github.com/yourusername/matrix-mud/pkg/game"
+)
+
+// itemSerial numbers the item instances created since the server started
+var itemSerial atomic.Uint64
+
+// Count returns how many items a stack holds
+func (i *Item) Count() int {
+	return max(i.Quantity, 1)
+}
+
+// TemplateID returns the template an item was made from. Items saved before
+// instances existed use their ID.
+func (i *Item) TemplateID() string {
+	if i.Template != "" {
+		return i.Template
+	}
+	return i.ID
+}
+
+// Stackable reports whether copies of an item share a stack
+func (i *Item) Stackable() bool {
+	return (i.Type == "consumable" || i.Type == "material") && len(i.Contents) == 0
+}
+
+// stacksWith reports whether two items can share a stack
+func (i *Item) stacksWith(other *Item) bool {
+	return i.Stackable() && other.Stackable() && i.TemplateID() == other.TemplateID() &&
+		i.Name == other.Name && i.Rarity == other.Rarity
+}
+
+// matches reports whether a player's word for an item names it
+func (i *Item) matches(name string) bool {
+	return strings.Contains(strings.ToLower(i.Name), name) || i.ID == name || i.TemplateID() == name
+}
+
+// instanceID returns a new unique ID for an instance of a template
+func (w *World) instanceID(template string) string {
+	return fmt.Sprintf("%s_%d_%d", template, w.now().Unix(), itemSerial.Add(1))
+}
+
+// newItem makes n of a template: one stack if it stacks, otherwise one item
+func (w *World) newItem(tmpl *Item, n int) *Item {
+	item := *tmpl
+	item.Template = tmpl.TemplateID()
+	item.ID = w.instanceID(item.Template)
+	item.Quantity = 0
+	if item.Stackable() {
+		item.Quantity = max(n, 1)
+	}
+	return &item
+}
+
+// adoptItem links an item saved before instances existed to its template,
+// trimming generated suffixes such as "katana_1234". An untyped item takes
+// its template's type, so old trash stacks as a material.
+func (w *World) adoptItem(item *Item) {
+	for _, c := range item.Contents {
+		w.adoptItem(c)
+	}
+	if item.Template != "" {
+		return
+	}
+	for id := item.ID; id != ""; {
+		if tmpl, ok := w.ItemTemplates[id]; ok {
+			item.Template = id
+			if item.Type == "" {
+				item.Type = tmpl.Type
+			}
+			return
+		}
+		cut := strings.LastIndex(id, "_")
+		if cut < 0 {
+			return
+		}
+		id = id[:cut]
+	}
+}
+
+// adoptItems adopts a saved player's items, gives bare template copies an
+// instance ID and merges duplicates into stacks
+func (w *World) adoptItems(items []*Item) []*Item {
+	adopted := make([]*Item, 0, len(items))
+	for _, item := range items {
+		w.adoptItem(item)
+		if item.ID == item.Template {
+			item.ID = w.instanceID(item.Template)
+		}
+		adopted = addItem(adopted, item)
+	}
+	return adopted
+}
+
+// addItem adds an item to a list, merging it into a matching stack
+func addItem(items []*Item, item *Item) []*Item {
+	for _, have := range items {
+		if have.stacksWith(item) {
+			have.Quantity = have.Count() + item.Count()
+			return items
+		}
+	}
+	return append(items, item)
+}
+
+// removeItem removes an item from a list
+func removeItem(items []*Item, item *Item) []*Item {
+	for i, have := range items {
+		if have == item {
+			return append(items[:i], items[i+1:]...)
+		}
+	}
+	return items
+}
+
+// canCarry reports whether an item fits in a player's inventory: it joins a
+// stack they carry or there is a free slot
+func canCarry(p *Player, item *Item) bool {
+	if len(p.Inventory) < MaxInventorySize {
+		return true
+	}
+	for _, have := range p.Inventory {
+		if have.stacksWith(item) {
+			return true
+		}
+	}
+	return false
+}
+
+// pick is part or all of one item chosen by an item command
+type pick struct {
+	item *Item
+	n    int
+}
+
+// pickItems chooses what an item command acts on (see game.ParseQuantity),
+// taking counts across stacks in order. It reports false if fewer items
+// match than were asked for.
+func pickItems(items []*Item, arg string) ([]pick, bool) {
+	n, all, name := game.ParseQuantity(arg)
+	var picks []pick
+	for _, item := range items {
+		if name != "" && !item.matches(name) {
+			continue
+		}
+		if all {
+			picks = append(picks, pick{item, item.Count()})
+			continue
+		}
+		take := min(n, item.Count())
+		picks = append(picks, pick{item, take})
+		if n -= take; n == 0 {
+			return picks, true
+		}
+	}
+	return picks, all && len(picks) > 0
+}
+
+// take removes n items from a pick's stack and returns them. Taking the
+// whole stack returns the item itself, which the caller must remove.
+func (w *World) take(pk pick) *Item {
+	if pk.n >= pk.item.Count() {
+		return pk.item
+	}
+	pk.item.Quantity -= pk.n
+	part := *pk.item
+	part.ID = w.instanceID(part.TemplateID())
+	part.Quantity = pk.n
+	return &part
+}
+
+// roomItems lists the items in a room in a stable order
+func roomItems(room *Room) []*Item {
+	items := make([]*Item, 0, len(room.ItemMap))
+	for _, item := range room.ItemMap {
+		items = append(items, item)
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].ID < items[j].ID })
+	return items
+}
+
+// addToRoom puts an item in a room, merging it into a matching stack
+func addToRoom(room *Room, item *Item) {
+	for _, have := range room.ItemMap {
+		if have.stacksWith(item) {
+			have.Quantity = have.Count() + item.Count()
+			return
+		}
+	}
+	room.ItemMap[item.ID] = item
+}
+
+// itemList names the items moved by an item command
+func itemList(items []*Item) string {
+	names := make([]string, len(items))
+	for i, item := range items {
+		names[i] = ColorizeItem(item)
+	}
+	return strings.Join(names, ", ")
+}
+
+// moveItems moves picked items: remove takes a whole item from its source
+// and put adds items to their destination. A move stops at the first pick
+// that fits refuses, if fits is set. It returns the items moved.
+func (w *World) moveItems(picks []pick, fits func(pick) bool, remove, put func(*Item)) []*Item {
+	var moved []*Item
+	for _, pk := range picks {
+		if fits != nil && !fits(pk) {
+			break
+		}
+		item := w.take(pk)
+		if item == pk.item {
+			remove(item)
+		}
+		put(item)
+		moved = append(moved, item)
+	}
+	return moved
+}
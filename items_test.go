@@ -0,0 +1,157 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// setupItems returns a world with a merchant in the dojo and a few templates
+func setupItems(t *testing.T) (*World, *Player) {
+	t.Helper()
+	w, p := setupSkills(t, "Neo", "Rebel", 4)
+	w.ItemTemplates = map[string]*Item{
+		"trash":       {ID: "trash", Name: "Digital Trash", Type: "material", Price: 2},
+		"health_vial": {ID: "health_vial", Name: "Health Vial", Type: "consumable", Effect: "heal", Value: 10, Price: 10},
+		"katana":      {ID: "katana", Name: "Training Katana", Slot: "hand", Damage: 5, Price: 50},
+		"phone":       {ID: "phone", Name: "Nokia Phone", Slot: "hand", Damage: 1, Price: 10},
+	}
+	w.Rooms["dojo"].NPCMap["merchant"] = &NPC{ID: "merchant", Name: "Merchant", RoomID: "dojo", Vendor: true, Inventory: []string{"trash", "health_vial", "katana"}}
+	p.Money = 1000
+	return w, p
+}
+
+func TestItemStacking(t *testing.T) {
+	w, p := setupItems(t)
+	room := w.Rooms["dojo"]
+
+	// Twenty pieces of trash share one slot
+	for range 20 {
+		addToRoom(room, w.newItem(w.ItemTemplates["trash"], 1))
+	}
+	if len(room.ItemMap) != 1 {
+		t.Fatalf("room holds %d stacks, want 1", len(room.ItemMap))
+	}
+	if got := w.GetItem(p, "5 trash"); !strings.Contains(got, "Digital Trash (x5)") || len(p.Inventory) != 1 || p.Inventory[0].Count() != 5 {
+		t.Errorf("get 5 trash = %q, inventory %d", got, len(p.Inventory))
+	}
+	if got := w.GetItem(p, "99 trash"); got != "There aren't that many here." {
+		t.Errorf("get 99 trash = %q", got)
+	}
+	w.GetItem(p, "all.trash")
+	if len(room.ItemMap) != 0 || len(p.Inventory) != 1 || p.Inventory[0].Count() != 20 {
+		t.Errorf("get all.trash left %d in the room, %d stacks carried", len(room.ItemMap), len(p.Inventory))
+	}
+	if got := w.DropItem(p, "3 trash"); !strings.Contains(got, "(x3)") || p.Inventory[0].Count() != 17 {
+		t.Errorf("drop 3 trash = %q", got)
+	}
+
+	// Gear stays unique; every instance has its own ID
+	w.BuyItem(p, "2 katana")
+	if len(p.Inventory) != 3 || p.Inventory[1].ID == p.Inventory[2].ID || p.Inventory[1].TemplateID() != "katana" {
+		t.Errorf("bought katanas: %+v", p.Inventory)
+	}
+	if got := w.BuyItem(p, "3 health"); !strings.Contains(got, "Health Vial (x3)") || p.Money != 870 || p.Inventory[3].Count() != 3 {
+		t.Errorf("buy 3 health = %q, money %d", got, p.Money)
+	}
+	w.UseItem(p, "health")
+	if p.Inventory[3].Count() != 2 {
+		t.Errorf("using a vial should leave 2, have %d", p.Inventory[3].Count())
+	}
+	if got := w.SellItem(p, "all.trash"); got != "Sold "+White+"Digital Trash (x17)"+Reset+" for 17." || len(p.Inventory) != 3 {
+		t.Errorf("sell all.trash = %q", got)
+	}
+
+	// A full inventory still takes more of a stack it carries
+	for len(p.Inventory) < MaxInventorySize {
+		p.Inventory = append(p.Inventory, w.newItem(w.ItemTemplates["phone"], 1))
+	}
+	if got := w.BuyItem(p, "health"); got != "Bought "+White+"Health Vial"+Reset+"." || p.Inventory[2].Count() != 3 {
+		t.Errorf("buy into a stack when full = %q", got)
+	}
+	if got := w.BuyItem(p, "katana"); !strings.Contains(got, "inventory is full") {
+		t.Errorf("buy gear when full = %q", got)
+	}
+}
+
+func TestBuyHugeCount(t *testing.T) {
+	w, p := setupItems(t)
+
+	// Gear is refused before any of it is made
+	if got := w.BuyItem(p, "1000000000 katana"); !strings.Contains(got, "Not enough Fragments") || len(p.Inventory) != 0 {
+		t.Errorf("buy a billion katanas = %q, inventory %d", got, len(p.Inventory))
+	}
+	p.Money = 1 << 40
+	if got := w.BuyItem(p, "1000000000 katana"); !strings.Contains(got, "inventory is full") || len(p.Inventory) != 0 || p.Money != 1<<40 {
+		t.Errorf("buy a billion katanas rich = %q, inventory %d", got, len(p.Inventory))
+	}
+
+	// A count whose cost overflows must not pay the buyer
+	p.Money = 1000
+	if got := w.BuyItem(p, "922337203685477581 health"); got != "Not enough Fragments." || p.Money != 1000 || len(p.Inventory) != 0 {
+		t.Errorf("buy an overflowing count = %q, money %d", got, p.Money)
+	}
+}
+
+func TestCraftCount(t *testing.T) {
+	w, p := setupItems(t)
+	p.Inventory = []*Item{w.newItem(w.ItemTemplates["trash"], 10)}
+	p.XP = p.Level*XPPerLevel - 1
+	got := w.Craft(p, "3 health_vial")
+	if !strings.Contains(got, "You crafted 3x Health Vial! (+30 XP)") {
+		t.Fatalf("craft 3 = %q", got)
+	}
+	if p.Level != 5 || !strings.Contains(got, "LEVEL UP") {
+		t.Errorf("crafting XP should level up: level %d, %q", p.Level, got)
+	}
+	if len(p.Inventory) != 2 || p.Inventory[0].Count() != 1 || p.Inventory[1].Count() != 3 {
+		t.Errorf("crafting 3 should use 9 trash and make one stack: %+v", p.Inventory)
+	}
+	if got := w.Craft(p, "2 health_vial"); got != "Missing materials: 5x trash" {
+		t.Errorf("craft without enough materials = %q", got)
+	}
+	if got := w.Craft(p, "9223372036854775807 health_vial"); !strings.HasPrefix(got, "Missing materials:") || p.Inventory[0].Count() != 1 {
+		t.Errorf("craft a huge count = %q", got)
+	}
+	if got := w.Craft(p, "all.health_vial"); got != "Craft how many?" {
+		t.Errorf("craft all = %q", got)
+	}
+}
+
+func TestStackedCraftingAndBanking(t *testing.T) {
+	w, p := setupItems(t)
+	p.Inventory = []*Item{w.newItem(w.ItemTemplates["trash"], 4)}
+	if got := w.Craft(p, "health_vial"); !strings.Contains(got, "You crafted Health Vial") {
+		t.Fatalf("craft = %q", got)
+	}
+	if len(p.Inventory) != 2 || p.Inventory[0].Count() != 1 || p.Inventory[1].TemplateID() != "health_vial" {
+		t.Errorf("crafting should use 3 of the stack: %+v", p.Inventory)
+	}
+
+	p.RoomID = "construct_archive"
+	w.Rooms["construct_archive"] = &Room{ID: "construct_archive", ItemMap: map[string]*Item{}, NPCMap: map[string]*NPC{}}
+	p.Inventory[0].Quantity = 10
+	w.DepositItem(p, "4 trash")
+	w.DepositItem(p, "2 trash")
+	if len(p.Bank) != 1 || p.Bank[0].Count() != 6 || p.Inventory[0].Count() != 4 {
+		t.Errorf("deposits should share a stack: bank %+v", p.Bank)
+	}
+	if got := w.WithdrawItem(p, "all"); !strings.Contains(got, "(x6)") || len(p.Bank) != 0 || p.Inventory[0].Count() != 10 {
+		t.Errorf("withdraw all = %q", got)
+	}
+}
+
+func TestAdoptSavedItems(t *testing.T) {
+	w, _ := setupItems(t)
+	items := w.adoptItems([]*Item{
+		{ID: "trash", Name: "Digital Trash"},
+		{ID: "trash", Name: "Digital Trash"},
+		{ID: "katana_1234", Name: "Training Katana", Slot: "hand"},
+		{ID: "katana", Name: "Training Katana", Slot: "hand"},
+	})
+	if len(items) != 3 || items[0].Count() != 2 || items[0].Type != "material" {
+		t.Fatalf("saved trash should merge into a stack: %+v", items)
+	}
+	if items[1].TemplateID() != "katana" || items[1].ID != "katana_1234" || items[2].ID == "katana" {
+		t.Errorf("saved gear: %+v, %+v", items[1], items[2])
+	}
+}
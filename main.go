@@ -11,6 +11,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
@@ -21,17 +22,20 @@ import (
 	"github.com/yourusername/matrix-mud/pkg/analytics"
 	"github.com/yourusername/matrix-mud/pkg/chat"
 	"github.com/yourusername/matrix-mud/pkg/cooldown"
+	"github.com/yourusername/matrix-mud/pkg/db"
 	"github.com/yourusername/matrix-mud/pkg/faction"
 	"github.com/yourusername/matrix-mud/pkg/game"
 	"github.com/yourusername/matrix-mud/pkg/help"
 	"github.com/yourusername/matrix-mud/pkg/leaderboard"
 	"github.com/yourusername/matrix-mud/pkg/logging"
 	"github.com/yourusername/matrix-mud/pkg/metrics"
+	"github.com/yourusername/matrix-mud/pkg/moderation"
 	"github.com/yourusername/matrix-mud/pkg/party"
 	"github.com/yourusername/matrix-mud/pkg/pvp"
 	"github.com/yourusername/matrix-mud/pkg/quest"
 	"github.com/yourusername/matrix-mud/pkg/ratelimit"
 	"github.com/yourusername/matrix-mud/pkg/readline"
+	"github.com/yourusername/matrix-mud/pkg/recording"
 	"github.com/yourusername/matrix-mud/pkg/session"
 	"github.com/yourusername/matrix-mud/pkg/trade"
 	"github.com/yourusername/matrix-mud/pkg/training"
@@ -82,9 +86,18 @@ func init() {
 		ticker := time.NewTicker(1 * time.Hour)
 		for range ticker.C {
 			authLimiter.CleanupOldEntries()
+			moderation.GlobalModeration.Cleanup()
 			logging.Info().Msg("Rate limiter cleanup completed")
 		}
 	}()
+
+	// Settle auctions that run out with a winning bid
+	go func() {
+		ticker := time.NewTicker(time.Minute)
+		for range ticker.C {
+			settleExpiredAuctions(trade.GlobalTrade)
+		}
+	}()
 }
 
 func authenticate(c *Client, name string) bool {
@@ -92,6 +105,7 @@ func authenticate(c *Client, name string) bool {
 	if !authLimiter.Allow(name) {
 		c.Write(Red + "Too many authentication attempts. Try again later.\r\n" + Reset)
 		logging.Warn().Str("user", name).Msg("Rate limit exceeded")
+		recordAudit(name, db.AuditWarning, "login rate limited", clientIP(c))
 		time.Sleep(3 * time.Second) // Add delay for rate-limited clients
 		return false
 	}
@@ -131,11 +145,13 @@ func authenticate(c *Client, name string) bool {
 		err = bcrypt.CompareHashAndPassword([]byte(storedHash), []byte(pass))
 		if err == nil {
 			logging.Info().Str("user", cleanName).Msg("Authentication successful")
+			recordAudit(cleanName, db.AuditLogin, "login", clientIP(c))
 			return true
 		}
 
 		c.Write(Red + "Access Denied.\r\n" + Reset)
 		logging.Warn().Str("user", cleanName).Msg("Failed authentication attempt")
+		recordAudit(cleanName, db.AuditWarning, "failed login: bad password", clientIP(c))
 		return false
 	} else {
 		// New user - create account with bcrypt hashed password
@@ -171,6 +187,7 @@ func authenticate(c *Client, name string) bool {
 
 		c.Write("Identity created.\r\n")
 		logging.Info().Str("user", cleanName).Msg("New user created")
+		recordAudit(cleanName, db.AuditCreate, "account created", clientIP(c))
 		return true
 	}
 }
@@ -221,17 +238,33 @@ func chooseClass(c *Client, p *Player) {
 // Each client connection runs in its own goroutine and maintains a buffered
 // reader for efficient line-based command input.
 type Client struct {
-	conn   net.Conn      // TCP connection to the client
-	reader *bufio.Reader // Buffered reader for line reading
+	conn     net.Conn                           // TCP connection to the client
+	reader   *bufio.Reader                      // Buffered reader for line reading
+	recorder atomic.Pointer[recording.Recorder] // Session recording, if any
+	snoopers atomic.Pointer[[]*Client]          // Staff mirroring this client's output
 }
 
 // Write sends a message to the client over the TCP connection.
 // Messages are sent as raw bytes. The caller should include appropriate
 // line endings (\r\n for telnet compatibility).
 func (c *Client) Write(msg string) {
+	if rec := c.recorder.Load(); rec != nil {
+		rec.Output(msg)
+	}
+	c.mirrorToSnoopers(msg)
 	c.conn.Write([]byte(msg))
 }
 
+// echoInput passes a typed line to the session recording and snoopers,
+// along with the echo the line editor wrote directly to the connection
+func (c *Client) echoInput(line string) {
+	if rec := c.recorder.Load(); rec != nil {
+		rec.Input(line + "\r")
+		rec.Output(line + "\r\n")
+	}
+	c.mirrorToSnoopers("> " + line + "\r\n")
+}
+
 // suppressEcho sends telnet IAC WILL ECHO to suppress client-side echo.
 // This should be called before reading sensitive input like passwords.
 func (c *Client) suppressEcho() {
@@ -257,10 +290,19 @@ func (c *Client) readPassword() (string, error) {
 	if err != nil {
 		return "", err
 	}
+	// The password was never echoed, and must not reach a recording either
+	if rec := c.recorder.Load(); rec != nil {
+		rec.Redacted()
+	}
 	return strings.TrimSpace(pass), nil
 }
 
 func main() {
+	// Offline tools run instead of the server
+	if len(os.Args) > 1 && os.Args[1] == "events" {
+		os.Exit(runEventsCommand(os.Args[2:], os.Stdout, os.Stderr))
+	}
+
 	// Create server context for graceful shutdown
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -270,38 +312,46 @@ func main() {
 
 	// Use configured port
 	listenAddr := ":" + Config.TelnetPort
-	listener, err := net.Listen("tcp", listenAddr)
+	listener, inherited, err := listenTelnet(listenAddr)
 	if err != nil {
 		logging.Fatal().Err(err).Str("addr", listenAddr).Msg("Failed to start telnet server")
 	}
+	telnetListener = listener
 
 	world := NewWorld()
 
+	// Load bans and sanctions, and open the audit log
+	if err := moderation.GlobalModeration.Load(Config.SanctionsFile); err != nil {
+		logging.Error().Err(err).Str("path", Config.SanctionsFile).Msg("Failed to load sanctions")
+	}
+	initAuditLog(Config.AuditDBPath)
+
 	// Start event bus for Discord/webhook integration
 	events.GlobalEventBus.Start()
+	events.GlobalStream.Attach(events.GlobalEventBus)
+	openEventJournal()
+	startWebhooks()
+	startRecordings()
+	startIRCBridge(world)
+	registerGameMetrics(world)
 
 	go startWebServer(world)
 	go startAdminServer(world)
+	go startAPIServer(world)
 
-	go func() {
-		ticker := time.NewTicker(500 * time.Millisecond)
-		for {
-			select {
-			case <-ctx.Done():
-				ticker.Stop()
-				return
-			case <-ticker.C:
-				world.Update()
-			}
-		}
-	}()
+	// Re-attach sessions handed over by a copyover
+	resumeCopyoverSessions(ctx, world, inherited, connSemaphore)
+
+	go runWorldTicker(ctx, world)
 
 	logging.Info().
 		Str("version", Version).
 		Str("telnet_port", Config.TelnetPort).
 		Str("web_port", Config.WebPort).
 		Str("admin_addr", Config.AdminBindAddr).
+		Str("api_port", Config.APIPort).
 		Int("max_connections", MaxConnections).
+		Str("sim_seed", seedStatus(world)).
 		Msg("Matrix Construct Server started")
 
 	// Setup graceful shutdown handler
@@ -323,6 +373,14 @@ func main() {
 				}
 			}
 
+			// Reject banned addresses before spending a connection slot
+			if s := connectionSanction(conn.RemoteAddr().String()); s != nil {
+				conn.Write([]byte(sanctionMessage(s)))
+				conn.Close()
+				logging.Warn().Str("addr", conn.RemoteAddr().String()).Int64("sanction", s.ID).Msg("Connection rejected: banned address")
+				continue
+			}
+
 			// Try to acquire connection slot
 			select {
 			case connSemaphore <- struct{}{}:
@@ -340,9 +398,14 @@ func main() {
 		}
 	}()
 
-	// Wait for shutdown signal
-	<-shutdown
-	logging.Info().Msg("Shutdown signal received, canceling context...")
+	// Wait for shutdown signal or a scheduled shutdown/reboot from the admin console
+	reboot := false
+	select {
+	case <-shutdown:
+		logging.Info().Msg("Shutdown signal received, canceling context...")
+	case reboot = <-shutdownRequests:
+		logging.Info().Bool("reboot", reboot).Msg("Scheduled shutdown reached, canceling context...")
+	}
 
 	// Cancel context to signal all goroutines
 	cancel()
@@ -364,9 +427,15 @@ func main() {
 
 	// Save world state
 	world.SaveWorld()
+	stopIRCBridge()
+	stopAllRecordings()
 
 	logging.Info().Int("players_saved", playerCount).Msg("Graceful shutdown complete")
 	listener.Close()
+
+	if reboot {
+		restartServer()
+	}
 }
 
 func handleConnection(ctx context.Context, conn net.Conn, world *World) {
@@ -423,6 +492,20 @@ func handleConnection(ctx context.Context, conn net.Conn, world *World) {
 		return
 	}
 
+	// Enforce bans again at login (sanctions may have changed since accept)
+	if s := moderation.GlobalModeration.CheckAccount(name); s != nil {
+		client.Write(sanctionMessage(s))
+		recordAudit(name, db.AuditBan, fmt.Sprintf("login rejected: account banned (#%d)", s.ID), remoteIP(remoteAddr))
+		connLog.Warn().Str("user", name).Msg("Login rejected: account banned")
+		return
+	}
+	if s := connectionSanction(remoteAddr); s != nil {
+		client.Write(sanctionMessage(s))
+		recordAudit(name, db.AuditBan, fmt.Sprintf("login rejected: address banned (#%d)", s.ID), remoteIP(remoteAddr))
+		connLog.Warn().Str("user", name).Msg("Login rejected: address banned")
+		return
+	}
+
 	if !authenticate(client, name) {
 		return
 	}
@@ -440,21 +523,39 @@ func handleConnection(ctx context.Context, conn net.Conn, world *World) {
 		world.SavePlayer(player)
 	}
 
+	runSession(ctx, client, world, player, false)
+}
+
+// runSession registers a logged-in player and runs their command loop until
+// they quit, disconnect or the server shuts down. Resumed sessions are those
+// re-attached after a copyover; they skip the MOTD.
+func runSession(ctx context.Context, client *Client, world *World, player *Player, resumed bool) {
+	conn := client.conn
+
 	// Auto-join default chat channels for all players
 	chat.GlobalChat.AutoJoinDefaultChannels(player.Name)
 
 	world.mutex.Lock()
 	world.Players[client] = player
 	world.mutex.Unlock()
+	publishPlayerJoin(player)
 
 	// Create or update session
 	sessionManager.CreateSession(player.Name, player.RoomID, player.HP, player.MP)
 
+	// Players who opted in are recorded from the start of each session
+	if player.RecordSessions {
+		startSessionRecording(client, player, "opt-in")
+	}
+
 	defer func() {
+		endSupportSessions(client, player)
+		stopSessionRecording(client, player)
 		world.SavePlayer(player)
 		world.mutex.Lock()
 		delete(world.Players, client)
 		world.mutex.Unlock()
+		publishPlayerLeave(player)
 		// Mark session as disconnected (allows reconnect within 30 min)
 		sessionManager.Disconnect(player.Name)
 		analytics.EndSession(player.Name)
@@ -466,7 +567,9 @@ func handleConnection(ctx context.Context, conn net.Conn, world *World) {
 	metrics.IncrPlayers()
 
 	// Show MOTD
-	if motd := world.GetMOTD(); motd != "" {
+	if resumed {
+		client.Write("\r\n" + SystemMsg("The Matrix has been reloaded."))
+	} else if motd := world.GetMOTD(); motd != "" {
 		client.Write(Matrixify(motd))
 	}
 
@@ -480,7 +583,16 @@ func handleConnection(ctx context.Context, conn net.Conn, world *World) {
 	history := getPlayerHistory(strings.ToLower(player.Name))
 	rl := readline.NewReader(conn, history, "> ")
 
+	// A command's latency runs until the loop is back waiting for input
+	var lastCmd string
+	var cmdStart time.Time
+
 	for {
+		if lastCmd != "" {
+			metrics.ObserveCommand(lastCmd, time.Since(cmdStart))
+			lastCmd = ""
+		}
+
 		// Check for server shutdown
 		select {
 		case <-ctx.Done():
@@ -498,6 +610,7 @@ func handleConnection(ctx context.Context, conn net.Conn, world *World) {
 			}
 			break
 		}
+		client.echoInput(input)
 
 		// Reset idle timeout on each valid input
 		conn.SetDeadline(time.Now().Add(IdleTimeout))
@@ -520,6 +633,25 @@ func handleConnection(ctx context.Context, conn net.Conn, world *World) {
 
 		// Record command for metrics
 		metrics.RecordCommand(cmd)
+		lastCmd, cmdStart = commandLabel(cmd), time.Now()
+
+		// Mutes and freezes block commands before any other handling
+		if msg := sanctionBlocksCommand(player, cmd); msg != "" {
+			client.Write(msg + "> ")
+			continue
+		}
+
+		// Ghosts can only look around, talk, move and release
+		if msg := ghostBlocksCommand(world, player, cmd); msg != "" {
+			client.Write(msg + "> ")
+			continue
+		}
+
+		// Staff switched into an NPC speak and move as it
+		if response, ok := handleSwitchedCommand(world, player, cmd, arg); ok {
+			client.Write(response + "> ")
+			continue
+		}
 
 		// --- SPECIAL STATE HANDLING ---
 		// Handle dialogue numeric input and instance state before normal commands
@@ -583,7 +715,7 @@ func handleConnection(ctx context.Context, conn net.Conn, world *World) {
 		case "look", "l":
 			lookResult := world.Look(player, arg)
 			// Add time-of-day atmosphere to room descriptions (not when looking at specific targets)
-			if arg == "" {
+			if arg == "" && world.Outdoors(player) {
 				lookResult = fmt.Sprintf("%s%s%s\r\n%s", Cyan, gameClock.AmbientDescription(), Reset, lookResult)
 			}
 			response = Matrixify(lookResult)
@@ -600,9 +732,37 @@ func handleConnection(ctx context.Context, conn net.Conn, world *World) {
 		case "down", "dn":
 			response = Matrixify(world.MovePlayer(player, "down"))
 		case "get", "g":
-			response = Matrixify(world.GetItem(player, arg))
+			if itemName, container, ok := strings.Cut(arg, " from "); ok {
+				response = Matrixify(world.GetFrom(player, itemName, container))
+			} else {
+				response = Matrixify(world.GetItem(player, arg))
+			}
+		case "put":
+			if itemName, container, ok := strings.Cut(arg, " in "); ok {
+				response = Matrixify(world.PutItem(player, itemName, container))
+			} else {
+				response = "Put what in what?\r\n"
+			}
+		case "open":
+			response = Matrixify(world.OpenTarget(player, arg))
+		case "close":
+			response = Matrixify(world.CloseTarget(player, arg))
+		case "lock":
+			response = Matrixify(world.LockTarget(player, arg, true))
+		case "unlock":
+			response = Matrixify(world.LockTarget(player, arg, false))
+		case "pick":
+			response = Matrixify(world.PickLock(player, arg))
+		case "hack":
+			response = Matrixify(world.HackLock(player, arg))
+		case "search":
+			response = Matrixify(world.Search(player))
 		case "drop", "d":
 			response = Matrixify(world.DropItem(player, arg))
+		case "loot":
+			response = Matrixify(world.Loot(player, arg))
+		case "release":
+			response = Matrixify(world.Release(player))
 		case "inv", "i":
 			response = Matrixify(world.ShowInventory(player))
 		case "score", "sc", "balance", "bal":
@@ -612,7 +772,7 @@ func handleConnection(ctx context.Context, conn net.Conn, world *World) {
 			if arena := pvp.GlobalPvP.GetPlayerArena(player.Name); arena != nil {
 				if arg == "" {
 					response = "Attack who?\r\n"
-				} else if result, err := pvp.GlobalPvP.AttackPlayer(arena.ID, player.Name, arg); err != nil {
+				} else if result, err := pvp.GlobalPvP.AttackPlayer(world.rng(), arena.ID, player.Name, arg); err != nil {
 					response = err.Error() + "\r\n"
 				} else {
 					response = result + "\r\n"
@@ -629,22 +789,14 @@ func handleConnection(ctx context.Context, conn net.Conn, world *World) {
 				if len(skillParts) > 1 {
 					target = strings.Join(skillParts[1:], " ")
 				}
-				// Check cooldown before casting
-				if !cooldown.GlobalCD.IsReady(player.Name, skill) {
-					remaining := cooldown.GlobalCD.TimeRemaining(player.Name, skill)
-					response = fmt.Sprintf("%s%s is on cooldown (%.1fs remaining)%s\r\n", Yellow, skill, remaining.Seconds(), Reset)
-				} else {
-					response = Matrixify(world.CastSkill(player, skill, target))
-					// Only trigger cooldown if cast succeeded (response doesn't contain error indicators)
-					if !strings.Contains(response, "don't know") && !strings.Contains(response, "not in combat") {
-						cooldown.GlobalCD.Use(player.Name, skill)
-					}
-				}
+				response = Matrixify(world.CastSkill(player, skill, target))
 			} else {
 				response = "Cast what?\r\n"
 			}
 		case "flee", "stop":
 			response = Matrixify(world.StopCombat(player))
+		case "assist":
+			response = Matrixify(world.Assist(player, arg))
 		case "wear", "wield", "equip":
 			response = Matrixify(world.WearItem(player, arg))
 		case "remove", "unequip":
@@ -682,9 +834,13 @@ func handleConnection(ctx context.Context, conn net.Conn, world *World) {
 		case "list", "vendor":
 			response = Matrixify(world.ListGoods(player))
 		case "buy":
+			before := player.Money
 			response = Matrixify(world.BuyItem(player, arg))
+			auditMoney(player.Name, db.AuditPurchase, before-player.Money, "vendor purchase: "+arg, clientIP(client))
 		case "sell":
+			before := player.Money
 			response = Matrixify(world.SellItem(player, arg))
+			auditMoney(player.Name, db.AuditSale, player.Money-before, "vendor sale: "+arg, clientIP(client))
 		case "give":
 			giveParts := strings.Fields(arg)
 			if len(giveParts) >= 2 {
@@ -716,7 +872,7 @@ func handleConnection(ctx context.Context, conn net.Conn, world *World) {
 			response = fmt.Sprintf("%s%s%s\r\n%s%s%s\r\n", Cyan, gameClock.FormatTimeDisplay(), Reset, Green, gameClock.TimeString(), Reset)
 
 		case "cooldowns", "cd":
-			cds := cooldown.GlobalCD.GetAllCooldowns(player.Name)
+			cds := cooldown.GlobalCD.GetAllCooldownsAt(player.Name, world.now())
 			if len(cds) == 0 {
 				response = "All abilities ready.\r\n"
 			} else {
@@ -739,7 +895,11 @@ func handleConnection(ctx context.Context, conn net.Conn, world *World) {
 		case "help", "?":
 			response = Matrixify(formatHelp(arg))
 		case "teleport":
-			response = Matrixify(world.Teleport(player, arg))
+			if err := world.Teleport(player, arg); err != nil {
+				response = Matrixify(fmt.Sprintf("Teleport failed: %v.", err))
+			} else {
+				response = Matrixify("Teleported.")
+			}
 
 		// --- AWAKENING & MATRIX COMMANDS ---
 		case "take":
@@ -752,6 +912,10 @@ func handleConnection(ctx context.Context, conn net.Conn, world *World) {
 			}
 		case "abilities", "skills":
 			response = world.ShowAbilities(player)
+		case "learn":
+			response = Matrixify(world.LearnSkill(player, strings.ToLower(arg)))
+		case "practice", "prac":
+			response = Matrixify(world.PracticeSkill(player, strings.ToLower(arg)))
 		case "see_code", "seecode", "code":
 			response = world.SeeCode(player)
 		case "focus":
@@ -816,7 +980,9 @@ func handleConnection(ctx context.Context, conn net.Conn, world *World) {
 				rows, _ := strconv.Atoi(genParts[1])
 				cols, _ := strconv.Atoi(genParts[2])
 				if rows > 0 && cols > 0 {
-					response = Matrixify(world.GenerateCity(player, rows, cols))
+					result := world.GenerateCity(player, rows, cols)
+					auditBuild(player, cmd, arg, result, clientIP(client))
+					response = Matrixify(result)
 				} else {
 					response = "Invalid size.\r\n"
 				}
@@ -825,19 +991,25 @@ func handleConnection(ctx context.Context, conn net.Conn, world *World) {
 			}
 		case "dig":
 			if len(parts) >= 3 {
-				response = Matrixify(world.Dig(player, parts[1], strings.Join(parts[2:], " ")))
+				result := world.Dig(player, parts[1], strings.Join(parts[2:], " "))
+				auditBuild(player, cmd, arg, result, clientIP(client))
+				response = Matrixify(result)
 			} else {
 				response = "Usage: dig [dir] [name]\r\n"
 			}
 		case "create":
 			if len(parts) >= 3 {
-				response = Matrixify(world.CreateEntity(player, parts[1], parts[2]))
+				result := world.CreateEntity(player, parts[1], parts[2])
+				auditBuild(player, cmd, arg, result, clientIP(client))
+				response = Matrixify(result)
 			} else {
 				response = "Usage: create [item|npc] [id]\r\n"
 			}
 		case "delete", "del":
 			if len(parts) >= 2 {
-				response = Matrixify(world.DeleteEntity(player, arg))
+				result := world.DeleteEntity(player, arg)
+				auditBuild(player, cmd, arg, result, clientIP(client))
+				response = Matrixify(result)
 			} else {
 				response = "Delete what?\r\n"
 			}
@@ -845,9 +1017,11 @@ func handleConnection(ctx context.Context, conn net.Conn, world *World) {
 			if len(parts) >= 3 {
 				field := parts[1]
 				val := strings.Join(parts[2:], " ")
-				response = Matrixify(world.EditRoom(player, field, val))
+				result := world.EditRoom(player, field, val)
+				auditBuild(player, cmd, arg, result, clientIP(client))
+				response = Matrixify(result)
 			} else {
-				response = "Usage: edit desc [text]\r\n"
+				response = "Usage: edit desc [text] | edit exit [dir] [field] [value] | edit flag [flag] [on|off] | edit env [type]\r\n"
 			}
 		case "save":
 			if arg == "world" {
@@ -884,6 +1058,9 @@ func handleConnection(ctx context.Context, conn net.Conn, world *World) {
 		case "recall":
 			response = world.Recall(player)
 
+		case "record":
+			response = handleRecordCommand(world, client, player, arg)
+
 		// --- CHAT CHANNEL COMMANDS ---
 		case "channels":
 			// List available channels
@@ -1030,23 +1207,24 @@ func handleConnection(ctx context.Context, conn net.Conn, world *World) {
 					}
 				case "add":
 					if len(parts) < 2 {
-						response = "Usage: trade add <item>\r\n"
+						response = "Usage: trade add [count|all.]<item>\r\n"
 					} else {
-						itemName := strings.Join(parts[1:], " ")
-						// Find item in inventory
-						var item *Item
-						for _, i := range player.Inventory {
-							if strings.Contains(strings.ToLower(i.Name), strings.ToLower(itemName)) {
-								item = i
-								break
-							}
-						}
-						if item == nil {
+						// Find items in inventory, e.g. "trade add 5 trash"
+						world.mutex.Lock()
+						picks, ok := pickItems(player.Inventory, strings.Join(parts[1:], " "))
+						world.mutex.Unlock()
+						if len(picks) == 0 {
 							response = "You don't have that item.\r\n"
-						} else if err := trade.GlobalTrade.AddItem(player.Name, item.ID, item.Name, 1); err != nil {
-							response = err.Error() + "\r\n"
+						} else if !ok {
+							response = "You don't have that many.\r\n"
 						} else {
-							response = fmt.Sprintf("Added %s to trade.\r\n", item.Name)
+							for _, pk := range picks {
+								if err := trade.GlobalTrade.AddItem(player.Name, pk.item.ID, pk.item.Name, pk.n); err != nil {
+									response += err.Error() + "\r\n"
+									break
+								}
+								response += fmt.Sprintf("Added %s x%d to trade.\r\n", pk.item.Name, pk.n)
+							}
 						}
 					}
 				case "remove":
@@ -1068,10 +1246,12 @@ func handleConnection(ctx context.Context, conn net.Conn, world *World) {
 						response = fmt.Sprintf("Set money offer to %d.\r\n", amount)
 					}
 				case "confirm":
+					pending := trade.GlobalTrade.GetTrade(player.Name)
 					if completed, err := trade.GlobalTrade.ConfirmTrade(player.Name); err != nil {
 						response = err.Error() + "\r\n"
 					} else if completed {
 						response = "Trade completed!\r\n"
+						auditTrade(pending, clientIP(client))
 						// TODO: Actually exchange items and money
 					} else {
 						response = "Trade confirmed. Waiting for other party...\r\n"
@@ -1098,7 +1278,7 @@ func handleConnection(ctx context.Context, conn net.Conn, world *World) {
 					response = trade.GlobalTrade.FormatListings(listings)
 				case "sell":
 					if len(parts) < 3 {
-						response = "Usage: auction sell <item> <start_price> <buyout_price>\r\n"
+						response = "Usage: auction sell [all.]<item> <start_price> <buyout_price>\r\n"
 					} else {
 						itemName := parts[1]
 						startPrice, _ := strconv.Atoi(parts[2])
@@ -1106,28 +1286,21 @@ func handleConnection(ctx context.Context, conn net.Conn, world *World) {
 						if len(parts) > 3 {
 							buyoutPrice, _ = strconv.Atoi(parts[3])
 						}
-						// Find item in inventory
-						var item *Item
-						for _, i := range player.Inventory {
-							if strings.Contains(strings.ToLower(i.Name), strings.ToLower(itemName)) {
-								item = i
-								break
-							}
-						}
-						if item == nil {
+						// Find item in inventory; all.<item> lists a whole stack
+						world.mutex.Lock()
+						picks, _ := pickItems(player.Inventory, itemName)
+						if len(picks) == 0 {
 							response = "You don't have that item.\r\n"
-						} else if listing, err := trade.GlobalTrade.CreateListing(player.Name, item.ID, item.Name, 1, startPrice, buyoutPrice, 24*time.Hour, "general"); err != nil {
+						} else if listing, err := trade.GlobalTrade.CreateListing(player.Name, picks[0].item.ID, picks[0].item.Name, picks[0].n, startPrice, buyoutPrice, 24*time.Hour, "general"); err != nil {
 							response = err.Error() + "\r\n"
 						} else {
-							response = fmt.Sprintf("Listed %s on auction (ID: %s).\r\n", item.Name, listing.ID)
+							response = fmt.Sprintf("Listed %s x%d on auction (ID: %s).\r\n", picks[0].item.Name, picks[0].n, listing.ID)
 							// Remove from inventory
-							for i, inv := range player.Inventory {
-								if inv == item {
-									player.Inventory = append(player.Inventory[:i], player.Inventory[i+1:]...)
-									break
-								}
+							if world.take(picks[0]) == picks[0].item {
+								player.Inventory = removeItem(player.Inventory, picks[0].item)
 							}
 						}
+						world.mutex.Unlock()
 					}
 				case "bid":
 					if len(parts) < 3 {
@@ -1146,6 +1319,9 @@ func handleConnection(ctx context.Context, conn net.Conn, world *World) {
 						response = err.Error() + "\r\n"
 					} else {
 						response = "Item purchased!\r\n"
+						listing := trade.GlobalTrade.GetListing(parts[1])
+						auditAuctionSale(listing, clientIP(client))
+						publishAuctionSale(listing)
 						// TODO: Add item to player inventory
 					}
 				default:
@@ -1331,6 +1507,22 @@ func handleConnection(ctx context.Context, conn net.Conn, world *World) {
 			// Instance/dungeon commands
 			response = HandleInstanceCommand(world, player, arg)
 
+		// --- MODERATION COMMANDS (staff only) ---
+		case "ban", "unban", "mute", "unmute", "freeze", "unfreeze", "sanctions":
+			response = handleSanctionCommand(world, player, cmd, parts[1:])
+		case "audit":
+			response = handleAuditCommand(player, parts[1:])
+		case "copyover":
+			response = handleCopyoverCommand(world, player)
+		case "snoop":
+			response = handleSnoopCommand(world, player, arg)
+		case "switch":
+			response = handleSwitchCommand(world, player, arg)
+		case "return":
+			response = handleReturnCommand(player)
+		case "seed":
+			response = handleSeedCommand(world, player, arg)
+
 		case "quit":
 			return
 		default:
@@ -1656,6 +1848,7 @@ func handleFactionCommand(player *Player, arg string) string {
 		}
 		// Award achievement for joining a faction
 		if ach := achievements.GlobalAchievements.Award(player.Name, achievements.AchAwakened); ach != nil {
+			publishAchievement(player, ach)
 			msg += fmt.Sprintf("\r\n%s*** Achievement Unlocked: %s ***%s", Yellow, ach.Name, Reset)
 		}
 		return Green + msg + Reset + "\r\n"
@@ -119,7 +119,7 @@ func TestBriefModeLookDescription(t *testing.T) {
 	w.Rooms["test_room"] = &Room{
 		ID:          "test_room",
 		Description: longDesc,
-		Exits:       map[string]string{},
+		Exits:       map[string]*Exit{},
 		Items:       []*Item{},
 		NPCs:        []*NPC{},
 	}
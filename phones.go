@@ -6,6 +6,8 @@ package main
 import (
 	"fmt"
 	"strings"
+
+	"github.com/yourusername/matrix-mud/pkg/world"
 )
 
 // DiscoverPhone adds a phone booth to a player's known locations
@@ -45,6 +47,9 @@ func (w *World) CallPhone(p *Player, destination string) string {
 	if currentRoom == nil || !currentRoom.HasPhone {
 		return "You need to be at a phone booth to make a call.\r\n"
 	}
+	if currentRoom.HasFlag(world.FlagNoTeleport) {
+		return "The line is dead. Nothing connects from here.\r\n"
+	}
 
 	if destination == "" {
 		return w.ListPhones(p)
@@ -78,11 +83,16 @@ func (w *World) CallPhone(p *Player, destination string) string {
 	if targetID == p.RoomID {
 		return "You're already here.\r\n"
 	}
+	if targetRoom.HasFlag(world.FlagNoTeleport) {
+		return "The phone rings and rings, but nothing answers there.\r\n"
+	}
 
 	// Teleport
 	w.mutex.Lock()
-	oldRoom := w.Rooms[p.RoomID]
+	from := p.RoomID
+	oldRoom := w.Rooms[from]
 	p.RoomID = targetID
+	w.playerEntered(p, from)
 	w.mutex.Unlock()
 
 	// Announce departure and arrival
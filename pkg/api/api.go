@@ -6,10 +6,13 @@ import (
 	"crypto/subtle"
 	"encoding/json"
 	"net/http"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/yourusername/matrix-mud/pkg/events"
 )
 
 // Config holds API configuration
@@ -19,6 +22,7 @@ type Config struct {
 	RateLimitRPS  int
 	CORSOrigins   []string
 	EnableSwagger bool
+	KeysFile      string // Where created and revoked keys are persisted
 }
 
 // APIKey represents an API key with permissions
@@ -46,6 +50,7 @@ type Meta struct {
 	PerPage    int    `json:"per_page,omitempty"`
 	TotalPages int    `json:"total_pages,omitempty"`
 	Version    string `json:"version,omitempty"`
+	NextCursor uint64 `json:"next_cursor,omitempty"`
 }
 
 // ErrorResponse creates an error response
@@ -123,10 +128,12 @@ type Server struct {
 	rateLimiter *RateLimiter
 	server      *http.Server
 	version     string
+	keysMu      sync.RWMutex
 
-	// Data providers (set by main application)
+	// Data providers (set by main application, see UseReadModel)
 	GetOnlinePlayers    func() []PlayerInfo
 	GetPlayerByName     func(name string) *PlayerInfo
+	GetPlayerInventory  func(name string) []ItemInfo
 	GetServerStatus     func() *ServerStatus
 	GetRooms            func() []RoomInfo
 	GetRoom             func(id string) *RoomInfo
@@ -134,6 +141,13 @@ type Server struct {
 	GetItems            func() []ItemInfo
 	GetLeaderboard      func(category string, limit int) []LeaderboardEntry
 	SendMessageToPlayer func(name, message string) error
+	SendChannelMessage  func(channel, message string) error
+
+	// Events feeds /api/events/stream and /api/events/ws
+	Events *events.Stream
+
+	// Journal serves past events from /api/events
+	Journal *events.Journal
 }
 
 // PlayerInfo represents player data for API responses
@@ -152,6 +166,7 @@ type PlayerInfo struct {
 	Faction  string    `json:"faction,omitempty"`
 	Online   bool      `json:"online"`
 	LastSeen time.Time `json:"last_seen,omitempty"`
+	Effects  []string  `json:"effects,omitempty"` // Active status effects, online players only
 }
 
 // ServerStatus represents server status
@@ -235,25 +250,33 @@ func (s *Server) registerRoutes() {
 	s.mux.HandleFunc("/api/status", s.handleStatus)
 
 	// Players
-	s.mux.HandleFunc("/api/players", s.withAuth(s.handlePlayers))
-	s.mux.HandleFunc("/api/players/", s.withAuth(s.handlePlayerByName))
+	s.mux.HandleFunc("/api/players", s.withAuth(ScopeRead, s.handlePlayers))
+	s.mux.HandleFunc("/api/players/", s.withAuth(ScopeRead, s.handlePlayerByName))
 
 	// World
-	s.mux.HandleFunc("/api/world/rooms", s.withAuth(s.handleRooms))
-	s.mux.HandleFunc("/api/world/rooms/", s.withAuth(s.handleRoomByID))
-	s.mux.HandleFunc("/api/world/npcs", s.withAuth(s.handleNPCs))
-	s.mux.HandleFunc("/api/world/items", s.withAuth(s.handleItems))
+	s.mux.HandleFunc("/api/world/rooms", s.withAuth(ScopeRead, s.handleRooms))
+	s.mux.HandleFunc("/api/world/rooms/", s.withAuth(ScopeRead, s.handleRoomByID))
+	s.mux.HandleFunc("/api/world/npcs", s.withAuth(ScopeRead, s.handleNPCs))
+	s.mux.HandleFunc("/api/world/items", s.withAuth(ScopeRead, s.handleItems))
 
 	// Leaderboards
-	s.mux.HandleFunc("/api/leaderboards", s.withAuth(s.handleLeaderboardCategories))
-	s.mux.HandleFunc("/api/leaderboards/", s.withAuth(s.handleLeaderboard))
+	s.mux.HandleFunc("/api/leaderboards", s.withAuth(ScopeRead, s.handleLeaderboardCategories))
+	s.mux.HandleFunc("/api/leaderboards/", s.withAuth(ScopeRead, s.handleLeaderboard))
 
 	// Messages
-	s.mux.HandleFunc("/api/messages", s.withAuth(s.handleSendMessage))
+	s.mux.HandleFunc("/api/messages", s.withAuth(ScopeMessaging, s.handleSendMessage))
+
+	// Events
+	s.mux.HandleFunc("/api/events", s.withAuth(ScopeRead, s.handleEventLog))
+	s.mux.HandleFunc("/api/events/stream", s.withAuth(ScopeRead, s.handleEventStream))
+	s.mux.HandleFunc("/api/events/ws", s.withAuth(ScopeRead, s.handleEventSocket))
+
+	// Key management
+	s.mux.HandleFunc("/api/keys", s.withAuth(ScopeAdmin, s.handleKeys))
 }
 
-// withAuth wraps a handler with authentication
-func (s *Server) withAuth(handler http.HandlerFunc) http.HandlerFunc {
+// withAuth wraps a handler with authentication and requires the given scope
+func (s *Server) withAuth(scope string, handler http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// CORS
 		s.setCORSHeaders(w, r)
@@ -286,15 +309,24 @@ func (s *Server) withAuth(handler http.HandlerFunc) http.HandlerFunc {
 			return
 		}
 
+		if !key.HasScope(scope) {
+			s.writeError(w, "API key lacks the "+scope+" scope", http.StatusForbidden)
+			return
+		}
+
 		// Update last used
+		s.keysMu.Lock()
 		key.LastUsed = time.Now()
+		s.keysMu.Unlock()
 
-		handler(w, r)
+		handler(w, r.WithContext(withKey(r.Context(), key)))
 	}
 }
 
 // validateAPIKey validates an API key
 func (s *Server) validateAPIKey(key string) *APIKey {
+	s.keysMu.RLock()
+	defer s.keysMu.RUnlock()
 	for _, k := range s.config.APIKeys {
 		if k.Enabled && subtle.ConstantTimeCompare([]byte(k.Key), []byte(key)) == 1 {
 			return k
@@ -410,7 +442,16 @@ func (s *Server) handlePlayerByName(w http.ResponseWriter, r *http.Request) {
 	if len(parts) > 1 {
 		switch parts[1] {
 		case "inventory":
-			s.writeSuccess(w, map[string]string{"message": "Inventory endpoint"})
+			// Inventories are private to staff tooling
+			if key := keyFromContext(r.Context()); key == nil || !key.HasScope(ScopeAdmin) {
+				s.writeError(w, "API key lacks the "+ScopeAdmin+" scope", http.StatusForbidden)
+				return
+			}
+			items := []ItemInfo{}
+			if s.GetPlayerInventory != nil {
+				items = s.GetPlayerInventory(player.Name)
+			}
+			s.writeSuccess(w, items)
 		case "stats":
 			s.writeSuccess(w, map[string]interface{}{
 				"name":  player.Name,
@@ -553,6 +594,7 @@ func (s *Server) handleSendMessage(w http.ResponseWriter, r *http.Request) {
 
 	var req struct {
 		Player  string `json:"player"`
+		Channel string `json:"channel"`
 		Message string `json:"message"`
 	}
 
@@ -561,17 +603,23 @@ func (s *Server) handleSendMessage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if req.Player == "" || req.Message == "" {
-		s.writeError(w, "Player and message required", http.StatusBadRequest)
+	if (req.Player == "" && req.Channel == "") || req.Message == "" {
+		s.writeError(w, "Player or channel, and message required", http.StatusBadRequest)
 		return
 	}
 
-	if s.SendMessageToPlayer == nil {
+	send := s.SendMessageToPlayer
+	target := req.Player
+	if req.Player == "" {
+		send = s.SendChannelMessage
+		target = req.Channel
+	}
+	if send == nil {
 		s.writeError(w, "Messaging not available", http.StatusServiceUnavailable)
 		return
 	}
 
-	if err := s.SendMessageToPlayer(req.Player, req.Message); err != nil {
+	if err := send(target, req.Message); err != nil {
 		s.writeError(w, err.Error(), http.StatusBadRequest)
 		return
 	}
@@ -607,6 +655,8 @@ func (s *Server) Handler() http.Handler {
 
 // AddAPIKey adds an API key
 func (s *Server) AddAPIKey(key *APIKey) {
+	s.keysMu.Lock()
+	defer s.keysMu.Unlock()
 	if s.config.APIKeys == nil {
 		s.config.APIKeys = make(map[string]*APIKey)
 	}
@@ -615,15 +665,19 @@ func (s *Server) AddAPIKey(key *APIKey) {
 
 // RemoveAPIKey removes an API key
 func (s *Server) RemoveAPIKey(key string) {
+	s.keysMu.Lock()
+	defer s.keysMu.Unlock()
 	delete(s.config.APIKeys, key)
 }
 
 // ListAPIKeys returns all API keys (without the actual key values)
 func (s *Server) ListAPIKeys() []*APIKey {
+	s.keysMu.RLock()
+	defer s.keysMu.RUnlock()
 	keys := make([]*APIKey, 0, len(s.config.APIKeys))
 	for _, k := range s.config.APIKeys {
 		keys = append(keys, &APIKey{
-			Key:         k.Key[:8] + "...", // Truncate for security
+			Key:         truncateKey(k.Key), // Truncate for security
 			Name:        k.Name,
 			Permissions: k.Permissions,
 			CreatedAt:   k.CreatedAt,
@@ -631,5 +685,6 @@ func (s *Server) ListAPIKeys() []*APIKey {
 			Enabled:     k.Enabled,
 		})
 	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].Name < keys[j].Name })
 	return keys
 }
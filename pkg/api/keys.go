@@ -0,0 +1,226 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// API key scopes
+const (
+	ScopeRead      = "read"      // Query players, world and leaderboards
+	ScopeMessaging = "messaging" // Send messages to players and channels
+	ScopeAdmin     = "admin"     // Everything, including key management
+)
+
+// scopeAliases maps legacy permission names onto scopes
+var scopeAliases = map[string]string{
+	"write": ScopeMessaging,
+}
+
+// ValidScope reports whether scope is a known scope name
+func ValidScope(scope string) bool {
+	switch scope {
+	case ScopeRead, ScopeMessaging, ScopeAdmin:
+		return true
+	}
+	return false
+}
+
+// HasScope reports whether the key grants scope. Every key can read;
+// admin keys are granted every scope.
+func (k *APIKey) HasScope(scope string) bool {
+	if scope == ScopeRead {
+		return true
+	}
+	for _, p := range k.Permissions {
+		if alias, ok := scopeAliases[p]; ok {
+			p = alias
+		}
+		if p == scope || p == ScopeAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+// GenerateKey returns a new random API key
+func GenerateKey() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// LoadAPIKeys reads keys from a JSON file. A missing file yields no keys.
+func LoadAPIKeys(path string) (map[string]*APIKey, error) {
+	keys := make(map[string]*APIKey)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return keys, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var list []*APIKey
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	for _, k := range list {
+		if k.Key != "" {
+			keys[k.Key] = k
+		}
+	}
+	return keys, nil
+}
+
+// SaveAPIKeys writes keys to a JSON file readable only by the owner
+func SaveAPIKeys(path string, keys map[string]*APIKey) error {
+	list := make([]*APIKey, 0, len(keys))
+	for _, k := range keys {
+		list = append(list, k)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// CreateAPIKey generates and registers a key with the given scopes. The
+// returned key holds the only copy of the full key value. If the key file
+// cannot be written the key is still active until restart.
+func (s *Server) CreateAPIKey(name string, scopes []string) (*APIKey, error) {
+	if name == "" {
+		return nil, fmt.Errorf("key name required")
+	}
+	for _, scope := range scopes {
+		if !ValidScope(scope) {
+			return nil, fmt.Errorf("unknown scope %q", scope)
+		}
+	}
+
+	value, err := GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+	key := &APIKey{
+		Key:         value,
+		Name:        name,
+		Permissions: scopes,
+		CreatedAt:   time.Now(),
+		Enabled:     true,
+	}
+
+	s.keysMu.Lock()
+	for _, k := range s.config.APIKeys {
+		if k.Name == name {
+			s.keysMu.Unlock()
+			return nil, fmt.Errorf("a key named %q already exists", name)
+		}
+	}
+	if s.config.APIKeys == nil {
+		s.config.APIKeys = make(map[string]*APIKey)
+	}
+	s.config.APIKeys[key.Key] = key
+	s.keysMu.Unlock()
+
+	return key, s.SaveKeys()
+}
+
+// RevokeAPIKey removes the key with the given name
+func (s *Server) RevokeAPIKey(name string) error {
+	s.keysMu.Lock()
+	found := false
+	for value, k := range s.config.APIKeys {
+		if k.Name == name {
+			delete(s.config.APIKeys, value)
+			found = true
+		}
+	}
+	s.keysMu.Unlock()
+
+	if !found {
+		return fmt.Errorf("no key named %q", name)
+	}
+	return s.SaveKeys()
+}
+
+// SaveKeys persists the current keys to Config.KeysFile, if set
+func (s *Server) SaveKeys() error {
+	if s.config.KeysFile == "" {
+		return nil
+	}
+	s.keysMu.RLock()
+	defer s.keysMu.RUnlock()
+	return SaveAPIKeys(s.config.KeysFile, s.config.APIKeys)
+}
+
+// truncateKey shortens a key value for display
+func truncateKey(key string) string {
+	if len(key) > 8 {
+		key = key[:8]
+	}
+	return key + "..."
+}
+
+// keyContextKey stores the authenticated key on a request context
+type keyContextKey struct{}
+
+func withKey(ctx context.Context, key *APIKey) context.Context {
+	return context.WithValue(ctx, keyContextKey{}, key)
+}
+
+func keyFromContext(ctx context.Context) *APIKey {
+	key, _ := ctx.Value(keyContextKey{}).(*APIKey)
+	return key
+}
+
+// handleKeys lists (GET), creates (POST) and revokes (DELETE ?name=) keys
+func (s *Server) handleKeys(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+		keys := s.ListAPIKeys()
+		s.writeJSON(w, SuccessResponseWithMeta(keys, &Meta{Total: len(keys)}), http.StatusOK)
+
+	case "POST":
+		var req struct {
+			Name   string   `json:"name"`
+			Scopes []string `json:"scopes"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			s.writeError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		key, err := s.CreateAPIKey(req.Name, req.Scopes)
+		if err != nil {
+			s.writeError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.writeJSON(w, SuccessResponse(key), http.StatusCreated)
+
+	case "DELETE":
+		if err := s.RevokeAPIKey(r.URL.Query().Get("name")); err != nil {
+			s.writeError(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		s.writeSuccess(w, map[string]string{"status": "revoked"})
+
+	default:
+		s.writeError(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,162 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestHasScope(t *testing.T) {
+	readOnly := &APIKey{}
+	legacy := &APIKey{Permissions: []string{"read", "write"}}
+	admin := &APIKey{Permissions: []string{ScopeAdmin}}
+
+	if !readOnly.HasScope(ScopeRead) || readOnly.HasScope(ScopeMessaging) {
+		t.Error("Keys without scopes should be read-only")
+	}
+	if !legacy.HasScope(ScopeMessaging) || legacy.HasScope(ScopeAdmin) {
+		t.Error("Legacy write permission should grant messaging only")
+	}
+	if !admin.HasScope(ScopeMessaging) || !admin.HasScope(ScopeAdmin) {
+		t.Error("Admin should grant every scope")
+	}
+}
+
+func TestLoadSaveAPIKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys", "api_keys.json")
+
+	keys, err := LoadAPIKeys(path)
+	if err != nil || len(keys) != 0 {
+		t.Fatalf("Missing file should load empty, got %v, %v", keys, err)
+	}
+
+	keys["abc"] = &APIKey{Key: "abc", Name: "bot", Permissions: []string{ScopeMessaging}, Enabled: true, CreatedAt: time.Now()}
+	if err := SaveAPIKeys(path, keys); err != nil {
+		t.Fatalf("SaveAPIKeys failed: %v", err)
+	}
+
+	loaded, err := LoadAPIKeys(path)
+	if err != nil {
+		t.Fatalf("LoadAPIKeys failed: %v", err)
+	}
+	if k := loaded["abc"]; k == nil || k.Name != "bot" || !k.HasScope(ScopeMessaging) {
+		t.Errorf("Key not round-tripped: %+v", loaded)
+	}
+}
+
+func TestScopeEnforcement(t *testing.T) {
+	config := testConfig()
+	config.APIKeys["reader"] = &APIKey{Key: "reader", Name: "Reader", Permissions: []string{ScopeRead}, Enabled: true}
+	s := NewServer(config, "1.0.0")
+	s.SendMessageToPlayer = func(name, message string) error { return nil }
+	s.GetPlayerByName = func(name string) *PlayerInfo { return &PlayerInfo{Name: name} }
+	s.GetPlayerInventory = func(name string) []ItemInfo { return []ItemInfo{{ID: "phone"}} }
+
+	body, _ := json.Marshal(map[string]string{"player": "Neo", "message": "Hi"})
+	req := httptest.NewRequest("POST", "/api/messages", bytes.NewReader(body))
+	req.Header.Set("X-API-Key", "reader")
+	w := httptest.NewRecorder()
+	s.mux.ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Read-only key sending messages: status = %d, want 403", w.Code)
+	}
+
+	req = httptest.NewRequest("GET", "/api/players/Neo/inventory", nil)
+	req.Header.Set("X-API-Key", "test-key")
+	w = httptest.NewRecorder()
+	s.mux.ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Non-admin inventory: status = %d, want 403", w.Code)
+	}
+
+	s.AddAPIKey(&APIKey{Key: "root", Name: "Root", Permissions: []string{ScopeAdmin}, Enabled: true})
+	req = httptest.NewRequest("GET", "/api/players/Neo/inventory", nil)
+	req.Header.Set("X-API-Key", "root")
+	w = httptest.NewRecorder()
+	s.mux.ServeHTTP(w, req)
+	var resp struct {
+		Data []ItemInfo `json:"data"`
+	}
+	json.NewDecoder(w.Body).Decode(&resp)
+	if w.Code != http.StatusOK || len(resp.Data) != 1 {
+		t.Errorf("Admin inventory: status = %d, data = %+v", w.Code, resp.Data)
+	}
+}
+
+func TestSendChannelMessage(t *testing.T) {
+	s := NewServer(testConfig(), "1.0.0")
+	var got string
+	s.SendChannelMessage = func(channel, message string) error {
+		got = channel + ":" + message
+		return nil
+	}
+
+	body, _ := json.Marshal(map[string]string{"channel": "global", "message": "Wake up"})
+	req := httptest.NewRequest("POST", "/api/messages", bytes.NewReader(body))
+	req.Header.Set("X-API-Key", "test-key")
+	w := httptest.NewRecorder()
+	s.mux.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK || got != "global:Wake up" {
+		t.Errorf("Status = %d, delivered %q", w.Code, got)
+	}
+}
+
+func TestKeyManagementEndpoint(t *testing.T) {
+	config := testConfig()
+	config.KeysFile = filepath.Join(t.TempDir(), "api_keys.json")
+	config.APIKeys["root"] = &APIKey{Key: "root", Name: "Root", Permissions: []string{ScopeAdmin}, Enabled: true}
+	s := NewServer(config, "1.0.0")
+
+	do := func(method, target, key string, body interface{}) *httptest.ResponseRecorder {
+		data, _ := json.Marshal(body)
+		req := httptest.NewRequest(method, target, bytes.NewReader(data))
+		req.Header.Set("X-API-Key", key)
+		w := httptest.NewRecorder()
+		s.mux.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := do("GET", "/api/keys", "test-key", nil); w.Code != http.StatusForbidden {
+		t.Errorf("Non-admin key listing: status = %d, want 403", w.Code)
+	}
+	if w := do("POST", "/api/keys", "root", map[string]interface{}{"name": "bot", "scopes": []string{"superuser"}}); w.Code != http.StatusBadRequest {
+		t.Errorf("Unknown scope: status = %d, want 400", w.Code)
+	}
+
+	w := do("POST", "/api/keys", "root", map[string]interface{}{"name": "bot", "scopes": []string{ScopeMessaging}})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Create: status = %d, want 201", w.Code)
+	}
+	var created struct {
+		Data APIKey `json:"data"`
+	}
+	json.NewDecoder(w.Body).Decode(&created)
+
+	if w := do("POST", "/api/keys", "root", map[string]interface{}{"name": "bot"}); w.Code != http.StatusBadRequest {
+		t.Errorf("Duplicate name: status = %d, want 400", w.Code)
+	}
+
+	persisted, _ := LoadAPIKeys(config.KeysFile)
+	if persisted[created.Data.Key] == nil {
+		t.Error("Created key should be persisted")
+	}
+	if w := do("GET", "/api/players", created.Data.Key, nil); w.Code != http.StatusOK {
+		t.Errorf("Created key should authenticate, got %d", w.Code)
+	}
+
+	if w := do("DELETE", "/api/keys?name=bot", "root", nil); w.Code != http.StatusOK {
+		t.Errorf("Revoke: status = %d, want 200", w.Code)
+	}
+	if w := do("DELETE", "/api/keys?name=bot", "root", nil); w.Code != http.StatusNotFound {
+		t.Errorf("Second revoke: status = %d, want 404", w.Code)
+	}
+	persisted, _ = LoadAPIKeys(config.KeysFile)
+	if persisted[created.Data.Key] != nil {
+		t.Error("Revoked key should be removed from the key file")
+	}
+}
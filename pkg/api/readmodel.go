@@ -0,0 +1,32 @@
+package api
+
+// ReadModel is the view of the running game the API serves. The main
+// application implements it over the live world, leaderboards and chat.
+type ReadModel interface {
+	Status() *ServerStatus
+	OnlinePlayers() []PlayerInfo
+	Player(name string) *PlayerInfo
+	Inventory(name string) []ItemInfo
+	Rooms() []RoomInfo
+	Room(id string) *RoomInfo
+	NPCs() []NPCInfo
+	Items() []ItemInfo
+	Leaderboard(category string, limit int) []LeaderboardEntry
+	SendMessage(player, message string) error
+	SendChannelMessage(channel, message string) error
+}
+
+// UseReadModel wires every data provider to m
+func (s *Server) UseReadModel(m ReadModel) {
+	s.GetServerStatus = m.Status
+	s.GetOnlinePlayers = m.OnlinePlayers
+	s.GetPlayerByName = m.Player
+	s.GetPlayerInventory = m.Inventory
+	s.GetRooms = m.Rooms
+	s.GetRoom = m.Room
+	s.GetNPCs = m.NPCs
+	s.GetItems = m.Items
+	s.GetLeaderboard = m.Leaderboard
+	s.SendMessageToPlayer = m.SendMessage
+	s.SendChannelMessage = m.SendChannelMessage
+}
@@ -0,0 +1,229 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/yourusername/matrix-mud/pkg/events"
+)
+
+// streamHeartbeat is how often idle streams send a keep-alive
+const streamHeartbeat = 15 * time.Second
+
+// streamBuffer is how many events a client may fall behind before it is dropped
+const streamBuffer = 256
+
+// eventFilter parses ?types=combat.*,player.level_up&player=neo&room=dojo
+func eventFilter(r *http.Request) events.StreamFilter {
+	q := r.URL.Query()
+	filter := events.StreamFilter{
+		Player: q.Get("player"),
+		Room:   q.Get("room"),
+	}
+	for _, t := range strings.Split(q.Get("types"), ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			filter.Types = append(filter.Types, t)
+		}
+	}
+	return filter
+}
+
+// handleEventLog pages through the event journal. Pass the returned
+// next_cursor as ?after= to continue; limit defaults to 100 (max 1000).
+func (s *Server) handleEventLog(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		s.writeError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if s.Journal == nil {
+		s.writeError(w, "Event log not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	q := r.URL.Query()
+	var after uint64
+	if v := q.Get("after"); v != "" {
+		parsed, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			s.writeError(w, "Invalid cursor", http.StatusBadRequest)
+			return
+		}
+		after = parsed
+	}
+	limit := 100
+	if l := q.Get("limit"); l != "" {
+		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= 1000 {
+			limit = parsed
+		}
+	}
+
+	head := s.Journal.LastSeq()
+	page, err := s.Journal.Read(after, limit, eventFilter(r))
+	if err != nil {
+		s.writeError(w, "Failed to read event log", http.StatusInternalServerError)
+		return
+	}
+	next := after
+	if len(page) > 0 {
+		next = page[len(page)-1].Seq
+	}
+	if len(page) < limit && head > next {
+		// Everything up to head was scanned; skip past filtered-out events
+		next = head
+	}
+	if page == nil {
+		page = []events.StreamEvent{}
+	}
+	s.writeJSON(w, SuccessResponseWithMeta(page, &Meta{
+		Total:      len(page),
+		NextCursor: next,
+	}), http.StatusOK)
+}
+
+// streamSubscribe subscribes with the request's filters, resuming after the
+// sequence in the Last-Event-ID header or last_event_id parameter
+func (s *Server) streamSubscribe(r *http.Request) *events.StreamSubscriber {
+	q := r.URL.Query()
+	filter := eventFilter(r)
+
+	last := r.Header.Get("Last-Event-ID")
+	if last == "" {
+		last = q.Get("last_event_id")
+	}
+	lastSeq, _ := strconv.ParseUint(last, 10, 64)
+
+	return s.Events.Subscribe(filter, lastSeq, streamBuffer)
+}
+
+// handleEventStream serves events as Server-Sent Events
+func (s *Server) handleEventStream(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "GET" {
+		s.writeError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if s.Events == nil {
+		s.writeError(w, "Event stream not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	// Streams outlive the server's read and write timeouts
+	rc := http.NewResponseController(w)
+	rc.SetReadDeadline(time.Time{})
+	rc.SetWriteDeadline(time.Time{})
+
+	sub := s.streamSubscribe(r)
+	defer sub.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	rc.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+		case se, ok := <-sub.C:
+			if !ok {
+				if sub.Dropped() {
+					fmt.Fprint(w, "event: dropped\ndata: {}\n\n")
+					rc.Flush()
+				}
+				return
+			}
+			data, err := json.Marshal(se.Event)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", se.Seq, se.Event.Type, data)
+		}
+		if rc.Flush() != nil {
+			return
+		}
+	}
+}
+
+// handleEventSocket serves events over a WebSocket as JSON StreamEvents.
+// Browsers cannot set headers on WebSockets, so the key is usually passed
+// as ?api_key=.
+func (s *Server) handleEventSocket(w http.ResponseWriter, r *http.Request) {
+	if s.Events == nil {
+		s.writeError(w, "Event stream not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	upgrader := websocket.Upgrader{CheckOrigin: s.allowedOrigin}
+	ws, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer ws.Close()
+	ws.SetReadDeadline(time.Time{}) // Clear the server's read timeout
+
+	sub := s.streamSubscribe(r)
+	defer sub.Close()
+
+	// Drain client frames so close and ping control messages are handled
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			if _, _, err := ws.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-heartbeat.C:
+			if ws.WriteControl(websocket.PingMessage, nil, time.Now().Add(5*time.Second)) != nil {
+				return
+			}
+		case se, ok := <-sub.C:
+			if !ok {
+				if sub.Dropped() {
+					ws.WriteControl(websocket.CloseMessage,
+						websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "fell behind"),
+						time.Now().Add(5*time.Second))
+				}
+				return
+			}
+			ws.SetWriteDeadline(time.Now().Add(10 * time.Second))
+			if ws.WriteJSON(se) != nil {
+				return
+			}
+		}
+	}
+}
+
+// allowedOrigin checks a WebSocket origin against the CORS origins.
+// Non-browser clients send no Origin and are allowed.
+func (s *Server) allowedOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	for _, o := range s.config.CORSOrigins {
+		if o == "*" || o == origin {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,178 @@
+package api
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/yourusername/matrix-mud/pkg/events"
+)
+
+func streamServer(t *testing.T) (*Server, *httptest.Server) {
+	t.Helper()
+	s := NewServer(testConfig(), "1.0.0")
+	s.Events = events.NewStream(100)
+	ts := httptest.NewServer(s.Handler())
+	t.Cleanup(ts.Close)
+	return s, ts
+}
+
+// waitForSubscribers blocks until n clients are subscribed to the stream
+func waitForSubscribers(t *testing.T, s *Server, n int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for s.Events.SubscriberCount() < n {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected %d subscribers, have %d", n, s.Events.SubscriberCount())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestEventStreamSSE(t *testing.T) {
+	s, ts := streamServer(t)
+	s.Events.Push(events.NewEvent(events.EventPlayerJoin).WithPlayer("Neo", 0))
+	s.Events.Push(events.NewEvent(events.EventNPCKill).WithPlayer("Trinity", 0))
+
+	req, _ := http.NewRequest("GET", ts.URL+"/api/events/stream?types=combat.*,player.level_up", nil)
+	req.Header.Set("X-API-Key", "test-key")
+	req.Header.Set("Last-Event-ID", "1")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type = %q", ct)
+	}
+
+	waitForSubscribers(t, s, 1)
+	s.Events.Push(events.NewEvent(events.EventPlayerLevelUp).WithPlayer("Neo", 0))
+
+	reader := bufio.NewReader(resp.Body)
+	var ids, types []string
+	for len(types) < 2 {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("Read failed: %v", err)
+		}
+		if id, ok := strings.CutPrefix(line, "id: "); ok {
+			ids = append(ids, strings.TrimSpace(id))
+		}
+		if typ, ok := strings.CutPrefix(line, "event: "); ok {
+			types = append(types, strings.TrimSpace(typ))
+		}
+	}
+	if strings.Join(ids, ",") != "2,3" || strings.Join(types, ",") != "combat.npc_kill,player.level_up" {
+		t.Errorf("Got ids %v types %v", ids, types)
+	}
+}
+
+func TestEventStreamRequiresKey(t *testing.T) {
+	_, ts := streamServer(t)
+
+	resp, err := http.Get(ts.URL + "/api/events/stream")
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Status = %d, want 401", resp.StatusCode)
+	}
+}
+
+func TestEventStreamWebSocket(t *testing.T) {
+	s, ts := streamServer(t)
+
+	url := "ws" + strings.TrimPrefix(ts.URL, "http") + "/api/events/ws?api_key=test-key&player=neo"
+	ws, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer ws.Close()
+
+	waitForSubscribers(t, s, 1)
+	s.Events.Push(events.NewEvent(events.EventAuctionSold).WithPlayer("Trinity", 0))
+	s.Events.Push(events.NewEvent(events.EventAchievement).WithPlayer("Neo", 0))
+
+	var se events.StreamEvent
+	ws.SetReadDeadline(time.Now().Add(time.Second))
+	if err := ws.ReadJSON(&se); err != nil {
+		t.Fatalf("ReadJSON failed: %v", err)
+	}
+	if se.Seq != 2 || se.Event.Type != events.EventAchievement {
+		t.Errorf("Got %+v, want Neo's achievement", se)
+	}
+
+	ws.Close()
+	deadline := time.Now().Add(time.Second)
+	for s.Events.SubscriberCount() != 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if s.Events.SubscriberCount() != 0 {
+		t.Error("Closing the socket should unsubscribe")
+	}
+}
+
+func TestEventStreamUnavailable(t *testing.T) {
+	s := NewServer(testConfig(), "1.0.0")
+
+	req := httptest.NewRequest("GET", "/api/events/stream", nil)
+	req.Header.Set("X-API-Key", "test-key")
+	w := httptest.NewRecorder()
+	s.mux.ServeHTTP(w, req)
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Status = %d, want 503", w.Code)
+	}
+}
+
+func TestEventLogEndpoint(t *testing.T) {
+	s := NewServer(testConfig(), "1.0.0")
+	journal, err := events.OpenJournal(events.JournalConfig{Dir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("OpenJournal failed: %v", err)
+	}
+	defer journal.Close()
+	s.Journal = journal
+	for _, typ := range []events.EventType{events.EventNPCKill, events.EventPlayerJoin, events.EventNPCKill, events.EventPlayerLeave} {
+		journal.Append(events.NewEvent(typ))
+	}
+
+	get := func(query string) ([]events.StreamEvent, *Meta, int) {
+		req := httptest.NewRequest("GET", "/api/events"+query, nil)
+		req.Header.Set("X-API-Key", "test-key")
+		w := httptest.NewRecorder()
+		s.mux.ServeHTTP(w, req)
+		var resp struct {
+			Data []events.StreamEvent `json:"data"`
+			Meta *Meta                `json:"meta"`
+		}
+		json.NewDecoder(w.Body).Decode(&resp)
+		return resp.Data, resp.Meta, w.Code
+	}
+
+	page, meta, code := get("?limit=2")
+	if code != http.StatusOK || len(page) != 2 || meta.NextCursor != 2 {
+		t.Fatalf("First page: status %d, %d events, meta %+v", code, len(page), meta)
+	}
+	page, meta, _ = get("?after=2&limit=2")
+	if len(page) != 2 || page[0].Seq != 3 || meta.NextCursor != 4 {
+		t.Errorf("Second page: %+v, meta %+v", page, meta)
+	}
+
+	// A filtered page that comes up short advances the cursor to the head
+	page, meta, _ = get("?types=combat.*&after=3")
+	if len(page) != 0 || meta.NextCursor != 4 {
+		t.Errorf("Filtered page: %+v, meta %+v", page, meta)
+	}
+
+	if _, _, code := get("?after=abc"); code != http.StatusBadRequest {
+		t.Errorf("Invalid cursor: status %d, want 400", code)
+	}
+}
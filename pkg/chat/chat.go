@@ -52,6 +52,8 @@ type Manager struct {
 	MessageHistory map[string][]Message       // channel ID -> recent messages
 	messageID      int64
 	rateLimits     map[string][]time.Time // player -> message timestamps
+	listeners      []func(Message)
+	sendCheck      func(player string) error
 }
 
 // Profanity filter patterns
@@ -223,6 +225,11 @@ func (m *Manager) SendMessage(playerName, channelID, content string) ([]string,
 	if !ok {
 		return nil, fmt.Errorf("channel '%s' not found", channelID)
 	}
+	if m.sendCheck != nil {
+		if err := m.sendCheck(playerName); err != nil {
+			return nil, err
+		}
+	}
 
 	channel.mu.Lock()
 	defer channel.mu.Unlock()
@@ -271,20 +278,99 @@ func (m *Manager) SendMessage(playerName, channelID, content string) ([]string,
 	}
 	m.MessageHistory[channelID] = append(history, msg)
 
-	// Get recipients (members not ignoring sender)
+	for _, fn := range m.listeners {
+		fn(msg)
+	}
+
+	return m.recipients(channel, name), nil
+}
+
+// recipients returns channel members other than sender who are not ignoring
+// sender; m.mu and channel.mu are held
+func (m *Manager) recipients(channel *Channel, sender string) []string {
 	recipients := make([]string, 0)
 	for member := range channel.Members {
-		if member == name {
+		if member == sender {
 			continue
 		}
 		// Check if recipient is ignoring sender
-		if ignored, ok := m.Ignored[member]; ok && ignored[name] {
+		if ignored, ok := m.Ignored[member]; ok && ignored[sender] {
 			continue
 		}
 		recipients = append(recipients, member)
 	}
+	return recipients
+}
+
+// OnMessage registers fn to be called with every message players send.
+// fn runs while the manager is locked, so it must not block or call back
+// into the Manager.
+func (m *Manager) OnMessage(fn func(Message)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.listeners = append(m.listeners, fn)
+}
+
+// SetSendCheck registers fn to be asked before every message a player sends
+// on any channel, such as a server-wide mute. An error stops the message and
+// is returned to the sender. fn runs while the manager is locked.
+func (m *Manager) SetSendCheck(fn func(player string) error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sendCheck = fn
+}
+
+// RelayMessage posts a message from outside the game, such as a bridged IRC
+// user, to a channel. The sender need not be a member, but the channel's
+// mute list, the profanity filter and players' ignore lists still apply.
+// Relayed messages are not passed to OnMessage listeners.
+func (m *Manager) RelayMessage(sender, channelID, content string) (Message, []string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	name := strings.ToLower(sender)
+	channel, ok := m.Channels[channelID]
+	if !ok {
+		return Message{}, nil, fmt.Errorf("channel '%s' not found", channelID)
+	}
+
+	channel.mu.Lock()
+	defer channel.mu.Unlock()
+
+	if muteExpires, muted := channel.Muted[name]; muted {
+		if time.Now().Before(muteExpires) {
+			return Message{}, nil, fmt.Errorf("%s is muted in '%s'", sender, channel.Name)
+		}
+		delete(channel.Muted, name)
+	}
+
+	m.messageID++
+	msg := Message{
+		ID:        m.messageID,
+		Channel:   channelID,
+		Sender:    sender,
+		Content:   m.filterProfanity(content),
+		Timestamp: time.Now(),
+	}
+
+	history := m.MessageHistory[channelID]
+	if len(history) >= 100 {
+		history = history[1:]
+	}
+	m.MessageHistory[channelID] = append(history, msg)
+
+	return msg, m.recipients(channel, name), nil
+}
 
-	return recipients, nil
+// hasPosted reports whether sender appears in a channel's recent history;
+// m.mu is held
+func (m *Manager) hasPosted(channelID, sender string) bool {
+	for _, msg := range m.MessageHistory[channelID] {
+		if strings.ToLower(msg.Sender) == sender {
+			return true
+		}
+	}
+	return false
 }
 
 // checkRateLimit checks if a player can send a message
@@ -367,8 +453,8 @@ func (m *Manager) MutePlayer(moderatorName, targetName, channelID string, durati
 		return fmt.Errorf("you are not a moderator of '%s'", channel.Name)
 	}
 
-	// Check target is in channel
-	if !channel.Members[targName] {
+	// Check target is in channel, or is a relayed sender who posted to it
+	if !channel.Members[targName] && !m.hasPosted(channelID, targName) {
 		return fmt.Errorf("%s is not in this channel", targetName)
 	}
 
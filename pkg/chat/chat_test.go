@@ -114,6 +114,27 @@ func TestSendMessageNotInChannel(t *testing.T) {
 	}
 }
 
+func TestSendCheck(t *testing.T) {
+	m := NewManager()
+	m.JoinChannel("Cypher", "global")
+	m.JoinChannel("Cypher", "trade")
+	m.SetSendCheck(func(player string) error {
+		if player == "Cypher" {
+			return fmt.Errorf("you are silenced")
+		}
+		return nil
+	})
+
+	for _, id := range []string{"global", "trade"} {
+		if _, err := m.SendMessage("Cypher", id, "Hello"); err == nil || err.Error() != "you are silenced" {
+			t.Errorf("send on %s = %v, want the check's error", id, err)
+		}
+	}
+	if len(m.MessageHistory["global"]) != 0 {
+		t.Error("a stopped message should not reach the history")
+	}
+}
+
 func TestProfanityFilter(t *testing.T) {
 	m := NewManager()
 
@@ -405,3 +426,52 @@ func TestCaseInsensitivePlayerNames(t *testing.T) {
 		t.Error("Should be case insensitive (uppercase)")
 	}
 }
+
+func TestOnMessage(t *testing.T) {
+	m := NewManager()
+	m.JoinChannel("Neo", "global")
+
+	var got []Message
+	m.OnMessage(func(msg Message) { got = append(got, msg) })
+
+	m.SendMessage("Neo", "global", "what the fuck")
+	if len(got) != 1 || got[0].Sender != "Neo" || got[0].Channel != "global" {
+		t.Fatalf("Listener got %+v", got)
+	}
+	if got[0].Content != "what the ****" {
+		t.Errorf("Listener should see filtered content, got %q", got[0].Content)
+	}
+
+	m.RelayMessage("[IRC] tank", "global", "hello")
+	if len(got) != 1 {
+		t.Error("Relayed messages should not reach listeners")
+	}
+}
+
+func TestRelayMessage(t *testing.T) {
+	m := NewManager()
+	m.JoinChannel("Neo", "global")
+	m.JoinChannel("Trinity", "global")
+	m.IgnorePlayer("Trinity", "[IRC] tank")
+
+	msg, recipients, err := m.RelayMessage("[IRC] tank", "global", "oh shit")
+	if err != nil {
+		t.Fatalf("RelayMessage failed: %v", err)
+	}
+	if msg.Content != "oh ****" {
+		t.Errorf("Content = %q, want filtered", msg.Content)
+	}
+	if len(recipients) != 1 || recipients[0] != "neo" {
+		t.Errorf("Recipients = %v, want [neo]", recipients)
+	}
+
+	if _, _, err := m.RelayMessage("[IRC] tank", "nowhere", "hi"); err == nil {
+		t.Error("Unknown channel should fail")
+	}
+
+	m.AddModerator("global", "Morpheus")
+	m.MutePlayer("Morpheus", "[IRC] tank", "global", time.Minute)
+	if _, _, err := m.RelayMessage("[IRC] tank", "global", "hi"); err == nil {
+		t.Error("Muted relay sender should be rejected")
+	}
+}
@@ -46,42 +46,54 @@ var AbilityCooldowns = map[string]time.Duration{
 	"use":  2 * time.Second,
 }
 
+// cooldownsMu guards AbilityCooldowns once skills register their own
+var cooldownsMu sync.RWMutex
+
+// SetCooldown sets the cooldown duration for an ability
+func SetCooldown(ability string, d time.Duration) {
+	cooldownsMu.Lock()
+	defer cooldownsMu.Unlock()
+	AbilityCooldowns[ability] = d
+}
+
+// Duration returns the cooldown duration for an ability, 5s if it has none
+func Duration(ability string) time.Duration {
+	cooldownsMu.RLock()
+	defer cooldownsMu.RUnlock()
+	if d, ok := AbilityCooldowns[ability]; ok {
+		return d
+	}
+	return 5 * time.Second // Default cooldown
+}
+
 // Use marks an ability as used, starting its cooldown
 func (m *Manager) Use(playerName, ability string) {
+	m.UseAt(playerName, ability, time.Now())
+}
+
+// UseAt marks an ability as used at now, for callers with their own clock
+func (m *Manager) UseAt(playerName, ability string, now time.Time) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
 	if m.cooldowns[playerName] == nil {
 		m.cooldowns[playerName] = make(map[string]time.Time)
 	}
-	m.cooldowns[playerName][ability] = time.Now()
+	m.cooldowns[playerName][ability] = now
 }
 
 // IsReady checks if an ability is off cooldown
 func (m *Manager) IsReady(playerName, ability string) bool {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
-
-	playerCDs, ok := m.cooldowns[playerName]
-	if !ok {
-		return true
-	}
-
-	lastUsed, ok := playerCDs[ability]
-	if !ok {
-		return true
-	}
-
-	cooldown, ok := AbilityCooldowns[ability]
-	if !ok {
-		cooldown = 5 * time.Second // Default cooldown
-	}
-
-	return time.Since(lastUsed) >= cooldown
+	return m.TimeRemainingAt(playerName, ability, time.Now()) == 0
 }
 
 // TimeRemaining returns how long until the ability is ready
 func (m *Manager) TimeRemaining(playerName, ability string) time.Duration {
+	return m.TimeRemainingAt(playerName, ability, time.Now())
+}
+
+// TimeRemainingAt returns how long after now until the ability is ready
+func (m *Manager) TimeRemainingAt(playerName, ability string, now time.Time) time.Duration {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
@@ -95,12 +107,9 @@ func (m *Manager) TimeRemaining(playerName, ability string) time.Duration {
 		return 0
 	}
 
-	cooldown, ok := AbilityCooldowns[ability]
-	if !ok {
-		cooldown = 5 * time.Second
-	}
+	cooldown := Duration(ability)
 
-	remaining := cooldown - time.Since(lastUsed)
+	remaining := cooldown - now.Sub(lastUsed)
 	if remaining < 0 {
 		return 0
 	}
@@ -109,6 +118,11 @@ func (m *Manager) TimeRemaining(playerName, ability string) time.Duration {
 
 // GetAllCooldowns returns all active cooldowns for a player
 func (m *Manager) GetAllCooldowns(playerName string) map[string]time.Duration {
+	return m.GetAllCooldownsAt(playerName, time.Now())
+}
+
+// GetAllCooldownsAt returns all of a player's cooldowns still active at now
+func (m *Manager) GetAllCooldownsAt(playerName string, now time.Time) map[string]time.Duration {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
@@ -120,12 +134,9 @@ func (m *Manager) GetAllCooldowns(playerName string) map[string]time.Duration {
 	}
 
 	for ability, lastUsed := range playerCDs {
-		cooldown, ok := AbilityCooldowns[ability]
-		if !ok {
-			cooldown = 5 * time.Second
-		}
+		cooldown := Duration(ability)
 
-		remaining := cooldown - time.Since(lastUsed)
+		remaining := cooldown - now.Sub(lastUsed)
 		if remaining > 0 {
 			result[ability] = remaining
 		}
@@ -193,3 +193,35 @@ func TestCleanup(t *testing.T) {
 		t.Error("Recent cooldown should survive cleanup")
 	}
 }
+
+func TestSetCooldown(t *testing.T) {
+	SetCooldown("test_skill", time.Minute)
+	if got := Duration("test_skill"); got != time.Minute {
+		t.Errorf("Duration = %v, want 1m", got)
+	}
+	if got := Duration("never_registered"); got != 5*time.Second {
+		t.Errorf("default Duration = %v, want 5s", got)
+	}
+
+	m := NewManager()
+	m.Use("player1", "test_skill")
+	if r := m.TimeRemaining("player1", "test_skill"); r < 59*time.Second {
+		t.Errorf("TimeRemaining = %v, want about 1m", r)
+	}
+}
+
+func TestUseAt(t *testing.T) {
+	m := NewManager()
+	start := time.Unix(1000, 0)
+	m.UseAt("player1", "glitch", start)
+
+	if got := m.TimeRemainingAt("player1", "glitch", start.Add(2*time.Second)); got != 3*time.Second {
+		t.Errorf("remaining after 2s = %v, want 3s", got)
+	}
+	if got := m.TimeRemainingAt("player1", "glitch", start.Add(5*time.Second)); got != 0 {
+		t.Errorf("remaining after 5s = %v, want 0", got)
+	}
+	if cds := m.GetAllCooldownsAt("player1", start.Add(time.Second)); cds["glitch"] != 4*time.Second {
+		t.Errorf("cooldowns = %v", cds)
+	}
+}
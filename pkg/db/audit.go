@@ -57,7 +57,7 @@ func (r *AuditRepository) Log(entry *AuditEntry) error {
 	_, err := r.db.Exec(`
 		INSERT INTO audit_log (player_id, player_name, action, details, ip_address, timestamp)
 		VALUES (?, ?, ?, ?, ?, ?)
-	`, entry.PlayerID, entry.PlayerName, string(entry.Action), entry.Details, entry.IPAddress, time.Now())
+	`, entry.PlayerID, entry.PlayerName, string(entry.Action), entry.Details, entry.IPAddress, time.Now().UTC())
 	if err != nil {
 		return fmt.Errorf("failed to create audit log: %w", err)
 	}
@@ -133,14 +133,15 @@ func (r *AuditRepository) GetRecent(limit int) ([]*AuditLog, error) {
 	return r.scanLogs(rows)
 }
 
-// GetInTimeRange returns audit logs within a time range
+// GetInTimeRange returns audit logs within a time range. Times may be in
+// any zone; entries are stored in UTC.
 func (r *AuditRepository) GetInTimeRange(start, end time.Time, limit int) ([]*AuditLog, error) {
 	rows, err := r.db.Query(`
 		SELECT id, player_id, player_name, action, details, ip_address, timestamp
 		FROM audit_log 
 		WHERE timestamp BETWEEN ? AND ?
 		ORDER BY timestamp DESC LIMIT ?
-	`, start, end, limit)
+	`, start.UTC(), end.UTC(), limit)
 	if err != nil {
 		return nil, err
 	}
@@ -164,6 +165,55 @@ func (r *AuditRepository) Search(query string, limit int) ([]*AuditLog, error) {
 	return r.scanLogs(rows)
 }
 
+// AuditFilter narrows an audit log query. Zero-value fields are ignored.
+type AuditFilter struct {
+	PlayerName string      // Exact match, case-insensitive
+	Action     AuditAction // Exact match
+	Query      string      // Substring match against details
+	Since      time.Time   // Entries at or after this time
+	Limit      int         // Defaults to 50
+}
+
+// Find returns audit logs matching all set fields of the filter, newest first
+func (r *AuditRepository) Find(filter AuditFilter) ([]*AuditLog, error) {
+	query := `
+		SELECT id, player_id, player_name, action, details, ip_address, timestamp
+		FROM audit_log WHERE 1=1`
+	var args []interface{}
+
+	if filter.PlayerName != "" {
+		query += " AND player_name = ? COLLATE NOCASE"
+		args = append(args, filter.PlayerName)
+	}
+	if filter.Action != "" {
+		query += " AND action = ?"
+		args = append(args, string(filter.Action))
+	}
+	if filter.Query != "" {
+		query += " AND details LIKE ?"
+		args = append(args, "%"+filter.Query+"%")
+	}
+	if !filter.Since.IsZero() {
+		query += " AND timestamp >= ?"
+		args = append(args, filter.Since.UTC()) // Entries are stored in UTC
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = 50
+	}
+	query += " ORDER BY timestamp DESC, id DESC LIMIT ?"
+	args = append(args, limit)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return r.scanLogs(rows)
+}
+
 // GetCount returns total count of audit logs
 func (r *AuditRepository) GetCount() (int, error) {
 	var count int
@@ -180,7 +230,7 @@ func (r *AuditRepository) GetCountByAction(action AuditAction) (int, error) {
 
 // Cleanup removes audit logs older than specified duration
 func (r *AuditRepository) Cleanup(olderThan time.Duration) (int64, error) {
-	cutoff := time.Now().Add(-olderThan)
+	cutoff := time.Now().UTC().Add(-olderThan)
 	result, err := r.db.Exec("DELETE FROM audit_log WHERE timestamp < ?", cutoff)
 	if err != nil {
 		return 0, err
@@ -208,7 +258,7 @@ func (r *AuditRepository) scanLogs(rows *sql.Rows) ([]*AuditLog, error) {
 			log.PlayerID = playerID.Int64
 		}
 		if playerName.Valid {
-			log.Details = playerName.String
+			log.PlayerName = playerName.String
 		}
 		if details.Valid {
 			log.Details = details.String
@@ -145,6 +145,52 @@ func TestAuditSearch(t *testing.T) {
 	}
 }
 
+func TestAuditFind(t *testing.T) {
+	db, repo := setupAuditTestDB(t)
+	defer db.Close()
+
+	repo.LogAction(1, "Neo", AuditLogin, "Login from 10.0.0.1")
+	repo.LogAction(1, "Neo", AuditTrade, "Traded 500 bits")
+	repo.LogAction(2, "Smith", AuditTrade, "Traded a Cyberdeck")
+
+	logs, err := repo.Find(AuditFilter{PlayerName: "neo"})
+	if err != nil {
+		t.Fatalf("Find failed: %v", err)
+	}
+	if len(logs) != 2 {
+		t.Errorf("Expected 2 logs for neo, got %d", len(logs))
+	}
+	for _, l := range logs {
+		if l.PlayerName != "Neo" {
+			t.Errorf("Expected player name Neo, got %q", l.PlayerName)
+		}
+	}
+
+	logs, _ = repo.Find(AuditFilter{Action: AuditTrade, Query: "Cyberdeck"})
+	if len(logs) != 1 || logs[0].PlayerName != "Smith" {
+		t.Errorf("Expected Smith's trade, got %+v", logs)
+	}
+
+	logs, _ = repo.Find(AuditFilter{PlayerName: "Neo", Action: AuditTrade, Limit: 1})
+	if len(logs) != 1 || logs[0].Details != "Traded 500 bits" {
+		t.Errorf("Expected Neo's trade, got %+v", logs)
+	}
+
+	logs, _ = repo.Find(AuditFilter{Since: time.Now().Add(time.Hour)})
+	if len(logs) != 0 {
+		t.Errorf("Expected no future logs, got %d", len(logs))
+	}
+
+	// Since may be given in any zone
+	east, west := time.FixedZone("UTC+10", 10*3600), time.FixedZone("UTC-10", -10*3600)
+	if logs, _ = repo.Find(AuditFilter{Since: time.Now().Add(-time.Minute).In(east)}); len(logs) != 3 {
+		t.Errorf("Expected 3 logs since a minute ago east of UTC, got %d", len(logs))
+	}
+	if logs, _ = repo.Find(AuditFilter{Since: time.Now().Add(time.Minute).In(west)}); len(logs) != 0 {
+		t.Errorf("Expected no logs a minute ahead west of UTC, got %d", len(logs))
+	}
+}
+
 func TestAuditGetCount(t *testing.T) {
 	db, repo := setupAuditTestDB(t)
 	defer db.Close()
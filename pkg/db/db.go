@@ -82,12 +82,13 @@ type PlayerFaction struct {
 
 // AuditLog represents an audit log entry
 type AuditLog struct {
-	ID        int64
-	PlayerID  int64
-	Action    string
-	Details   string
-	IPAddress string
-	Timestamp time.Time
+	ID         int64
+	PlayerID   int64
+	PlayerName string
+	Action     string
+	Details    string
+	IPAddress  string
+	Timestamp  time.Time
 }
 
 // WorldState represents persistent world state
@@ -0,0 +1,348 @@
+// Package effects provides timed status effects for players and NPCs.
+// Effect definitions are loaded from data/effects.json; an Active effect is
+// one applied to a character, with its own expiry and stack count. Effects
+// can modify armor class and damage, change HP and MP every interval
+// (bleeding, regeneration) and stop a character acting (stun, root,
+// silence). An effect with a damage type can be resisted. The world tick
+// advances them.
+package effects
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yourusername/matrix-mud/pkg/game"
+	"github.com/yourusername/matrix-mud/pkg/logging"
+)
+
+// Control effects stop a character doing things while they last
+const (
+	Stun    = "stun"    // No attacking, casting or moving
+	Root    = "root"    // No moving or fleeing
+	Silence = "silence" // No casting
+)
+
+// Stacking rules for reapplying an active effect
+const (
+	StackRefresh = "refresh" // Restart the duration (default)
+	StackAdd     = "stack"   // Add a stack, up to MaxStacks, and restart the duration
+	StackExtend  = "extend"  // Add the duration to the time left, up to three times the duration
+)
+
+// DefaultInterval is how often periodic effects tick when they don't say
+const DefaultInterval = 2 * time.Second
+
+// Def describes a status effect
+type Def struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Duration    string   `json:"duration"`           // Go duration, e.g. "20s"
+	AC          int      `json:"ac"`                 // Added to armor class; negative weakens
+	Damage      int      `json:"damage"`             // Added to damage dealt
+	HP          int      `json:"hp,omitempty"`       // Per interval; negative hurts
+	MP          int      `json:"mp,omitempty"`       // Per interval; negative drains
+	Interval    string   `json:"interval,omitempty"` // Go duration between HP/MP ticks
+	Control     []string `json:"control,omitempty"`  // stun, root, silence
+	Type        string   `json:"type,omitempty"`     // Damage type; resistance to it can ward the effect off
+	Stacking    string   `json:"stacking,omitempty"` // refresh, stack, extend
+	MaxStacks   int      `json:"max_stacks,omitempty"`
+
+	duration time.Duration
+	interval time.Duration
+}
+
+// Length returns how long the effect lasts
+func (d *Def) Length() time.Duration {
+	return d.duration
+}
+
+// Periodic reports whether the effect changes HP or MP over time
+func (d *Def) Periodic() bool {
+	return d.HP != 0 || d.MP != 0
+}
+
+// Controls reports whether the effect applies a control kind
+func (d *Def) Controls(kind string) bool {
+	for _, c := range d.Control {
+		if c == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// validate parses the durations and checks the rules
+func (d *Def) validate() error {
+	var err error
+	if d.duration, err = time.ParseDuration(d.Duration); err != nil || d.duration <= 0 {
+		return fmt.Errorf("invalid duration %q", d.Duration)
+	}
+	d.interval = DefaultInterval
+	if d.Interval != "" {
+		if d.interval, err = time.ParseDuration(d.Interval); err != nil || d.interval <= 0 {
+			return fmt.Errorf("invalid interval %q", d.Interval)
+		}
+	}
+	switch d.Stacking {
+	case "":
+		d.Stacking = StackRefresh
+	case StackRefresh, StackAdd, StackExtend:
+	default:
+		return fmt.Errorf("unknown stacking %q", d.Stacking)
+	}
+	if d.MaxStacks < 1 {
+		d.MaxStacks = 1
+		if d.Stacking == StackAdd {
+			d.MaxStacks = 5
+		}
+	}
+	for _, c := range d.Control {
+		if c != Stun && c != Root && c != Silence {
+			return fmt.Errorf("unknown control %q", c)
+		}
+	}
+	if !game.ValidDamageType(d.Type) {
+		return fmt.Errorf("unknown damage type %q", d.Type)
+	}
+	return nil
+}
+
+// EffectsData is the JSON structure for effects.json
+type EffectsData struct {
+	Effects map[string]*Def `json:"effects"`
+}
+
+// Manager holds the effect definitions
+type Manager struct {
+	Effects map[string]*Def
+}
+
+// NewManager creates a manager and loads data/effects.json
+func NewManager() *Manager {
+	m := &Manager{Effects: make(map[string]*Def)}
+	m.Load("data/effects.json")
+	return m
+}
+
+// Load replaces the definitions with those in path, keeping the current
+// ones if the file cannot be read
+func (m *Manager) Load(path string) error {
+	file, err := os.ReadFile(path)
+	if err != nil {
+		logging.Warn().Err(err).Str("path", path).Msg("Could not read effects file")
+		return err
+	}
+	var data EffectsData
+	if err := json.Unmarshal(file, &data); err != nil {
+		logging.Warn().Err(err).Str("path", path).Msg("Could not parse effects file")
+		return err
+	}
+	defs := make(map[string]*Def, len(data.Effects))
+	for id, def := range data.Effects {
+		def.ID = id
+		if def.Name == "" {
+			def.Name = id
+		}
+		if err := def.validate(); err != nil {
+			logging.Warn().Err(err).Str("effect", id).Msg("Skipping invalid effect")
+			continue
+		}
+		defs[id] = def
+	}
+	m.Effects = defs
+	return nil
+}
+
+// Get returns the definition of an effect, or nil
+func (m *Manager) Get(id string) *Def {
+	return m.Effects[id]
+}
+
+// Active is an effect applied to a character
+type Active struct {
+	Def      *Def
+	Expires  time.Time
+	Stacks   int
+	Source   string // Who applied it
+	nextTick time.Time
+}
+
+// Remaining returns how long the effect has left at now
+func (a *Active) Remaining(now time.Time) time.Duration {
+	return a.Expires.Sub(now)
+}
+
+// Tick is the HP and MP change from one periodic effect during Advance
+type Tick struct {
+	Def    *Def
+	HP, MP int
+	Source string
+}
+
+// Set is the effects on one character. The zero value is empty and ready
+// to use. It is not safe for concurrent use; the world lock guards it.
+type Set struct {
+	active []*Active
+}
+
+// Apply adds an effect, or reapplies it by its stacking rule if it is
+// already active
+func (s *Set) Apply(def *Def, now time.Time, source string) *Active {
+	s.prune(now)
+	for _, a := range s.active {
+		if a.Def.ID != def.ID {
+			continue
+		}
+		switch def.Stacking {
+		case StackAdd:
+			if a.Stacks < def.MaxStacks {
+				a.Stacks++
+			}
+			a.Expires = now.Add(def.duration)
+		case StackExtend:
+			a.Expires = a.Expires.Add(def.duration)
+			if limit := now.Add(3 * def.duration); a.Expires.After(limit) {
+				a.Expires = limit
+			}
+		default:
+			a.Expires = now.Add(def.duration)
+		}
+		a.Source = source
+		return a
+	}
+	a := &Active{Def: def, Expires: now.Add(def.duration), Stacks: 1, Source: source, nextTick: now.Add(def.interval)}
+	s.active = append(s.active, a)
+	return a
+}
+
+// Remove ends an effect early and reports whether it was active
+func (s *Set) Remove(id string) bool {
+	for i, a := range s.active {
+		if a.Def.ID == id {
+			s.active = append(s.active[:i], s.active[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Has reports whether an effect is active at now
+func (s *Set) Has(id string, now time.Time) bool {
+	for _, a := range s.active {
+		if a.Def.ID == id && now.Before(a.Expires) {
+			return true
+		}
+	}
+	return false
+}
+
+// Controlled reports whether any effect active at now applies a control kind
+func (s *Set) Controlled(kind string, now time.Time) bool {
+	for _, a := range s.active {
+		if now.Before(a.Expires) && a.Def.Controls(kind) {
+			return true
+		}
+	}
+	return false
+}
+
+// List returns the effects active at now, soonest to expire first
+func (s *Set) List(now time.Time) []*Active {
+	var list []*Active
+	for _, a := range s.active {
+		if now.Before(a.Expires) {
+			list = append(list, a)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Expires.Before(list[j].Expires) })
+	return list
+}
+
+// Modifiers returns the total AC and damage bonuses active at now, each
+// effect counted once per stack
+func (s *Set) Modifiers(now time.Time) (ac, damage int) {
+	for _, a := range s.active {
+		if now.Before(a.Expires) {
+			ac += a.Def.AC * a.Stacks
+			damage += a.Def.Damage * a.Stacks
+		}
+	}
+	return ac, damage
+}
+
+// Advance moves the effects on to now. It returns the HP and MP changes
+// of periodic effects whose intervals have passed, including a final tick
+// that falls on the expiry, and the effects that have worn off.
+func (s *Set) Advance(now time.Time) (ticks []Tick, expired []*Def) {
+	for _, a := range s.active {
+		if !a.Def.Periodic() {
+			continue
+		}
+		t := Tick{Def: a.Def, Source: a.Source}
+		for !a.nextTick.After(now) && !a.nextTick.After(a.Expires) {
+			t.HP += a.Def.HP * a.Stacks
+			t.MP += a.Def.MP * a.Stacks
+			a.nextTick = a.nextTick.Add(a.Def.interval)
+		}
+		if t.HP != 0 || t.MP != 0 {
+			ticks = append(ticks, t)
+		}
+	}
+	kept := s.active[:0]
+	for _, a := range s.active {
+		if now.Before(a.Expires) {
+			kept = append(kept, a)
+		} else {
+			expired = append(expired, a.Def)
+		}
+	}
+	s.active = kept
+	return ticks, expired
+}
+
+// Clear removes every effect
+func (s *Set) Clear() {
+	s.active = nil
+}
+
+// Names returns the IDs of the effects active at now, soonest to expire first
+func (s *Set) Names(now time.Time) []string {
+	var names []string
+	for _, a := range s.List(now) {
+		names = append(names, a.Def.ID)
+	}
+	return names
+}
+
+// String lists the active effects with their stacks and time left, e.g.
+// "Bleeding x2 (4s), Fortified (12s)", or "" if there are none
+func (s *Set) String(now time.Time) string {
+	var parts []string
+	for _, a := range s.List(now) {
+		name := a.Def.Name
+		if a.Stacks > 1 {
+			name = fmt.Sprintf("%s x%d", name, a.Stacks)
+		}
+		parts = append(parts, fmt.Sprintf("%s (%ds)", name, int(a.Remaining(now).Round(time.Second).Seconds())))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// prune drops expired effects
+func (s *Set) prune(now time.Time) {
+	kept := s.active[:0]
+	for _, a := range s.active {
+		if now.Before(a.Expires) {
+			kept = append(kept, a)
+		}
+	}
+	s.active = kept
+}
+
+// GlobalEffects holds the effect definitions loaded at startup
+var GlobalEffects = NewManager()
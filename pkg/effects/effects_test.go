@@ -0,0 +1,138 @@
+package effects
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testDef builds a validated definition
+func testDef(t *testing.T, def Def) *Def {
+	t.Helper()
+	if def.Name == "" {
+		def.Name = def.ID
+	}
+	if err := def.validate(); err != nil {
+		t.Fatalf("%s: %v", def.ID, err)
+	}
+	return &def
+}
+
+func TestSetApplyAndExpire(t *testing.T) {
+	now := time.Unix(1000, 0)
+	var s Set
+	s.Apply(testDef(t, Def{ID: "fortified", Duration: "10s", AC: 4}), now, "Neo")
+	s.Apply(testDef(t, Def{ID: "overclocked", Duration: "5s", Damage: 3}), now, "Neo")
+
+	if ac, dmg := s.Modifiers(now); ac != 4 || dmg != 3 {
+		t.Errorf("Modifiers = %d, %d", ac, dmg)
+	}
+	if got := s.String(now); got != "overclocked (5s), fortified (10s)" {
+		t.Errorf("String = %q", got)
+	}
+
+	later := now.Add(6 * time.Second)
+	if s.Has("overclocked", later) || !s.Has("fortified", later) {
+		t.Error("overclocked should have expired before fortified")
+	}
+	if ac, dmg := s.Modifiers(later); ac != 4 || dmg != 0 {
+		t.Errorf("Modifiers after expiry = %d, %d", ac, dmg)
+	}
+	if _, expired := s.Advance(later); len(expired) != 1 || expired[0].ID != "overclocked" {
+		t.Errorf("expired = %v", expired)
+	}
+
+	// Reapplying refreshes instead of stacking
+	s.Apply(testDef(t, Def{ID: "fortified", Duration: "10s", AC: 4}), later, "Neo")
+	if list := s.List(later); len(list) != 1 || list[0].Remaining(later) != 10*time.Second || list[0].Stacks != 1 {
+		t.Errorf("List after refresh = %+v", list)
+	}
+
+	if !s.Remove("fortified") || s.Remove("fortified") {
+		t.Error("Remove should succeed once")
+	}
+}
+
+func TestStacking(t *testing.T) {
+	now := time.Unix(1000, 0)
+	var s Set
+	bleed := testDef(t, Def{ID: "bleeding", Duration: "10s", AC: -1, Stacking: StackAdd, MaxStacks: 3})
+	for i := 0; i < 5; i++ {
+		s.Apply(bleed, now, "Smith")
+	}
+	if ac, _ := s.Modifiers(now); ac != -3 {
+		t.Errorf("three stacks AC = %d", ac)
+	}
+	if got := s.String(now); got != "bleeding x3 (10s)" {
+		t.Errorf("String = %q", got)
+	}
+
+	stun := testDef(t, Def{ID: "stunned", Duration: "4s", Stacking: StackExtend, Control: []string{Stun}})
+	s.Apply(stun, now, "")
+	s.Apply(stun, now, "")
+	s.Apply(stun, now, "")
+	s.Apply(stun, now, "")
+	if got := s.List(now)[1]; got.Def.ID != "stunned" || got.Remaining(now) != 12*time.Second {
+		t.Errorf("extended stun = %+v, %v left", got.Def, got.Remaining(now))
+	}
+	if !s.Controlled(Stun, now) || s.Controlled(Silence, now) {
+		t.Error("stun should control stun only")
+	}
+	if s.Controlled(Stun, now.Add(13*time.Second)) {
+		t.Error("stun should have worn off")
+	}
+}
+
+func TestAdvancePeriodic(t *testing.T) {
+	now := time.Unix(1000, 0)
+	var s Set
+	s.Apply(testDef(t, Def{ID: "bleeding", Duration: "10s", HP: -2, Stacking: StackAdd}), now, "Smith")
+	s.Apply(testDef(t, Def{ID: "bleeding", Duration: "10s", HP: -2, Stacking: StackAdd}), now, "Smith")
+	s.Apply(testDef(t, Def{ID: "corrupted", Duration: "3s", HP: -1, MP: -3, Interval: "1s"}), now, "")
+
+	ticks, expired := s.Advance(now.Add(1500 * time.Millisecond))
+	if len(ticks) != 1 || ticks[0].Def.ID != "corrupted" || ticks[0].HP != -1 || ticks[0].MP != -3 || len(expired) != 0 {
+		t.Fatalf("after 1.5s: ticks %+v, expired %v", ticks, expired)
+	}
+
+	// A late tick catches up on every interval, up to the expiry
+	ticks, expired = s.Advance(now.Add(20 * time.Second))
+	hp := map[string]int{}
+	for _, tk := range ticks {
+		hp[tk.Def.ID] = tk.HP
+	}
+	if hp["bleeding"] != -20 || hp["corrupted"] != -2 {
+		t.Errorf("catch-up ticks = %v", hp)
+	}
+	if len(expired) != 2 || len(s.List(now)) != 0 {
+		t.Errorf("expired = %v", expired)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "effects.json")
+	data := `{"effects": {
+		"exposed": {"name": "Exposed", "duration": "20s", "ac": -3},
+		"regen": {"duration": "10s", "hp": 3, "interval": "1s", "stacking": "extend"},
+		"broken": {"duration": "soon"},
+		"bad_control": {"duration": "1s", "control": ["sleep"]},
+		"bad_stacking": {"duration": "1s", "stacking": "pile"}
+	}}`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m := &Manager{}
+	if err := m.Load(path); err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Effects) != 2 {
+		t.Fatalf("loaded %d effects, want 2", len(m.Effects))
+	}
+	if d := m.Get("exposed"); d == nil || d.Length() != 20*time.Second || d.AC != -3 || d.Stacking != StackRefresh {
+		t.Errorf("exposed = %+v", d)
+	}
+	if d := m.Get("regen"); d == nil || !d.Periodic() || d.interval != time.Second {
+		t.Errorf("regen = %+v", d)
+	}
+}
@@ -70,6 +70,7 @@ const (
 	EventServerStop      EventType = "server.stop"
 	EventServerBroadcast EventType = "server.broadcast"
 	EventAdminAction     EventType = "admin.action"
+	EventWebhookTest     EventType = "webhook.test"
 )
 
 // Event represents a game event
@@ -133,7 +134,8 @@ type EventBus struct {
 	mu          sync.RWMutex
 	subscribers map[EventType][]*Subscription
 	allHandlers []*Subscription
-	eventQueue  chan *Event
+	eventQueue  chan queuedEvent
+	queueWait   func(time.Duration)
 	workerCount int
 	running     bool
 	wg          sync.WaitGroup
@@ -148,7 +150,7 @@ func NewEventBus(workerCount int) *EventBus {
 	return &EventBus{
 		subscribers: make(map[EventType][]*Subscription),
 		allHandlers: make([]*Subscription, 0),
-		eventQueue:  make(chan *Event, 1000),
+		eventQueue:  make(chan queuedEvent, 1000),
 		workerCount: workerCount,
 		nextSubID:   1,
 	}
@@ -184,11 +186,31 @@ func (eb *EventBus) Stop() {
 	eb.wg.Wait()
 }
 
+// queuedEvent is an event waiting for a worker
+type queuedEvent struct {
+	event    *Event
+	enqueued time.Time
+}
+
+// ObserveQueueWait registers fn to receive how long each event waited in
+// the queue before a worker picked it up
+func (eb *EventBus) ObserveQueueWait(fn func(time.Duration)) {
+	eb.mu.Lock()
+	defer eb.mu.Unlock()
+	eb.queueWait = fn
+}
+
 // worker processes events from the queue
 func (eb *EventBus) worker() {
 	defer eb.wg.Done()
-	for event := range eb.eventQueue {
-		eb.dispatch(event)
+	for qe := range eb.eventQueue {
+		eb.mu.RLock()
+		observe := eb.queueWait
+		eb.mu.RUnlock()
+		if observe != nil {
+			observe(time.Since(qe.enqueued))
+		}
+		eb.dispatch(qe.event)
 	}
 }
 
@@ -296,7 +318,7 @@ func (eb *EventBus) Publish(event *Event) {
 	}
 
 	select {
-	case eb.eventQueue <- event:
+	case eb.eventQueue <- queuedEvent{event: event, enqueued: time.Now()}:
 	default:
 		// Queue full, drop event (could log this)
 	}
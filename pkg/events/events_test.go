@@ -309,3 +309,21 @@ func TestItoa(t *testing.T) {
 		}
 	}
 }
+
+func TestEventBusQueueWait(t *testing.T) {
+	eb := NewEventBus(1)
+	waits := make(chan time.Duration, 1)
+	eb.ObserveQueueWait(func(d time.Duration) { waits <- d })
+	eb.Start()
+	defer eb.Stop()
+
+	eb.Publish(NewEvent(EventServerStart))
+	select {
+	case d := <-waits:
+		if d < 0 || d > time.Second {
+			t.Errorf("Queue wait = %v", d)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Queue wait not observed")
+	}
+}
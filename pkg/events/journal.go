@@ -0,0 +1,335 @@
+package events
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Journal defaults
+const (
+	DefaultSegmentSize = 16 << 20            // Bytes per segment before rotation
+	DefaultRetention   = 30 * 24 * time.Hour // Age after which closed segments are deleted
+)
+
+// segmentPrefix and segmentSuffix frame segment file names. The number
+// between them is the sequence of the segment's first event, zero-padded
+// so names sort in order.
+const (
+	segmentPrefix = "events-"
+	segmentSuffix = ".jsonl"
+)
+
+// errStopScan ends a segment scan early
+var errStopScan = errors.New("stop scan")
+
+// JournalConfig configures an event journal
+type JournalConfig struct {
+	Dir         string        // Directory holding the segment files
+	SegmentSize int64         // Rotate once a segment reaches this many bytes
+	Retention   time.Duration // Delete closed segments older than this; 0 keeps them forever
+	ReadOnly    bool          // Open for reading while another process writes
+}
+
+// Journal is an append-only event log stored as JSON Lines. Each line is a
+// StreamEvent whose Seq is the journal cursor. The log is split into
+// segments that rotate by size and expire by age.
+type Journal struct {
+	mu     sync.Mutex
+	config JournalConfig
+	file   *os.File
+	size   int64
+	seq    uint64
+	subID  string
+	bus    *EventBus
+}
+
+// segment is a journal file and the sequence of its first event
+type segment struct {
+	path  string
+	first uint64
+}
+
+// OpenJournal opens or creates a journal, continuing after its last event
+func OpenJournal(config JournalConfig) (*Journal, error) {
+	if config.SegmentSize <= 0 {
+		config.SegmentSize = DefaultSegmentSize
+	}
+	if err := os.MkdirAll(config.Dir, 0755); err != nil {
+		return nil, err
+	}
+
+	j := &Journal{config: config}
+	segments, err := j.segments()
+	if err != nil {
+		return nil, err
+	}
+	if len(segments) > 0 && !config.ReadOnly {
+		last := segments[len(segments)-1]
+		j.seq = last.first - 1
+		if err := scanSegment(last.path, func(se StreamEvent) error {
+			j.seq = se.Seq
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+		if j.file, err = os.OpenFile(last.path, os.O_APPEND|os.O_WRONLY, 0644); err != nil {
+			return nil, err
+		}
+		info, err := j.file.Stat()
+		if err != nil {
+			j.file.Close()
+			return nil, err
+		}
+		j.size = info.Size()
+		if err := j.terminateTornLine(); err != nil {
+			j.file.Close()
+			return nil, err
+		}
+	}
+	return j, nil
+}
+
+// terminateTornLine ends a partial final line left by a crash so the next
+// append starts on a fresh line
+func (j *Journal) terminateTornLine() error {
+	if j.size == 0 {
+		return nil
+	}
+	f, err := os.Open(j.file.Name())
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	last := make([]byte, 1)
+	if _, err := f.ReadAt(last, j.size-1); err != nil {
+		return err
+	}
+	if last[0] != '\n' {
+		n, err := j.file.Write([]byte{'\n'})
+		j.size += int64(n)
+		return err
+	}
+	return nil
+}
+
+// Append writes an event and returns its cursor
+func (j *Journal) Append(e *Event) (uint64, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.config.ReadOnly {
+		return 0, fmt.Errorf("journal is read-only")
+	}
+
+	se := StreamEvent{Seq: j.seq + 1, Event: e}
+	line, err := json.Marshal(se)
+	if err != nil {
+		return 0, err
+	}
+	line = append(line, '\n')
+
+	if j.file == nil || j.size+int64(len(line)) > j.config.SegmentSize && j.size > 0 {
+		if err := j.rotate(se.Seq); err != nil {
+			return 0, err
+		}
+	}
+	if _, err := j.file.Write(line); err != nil {
+		return 0, err
+	}
+	j.size += int64(len(line))
+	j.seq = se.Seq
+	return se.Seq, nil
+}
+
+// rotate starts a new segment beginning at first and applies retention.
+// j.mu is held.
+func (j *Journal) rotate(first uint64) error {
+	if j.file != nil {
+		j.file.Sync()
+		j.file.Close()
+	}
+	path := filepath.Join(j.config.Dir, fmt.Sprintf("%s%020d%s", segmentPrefix, first, segmentSuffix))
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		j.file = nil
+		return err
+	}
+	j.file, j.size = f, 0
+	j.expire(path)
+	return nil
+}
+
+// expire deletes closed segments older than the retention period
+func (j *Journal) expire(current string) {
+	if j.config.Retention <= 0 {
+		return
+	}
+	segments, err := j.segments()
+	if err != nil {
+		return
+	}
+	cutoff := time.Now().Add(-j.config.Retention)
+	for _, s := range segments {
+		if s.path == current {
+			continue
+		}
+		if info, err := os.Stat(s.path); err == nil && info.ModTime().Before(cutoff) {
+			os.Remove(s.path)
+		}
+	}
+}
+
+// Read returns up to limit events with a cursor greater than after that
+// pass the filter, oldest first
+func (j *Journal) Read(after uint64, limit int, filter StreamFilter) ([]StreamEvent, error) {
+	segments, err := j.segments()
+	if err != nil {
+		return nil, err
+	}
+
+	// Skip segments that end before the cursor
+	start := 0
+	for i, s := range segments {
+		if s.first <= after+1 {
+			start = i
+		}
+	}
+
+	var result []StreamEvent
+	for _, s := range segments[start:] {
+		err := scanSegment(s.path, func(se StreamEvent) error {
+			if se.Seq <= after || !filter.Match(se.Event) {
+				return nil
+			}
+			result = append(result, se)
+			if limit > 0 && len(result) >= limit {
+				return errStopScan
+			}
+			return nil
+		})
+		if err == errStopScan {
+			break
+		}
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// Range calls fn for every event timestamped within [from, to), oldest
+// first. A zero from or to leaves that end open.
+func (j *Journal) Range(from, to time.Time, fn func(StreamEvent) error) error {
+	segments, err := j.segments()
+	if err != nil {
+		return err
+	}
+	for _, s := range segments {
+		err := scanSegment(s.path, func(se StreamEvent) error {
+			ts := se.Event.Timestamp
+			if (!from.IsZero() && ts.Before(from)) || (!to.IsZero() && !ts.Before(to)) {
+				return nil
+			}
+			return fn(se)
+		})
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// LastSeq returns the cursor of the newest event appended by this process
+func (j *Journal) LastSeq() uint64 {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.seq
+}
+
+// Attach appends every event published on bus
+func (j *Journal) Attach(bus *EventBus) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.bus != nil {
+		return
+	}
+	j.bus = bus
+	j.subID = bus.SubscribeAll(func(e *Event) {
+		j.Append(e)
+	})
+}
+
+// Close detaches the journal and closes the current segment
+func (j *Journal) Close() error {
+	j.mu.Lock()
+	bus, subID := j.bus, j.subID
+	j.bus, j.subID = nil, ""
+	j.mu.Unlock()
+	if bus != nil {
+		bus.Unsubscribe(subID)
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.file == nil {
+		return nil
+	}
+	j.file.Sync()
+	err := j.file.Close()
+	j.file = nil
+	return err
+}
+
+// segments lists segment files in cursor order
+func (j *Journal) segments() ([]segment, error) {
+	entries, err := os.ReadDir(j.config.Dir)
+	if err != nil {
+		return nil, err
+	}
+	var segments []segment
+	for _, e := range entries {
+		name := e.Name()
+		if !strings.HasPrefix(name, segmentPrefix) || !strings.HasSuffix(name, segmentSuffix) {
+			continue
+		}
+		first, err := strconv.ParseUint(strings.TrimSuffix(strings.TrimPrefix(name, segmentPrefix), segmentSuffix), 10, 64)
+		if err != nil {
+			continue
+		}
+		segments = append(segments, segment{path: filepath.Join(j.config.Dir, name), first: first})
+	}
+	sort.Slice(segments, func(a, b int) bool { return segments[a].first < segments[b].first })
+	return segments, nil
+}
+
+// scanSegment calls fn for each well-formed line in a segment. A torn final
+// line from a crash is skipped.
+func scanSegment(path string, fn func(StreamEvent) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 4<<20)
+	for scanner.Scan() {
+		var se StreamEvent
+		if json.Unmarshal(scanner.Bytes(), &se) != nil || se.Event == nil {
+			continue
+		}
+		if err := fn(se); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,159 @@
+package events
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func openTestJournal(t *testing.T, config JournalConfig) *Journal {
+	t.Helper()
+	j, err := OpenJournal(config)
+	if err != nil {
+		t.Fatalf("OpenJournal failed: %v", err)
+	}
+	t.Cleanup(func() { j.Close() })
+	return j
+}
+
+func TestJournalAppendRead(t *testing.T) {
+	j := openTestJournal(t, JournalConfig{Dir: t.TempDir()})
+
+	for _, name := range []string{"Neo", "Trinity", "Neo"} {
+		if _, err := j.Append(NewEvent(EventNPCKill).WithPlayer(name, 0)); err != nil {
+			t.Fatalf("Append failed: %v", err)
+		}
+	}
+
+	all, err := j.Read(0, 0, StreamFilter{})
+	if err != nil || len(all) != 3 || all[0].Seq != 1 || all[2].Seq != 3 {
+		t.Fatalf("Read all = %+v, %v", all, err)
+	}
+	page, _ := j.Read(1, 1, StreamFilter{})
+	if len(page) != 1 || page[0].Seq != 2 {
+		t.Errorf("Read after 1 limit 1 = %+v", page)
+	}
+	neo, _ := j.Read(0, 0, StreamFilter{Player: "neo"})
+	if len(neo) != 2 || neo[1].Seq != 3 {
+		t.Errorf("Filtered read = %+v", neo)
+	}
+}
+
+func TestJournalRotationAndReopen(t *testing.T) {
+	dir := t.TempDir()
+	j, err := OpenJournal(JournalConfig{Dir: dir, SegmentSize: 300})
+	if err != nil {
+		t.Fatalf("OpenJournal failed: %v", err)
+	}
+	for i := 0; i < 10; i++ {
+		j.Append(NewEvent(EventPlayerMove).WithRoom("dojo"))
+	}
+	j.Close()
+
+	segments, _ := filepath.Glob(filepath.Join(dir, "events-*.jsonl"))
+	if len(segments) < 3 {
+		t.Fatalf("Expected rotation into several segments, got %d", len(segments))
+	}
+
+	j = openTestJournal(t, JournalConfig{Dir: dir, SegmentSize: 300})
+	if j.LastSeq() != 10 {
+		t.Fatalf("Reopened LastSeq = %d, want 10", j.LastSeq())
+	}
+	if seq, _ := j.Append(NewEvent(EventPlayerMove)); seq != 11 {
+		t.Errorf("Append after reopen = %d, want 11", seq)
+	}
+
+	// Reads that start mid-journal skip earlier segments
+	page, _ := j.Read(7, 0, StreamFilter{})
+	if len(page) != 4 || page[0].Seq != 8 || page[3].Seq != 11 {
+		t.Errorf("Read across segments = %+v", page)
+	}
+}
+
+func TestJournalTornLine(t *testing.T) {
+	dir := t.TempDir()
+	j, _ := OpenJournal(JournalConfig{Dir: dir})
+	j.Append(NewEvent(EventPlayerJoin))
+	j.Close()
+
+	// Simulate a crash mid-write
+	segments, _ := filepath.Glob(filepath.Join(dir, "events-*.jsonl"))
+	f, _ := os.OpenFile(segments[0], os.O_APPEND|os.O_WRONLY, 0644)
+	f.WriteString(`{"seq":2,"event":{"id":"x","ty`)
+	f.Close()
+
+	j = openTestJournal(t, JournalConfig{Dir: dir})
+	if seq, _ := j.Append(NewEvent(EventPlayerLeave)); seq != 2 {
+		t.Errorf("Append after torn line = %d, want 2", seq)
+	}
+	all, _ := j.Read(0, 0, StreamFilter{})
+	if len(all) != 2 || all[1].Event.Type != EventPlayerLeave {
+		t.Errorf("Torn line should be skipped, got %+v", all)
+	}
+}
+
+func TestJournalRetention(t *testing.T) {
+	dir := t.TempDir()
+	j := openTestJournal(t, JournalConfig{Dir: dir, SegmentSize: 200, Retention: time.Hour})
+
+	j.Append(NewEvent(EventPlayerJoin))
+	j.Append(NewEvent(EventPlayerJoin))
+	segments, _ := filepath.Glob(filepath.Join(dir, "events-*.jsonl"))
+	old := time.Now().Add(-2 * time.Hour)
+	for _, s := range segments {
+		os.Chtimes(s, old, old)
+	}
+
+	// The next rotation expires the old segments
+	j.Append(NewEvent(EventPlayerJoin))
+	j.Append(NewEvent(EventPlayerJoin))
+	all, _ := j.Read(0, 0, StreamFilter{})
+	if len(all) == 0 || all[0].Seq < 3 {
+		t.Errorf("Old segments should be deleted, still have %+v", all)
+	}
+}
+
+func TestJournalRange(t *testing.T) {
+	j := openTestJournal(t, JournalConfig{Dir: t.TempDir()})
+	base := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 5; i++ {
+		e := NewEvent(EventAuctionSold)
+		e.Timestamp = base.Add(time.Duration(i) * time.Hour)
+		j.Append(e)
+	}
+
+	var seqs []uint64
+	j.Range(base.Add(time.Hour), base.Add(3*time.Hour), func(se StreamEvent) error {
+		seqs = append(seqs, se.Seq)
+		return nil
+	})
+	if len(seqs) != 2 || seqs[0] != 2 || seqs[1] != 3 {
+		t.Errorf("Range = %v, want [2 3]", seqs)
+	}
+}
+
+func TestJournalAttachAndReadOnly(t *testing.T) {
+	dir := t.TempDir()
+	bus := NewEventBus(1)
+	bus.Start()
+	defer bus.Stop()
+
+	j := openTestJournal(t, JournalConfig{Dir: dir})
+	j.Attach(bus)
+	bus.Publish(NewEvent(EventAchievement).WithPlayer("Neo", 0))
+
+	deadline := time.Now().Add(time.Second)
+	for j.LastSeq() == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	reader := openTestJournal(t, JournalConfig{Dir: dir, ReadOnly: true})
+	all, _ := reader.Read(0, 0, StreamFilter{})
+	if len(all) != 1 || all[0].Event.PlayerName != "Neo" {
+		t.Errorf("Bus event not journaled: %+v", all)
+	}
+	if _, err := reader.Append(NewEvent(EventPlayerJoin)); err == nil {
+		t.Error("Read-only journal should reject appends")
+	}
+}
@@ -0,0 +1,202 @@
+package events
+
+import (
+	"strings"
+	"sync"
+)
+
+// StreamEvent is an event with its position in a Stream. Seq increases by
+// one per event and is what clients send back to resume.
+type StreamEvent struct {
+	Seq   uint64 `json:"seq"`
+	Event *Event `json:"event"`
+}
+
+// StreamFilter selects events for a stream subscriber. Empty fields match
+// everything. Types may end in ".*" to match a whole category.
+type StreamFilter struct {
+	Types  []string
+	Player string
+	Room   string
+}
+
+// Match reports whether the event passes the filter
+func (f StreamFilter) Match(e *Event) bool {
+	if f.Player != "" && !strings.EqualFold(f.Player, e.PlayerName) {
+		return false
+	}
+	if f.Room != "" && f.Room != e.RoomID {
+		return false
+	}
+	if len(f.Types) == 0 {
+		return true
+	}
+	for _, t := range f.Types {
+		if prefix, ok := strings.CutSuffix(t, ".*"); ok {
+			if strings.HasPrefix(string(e.Type), prefix+".") {
+				return true
+			}
+		} else if t == string(e.Type) {
+			return true
+		}
+	}
+	return false
+}
+
+// Stream fans bus events out to live subscribers and keeps the most recent
+// events in a ring buffer so clients can resume after a disconnect.
+// Delivery never blocks: a subscriber whose buffer is full is dropped and
+// must reconnect with its last sequence number.
+type Stream struct {
+	mu    sync.Mutex
+	ring  []StreamEvent
+	start int // index of the oldest event in ring
+	count int
+	seq   uint64
+	subs  map[*StreamSubscriber]struct{}
+	bus   *EventBus
+	subID string
+}
+
+// StreamSubscriber receives matching events on C until closed
+type StreamSubscriber struct {
+	C       <-chan StreamEvent
+	ch      chan StreamEvent
+	filter  StreamFilter
+	stream  *Stream
+	closed  bool
+	dropped bool
+}
+
+// NewStream creates a stream that remembers the last size events
+func NewStream(size int) *Stream {
+	if size <= 0 {
+		size = 1000
+	}
+	return &Stream{
+		ring: make([]StreamEvent, size),
+		subs: make(map[*StreamSubscriber]struct{}),
+	}
+}
+
+// Attach starts feeding the stream from every event on bus
+func (s *Stream) Attach(bus *EventBus) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.bus != nil {
+		return
+	}
+	s.bus = bus
+	s.subID = bus.SubscribeAll(s.Push)
+}
+
+// Detach stops feeding the stream from its bus
+func (s *Stream) Detach() {
+	s.mu.Lock()
+	bus, subID := s.bus, s.subID
+	s.bus, s.subID = nil, ""
+	s.mu.Unlock()
+
+	if bus != nil {
+		bus.Unsubscribe(subID)
+	}
+}
+
+// Push records an event and delivers it to matching subscribers
+func (s *Stream) Push(e *Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.seq++
+	se := StreamEvent{Seq: s.seq, Event: e}
+	if s.count < len(s.ring) {
+		s.ring[(s.start+s.count)%len(s.ring)] = se
+		s.count++
+	} else {
+		s.ring[s.start] = se
+		s.start = (s.start + 1) % len(s.ring)
+	}
+
+	for sub := range s.subs {
+		if !sub.filter.Match(e) {
+			continue
+		}
+		select {
+		case sub.ch <- se:
+		default:
+			sub.dropped = true
+			s.closeLocked(sub)
+		}
+	}
+}
+
+// Subscribe registers a subscriber. Buffered events after lastSeq are
+// replayed first; pass 0 for live events only. buffer bounds how far the
+// subscriber may fall behind before it is dropped.
+func (s *Stream) Subscribe(filter StreamFilter, lastSeq uint64, buffer int) *StreamSubscriber {
+	if buffer <= 0 {
+		buffer = 64
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var replay []StreamEvent
+	if lastSeq > 0 {
+		for i := 0; i < s.count; i++ {
+			se := s.ring[(s.start+i)%len(s.ring)]
+			if se.Seq > lastSeq && filter.Match(se.Event) {
+				replay = append(replay, se)
+			}
+		}
+	}
+
+	ch := make(chan StreamEvent, buffer+len(replay))
+	for _, se := range replay {
+		ch <- se
+	}
+	sub := &StreamSubscriber{C: ch, ch: ch, filter: filter, stream: s}
+	s.subs[sub] = struct{}{}
+	return sub
+}
+
+// LastSeq returns the sequence number of the newest event
+func (s *Stream) LastSeq() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.seq
+}
+
+// SubscriberCount returns the number of live subscribers
+func (s *Stream) SubscriberCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.subs)
+}
+
+// closeLocked removes a subscriber and closes its channel; s.mu is held
+func (s *Stream) closeLocked(sub *StreamSubscriber) {
+	if sub.closed {
+		return
+	}
+	sub.closed = true
+	delete(s.subs, sub)
+	close(sub.ch)
+}
+
+// Close unsubscribes; C is closed once buffered events are drained
+func (sub *StreamSubscriber) Close() {
+	sub.stream.mu.Lock()
+	defer sub.stream.mu.Unlock()
+	sub.stream.closeLocked(sub)
+}
+
+// Dropped reports whether the subscriber was closed for falling behind
+func (sub *StreamSubscriber) Dropped() bool {
+	sub.stream.mu.Lock()
+	defer sub.stream.mu.Unlock()
+	return sub.dropped
+}
+
+// GlobalStream is the stream fed by GlobalEventBus
+var GlobalStream = NewStream(1000)
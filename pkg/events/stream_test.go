@@ -0,0 +1,124 @@
+package events
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStreamFilterMatch(t *testing.T) {
+	kill := NewEvent(EventNPCKill).WithPlayer("Neo", 0).WithRoom("dojo")
+
+	tests := []struct {
+		filter StreamFilter
+		want   bool
+	}{
+		{StreamFilter{}, true},
+		{StreamFilter{Types: []string{"combat.npc_kill"}}, true},
+		{StreamFilter{Types: []string{"combat.*"}}, true},
+		{StreamFilter{Types: []string{"player.*", "auction.sold"}}, false},
+		{StreamFilter{Player: "neo"}, true},
+		{StreamFilter{Player: "trinity"}, false},
+		{StreamFilter{Room: "dojo", Types: []string{"combat.*"}}, true},
+		{StreamFilter{Room: "street"}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.filter.Match(kill); got != tt.want {
+			t.Errorf("%+v.Match() = %v, want %v", tt.filter, got, tt.want)
+		}
+	}
+}
+
+func TestStreamResume(t *testing.T) {
+	s := NewStream(3)
+	for i := 0; i < 5; i++ {
+		s.Push(NewEvent(EventPlayerJoin))
+	}
+	if s.LastSeq() != 5 {
+		t.Fatalf("LastSeq = %d, want 5", s.LastSeq())
+	}
+
+	// Events 1 and 2 have left the ring; resume replays what remains
+	sub := s.Subscribe(StreamFilter{}, 1, 10)
+	defer sub.Close()
+	for _, want := range []uint64{3, 4, 5} {
+		if se := <-sub.C; se.Seq != want {
+			t.Errorf("Replayed seq %d, want %d", se.Seq, want)
+		}
+	}
+
+	s.Push(NewEvent(EventPlayerLeave))
+	if se := <-sub.C; se.Seq != 6 || se.Event.Type != EventPlayerLeave {
+		t.Errorf("Live event = %+v", se)
+	}
+
+	live := s.Subscribe(StreamFilter{}, 0, 10)
+	defer live.Close()
+	if len(live.C) != 0 {
+		t.Error("Subscribing from 0 should not replay")
+	}
+}
+
+func TestStreamSlowSubscriberDropped(t *testing.T) {
+	s := NewStream(10)
+	slow := s.Subscribe(StreamFilter{}, 0, 2)
+	fast := s.Subscribe(StreamFilter{}, 0, 10)
+	defer fast.Close()
+
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 5; i++ {
+			s.Push(NewEvent(EventCombatHit))
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Push blocked on a slow subscriber")
+	}
+
+	if !slow.Dropped() {
+		t.Error("Slow subscriber should be dropped")
+	}
+	n := 0
+	for range slow.C {
+		n++
+	}
+	if n != 2 {
+		t.Errorf("Slow subscriber should keep its 2 buffered events, got %d", n)
+	}
+	if len(fast.C) != 5 || fast.Dropped() {
+		t.Errorf("Fast subscriber should receive all 5 events, got %d", len(fast.C))
+	}
+	if s.SubscriberCount() != 1 {
+		t.Errorf("SubscriberCount = %d, want 1", s.SubscriberCount())
+	}
+}
+
+func TestStreamAttach(t *testing.T) {
+	bus := NewEventBus(1)
+	bus.Start()
+	defer bus.Stop()
+
+	s := NewStream(10)
+	s.Attach(bus)
+	sub := s.Subscribe(StreamFilter{Types: []string{"auction.sold"}}, 0, 10)
+	defer sub.Close()
+
+	bus.Publish(NewEvent(EventPlayerJoin))
+	bus.Publish(NewEvent(EventAuctionSold))
+
+	select {
+	case se := <-sub.C:
+		if se.Event.Type != EventAuctionSold {
+			t.Errorf("Got %s, want auction.sold", se.Event.Type)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Event not delivered from bus")
+	}
+
+	s.Detach()
+	if bus.SubscriberCount() != 0 {
+		t.Error("Detach should unsubscribe from the bus")
+	}
+}
@@ -8,11 +8,23 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"math/rand"
 	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 )
 
+// Retry and dead-letter defaults
+const (
+	DefaultRetryBaseDelay = time.Second
+	DefaultRetryMaxDelay  = 5 * time.Minute
+	MaxDeadLetters        = 1000
+)
+
 // WebhookConfig defines a webhook endpoint configuration
 type WebhookConfig struct {
 	ID         string      `json:"id"`
@@ -48,16 +60,31 @@ type WebhookDelivery struct {
 	Attempt      int           `json:"attempt"`
 }
 
+// DeadLetter is an event that exhausted its retries for a webhook
+type DeadLetter struct {
+	ID        string    `json:"id"`
+	WebhookID string    `json:"webhook_id"`
+	Event     *Event    `json:"event"`
+	Attempts  int       `json:"attempts"`
+	LastError string    `json:"last_error"`
+	FailedAt  time.Time `json:"failed_at"`
+}
+
 // WebhookManager manages webhook subscriptions and delivery
 type WebhookManager struct {
 	mu                 sync.RWMutex
 	webhooks           map[string]*WebhookConfig
 	deliveries         []*WebhookDelivery
+	deadLetters        []*DeadLetter
 	eventBus           *EventBus
 	serverID           string
 	httpClient         *http.Client
 	subID              string
 	maxDeliveryHistory int
+	baseDelay          time.Duration
+	maxDelay           time.Duration
+	configFile         string
+	deadLetterFile     string
 }
 
 // NewWebhookManager creates a new webhook manager
@@ -69,9 +96,39 @@ func NewWebhookManager(eventBus *EventBus, serverID string) *WebhookManager {
 		serverID:           serverID,
 		httpClient:         &http.Client{Timeout: 10 * time.Second},
 		maxDeliveryHistory: 1000,
+		baseDelay:          DefaultRetryBaseDelay,
+		maxDelay:           DefaultRetryMaxDelay,
 	}
 }
 
+// SetBackoff sets the delay before the first retry and the cap on later ones
+func (wm *WebhookManager) SetBackoff(base, max time.Duration) {
+	wm.mu.Lock()
+	defer wm.mu.Unlock()
+	wm.baseDelay, wm.maxDelay = base, max
+}
+
+// backoff returns the delay after a failed attempt: exponential in the
+// attempt number, capped, with jitter so retries from many events spread out
+func (wm *WebhookManager) backoff(attempt int) time.Duration {
+	wm.mu.RLock()
+	base, max := wm.baseDelay, wm.maxDelay
+	wm.mu.RUnlock()
+
+	d := base
+	for i := 1; i < attempt && d < max; i++ {
+		d *= 2
+	}
+	if d > max {
+		d = max
+	}
+	if d <= 0 {
+		return 0
+	}
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(d-half)+1))
+}
+
 // Start subscribes to events and begins processing
 func (wm *WebhookManager) Start() {
 	wm.subID = wm.eventBus.SubscribeAll(wm.handleEvent)
@@ -95,6 +152,9 @@ func (wm *WebhookManager) AddWebhook(config *WebhookConfig) error {
 	if config.URL == "" {
 		return fmt.Errorf("webhook URL is required")
 	}
+	if config.RetryCount < 0 {
+		return fmt.Errorf("webhook retry_count must be at least 1")
+	}
 	if config.RetryCount == 0 {
 		config.RetryCount = 3
 	}
@@ -129,6 +189,7 @@ func (wm *WebhookManager) ListWebhooks() []*WebhookConfig {
 	for _, wh := range wm.webhooks {
 		webhooks = append(webhooks, wh)
 	}
+	sort.Slice(webhooks, func(i, j int) bool { return webhooks[i].ID < webhooks[j].ID })
 	return webhooks
 }
 
@@ -174,7 +235,8 @@ func (wm *WebhookManager) handleEvent(event *Event) {
 	}
 }
 
-// shouldDeliver checks if webhook should receive the event
+// shouldDeliver checks if webhook should receive the event. Filters may
+// end in ".*" to match a whole category.
 func (wm *WebhookManager) shouldDeliver(wh *WebhookConfig, event *Event) bool {
 	if len(wh.Events) == 0 {
 		return true // No filter, deliver all
@@ -183,33 +245,49 @@ func (wm *WebhookManager) shouldDeliver(wh *WebhookConfig, event *Event) bool {
 		if et == event.Type {
 			return true
 		}
+		if prefix, ok := strings.CutSuffix(string(et), ".*"); ok && strings.HasPrefix(string(event.Type), prefix+".") {
+			return true
+		}
 	}
 	return false
 }
 
-// deliver sends the event to a webhook
+// deliver sends the event to a webhook, retrying with backoff. Events that
+// exhaust their retries go to the dead-letter queue.
 func (wm *WebhookManager) deliver(wh *WebhookConfig, event *Event) {
-	payload := &WebhookPayload{
-		Event:     event,
-		Timestamp: time.Now(),
-		ServerID:  wm.serverID,
-	}
-
-	body, err := json.Marshal(payload)
+	body, err := wm.payload(event)
 	if err != nil {
 		wm.recordDelivery(wh, event, nil, 0, err, 1)
 		return
 	}
 
+	var delivery *WebhookDelivery
 	for attempt := 1; attempt <= wh.RetryCount; attempt++ {
-		delivery := wm.attemptDelivery(wh, event, body, attempt)
+		delivery = wm.attemptDelivery(wh, event, body, attempt)
 		if delivery.Success {
-			break
+			return
 		}
 		if attempt < wh.RetryCount {
-			time.Sleep(time.Duration(attempt) * time.Second) // Exponential backoff
+			time.Sleep(wm.backoff(attempt))
 		}
 	}
+	wm.addDeadLetter(&DeadLetter{
+		ID:        generateEventID(),
+		WebhookID: wh.ID,
+		Event:     event,
+		Attempts:  wh.RetryCount,
+		LastError: delivery.Error,
+		FailedAt:  time.Now(),
+	})
+}
+
+// payload builds the request body for an event
+func (wm *WebhookManager) payload(event *Event) ([]byte, error) {
+	return json.Marshal(&WebhookPayload{
+		Event:     event,
+		Timestamp: time.Now(),
+		ServerID:  wm.serverID,
+	})
 }
 
 // attemptDelivery makes a single delivery attempt
@@ -341,3 +419,188 @@ func VerifySignature(payload []byte, signature, secret string) bool {
 	expected := "sha256=" + hex.EncodeToString(mac.Sum(nil))
 	return hmac.Equal([]byte(signature), []byte(expected))
 }
+
+// SendTestEvent delivers a single test event to a webhook, bypassing its
+// event filter and retries, and returns the outcome
+func (wm *WebhookManager) SendTestEvent(id string) (*WebhookDelivery, error) {
+	wh := wm.GetWebhook(id)
+	if wh == nil {
+		return nil, fmt.Errorf("webhook not found: %s", id)
+	}
+	event := NewEvent(EventWebhookTest).WithData("message", "Test delivery from the admin console")
+	body, err := wm.payload(event)
+	if err != nil {
+		return nil, err
+	}
+	return wm.attemptDelivery(wh, event, body, 1), nil
+}
+
+// addDeadLetter queues a failed event, dropping the oldest beyond MaxDeadLetters
+func (wm *WebhookManager) addDeadLetter(dl *DeadLetter) {
+	wm.mu.Lock()
+	wm.deadLetters = append(wm.deadLetters, dl)
+	if len(wm.deadLetters) > MaxDeadLetters {
+		wm.deadLetters = wm.deadLetters[len(wm.deadLetters)-MaxDeadLetters:]
+	}
+	wm.mu.Unlock()
+	wm.saveDeadLetters()
+}
+
+// DeadLetters returns the dead-letter queue, oldest first
+func (wm *WebhookManager) DeadLetters() []*DeadLetter {
+	wm.mu.RLock()
+	defer wm.mu.RUnlock()
+	result := make([]*DeadLetter, len(wm.deadLetters))
+	copy(result, wm.deadLetters)
+	return result
+}
+
+// ReplayDeadLetter makes one more delivery attempt for a dead letter. It
+// leaves the queue on success and stays queued, with the new error, on failure.
+func (wm *WebhookManager) ReplayDeadLetter(id string) (*WebhookDelivery, error) {
+	dl := wm.findDeadLetter(id)
+	if dl == nil {
+		return nil, fmt.Errorf("dead letter not found: %s", id)
+	}
+	wh := wm.GetWebhook(dl.WebhookID)
+	if wh == nil {
+		return nil, fmt.Errorf("webhook not found: %s", dl.WebhookID)
+	}
+
+	body, err := wm.payload(dl.Event)
+	if err != nil {
+		return nil, err
+	}
+
+	wm.mu.RLock()
+	attempt := dl.Attempts + 1
+	wm.mu.RUnlock()
+	delivery := wm.attemptDelivery(wh, dl.Event, body, attempt)
+
+	wm.mu.Lock()
+	if delivery.Success {
+		wm.removeDeadLetterLocked(id)
+	} else {
+		dl.Attempts = attempt
+		dl.LastError = delivery.Error
+		dl.FailedAt = time.Now()
+	}
+	wm.mu.Unlock()
+	wm.saveDeadLetters()
+	return delivery, nil
+}
+
+// DiscardDeadLetter removes a dead letter without delivering it
+func (wm *WebhookManager) DiscardDeadLetter(id string) error {
+	wm.mu.Lock()
+	found := wm.removeDeadLetterLocked(id)
+	wm.mu.Unlock()
+	if !found {
+		return fmt.Errorf("dead letter not found: %s", id)
+	}
+	return wm.saveDeadLetters()
+}
+
+func (wm *WebhookManager) findDeadLetter(id string) *DeadLetter {
+	wm.mu.RLock()
+	defer wm.mu.RUnlock()
+	for _, dl := range wm.deadLetters {
+		if dl.ID == id {
+			return dl
+		}
+	}
+	return nil
+}
+
+func (wm *WebhookManager) removeDeadLetterLocked(id string) bool {
+	for i, dl := range wm.deadLetters {
+		if dl.ID == id {
+			wm.deadLetters = append(wm.deadLetters[:i], wm.deadLetters[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Load reads webhook definitions from configFile and the dead-letter queue
+// from deadLetterFile, and remembers both paths for SaveWebhooks and
+// dead-letter updates. Missing files are treated as empty.
+func (wm *WebhookManager) Load(configFile, deadLetterFile string) error {
+	var webhooks []*WebhookConfig
+	if err := readJSONFile(configFile, &webhooks); err != nil {
+		return fmt.Errorf("failed to load webhooks: %w", err)
+	}
+	var deadLetters []*DeadLetter
+	if err := readJSONFile(deadLetterFile, &deadLetters); err != nil {
+		return fmt.Errorf("failed to load dead letters: %w", err)
+	}
+
+	for _, wh := range webhooks {
+		if err := wm.AddWebhook(wh); err != nil {
+			return fmt.Errorf("invalid webhook %q: %w", wh.Name, err)
+		}
+	}
+
+	wm.mu.Lock()
+	wm.configFile, wm.deadLetterFile = configFile, deadLetterFile
+	wm.deadLetters = deadLetters
+	wm.mu.Unlock()
+	return nil
+}
+
+// SaveWebhooks writes the webhook definitions to the file given to Load
+func (wm *WebhookManager) SaveWebhooks() error {
+	webhooks := wm.ListWebhooks()
+	wm.mu.RLock()
+	path := wm.configFile
+	wm.mu.RUnlock()
+	if path == "" {
+		return nil
+	}
+	return writeJSONFile(path, webhooks)
+}
+
+// saveDeadLetters writes the dead-letter queue to the file given to Load
+func (wm *WebhookManager) saveDeadLetters() error {
+	wm.mu.RLock()
+	path := wm.deadLetterFile
+	deadLetters := make([]*DeadLetter, len(wm.deadLetters))
+	copy(deadLetters, wm.deadLetters)
+	wm.mu.RUnlock()
+	if path == "" {
+		return nil
+	}
+	return writeJSONFile(path, deadLetters)
+}
+
+// readJSONFile decodes path into v, leaving v untouched if the file is missing
+func readJSONFile(path string, v interface{}) error {
+	if path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// writeJSONFile atomically replaces path with v encoded as JSON. Webhook
+// secrets are stored here, so the file is readable only by the owner.
+func writeJSONFile(path string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -3,6 +3,8 @@ package events
 import (
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -62,6 +64,15 @@ func TestWebhookValidation(t *testing.T) {
 	if err == nil {
 		t.Error("Should fail with missing URL")
 	}
+
+	// Negative retry count would never attempt a delivery
+	err = wm.AddWebhook(&WebhookConfig{ID: "test", URL: "http://example.com", RetryCount: -1})
+	if err == nil {
+		t.Error("Should fail with negative retry_count")
+	}
+	if wm.GetWebhook("test") != nil {
+		t.Error("Rejected webhook should not be added")
+	}
 }
 
 func TestWebhookDefaults(t *testing.T) {
@@ -362,3 +373,162 @@ func TestGetDeliveriesForWebhook(t *testing.T) {
 		}
 	}
 }
+
+func TestWebhookBackoff(t *testing.T) {
+	wm := NewWebhookManager(NewEventBus(2), "test-server")
+	wm.SetBackoff(100*time.Millisecond, 300*time.Millisecond)
+
+	for attempt, max := range map[int]time.Duration{1: 100, 2: 200, 3: 300, 10: 300} {
+		max *= time.Millisecond
+		for i := 0; i < 20; i++ {
+			d := wm.backoff(attempt)
+			if d < max/2 || d > max {
+				t.Fatalf("backoff(%d) = %v, want within [%v, %v]", attempt, d, max/2, max)
+			}
+		}
+	}
+}
+
+func TestWebhookCategoryFilter(t *testing.T) {
+	wm := NewWebhookManager(NewEventBus(2), "test-server")
+	wh := &WebhookConfig{Events: []EventType{"player.*"}}
+
+	if !wm.shouldDeliver(wh, NewEvent(EventPlayerLevelUp)) {
+		t.Error("player.* should match player.level_up")
+	}
+	if wm.shouldDeliver(wh, NewEvent(EventCombatStart)) {
+		t.Error("player.* should not match combat events")
+	}
+}
+
+func TestWebhookDeadLetterReplay(t *testing.T) {
+	var healthy atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	dlqFile := filepath.Join(dir, "dlq.json")
+	eb := NewEventBus(2)
+	eb.Start()
+	defer eb.Stop()
+
+	wm := NewWebhookManager(eb, "test-server")
+	wm.SetBackoff(time.Millisecond, time.Millisecond)
+	if err := wm.Load(filepath.Join(dir, "webhooks.json"), dlqFile); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	wm.AddWebhook(&WebhookConfig{ID: "flaky", URL: server.URL, Enabled: true, RetryCount: 3})
+	wm.Start()
+	defer wm.Stop()
+
+	eb.Publish(NewEvent(EventServerStart))
+
+	deadline := time.Now().Add(2 * time.Second)
+	for len(wm.DeadLetters()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	dls := wm.DeadLetters()
+	if len(dls) != 1 {
+		t.Fatalf("Expected 1 dead letter, got %d", len(dls))
+	}
+	if dls[0].Attempts != 3 || dls[0].LastError != "HTTP 503" {
+		t.Errorf("Dead letter = %+v", dls[0])
+	}
+	if len(wm.GetDeliveriesForWebhook("flaky", 10)) != 3 {
+		t.Error("Expected every attempt recorded")
+	}
+
+	// The queue survives a restart
+	reloaded := NewWebhookManager(NewEventBus(2), "test-server")
+	if err := reloaded.Load("", dlqFile); err != nil || len(reloaded.DeadLetters()) != 1 {
+		t.Fatalf("Dead letters not persisted: %v", err)
+	}
+
+	// A failed replay stays queued with another attempt counted
+	d, err := wm.ReplayDeadLetter(dls[0].ID)
+	if err != nil || d.Success {
+		t.Fatalf("Replay against failing endpoint: %+v, %v", d, err)
+	}
+	if got := wm.DeadLetters(); len(got) != 1 || got[0].Attempts != 4 {
+		t.Fatalf("Dead letter after failed replay = %+v", got)
+	}
+
+	healthy.Store(true)
+	if d, err := wm.ReplayDeadLetter(dls[0].ID); err != nil || !d.Success {
+		t.Fatalf("Replay: %+v, %v", d, err)
+	}
+	if len(wm.DeadLetters()) != 0 {
+		t.Error("Replayed dead letter should leave the queue")
+	}
+	if _, err := wm.ReplayDeadLetter(dls[0].ID); err == nil {
+		t.Error("Replaying a removed dead letter should fail")
+	}
+}
+
+func TestWebhookDiscardDeadLetter(t *testing.T) {
+	wm := NewWebhookManager(NewEventBus(2), "test-server")
+	wm.addDeadLetter(&DeadLetter{ID: "dl1", WebhookID: "x", Event: NewEvent(EventServerStart)})
+
+	if err := wm.DiscardDeadLetter("dl1"); err != nil {
+		t.Fatalf("Discard failed: %v", err)
+	}
+	if err := wm.DiscardDeadLetter("dl1"); err == nil {
+		t.Error("Discarding twice should fail")
+	}
+}
+
+func TestWebhookPersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "webhooks.json")
+	wm := NewWebhookManager(NewEventBus(2), "test-server")
+	if err := wm.Load(path, ""); err != nil {
+		t.Fatalf("Load of missing file failed: %v", err)
+	}
+	wm.AddWebhook(&WebhookConfig{ID: "a", Name: "Alerts", URL: "http://example.com", Secret: "s",
+		Events: []EventType{"player.*"}, Enabled: true})
+	wm.AddWebhook(&WebhookConfig{ID: "b", URL: "http://example.org"})
+	if err := wm.SaveWebhooks(); err != nil {
+		t.Fatalf("SaveWebhooks failed: %v", err)
+	}
+
+	reloaded := NewWebhookManager(NewEventBus(2), "test-server")
+	if err := reloaded.Load(path, ""); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	a := reloaded.GetWebhook("a")
+	if a == nil || a.Secret != "s" || !a.Enabled || len(a.Events) != 1 || a.Events[0] != "player.*" {
+		t.Errorf("Webhook a = %+v", a)
+	}
+	if b := reloaded.GetWebhook("b"); b == nil || b.Enabled {
+		t.Errorf("Webhook b = %+v", b)
+	}
+}
+
+func TestWebhookSendTestEvent(t *testing.T) {
+	var gotType string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotType = r.Header.Get("X-Event-Type")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	wm := NewWebhookManager(NewEventBus(2), "test-server")
+	// Filters and the enabled flag do not apply to test events
+	wm.AddWebhook(&WebhookConfig{ID: "t", URL: server.URL, Events: []EventType{EventAuctionSold}})
+
+	d, err := wm.SendTestEvent("t")
+	if err != nil || !d.Success {
+		t.Fatalf("SendTestEvent: %+v, %v", d, err)
+	}
+	if gotType != string(EventWebhookTest) {
+		t.Errorf("X-Event-Type = %q", gotType)
+	}
+	if _, err := wm.SendTestEvent("missing"); err == nil {
+		t.Error("Unknown webhook should fail")
+	}
+}
@@ -3,7 +3,9 @@ package game
 
 import (
 	"fmt"
-	"math/rand"
+	"time"
+
+	"github.com/yourusername/matrix-mud/pkg/sim"
 )
 
 // Combat constants
@@ -16,16 +18,105 @@ const (
 	RespawnTime    = 60  // seconds until NPC respawns
 )
 
+// Weapon speed: attacks come every RoundInterval at NormalSpeed. A weapon
+// with speed 150 attacks half as often again; one with 75 a third less.
+const (
+	RoundInterval = 1500 * time.Millisecond
+	NormalSpeed   = 100
+)
+
+// MaxAvoid caps dodge and parry chances (percent)
+const MaxAvoid = 50
+
+// Combatant is one side of an attack. World players and NPCs, instance
+// enemies and arena fighters are all turned into combatants so they fight
+// by the same rules.
+type Combatant struct {
+	Name       string
+	Strength   int            // Adds to hit chance and damage
+	Damage     int            // Weapon or natural damage; 0 fights unarmed
+	Bonus      int            // Flat damage added before criticals, e.g. from effects
+	DamageType string         // Defaults to kinetic
+	AC         int            // Total armor class
+	Dodge      int            // Percent chance to avoid a hit
+	Parry      int            // Percent chance to turn a hit aside with a weapon
+	Resist     map[string]int // Percent damage reduction by damage type
+}
+
 // CombatResult represents the outcome of a combat action
 type CombatResult struct {
-	Hit      bool
-	Critical bool
-	Damage   int
-	Message  string
+	Hit        bool
+	Critical   bool
+	Dodged     bool
+	Parried    bool
+	Damage     int
+	DamageType string
+	Message    string
+}
+
+// NPCCombatant builds the combatant for a monster with a damage stat and
+// armor class. Its damage stat adds to its hit chance; its hits land for
+// about three quarters of it.
+func NPCCombatant(name string, damage, ac int) Combatant {
+	natural := (damage + 1) / 2
+	if natural < 1 {
+		natural = 1
+	}
+	return Combatant{Name: name, Strength: damage, Damage: natural, AC: ac}
+}
+
+// TotalAC adds the AC of each worn piece to a base armor class
+func TotalAC(base int, pieces ...int) int {
+	for _, ac := range pieces {
+		base += ac
+	}
+	return base
+}
+
+// AttackInterval returns the time between attacks for a weapon speed; 0
+// is normal speed
+func AttackInterval(speed int) time.Duration {
+	if speed <= 0 {
+		speed = NormalSpeed
+	}
+	return RoundInterval * NormalSpeed / time.Duration(speed)
+}
+
+// Attack resolves one attack: the hit roll, the defender's dodge and
+// parry, damage with criticals and variance, and resistance. It returns
+// the result without applying the damage or writing a message. Dodge and
+// parry are only rolled when the defender has a chance, so fights without
+// them use the same rolls as before.
+func Attack(rng sim.RNG, attacker, defender Combatant) CombatResult {
+	result := CombatResult{DamageType: attacker.DamageType}
+	if result.DamageType == "" {
+		result.DamageType = DamageKinetic
+	}
+	if !CalculateHit(rng, attacker.Strength, defender.AC) {
+		return result
+	}
+	if defender.Dodge > 0 && rng.Intn(100) < min(defender.Dodge, MaxAvoid) {
+		result.Dodged = true
+		return result
+	}
+	if defender.Parry > 0 && rng.Intn(100) < min(defender.Parry, MaxAvoid) {
+		result.Parried = true
+		return result
+	}
+	base := attacker.Damage
+	if base == 0 {
+		base = 1 + attacker.Strength/5 // Unarmed damage
+	}
+	damage, critical := CalculateDamage(rng, base+attacker.Bonus, attacker.Strength)
+	result.Hit = true
+	result.Critical = critical
+	result.Damage = Resist(damage, result.DamageType, defender.Resist)
+	return result
 }
 
-// CalculateHit determines if an attack hits based on attacker strength and defender AC
-func CalculateHit(attackerStrength, defenderAC int) bool {
+// CalculateHit determines if an attack hits based on attacker strength and defender AC.
+// Rolls come from rng so fights can be replayed from a seed.
+func CalculateHit(rng sim.RNG, attackerStrength, defenderAC int) bool {
 	// Base hit chance modified by AC difference
 	hitChance := BaseHitChance + (attackerStrength - defenderAC)
 	if hitChance < 5 {
@@ -34,11 +125,11 @@ func CalculateHit(attackerStrength, defenderAC int) bool {
 	if hitChance > 95 {
 		hitChance = 95 // Maximum 95% hit chance
 	}
-	return rand.Intn(100) < hitChance
+	return rng.Intn(100) < hitChance
 }
 
 // CalculateDamage computes damage dealt by an attack
-func CalculateDamage(baseDamage, strength int) (damage int, critical bool) {
+func CalculateDamage(rng sim.RNG, baseDamage, strength int) (damage int, critical bool) {
 	// Base damage plus strength modifier
 	damage = baseDamage + (strength / 4)
 	if damage < 1 {
@@ -46,7 +137,7 @@ func CalculateDamage(baseDamage, strength int) (damage int, critical bool) {
 	}
 
 	// Check for critical hit
-	if rand.Intn(100) < CriticalChance {
+	if rng.Intn(100) < CriticalChance {
 		critical = true
 		damage *= CriticalMulti
 	}
@@ -54,42 +145,29 @@ func CalculateDamage(baseDamage, strength int) (damage int, critical bool) {
 	// Add some variance (+/- 20%)
 	variance := damage / 5
 	if variance > 0 {
-		damage += rand.Intn(variance*2+1) - variance
+		damage += rng.Intn(variance*2+1) - variance
 	}
 
 	return damage, critical
 }
 
 // AttackNPC performs a player attack against an NPC
-func AttackNPC(player *Player, npc *NPC, weaponDamage int) CombatResult {
-	result := CombatResult{}
-
-	// Calculate player's effective damage
-	playerDamage := weaponDamage
-	if playerDamage == 0 {
-		playerDamage = 1 + (player.Strength / 5) // Unarmed damage
-	}
-
-	// Check if attack hits
-	if !CalculateHit(player.Strength, npc.AC) {
-		result.Hit = false
+func AttackNPC(rng sim.RNG, player *Player, npc *NPC, weaponDamage int) CombatResult {
+	attacker := Combatant{Name: player.Name, Strength: player.Strength, Damage: weaponDamage}
+	defender := NPCCombatant(npc.Name, npc.Damage, npc.AC)
+	result := Attack(rng, attacker, defender)
+	if !result.Hit {
 		result.Message = fmt.Sprintf("You swing at %s but miss!", npc.Name)
 		return result
 	}
 
-	// Calculate damage
-	damage, critical := CalculateDamage(playerDamage, player.Strength)
-	result.Hit = true
-	result.Critical = critical
-	result.Damage = damage
-
 	// Apply damage to NPC
-	npc.HP -= damage
+	npc.HP -= result.Damage
 
-	if critical {
-		result.Message = fmt.Sprintf("CRITICAL HIT! You strike %s for %d damage!", npc.Name, damage)
+	if result.Critical {
+		result.Message = fmt.Sprintf("CRITICAL HIT! You strike %s for %d damage!", npc.Name, result.Damage)
 	} else {
-		result.Message = fmt.Sprintf("You hit %s for %d damage.", npc.Name, damage)
+		result.Message = fmt.Sprintf("You hit %s for %d damage.", npc.Name, result.Damage)
 	}
 
 	if npc.HP <= 0 {
@@ -100,37 +178,21 @@ func AttackNPC(player *Player, npc *NPC, weaponDamage int) CombatResult {
 }
 
 // NPCAttackPlayer performs an NPC attack against a player
-func NPCAttackPlayer(npc *NPC, player *Player) CombatResult {
-	result := CombatResult{}
-
-	// Calculate player's effective AC
-	playerAC := player.BaseAC
-	for _, item := range player.Equipment {
-		if item != nil {
-			playerAC += item.AC
-		}
-	}
-
-	// Check if attack hits
-	if !CalculateHit(npc.Damage, playerAC) {
-		result.Hit = false
+func NPCAttackPlayer(rng sim.RNG, npc *NPC, player *Player) CombatResult {
+	defender := Combatant{Name: player.Name, Strength: player.Strength, AC: GetTotalAC(player)}
+	result := Attack(rng, NPCCombatant(npc.Name, npc.Damage, npc.AC), defender)
+	if !result.Hit {
 		result.Message = fmt.Sprintf("%s swings at you but misses!", npc.Name)
 		return result
 	}
 
-	// Calculate damage (NPCs use Damage stat as base)
-	damage, critical := CalculateDamage(npc.Damage, npc.Damage)
-	result.Hit = true
-	result.Critical = critical
-	result.Damage = damage
-
 	// Apply damage to player
-	player.HP -= damage
+	player.HP -= result.Damage
 
-	if critical {
-		result.Message = fmt.Sprintf("CRITICAL! %s strikes you for %d damage!", npc.Name, damage)
+	if result.Critical {
+		result.Message = fmt.Sprintf("CRITICAL! %s strikes you for %d damage!", npc.Name, result.Damage)
 	} else {
-		result.Message = fmt.Sprintf("%s hits you for %d damage.", npc.Name, damage)
+		result.Message = fmt.Sprintf("%s hits you for %d damage.", npc.Name, result.Damage)
 	}
 
 	if player.HP <= 0 {
@@ -141,6 +203,6 @@ func NPCAttackPlayer(npc *NPC, player *Player) CombatResult {
 }
 
 // TryFlee attempts to flee from combat
-func TryFlee() bool {
-	return rand.Intn(100) < FleeChance
+func TryFlee(rng sim.RNG) bool {
+	return rng.Intn(100) < FleeChance
 }
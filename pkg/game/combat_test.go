@@ -2,8 +2,14 @@ package game
 
 import (
 	"testing"
+	"time"
+
+	"github.com/yourusername/matrix-mud/pkg/sim"
 )
 
+// rng is shared by the statistical tests; a fixed seed keeps them stable
+var rng = sim.NewRand(1)
+
 func TestCalculateHit(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -23,7 +29,7 @@ func TestCalculateHit(t *testing.T) {
 			hits := 0
 			attempts := 1000
 			for i := 0; i < attempts; i++ {
-				if CalculateHit(tt.strength, tt.ac) {
+				if CalculateHit(rng, tt.strength, tt.ac) {
 					hits++
 				}
 			}
@@ -55,7 +61,7 @@ func TestCalculateDamage(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			damage, _ := CalculateDamage(tt.baseDamage, tt.strength)
+			damage, _ := CalculateDamage(rng, tt.baseDamage, tt.strength)
 			if damage < tt.minDamage {
 				t.Errorf("Damage %d is below minimum %d", damage, tt.minDamage)
 			}
@@ -67,7 +73,7 @@ func TestCriticalHits(t *testing.T) {
 	crits := 0
 	attempts := 10000
 	for i := 0; i < attempts; i++ {
-		_, crit := CalculateDamage(10, 10)
+		_, crit := CalculateDamage(rng, 10, 10)
 		if crit {
 			crits++
 		}
@@ -101,7 +107,7 @@ func TestAttackNPC(t *testing.T) {
 	// Attack multiple times
 	totalDamage := 0
 	for i := 0; i < 10; i++ {
-		result := AttackNPC(player, npc, 5)
+		result := AttackNPC(rng, player, npc, 5)
 		if result.Hit {
 			totalDamage += result.Damage
 			if result.Damage <= 0 {
@@ -133,7 +139,7 @@ func TestNPCAttackPlayer(t *testing.T) {
 	}
 
 	startHP := player.HP
-	result := NPCAttackPlayer(npc, player)
+	result := NPCAttackPlayer(rng, npc, player)
 
 	if result.Hit {
 		if player.HP >= startHP {
@@ -150,7 +156,7 @@ func TestTryFlee(t *testing.T) {
 	successes := 0
 	attempts := 1000
 	for i := 0; i < attempts; i++ {
-		if TryFlee() {
+		if TryFlee(rng) {
 			successes++
 		}
 	}
@@ -182,7 +188,7 @@ func TestNPCDefeat(t *testing.T) {
 
 	// Keep attacking until NPC is defeated
 	for npc.HP > 0 {
-		result := AttackNPC(player, npc, 10)
+		result := AttackNPC(rng, player, npc, 10)
 		if result.Hit {
 			t.Logf("Attack dealt %d damage, NPC HP: %d", result.Damage, npc.HP)
 		}
@@ -192,3 +198,95 @@ func TestNPCDefeat(t *testing.T) {
 		t.Error("NPC should be defeated")
 	}
 }
+
+// fixedRNG returns its rolls in order, for exact outcomes
+type fixedRNG []int
+
+func (f *fixedRNG) Intn(n int) int {
+	v := (*f)[0] % n
+	*f = (*f)[1:]
+	return v
+}
+
+func TestCombatRollsFromRNG(t *testing.T) {
+	player := &Player{Name: "Neo", Strength: 10, BaseAC: 10, HP: 100, MaxHP: 100, Equipment: map[string]*Item{}}
+	npc := &NPC{Name: "Agent", HP: 50, MaxHP: 50, AC: 10, Damage: 8}
+
+	// Hit roll 10 (< 65), no crit (50), variance roll 1 of [0, 3) -> -0
+	rolls := fixedRNG{10, 50, 1}
+	result := AttackNPC(&rolls, player, npc, 5)
+	if !result.Hit || result.Critical || result.Damage != 7 || npc.HP != 43 {
+		t.Errorf("attack = %+v, npc HP %d", result, npc.HP)
+	}
+
+	// Hit roll 90 misses
+	rolls = fixedRNG{90}
+	if result := NPCAttackPlayer(&rolls, npc, player); result.Hit || player.HP != 100 {
+		t.Errorf("expected a miss, got %+v", result)
+	}
+
+	rolls = fixedRNG{49, 50}
+	if !TryFlee(&rolls) || TryFlee(&rolls) {
+		t.Error("flee should succeed below FleeChance only")
+	}
+
+	// The same seed replays the same fight
+	fight := func(seed int64) []int {
+		r := sim.NewRand(seed)
+		target := &NPC{Name: "Agent", HP: 1000, AC: 10}
+		var damage []int
+		for i := 0; i < 20; i++ {
+			damage = append(damage, AttackNPC(r, player, target, 5).Damage)
+		}
+		return damage
+	}
+	a, b := fight(99), fight(99)
+	for i := range a {
+		if a[i] != b[i] {
+			t.Fatalf("round %d: %d != %d with the same seed", i, a[i], b[i])
+		}
+	}
+}
+
+func TestAttackAvoidanceAndResistance(t *testing.T) {
+	attacker := Combatant{Name: "Neo", Strength: 10, Damage: 10, DamageType: DamageCode}
+	defender := Combatant{Name: "Agent", AC: 10, Dodge: 20, Parry: 80}
+
+	// Hit roll 10, dodge roll 15 (< 20)
+	rolls := fixedRNG{10, 15}
+	if result := Attack(&rolls, attacker, defender); !result.Dodged || result.Hit {
+		t.Errorf("expected a dodge, got %+v", result)
+	}
+	// Dodge roll 30 fails; parry is capped at MaxAvoid, so 60 gets through
+	rolls = fixedRNG{10, 30, 60, 50, 2}
+	defender.Resist = map[string]int{DamageCode: -50, DamageKinetic: 100}
+	result := Attack(&rolls, attacker, defender)
+	if !result.Hit || result.DamageType != DamageCode || result.Damage != 18 {
+		t.Errorf("weak to code: %+v", result)
+	}
+	// Immune to kinetic damage
+	attacker.DamageType = ""
+	rolls = fixedRNG{10, 30, 60, 50, 2}
+	if result := Attack(&rolls, attacker, defender); !result.Hit || result.DamageType != DamageKinetic || result.Damage != 0 {
+		t.Errorf("immune to kinetic: %+v", result)
+	}
+}
+
+func TestAttackIntervalAndArmor(t *testing.T) {
+	if got := AttackInterval(0); got != RoundInterval {
+		t.Errorf("normal speed = %v", got)
+	}
+	if got := AttackInterval(150); got != time.Second {
+		t.Errorf("fast weapon = %v", got)
+	}
+	if got := AttackInterval(75); got != 2*time.Second {
+		t.Errorf("slow weapon = %v", got)
+	}
+	player := &Player{BaseAC: 10, Equipment: map[string]*Item{"body": {AC: 3}, "head": {AC: 2}, "feet": {AC: 1}}}
+	if got := GetTotalAC(player); got != 16 {
+		t.Errorf("GetTotalAC = %d, want 16", got)
+	}
+	if c := NPCCombatant("Cop", 3, 11); c.Strength != 3 || c.Damage != 2 || c.AC != 11 {
+		t.Errorf("NPCCombatant = %+v", c)
+	}
+}
@@ -0,0 +1,111 @@
+// Package game implements core game mechanics for Matrix MUD.
+package game
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Damage types. Resistances are percentages keyed by type: 100 is immune,
+// negative is a weakness.
+const (
+	DamageKinetic = "kinetic" // Fists, blades and bullets
+	DamageCode    = "code"    // Hacks and logic bombs
+	DamageEMP     = "emp"     // Electromagnetic pulses
+	DamagePsychic = "psychic" // Attacks on the mind, such as fear
+)
+
+// DamageTypes lists every damage type
+var DamageTypes = []string{DamageKinetic, DamageCode, DamageEMP, DamagePsychic}
+
+// NPC kinds. An NPC with no kind is human.
+const (
+	KindAgent    = "agent"    // Sentient programs that police the Matrix
+	KindProgram  = "program"  // Exiles and other programs
+	KindMachine  = "machine"  // Sentinels and other machines
+	KindBluepill = "bluepill" // Cops, guards and thugs still plugged in
+)
+
+// KindResist is the resistance each kind of NPC starts with. Code tears
+// through programs and Agents, kinetic damage beats bluepill thugs, and
+// machines have no mind to frighten but short out under EMP.
+var KindResist = map[string]map[string]int{
+	KindAgent:    {DamageCode: -50, DamageKinetic: 25, DamagePsychic: 50},
+	KindProgram:  {DamageCode: -50, DamagePsychic: 25},
+	KindMachine:  {DamageEMP: -100, DamagePsychic: 100, DamageKinetic: 25},
+	KindBluepill: {DamageKinetic: -50, DamageCode: 50, DamagePsychic: -25},
+}
+
+// ValidDamageType reports whether t is a damage type; "" counts as kinetic
+func ValidDamageType(t string) bool {
+	if t == "" {
+		return true
+	}
+	for _, dt := range DamageTypes {
+		if dt == t {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidKind reports whether kind is an NPC kind; "" is human
+func ValidKind(kind string) bool {
+	_, ok := KindResist[kind]
+	return ok || kind == ""
+}
+
+// Disables reports whether a damage type shuts down an NPC of a kind
+func Disables(kind, damageType string) bool {
+	return kind == KindMachine && damageType == DamageEMP
+}
+
+// MergeResist adds resistance tables together
+func MergeResist(tables ...map[string]int) map[string]int {
+	var total map[string]int
+	for _, t := range tables {
+		for dt, pct := range t {
+			if pct == 0 {
+				continue
+			}
+			if total == nil {
+				total = make(map[string]int)
+			}
+			total[dt] += pct
+		}
+	}
+	return total
+}
+
+// Resist reduces damage by a resistance table. Weaknesses increase it.
+func Resist(damage int, damageType string, resist map[string]int) int {
+	if damageType == "" {
+		damageType = DamageKinetic
+	}
+	pct := resist[damageType]
+	if pct > 100 {
+		pct = 100
+	}
+	damage = damage * (100 - pct) / 100
+	if damage < 0 {
+		damage = 0
+	}
+	return damage
+}
+
+// DescribeResist lists a resistance table in damage type order, e.g.
+// "kinetic 25%, weak to code 50%", or "" if it is empty
+func DescribeResist(resist map[string]int) string {
+	var parts []string
+	for _, dt := range DamageTypes {
+		switch pct := resist[dt]; {
+		case pct >= 100:
+			parts = append(parts, "immune to "+dt)
+		case pct > 0:
+			parts = append(parts, fmt.Sprintf("%s %d%%", dt, pct))
+		case pct < 0:
+			parts = append(parts, fmt.Sprintf("weak to %s %d%%", dt, -pct))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,47 @@
+package game
+
+import "testing"
+
+func TestResist(t *testing.T) {
+	agent := MergeResist(KindResist[KindAgent], map[string]int{DamageKinetic: 25})
+	tests := []struct {
+		name       string
+		damageType string
+		want       int
+	}{
+		{"untyped counts as kinetic", "", 50},
+		{"resisted", DamageKinetic, 50},
+		{"weakness", DamageCode, 150},
+		{"partly immune", DamagePsychic, 50},
+		{"no resistance", DamageEMP, 100},
+	}
+	for _, tt := range tests {
+		if got := Resist(100, tt.damageType, agent); got != tt.want {
+			t.Errorf("%s: Resist(100, %q) = %d, want %d", tt.name, tt.damageType, got, tt.want)
+		}
+	}
+	if got := Resist(100, DamagePsychic, KindResist[KindMachine]); got != 0 {
+		t.Errorf("machines should be immune to psychic damage, took %d", got)
+	}
+	if KindResist[KindAgent][DamageKinetic] != 25 {
+		t.Error("MergeResist changed the kind table")
+	}
+}
+
+func TestKindsAndDescribe(t *testing.T) {
+	if !ValidKind("") || !ValidKind(KindMachine) || ValidKind("dragon") {
+		t.Error("ValidKind")
+	}
+	if !ValidDamageType("") || !ValidDamageType(DamageEMP) || ValidDamageType("fire") {
+		t.Error("ValidDamageType")
+	}
+	if !Disables(KindMachine, DamageEMP) || Disables(KindAgent, DamageEMP) || Disables(KindMachine, DamageCode) {
+		t.Error("only EMP should disable, and only machines")
+	}
+	if got := DescribeResist(KindResist[KindMachine]); got != "kinetic 25%, weak to emp 100%, immune to psychic" {
+		t.Errorf("DescribeResist = %q", got)
+	}
+	if got := DescribeResist(nil); got != "" {
+		t.Errorf("DescribeResist(nil) = %q", got)
+	}
+}
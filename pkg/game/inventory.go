@@ -1,7 +1,11 @@
 // Package game implements core game mechanics for Matrix MUD.
 package game
 
-import "fmt"
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
 
 // Inventory constants
 const (
@@ -135,15 +139,34 @@ func GetEquippedWeaponDamage(player *Player) int {
 
 // GetTotalAC calculates total armor class from base + equipment
 func GetTotalAC(player *Player) int {
-	total := player.BaseAC
-	if player.Equipment != nil {
-		for _, item := range player.Equipment {
-			if item != nil {
-				total += item.AC
-			}
+	var pieces []int
+	for _, item := range player.Equipment {
+		if item != nil {
+			pieces = append(pieces, item.AC)
+		}
+	}
+	return TotalAC(player.BaseAC, pieces...)
+}
+
+// ParseQuantity splits an item command argument into a quantity and an item
+// name: "5 trash" is five trash, "all.trash" (or "all trash") every trash
+// and "all" everything, with an empty name. A plain name means one.
+func ParseQuantity(arg string) (n int, all bool, name string) {
+	arg = strings.ToLower(strings.TrimSpace(arg))
+	if arg == "all" {
+		return 0, true, ""
+	}
+	for _, prefix := range []string{"all.", "all "} {
+		if rest, ok := strings.CutPrefix(arg, prefix); ok {
+			return 0, true, strings.TrimSpace(rest)
+		}
+	}
+	if count, rest, ok := strings.Cut(arg, " "); ok {
+		if n, err := strconv.Atoi(count); err == nil && n > 0 {
+			return n, false, strings.TrimSpace(rest)
 		}
 	}
-	return total
+	return 1, false, arg
 }
 
 // matchesItemName checks if a search string matches an item's name (case-insensitive prefix)
@@ -280,3 +280,26 @@ func TestEquipReplacesExisting(t *testing.T) {
 		t.Error("Old weapon should be in inventory")
 	}
 }
+
+func TestParseQuantity(t *testing.T) {
+	tests := []struct {
+		arg  string
+		n    int
+		all  bool
+		name string
+	}{
+		{"trash", 1, false, "trash"},
+		{"5 Trash", 5, false, "trash"},
+		{"all.trash", 0, true, "trash"},
+		{"all health vial", 0, true, "health vial"},
+		{"all", 0, true, ""},
+		{"0 trash", 1, false, "0 trash"},
+		{"red pill", 1, false, "red pill"},
+	}
+	for _, tt := range tests {
+		n, all, name := ParseQuantity(tt.arg)
+		if n != tt.n || all != tt.all || name != tt.name {
+			t.Errorf("ParseQuantity(%q) = %d, %v, %q; want %d, %v, %q", tt.arg, n, all, name, tt.n, tt.all, tt.name)
+		}
+	}
+}
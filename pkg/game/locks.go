@@ -0,0 +1,30 @@
+// Package game implements core game mechanics for Matrix MUD.
+package game
+
+import "github.com/yourusername/matrix-mud/pkg/sim"
+
+// Lock constants. Picking and hacking a lock are checks against its
+// difficulty: a roll of 1 to LockDie plus the player's skill must reach it.
+const (
+	LockDie         = 20 // Sides of the die rolled for a lock check
+	SpecialistBonus = 5  // Skill bonus for a class's own trade: Rebels pick, Hackers hack
+)
+
+// LockSkill returns a player's skill at picking or hacking locks: half their
+// level, plus SpecialistBonus if it is their class's trade
+func LockSkill(level int, specialist bool) int {
+	skill := level / 2
+	if specialist {
+		skill += SpecialistBonus
+	}
+	return skill
+}
+
+// UnlockCheck rolls a lock check. A difficulty of 0 means the lock can't be
+// picked or hacked at all.
+func UnlockCheck(rng sim.RNG, skill, difficulty int) bool {
+	if difficulty <= 0 {
+		return false
+	}
+	return rng.Intn(LockDie)+1+skill >= difficulty
+}
@@ -0,0 +1,25 @@
+package game
+
+import "testing"
+
+func TestLockSkill(t *testing.T) {
+	if got := LockSkill(10, false); got != 5 {
+		t.Errorf("LockSkill(10, false) = %d, want 5", got)
+	}
+	if got := LockSkill(10, true); got != 5+SpecialistBonus {
+		t.Errorf("LockSkill(10, true) = %d, want %d", got, 5+SpecialistBonus)
+	}
+}
+
+func TestUnlockCheck(t *testing.T) {
+	// Roll 9 of [0, 20) is a 10: with skill 5 it reaches 15 but not 16
+	rolls := fixedRNG{9, 9}
+	if !UnlockCheck(&rolls, 5, 15) || UnlockCheck(&rolls, 5, 16) {
+		t.Error("check should succeed when roll plus skill reaches the difficulty")
+	}
+
+	rolls = fixedRNG{19}
+	if UnlockCheck(&rolls, 100, 0) || len(rolls) != 1 {
+		t.Error("difficulty 0 should fail without rolling")
+	}
+}
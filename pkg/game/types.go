@@ -16,7 +16,8 @@ type Item struct {
 	Damage, AC            int
 	Slot, Type, Effect    string
 	Value, Price          int
-	Rarity                int `json:"rarity"` // 0=Common, 1=Uncommon, 2=Rare, 3=Legendary
+	Rarity                int `json:"rarity"`          // 0=Common, 1=Uncommon, 2=Rare, 3=Legendary
+	Speed                 int `json:"speed,omitempty"` // Weapon speed; 0 is NormalSpeed
 }
 
 // Quest represents an NPC quest that rewards the player for delivering a specific item.
@@ -84,14 +84,28 @@ COMBAT ACTIONS
   use <item> - Use a consumable item
 
 SKILLS
-  Each class has unique skills:
-  - Hacker: glitch (damage), patch (heal), decrypt (buff)
-  - Runner: strike (damage), evade (defense), sprint (escape)
-  - Operator: scan (info), boost (buff), disrupt (debuff)
+  Each class has unique skills, unlocked as you level:
+  - Hacker: glitch (code), patch (heal), overflow, pulse (EMP), backdoor
+  - Rebel: smash (damage), fortify (defense), rampage (room), ironwall
+  - Operator: patch (heal ally), strike, vanish, mindjack (fear), assassinate
+  Use 'learn' to pick up new skills and 'practice' to improve them.
+
+DAMAGE TYPES
+  kinetic - Fists and blades; beats bluepill cops and guards
+  code    - Hacks; tears through programs and Agents
+  emp     - Shorts out Sentinels and other machines
+  psychic - Fear; useless against machines, which have no mind
+  'look <enemy>' shows its kind and resistances. Armor can add
+  resistance, and resistance can ward off effects of that type.
 
 DEATH & RESPAWN
-  If you die, you respawn at the Dojo with reduced HP/MP.
-  Your items remain with you.
+  If you die, you become a ghost beside your corpse. Ghosts can
+  look, talk and move, and nothing attacks them.
+  - 'release' returns you to life in Zion with half HP/MP
+  - An Operator can 'cast reboot <you>' where you fell
+  Your corpse keeps what you carried; 'loot' it before it decays.
+  Dying costs XP debt (repaid from half of later XP) and
+  durability. Below level 5 you keep your items and owe no XP.
 
 TIPS
   - Check enemy levels before engaging
@@ -111,7 +125,7 @@ OVERVIEW
 COMMANDS
   recipes - List all known recipes
   recipe <name> - Show recipe details
-  craft <name> - Craft an item if you have materials
+  craft [count] <name> - Craft an item if you have materials
 
 CRAFTING STATIONS
   Some recipes require specific locations:
@@ -240,9 +254,9 @@ var Entries = map[string]*Entry{
 	"look": {
 		Command:     "look",
 		Aliases:     []string{"l"},
-		Description: "Look at your surroundings, an item, or an NPC.",
-		Usage:       "look [target]",
-		Examples:    []string{"look", "look morpheus", "look katana"},
+		Description: "Look at your surroundings, an item, or an NPC. Look in a container to see what it holds. Dark rooms need a light, such as a flashlight, unless you are awakened.",
+		Usage:       "look [target] | look in <container>",
+		Examples:    []string{"look", "look morpheus", "look katana", "look in backpack"},
 		Category:    CatInfo,
 		Related:     []string{"examine", "inventory"},
 	},
@@ -302,22 +316,86 @@ var Entries = map[string]*Entry{
 	},
 	"get": {
 		Command:     "get",
-		Aliases:     []string{"g", "take", "pick"},
-		Description: "Pick up an item from the room.",
-		Usage:       "get <item>",
-		Examples:    []string{"get phone", "get katana", "get all"},
+		Aliases:     []string{"g", "take"},
+		Description: "Pick up items from the room, or take them out of a container. Give a count or all. to take part or all of a stack.",
+		Usage:       "get [count|all.]<item> [from <container>]",
+		Examples:    []string{"get phone", "get 5 trash", "get all.trash", "get all", "get all from footlocker"},
 		Category:    CatItems,
-		Related:     []string{"drop", "inventory"},
+		Related:     []string{"drop", "put", "inventory"},
 	},
 	"drop": {
 		Command:     "drop",
 		Aliases:     []string{"d"},
-		Description: "Drop an item from your inventory.",
-		Usage:       "drop <item>",
-		Examples:    []string{"drop phone", "drop trash"},
+		Description: "Drop items from your inventory. Give a count or all. to drop part or all of a stack.",
+		Usage:       "drop [count|all.]<item>",
+		Examples:    []string{"drop phone", "drop 3 trash", "drop all.trash"},
 		Category:    CatItems,
 		Related:     []string{"get", "inventory"},
 	},
+	"put": {
+		Command:     "put",
+		Description: "Put items you carry into a container. A backpack takes one inventory slot however much it holds.",
+		Usage:       "put [count|all.]<item> in <container>",
+		Examples:    []string{"put 5 trash in backpack", "put all in footlocker"},
+		Category:    CatItems,
+		Related:     []string{"get", "open", "look"},
+	},
+	"open": {
+		Command:     "open",
+		Description: "Open a closed container, or a door by its direction or name.",
+		Usage:       "open <container|door>",
+		Examples:    []string{"open footlocker", "open port", "open hatch"},
+		Category:    CatItems,
+		Related:     []string{"close", "unlock", "put"},
+	},
+	"close": {
+		Command:     "close",
+		Description: "Close an open container or door.",
+		Usage:       "close <container|door>",
+		Examples:    []string{"close footlocker", "close port"},
+		Category:    CatItems,
+		Related:     []string{"open", "lock"},
+	},
+	"lock": {
+		Command:     "lock",
+		Description: "Lock a closed container or door. You must carry its key.",
+		Usage:       "lock <container|door>",
+		Examples:    []string{"lock footlocker", "lock hatch"},
+		Category:    CatItems,
+		Related:     []string{"unlock", "close"},
+	},
+	"unlock": {
+		Command:     "unlock",
+		Description: "Unlock a locked container or door. You must carry its key.",
+		Usage:       "unlock <container|door>",
+		Examples:    []string{"unlock footlocker", "unlock hatch"},
+		Category:    CatItems,
+		Related:     []string{"lock", "open", "pick", "hack"},
+	},
+	"pick": {
+		Command:     "pick",
+		Description: "Try to pick a locked door's lock. Rebels are best at it.",
+		Usage:       "pick <door>",
+		Examples:    []string{"pick hatch", "pick port"},
+		Category:    CatMovement,
+		Related:     []string{"hack", "unlock"},
+	},
+	"hack": {
+		Command:     "hack",
+		Description: "Spend 5 MP to hack a door's electronic lock. Hackers are best at it; a failed hack raises your heat.",
+		Usage:       "hack <door>",
+		Examples:    []string{"hack hatch"},
+		Category:    CatMovement,
+		Related:     []string{"pick", "unlock"},
+	},
+	"search": {
+		Command:     "search",
+		Description: "Search the room for hidden exits. Awakened players find them with see_code too.",
+		Usage:       "search",
+		Examples:    []string{"search"},
+		Category:    CatMovement,
+		Related:     []string{"look"},
+	},
 	"inv": {
 		Command:     "inv",
 		Aliases:     []string{"i", "inventory"},
@@ -339,11 +417,35 @@ var Entries = map[string]*Entry{
 	"kill": {
 		Command:     "kill",
 		Aliases:     []string{"k", "attack", "a"},
-		Description: "Attack an NPC to start combat.",
+		Description: "Attack an NPC to start combat. Rooms marked [PVP] let you attack other players; no one can fight in [SAFE] rooms.",
 		Usage:       "kill <target>",
-		Examples:    []string{"kill agent", "attack cop"},
+		Examples:    []string{"kill agent", "attack cop", "kill trinity"},
+		Category:    CatCombat,
+		Related:     []string{"flee", "cast", "assist"},
+	},
+	"assist": {
+		Command:     "assist",
+		Description: "Join a party member's fight against the NPC they are attacking. NPCs attack whoever has built the most threat; Rebels draw extra threat and can taunt.",
+		Usage:       "assist <player>",
+		Examples:    []string{"assist morpheus"},
+		Category:    CatCombat,
+		Related:     []string{"kill", "party", "cast"},
+	},
+	"loot": {
+		Command:     "loot",
+		Description: "Take what a corpse holds. NPC corpses keep loot nobody took; your own corpse keeps everything you carried when you died. Only you can loot your corpse, and corpses decay.",
+		Usage:       "loot [corpse]",
+		Examples:    []string{"loot", "loot corpse of neo"},
+		Category:    CatCombat,
+		Related:     []string{"release", "get"},
+	},
+	"release": {
+		Command:     "release",
+		Description: "As a ghost, leave your corpse and return to life at a Zion respawn point with half your HP and MP. An Operator can instead reboot you where you fell.",
+		Usage:       "release",
+		Examples:    []string{"release"},
 		Category:    CatCombat,
-		Related:     []string{"flee", "cast"},
+		Related:     []string{"loot", "cast"},
 	},
 	"flee": {
 		Command:     "flee",
@@ -361,7 +463,7 @@ var Entries = map[string]*Entry{
 		Usage:       "cast <skill> [target]",
 		Examples:    []string{"cast glitch agent", "cast patch", "cast smash cop"},
 		Category:    CatCombat,
-		Related:     []string{"skills", "kill"},
+		Related:     []string{"skills", "learn", "kill"},
 	},
 	"wear": {
 		Command:     "wear",
@@ -461,33 +563,33 @@ var Entries = map[string]*Entry{
 	},
 	"buy": {
 		Command:     "buy",
-		Description: "Buy an item from a vendor.",
-		Usage:       "buy <item>",
-		Examples:    []string{"buy katana", "buy coat"},
+		Description: "Buy items from a vendor. Consumables and materials arrive as one stack.",
+		Usage:       "buy [count] <item>",
+		Examples:    []string{"buy katana", "buy 5 health vial"},
 		Category:    CatEconomy,
 		Related:     []string{"list", "sell"},
 	},
 	"sell": {
 		Command:     "sell",
-		Description: "Sell an item to a vendor.",
-		Usage:       "sell <item>",
-		Examples:    []string{"sell trash", "sell baton"},
+		Description: "Sell items to a vendor for half their price.",
+		Usage:       "sell [count|all.]<item>",
+		Examples:    []string{"sell baton", "sell 10 trash", "sell all.trash"},
 		Category:    CatEconomy,
 		Related:     []string{"list", "buy"},
 	},
 	"deposit": {
 		Command:     "deposit",
-		Description: "Deposit an item into your bank storage (at The Archive).",
-		Usage:       "deposit <item>",
-		Examples:    []string{"deposit katana", "deposit red_pill"},
+		Description: "Deposit items into your bank storage (at The Archive).",
+		Usage:       "deposit [count|all.]<item>",
+		Examples:    []string{"deposit katana", "deposit all.trash"},
 		Category:    CatEconomy,
 		Related:     []string{"withdraw", "storage"},
 	},
 	"withdraw": {
 		Command:     "withdraw",
-		Description: "Withdraw an item from your bank storage (at The Archive).",
-		Usage:       "withdraw <item>",
-		Examples:    []string{"withdraw katana", "withdraw coat"},
+		Description: "Withdraw items from your bank storage (at The Archive).",
+		Usage:       "withdraw [count|all.]<item>",
+		Examples:    []string{"withdraw katana", "withdraw 5 trash"},
 		Category:    CatEconomy,
 		Related:     []string{"deposit", "storage"},
 	},
@@ -510,11 +612,18 @@ var Entries = map[string]*Entry{
 	},
 	"recall": {
 		Command:     "recall",
-		Description: "Teleport back to the dojo (safe room). Useful if stuck.",
+		Description: "Teleport back to the dojo (safe room). Useful if stuck, though some places block recall.",
 		Usage:       "recall",
 		Examples:    []string{"recall"},
 		Category:    CatSystem,
 	},
+	"record": {
+		Command:     "record",
+		Description: "Record your sessions for playtesting. Passwords are never recorded.",
+		Usage:       "record [on|off]",
+		Examples:    []string{"record", "record on", "record off"},
+		Category:    CatSystem,
+	},
 	"quit": {
 		Command:     "quit",
 		Aliases:     []string{"exit", "logout"},
@@ -608,6 +717,23 @@ var Entries = map[string]*Entry{
 		Category:    CatCombat,
 		Related:     []string{"cast", "score"},
 	},
+	"learn": {
+		Command:     "learn",
+		Description: "List your class skills, or learn one you have reached the level for.",
+		Usage:       "learn [skill]",
+		Examples:    []string{"learn", "learn fortify"},
+		Category:    CatCombat,
+		Related:     []string{"practice", "skills", "cast"},
+	},
+	"practice": {
+		Command:     "practice",
+		Aliases:     []string{"prac"},
+		Description: "Spend a practice session to raise a skill's proficiency. Higher proficiency means fewer fizzled casts. You earn sessions by leveling.",
+		Usage:       "practice [skill]",
+		Examples:    []string{"practice", "practice glitch"},
+		Category:    CatCombat,
+		Related:     []string{"learn", "skills"},
+	},
 	"recipes": {
 		Command:     "recipes",
 		Description: "View available crafting recipes.",
@@ -619,8 +745,8 @@ var Entries = map[string]*Entry{
 	"craft": {
 		Command:     "craft",
 		Description: "Craft an item from components.",
-		Usage:       "craft <recipe>",
-		Examples:    []string{"craft health_vial", "craft katana"},
+		Usage:       "craft [count] <recipe>",
+		Examples:    []string{"craft health_vial", "craft 3 health_vial", "craft katana"},
 		Category:    CatItems,
 		Related:     []string{"recipes"},
 	},
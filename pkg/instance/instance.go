@@ -9,6 +9,9 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/yourusername/matrix-mud/pkg/game"
+	"github.com/yourusername/matrix-mud/pkg/sim"
 )
 
 // Difficulty levels for instances
@@ -74,6 +77,7 @@ type InstanceNPC struct {
 	HP      int    `json:"hp"`
 	MaxHP   int    `json:"max_hp"`
 	Damage  int    `json:"damage"`
+	AC      int    `json:"ac"`
 	IsAlive bool   `json:"alive"`
 }
 
@@ -332,6 +336,7 @@ func (m *Manager) CreateInstance(templateID, ownerName string, playerLevel int)
 				HP:      getNPCHP(npcID, template.Difficulty),
 				MaxHP:   getNPCHP(npcID, template.Difficulty),
 				Damage:  getNPCDamage(npcID, template.Difficulty),
+				AC:      getNPCAC(template.Difficulty),
 				IsAlive: true,
 			}
 			room.NPCs = append(room.NPCs, npc)
@@ -375,6 +380,20 @@ func (m *Manager) GetPlayerInstance(playerName string) *Instance {
 	return m.Instances[instanceID]
 }
 
+// ActiveCount returns the number of instances in progress
+func (m *Manager) ActiveCount() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	count := 0
+	for _, inst := range m.Instances {
+		if inst.State == "active" {
+			count++
+		}
+	}
+	return count
+}
+
 // GetCurrentRoom returns the current room in a player's instance
 func (m *Manager) GetCurrentRoom(playerName string) *InstanceRoom {
 	inst := m.GetPlayerInstance(playerName)
@@ -420,8 +439,9 @@ func (m *Manager) MoveInInstance(playerName, direction string) (string, bool) {
 	return fmt.Sprintf("You enter %s.\r\n\r\n%s", newRoom.Name, newRoom.Description), true
 }
 
-// AttackInInstance handles combat in an instance
-func (m *Manager) AttackInInstance(playerName, targetName string, playerDamage int) (string, bool) {
+// AttackInInstance handles combat in an instance. The attack is resolved
+// by the shared combat engine against the enemy's armor class.
+func (m *Manager) AttackInInstance(rng sim.RNG, playerName, targetName string, attacker game.Combatant) (string, bool) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -442,8 +462,13 @@ func (m *Manager) AttackInInstance(playerName, targetName string, playerDamage i
 			continue
 		}
 		if strings.Contains(strings.ToLower(npc.Name), targetLower) {
+			result := game.Attack(rng, attacker, game.NPCCombatant(npc.Name, npc.Damage, npc.AC))
+			if !result.Hit {
+				return fmt.Sprintf("You swing at %s but miss.", npc.Name), true
+			}
+
 			// Deal damage
-			npc.HP -= playerDamage
+			npc.HP -= result.Damage
 			if npc.HP <= 0 {
 				npc.HP = 0
 				npc.IsAlive = false
@@ -467,7 +492,7 @@ func (m *Manager) AttackInInstance(playerName, targetName string, playerDamage i
 				}
 				return fmt.Sprintf("You defeat %s!", npc.Name), true
 			}
-			return fmt.Sprintf("You hit %s for %d damage. (%d/%d HP)", npc.Name, playerDamage, npc.HP, npc.MaxHP), true
+			return fmt.Sprintf("You hit %s for %d damage. (%d/%d HP)", npc.Name, result.Damage, npc.HP, npc.MaxHP), true
 		}
 	}
 
@@ -663,6 +688,11 @@ func getNPCDamage(npcID string, diff Difficulty) int {
 	return base * int(diff) / 2
 }
 
+// getNPCAC returns an instance enemy's armor class, tougher on harder runs
+func getNPCAC(diff Difficulty) int {
+	return 8 + 2*int(diff)
+}
+
 func difficultyName(d Difficulty) string {
 	switch d {
 	case DiffEasy:
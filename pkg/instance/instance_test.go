@@ -3,6 +3,9 @@ package instance
 import (
 	"strings"
 	"testing"
+
+	"github.com/yourusername/matrix-mud/pkg/game"
+	"github.com/yourusername/matrix-mud/pkg/sim"
 )
 
 func TestNewManager(t *testing.T) {
@@ -111,7 +114,7 @@ func TestAttackInInstance(t *testing.T) {
 	m.CreateInstance("training_gauntlet", "TestPlayer", 5)
 
 	// Attack training bot
-	msg, ok := m.AttackInInstance("TestPlayer", "training", 100)
+	msg, ok := m.AttackInInstance(sim.NewRand(1), "TestPlayer", "training", game.Combatant{Name: "TestPlayer", Strength: 30, Damage: 100})
 	if !ok {
 		t.Errorf("Attack failed: %s", msg)
 	}
@@ -125,7 +128,7 @@ func TestAttackInvalidTarget(t *testing.T) {
 
 	m.CreateInstance("training_gauntlet", "TestPlayer", 5)
 
-	msg, ok := m.AttackInInstance("TestPlayer", "nonexistent", 10)
+	msg, ok := m.AttackInInstance(sim.NewRand(1), "TestPlayer", "nonexistent", game.Combatant{Name: "TestPlayer", Strength: 10, Damage: 10})
 	if ok {
 		t.Error("Should fail for invalid target")
 	}
@@ -308,8 +311,9 @@ func TestInstanceRoomClearing(t *testing.T) {
 	m.CreateInstance("training_gauntlet", "TestPlayer", 5)
 
 	// Kill all enemies in first room (one training bot)
+	rng := sim.NewRand(1)
 	for i := 0; i < 10; i++ {
-		msg, _ := m.AttackInInstance("TestPlayer", "training", 100)
+		msg, _ := m.AttackInInstance(rng, "TestPlayer", "training", game.Combatant{Name: "TestPlayer", Strength: 30, Damage: 100})
 		if strings.Contains(msg, "cleared") {
 			break
 		}
@@ -341,3 +345,18 @@ func TestGetCurrentRoom(t *testing.T) {
 		t.Errorf("Wrong room name: %s", room.Name)
 	}
 }
+
+func TestActiveCount(t *testing.T) {
+	m := NewManager()
+	if m.ActiveCount() != 0 {
+		t.Error("New manager should have no active instances")
+	}
+	m.CreateInstance("training_gauntlet", "TestPlayer", 5)
+	if m.ActiveCount() != 1 {
+		t.Errorf("ActiveCount = %d, want 1", m.ActiveCount())
+	}
+	m.LeaveInstance("TestPlayer")
+	if m.ActiveCount() != 0 {
+		t.Errorf("ActiveCount after leaving = %d, want 0", m.ActiveCount())
+	}
+}
@@ -0,0 +1,387 @@
+// Package irc bridges Matrix MUD chat channels to IRC channels.
+// A Bridge is a minimal IRC client: it registers, joins the mapped IRC
+// channels, relays messages in both directions and reconnects with
+// backoff when the connection drops.
+package irc
+
+import (
+	"bufio"
+	"crypto/tls"
+	"fmt"
+	"math/rand"
+	"net"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Reconnect defaults
+const (
+	DefaultReconnectMin = 2 * time.Second
+	DefaultReconnectMax = 5 * time.Minute
+)
+
+// maxTextBytes keeps relayed lines well inside IRC's 512-byte limit once
+// the prefix, command and channel are added
+const maxTextBytes = 400
+
+// sendQueueSize is how many outbound lines may wait while disconnected or
+// flooded before new ones are dropped
+const sendQueueSize = 100
+
+// Config configures a Bridge
+type Config struct {
+	Server   string            // host:port
+	TLS      bool              // Connect with TLS
+	Nick     string            // Bot nickname; "_" is appended if taken
+	Password string            // Server password (PASS), optional
+	Channels map[string]string // Game channel ID -> IRC channel
+
+	ReconnectMin time.Duration // First reconnect delay
+	ReconnectMax time.Duration // Cap on reconnect delay
+	SendInterval time.Duration // Minimum gap between outbound lines (flood control)
+}
+
+// Message is a line received from a linked IRC channel
+type Message struct {
+	Channel string // Game channel ID
+	Nick    string // IRC nickname of the sender
+	Text    string // Text with IRC formatting removed
+}
+
+// Bridge relays chat between game channels and IRC channels
+type Bridge struct {
+	config    Config
+	onMessage func(Message)
+	toGame    map[string]string // lower-case IRC channel -> game channel ID
+	send      chan string
+
+	mu        sync.Mutex
+	conn      net.Conn
+	nick      string
+	connected bool
+	started   bool
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// ParseChannels parses "global=#construct,trade=#construct-trade" into a
+// game channel -> IRC channel map
+func ParseChannels(s string) (map[string]string, error) {
+	channels := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		game, ircChannel, ok := strings.Cut(pair, "=")
+		game, ircChannel = strings.TrimSpace(game), strings.TrimSpace(ircChannel)
+		if !ok || game == "" || !strings.HasPrefix(ircChannel, "#") || strings.ContainsAny(ircChannel, " ,\a") {
+			return nil, fmt.Errorf("invalid channel mapping %q (want game=#irc)", pair)
+		}
+		channels[game] = ircChannel
+	}
+	return channels, nil
+}
+
+// NewBridge creates a bridge that calls onMessage for every message from a
+// linked IRC channel. onMessage runs on the bridge's read loop.
+func NewBridge(config Config, onMessage func(Message)) *Bridge {
+	if config.ReconnectMin <= 0 {
+		config.ReconnectMin = DefaultReconnectMin
+	}
+	if config.ReconnectMax < config.ReconnectMin {
+		config.ReconnectMax = DefaultReconnectMax
+	}
+	if config.SendInterval <= 0 {
+		config.SendInterval = 500 * time.Millisecond
+	}
+	toGame := make(map[string]string, len(config.Channels))
+	for game, ircChannel := range config.Channels {
+		toGame[strings.ToLower(ircChannel)] = game
+	}
+	return &Bridge{
+		config:    config,
+		onMessage: onMessage,
+		toGame:    toGame,
+		send:      make(chan string, sendQueueSize),
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+}
+
+// Start connects in the background, reconnecting until Stop is called
+func (b *Bridge) Start() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.started {
+		return
+	}
+	b.started = true
+	go b.run()
+}
+
+// Stop quits IRC and waits for the bridge to shut down
+func (b *Bridge) Stop() {
+	select {
+	case <-b.stop:
+		return
+	default:
+	}
+	close(b.stop)
+
+	b.mu.Lock()
+	started := b.started
+	if b.conn != nil {
+		fmt.Fprintf(b.conn, "QUIT :Shutting down\r\n")
+		b.conn.Close()
+	}
+	b.mu.Unlock()
+	if started {
+		<-b.done
+	}
+}
+
+// Connected reports whether the bridge is registered with the server
+func (b *Bridge) Connected() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.connected
+}
+
+// Relay sends a game chat message to the IRC channel linked to channelID,
+// formatted as "<sender> text". It never blocks: messages for unlinked
+// channels, and messages that arrive while the queue is full, are dropped.
+func (b *Bridge) Relay(channelID, sender, text string) {
+	ircChannel, ok := b.config.Channels[channelID]
+	if !ok {
+		return
+	}
+	line := fmt.Sprintf("PRIVMSG %s :<%s> %s", ircChannel, stripControl(sender), stripControl(text))
+	if len(line) > maxTextBytes {
+		line = truncateUTF8(line, maxTextBytes)
+	}
+	select {
+	case b.send <- line:
+	default:
+	}
+}
+
+// run is the connect loop
+func (b *Bridge) run() {
+	defer close(b.done)
+
+	failures := 0
+	for {
+		registered := b.session()
+		if registered {
+			failures = 0
+		}
+		failures++
+
+		select {
+		case <-b.stop:
+			return
+		case <-time.After(b.backoff(failures)):
+		}
+	}
+}
+
+// backoff returns the delay before reconnect attempt n (starting at 1):
+// exponential with jitter, capped at ReconnectMax
+func (b *Bridge) backoff(n int) time.Duration {
+	d := b.config.ReconnectMin
+	for i := 1; i < n && d < b.config.ReconnectMax; i++ {
+		d *= 2
+	}
+	if d > b.config.ReconnectMax {
+		d = b.config.ReconnectMax
+	}
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(d-half)+1))
+}
+
+// dial opens the connection to the server
+func (b *Bridge) dial() (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: 15 * time.Second}
+	if b.config.TLS {
+		host, _, _ := net.SplitHostPort(b.config.Server)
+		return tls.DialWithDialer(dialer, "tcp", b.config.Server, &tls.Config{ServerName: host})
+	}
+	return dialer.Dial("tcp", b.config.Server)
+}
+
+// session runs one connection until it drops and reports whether it got as
+// far as registering
+func (b *Bridge) session() bool {
+	conn, err := b.dial()
+	if err != nil {
+		return false
+	}
+
+	b.mu.Lock()
+	select {
+	case <-b.stop:
+		b.mu.Unlock()
+		conn.Close()
+		return false
+	default:
+	}
+	b.conn = conn
+	b.nick = b.config.Nick
+	b.mu.Unlock()
+
+	defer func() {
+		b.mu.Lock()
+		b.conn, b.connected = nil, false
+		b.mu.Unlock()
+		conn.Close()
+	}()
+
+	if b.config.Password != "" {
+		b.writeLine(conn, "PASS "+b.config.Password)
+	}
+	b.writeLine(conn, "NICK "+b.config.Nick)
+	b.writeLine(conn, fmt.Sprintf("USER %s 0 * :Matrix MUD bridge", b.config.Nick))
+
+	// Outbound lines are written by a separate loop so a slow server never
+	// blocks the read loop, and paced so the bot is not kicked for flooding
+	writerDone := make(chan struct{})
+	defer close(writerDone)
+	go b.writeLoop(conn, writerDone)
+
+	registered := false
+	reader := bufio.NewReader(conn)
+	for {
+		conn.SetReadDeadline(time.Now().Add(5 * time.Minute))
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return registered
+		}
+		if b.handleLine(conn, strings.TrimRight(line, "\r\n")) {
+			registered = true
+		}
+	}
+}
+
+// writeLoop drains the send queue while registered
+func (b *Bridge) writeLoop(conn net.Conn, done chan struct{}) {
+	ticker := time.NewTicker(b.config.SendInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+		if !b.Connected() {
+			continue
+		}
+		select {
+		case line := <-b.send:
+			if b.writeLine(conn, line) != nil {
+				return
+			}
+		default:
+		}
+	}
+}
+
+// writeLine sends one protocol line
+func (b *Bridge) writeLine(conn net.Conn, line string) error {
+	conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+	_, err := fmt.Fprintf(conn, "%s\r\n", line)
+	return err
+}
+
+// handleLine processes one line from the server and reports whether it
+// completed registration
+func (b *Bridge) handleLine(conn net.Conn, line string) bool {
+	prefix, command, params := parseLine(line)
+	switch command {
+	case "PING":
+		b.writeLine(conn, "PONG :"+strings.Join(params, " "))
+	case "001": // RPL_WELCOME
+		for _, ircChannel := range b.config.Channels {
+			b.writeLine(conn, "JOIN "+ircChannel)
+		}
+		b.mu.Lock()
+		b.connected = true
+		b.mu.Unlock()
+		return true
+	case "433": // ERR_NICKNAMEINUSE
+		b.mu.Lock()
+		b.nick += "_"
+		nick := b.nick
+		b.mu.Unlock()
+		b.writeLine(conn, "NICK "+nick)
+	case "PRIVMSG":
+		if len(params) < 2 {
+			return false
+		}
+		game, ok := b.toGame[strings.ToLower(params[0])]
+		if !ok {
+			return false // Private message or unlinked channel
+		}
+		nick, _, _ := strings.Cut(prefix, "!")
+		b.mu.Lock()
+		self := strings.EqualFold(nick, b.nick)
+		b.mu.Unlock()
+		if self || nick == "" {
+			return false
+		}
+		text := params[1]
+		if action, ok := strings.CutPrefix(text, "\x01ACTION "); ok {
+			text = "*" + strings.TrimSuffix(action, "\x01") + "*"
+		} else if strings.HasPrefix(text, "\x01") {
+			return false // Other CTCP requests
+		}
+		text = strings.TrimSpace(stripControl(text))
+		if text != "" && b.onMessage != nil {
+			b.onMessage(Message{Channel: game, Nick: stripControl(nick), Text: text})
+		}
+	}
+	return false
+}
+
+// parseLine splits ":prefix COMMAND a b :trailing text"
+func parseLine(line string) (prefix, command string, params []string) {
+	if rest, ok := strings.CutPrefix(line, ":"); ok {
+		prefix, line, _ = strings.Cut(rest, " ")
+	}
+	line, trailing, hasTrailing := strings.Cut(line, " :")
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return prefix, "", nil
+	}
+	command, params = strings.ToUpper(fields[0]), fields[1:]
+	if hasTrailing {
+		params = append(params, trailing)
+	}
+	return prefix, command, params
+}
+
+// ircFormatting matches IRC colour codes and other formatting bytes
+var ircFormatting = regexp.MustCompile(`\x03(\d{1,2}(,\d{1,2})?)?|[\x02\x0f\x11\x16\x1d\x1e\x1f]`)
+
+// stripControl removes IRC formatting and every other control character,
+// so relayed text can neither inject protocol lines nor terminal escapes
+func stripControl(s string) string {
+	s = ircFormatting.ReplaceAllString(s, "")
+	return strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// truncateUTF8 cuts s to at most n bytes without splitting a rune
+func truncateUTF8(s string, n int) string {
+	for n > 0 && n < len(s) && s[n]&0xC0 == 0x80 {
+		n--
+	}
+	return s[:n]
+}
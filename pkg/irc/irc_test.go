@@ -0,0 +1,269 @@
+package irc
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeServer is a scripted IRC server listening on localhost
+type fakeServer struct {
+	ln    net.Listener
+	conns chan *fakeConn
+}
+
+// fakeConn is one client connection to the fake server
+type fakeConn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+	t      *testing.T
+}
+
+func newFakeServer(t *testing.T) *fakeServer {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &fakeServer{ln: ln, conns: make(chan *fakeConn, 4)}
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			s.conns <- &fakeConn{conn: c, reader: bufio.NewReader(c), t: t}
+		}
+	}()
+	t.Cleanup(func() { ln.Close() })
+	return s
+}
+
+// accept waits for the bridge to connect
+func (s *fakeServer) accept(t *testing.T) *fakeConn {
+	t.Helper()
+	select {
+	case c := <-s.conns:
+		t.Cleanup(func() { c.conn.Close() })
+		return c
+	case <-time.After(3 * time.Second):
+		t.Fatal("bridge did not connect")
+		return nil
+	}
+}
+
+// expect reads lines until one starts with prefix
+func (c *fakeConn) expect(prefix string) string {
+	c.t.Helper()
+	c.conn.SetReadDeadline(time.Now().Add(3 * time.Second))
+	for {
+		line, err := c.reader.ReadString('\n')
+		if err != nil {
+			c.t.Fatalf("waiting for %q: %v", prefix, err)
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if strings.HasPrefix(line, prefix) {
+			return line
+		}
+	}
+}
+
+func (c *fakeConn) send(format string, args ...interface{}) {
+	fmt.Fprintf(c.conn, format+"\r\n", args...)
+}
+
+// register completes the handshake and waits for the channel joins
+func (c *fakeConn) register(nick string, joins int) {
+	c.t.Helper()
+	c.expect("NICK " + nick)
+	c.expect("USER " + nick)
+	c.send(":irc.test 001 %s :Welcome", nick)
+	for i := 0; i < joins; i++ {
+		c.expect("JOIN #")
+	}
+}
+
+func testBridge(s *fakeServer, onMessage func(Message)) *Bridge {
+	return NewBridge(Config{
+		Server:       s.ln.Addr().String(),
+		Nick:         "construct",
+		Channels:     map[string]string{"global": "#construct", "trade": "#Trade"},
+		ReconnectMin: 10 * time.Millisecond,
+		ReconnectMax: 20 * time.Millisecond,
+		SendInterval: time.Millisecond,
+	}, onMessage)
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(3 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestParseChannels(t *testing.T) {
+	channels, err := ParseChannels("global=#construct, trade = #trade,,zion=#zion")
+	if err != nil {
+		t.Fatalf("ParseChannels failed: %v", err)
+	}
+	if len(channels) != 3 || channels["trade"] != "#trade" || channels["zion"] != "#zion" {
+		t.Errorf("channels = %v", channels)
+	}
+
+	for _, bad := range []string{"global", "global=construct", "=#x", "global=#a b"} {
+		if _, err := ParseChannels(bad); err == nil {
+			t.Errorf("ParseChannels(%q) should fail", bad)
+		}
+	}
+}
+
+func TestBridgeInbound(t *testing.T) {
+	s := newFakeServer(t)
+	got := make(chan Message, 10)
+	b := testBridge(s, func(m Message) { got <- m })
+	b.Start()
+	defer b.Stop()
+
+	c := s.accept(t)
+	c.register("construct", 2)
+	waitFor(t, "registration", b.Connected)
+
+	c.send("PING :irc.test")
+	if line := c.expect("PONG"); line != "PONG :irc.test" {
+		t.Errorf("PONG = %q", line)
+	}
+
+	c.send(":tank!t@zion PRIVMSG #construct :\x02operator\x02 \x0304standing by\x1b[2J")
+	c.send(":tank!t@zion PRIVMSG #trade :\x01ACTION sells a phone\x01")
+	c.send(":tank!t@zion PRIVMSG #elsewhere :not linked")
+	c.send(":tank!t@zion PRIVMSG construct :private")
+	c.send(":tank!t@zion PRIVMSG #construct :\x01VERSION\x01")
+	c.send(":construct!c@host PRIVMSG #construct :echo of our own line")
+	c.send(":tank!t@zion PRIVMSG #construct :last")
+
+	want := []Message{
+		{Channel: "global", Nick: "tank", Text: "operator standing by[2J"},
+		{Channel: "trade", Nick: "tank", Text: "*sells a phone*"},
+		{Channel: "global", Nick: "tank", Text: "last"},
+	}
+	for _, w := range want {
+		select {
+		case m := <-got:
+			if m != w {
+				t.Errorf("got %+v, want %+v", m, w)
+			}
+		case <-time.After(3 * time.Second):
+			t.Fatalf("missing message %+v", w)
+		}
+	}
+}
+
+func TestBridgeOutbound(t *testing.T) {
+	s := newFakeServer(t)
+	b := testBridge(s, nil)
+
+	// Queued before connecting, delivered once registered
+	b.Relay("global", "Neo", "hello\r\nQUIT :injected")
+	b.Relay("help", "Neo", "unlinked channel")
+	b.Start()
+	defer b.Stop()
+
+	c := s.accept(t)
+	c.register("construct", 2)
+
+	if line := c.expect("PRIVMSG"); line != "PRIVMSG #construct :<Neo> helloQUIT :injected" {
+		t.Errorf("relayed line = %q", line)
+	}
+
+	b.Relay("trade", "Neo", strings.Repeat("é", 400))
+	line := c.expect("PRIVMSG #Trade")
+	if len(line) > maxTextBytes || !strings.HasSuffix(line, "é") {
+		t.Errorf("long line not truncated on a rune boundary: %d bytes", len(line))
+	}
+}
+
+func TestBridgeNickInUse(t *testing.T) {
+	s := newFakeServer(t)
+	got := make(chan Message, 1)
+	b := testBridge(s, func(m Message) { got <- m })
+	b.Start()
+	defer b.Stop()
+
+	c := s.accept(t)
+	c.expect("NICK construct")
+	c.send(":irc.test 433 * construct :Nickname is already in use")
+	c.expect("NICK construct_")
+	c.send(":irc.test 001 construct_ :Welcome")
+	c.expect("JOIN")
+
+	// Messages from the bot's new nick are its own echoes
+	c.send(":construct_!c@host PRIVMSG #construct :mine")
+	c.send(":construct!c@host PRIVMSG #construct :the other construct")
+	select {
+	case m := <-got:
+		if m.Nick != "construct" {
+			t.Errorf("got %+v", m)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("no message")
+	}
+}
+
+func TestBridgeReconnect(t *testing.T) {
+	s := newFakeServer(t)
+	b := testBridge(s, nil)
+	b.Start()
+	defer b.Stop()
+
+	c := s.accept(t)
+	c.register("construct", 2)
+	waitFor(t, "registration", b.Connected)
+
+	c.conn.Close()
+	waitFor(t, "disconnect", func() bool { return !b.Connected() })
+
+	c = s.accept(t)
+	c.register("construct", 2)
+	waitFor(t, "re-registration", b.Connected)
+}
+
+func TestBridgeStop(t *testing.T) {
+	s := newFakeServer(t)
+	b := testBridge(s, nil)
+	b.Start()
+
+	c := s.accept(t)
+	c.register("construct", 2)
+	waitFor(t, "registration", b.Connected)
+
+	b.Stop()
+	c.expect("QUIT")
+	b.Stop() // Idempotent
+
+	select {
+	case <-s.conns:
+		t.Error("bridge reconnected after Stop")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// Stopping a bridge that never started returns immediately
+	NewBridge(Config{Server: "127.0.0.1:1"}, nil).Stop()
+}
+
+func TestBackoff(t *testing.T) {
+	b := NewBridge(Config{ReconnectMin: time.Second, ReconnectMax: 4 * time.Second}, nil)
+	for n, max := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second, 8: 4 * time.Second} {
+		for i := 0; i < 20; i++ {
+			if d := b.backoff(n); d < max/2 || d > max {
+				t.Fatalf("backoff(%d) = %v, want within [%v, %v]", n, d, max/2, max)
+			}
+		}
+	}
+}
@@ -0,0 +1,222 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OtherLabel collects observations for label values beyond a vector's limit
+const OtherLabel = "other"
+
+// Default bucket layouts, in seconds
+var (
+	LatencyBuckets   = []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5}
+	QueueWaitBuckets = []float64{0.0001, 0.0005, 0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1}
+)
+
+// histogram counts observations into cumulative buckets
+type histogram struct {
+	counts []uint64 // counts[i] is observations <= buckets[i]; last is +Inf
+	sum    float64
+	count  uint64
+}
+
+// HistogramVec is a set of histograms partitioned by one label. At most
+// maxLabels distinct values are tracked; further values are folded into
+// OtherLabel so user input can never grow the series without bound.
+type HistogramVec struct {
+	mu        sync.Mutex
+	name      string
+	help      string
+	label     string // Empty for an unlabelled histogram
+	buckets   []float64
+	maxLabels int
+	series    map[string]*histogram
+}
+
+// NewHistogramVec creates a histogram vector. Pass an empty label for a
+// single unlabelled histogram.
+func NewHistogramVec(name, help, label string, buckets []float64, maxLabels int) *HistogramVec {
+	return &HistogramVec{
+		name:      name,
+		help:      help,
+		label:     label,
+		buckets:   buckets,
+		maxLabels: maxLabels,
+		series:    make(map[string]*histogram),
+	}
+}
+
+// Observe records a duration for a label value
+func (v *HistogramVec) Observe(labelValue string, d time.Duration) {
+	v.ObserveValue(labelValue, d.Seconds())
+}
+
+// ObserveValue records a raw value for a label value
+func (v *HistogramVec) ObserveValue(labelValue string, value float64) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	h, ok := v.series[labelValue]
+	if !ok {
+		if v.label != "" && len(v.series) >= v.maxLabels {
+			labelValue = OtherLabel
+			h = v.series[labelValue]
+		}
+		if h == nil {
+			h = &histogram{counts: make([]uint64, len(v.buckets)+1)}
+			v.series[labelValue] = h
+		}
+	}
+
+	for i, le := range v.buckets {
+		if value <= le {
+			h.counts[i]++
+		}
+	}
+	h.counts[len(v.buckets)]++
+	h.sum += value
+	h.count++
+}
+
+// Count returns the number of observations for a label value
+func (v *HistogramVec) Count(labelValue string) uint64 {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if h, ok := v.series[labelValue]; ok {
+		return h.count
+	}
+	return 0
+}
+
+// write renders the vector in the Prometheus text format
+func (v *HistogramVec) write(w io.Writer) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	fmt.Fprintf(w, "# HELP %s %s\n", v.name, v.help)
+	fmt.Fprintf(w, "# TYPE %s histogram\n", v.name)
+	for _, lv := range sortedKeys(v.series) {
+		h := v.series[lv]
+		labels := ""
+		if v.label != "" {
+			labels = v.label + "=" + quoteLabel(lv) + ","
+		}
+		for i, le := range v.buckets {
+			fmt.Fprintf(w, "%s_bucket{%sle=\"%s\"} %d\n", v.name, labels, formatFloat(le), h.counts[i])
+		}
+		fmt.Fprintf(w, "%s_bucket{%sle=\"+Inf\"} %d\n", v.name, labels, h.counts[len(v.buckets)])
+		selector := ""
+		if v.label != "" {
+			selector = "{" + strings.TrimSuffix(labels, ",") + "}"
+		}
+		fmt.Fprintf(w, "%s_sum%s %s\n", v.name, selector, formatFloat(h.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", v.name, selector, h.count)
+	}
+	fmt.Fprintln(w)
+}
+
+// GaugeVec is a set of gauges partitioned by one label, replaced wholesale
+// on each collection. Values beyond maxLabels are summed into OtherLabel.
+type GaugeVec struct {
+	mu        sync.Mutex
+	name      string
+	help      string
+	label     string
+	maxLabels int
+	values    map[string]float64
+}
+
+// NewGaugeVec creates a gauge vector
+func NewGaugeVec(name, help, label string, maxLabels int) *GaugeVec {
+	return &GaugeVec{
+		name:      name,
+		help:      help,
+		label:     label,
+		maxLabels: maxLabels,
+		values:    make(map[string]float64),
+	}
+}
+
+// Replace sets the gauges to values, dropping label values not present.
+// When there are more than maxLabels values the smallest are summed into
+// OtherLabel so the busiest series stay visible.
+func (v *GaugeVec) Replace(values map[string]float64) {
+	keys := sortedKeys(values)
+	sort.SliceStable(keys, func(i, j int) bool { return values[keys[i]] > values[keys[j]] })
+
+	next := make(map[string]float64, len(values))
+	for i, k := range keys {
+		if i < v.maxLabels {
+			next[k] = values[k]
+		} else {
+			next[OtherLabel] += values[k]
+		}
+	}
+
+	v.mu.Lock()
+	v.values = next
+	v.mu.Unlock()
+}
+
+// Get returns the gauge for a label value
+func (v *GaugeVec) Get(labelValue string) float64 {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.values[labelValue]
+}
+
+// write renders the vector in the Prometheus text format
+func (v *GaugeVec) write(w io.Writer) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	fmt.Fprintf(w, "# HELP %s %s\n", v.name, v.help)
+	fmt.Fprintf(w, "# TYPE %s gauge\n", v.name)
+	for _, lv := range sortedKeys(v.values) {
+		fmt.Fprintf(w, "%s{%s=%s} %s\n", v.name, v.label, quoteLabel(lv), formatFloat(v.values[lv]))
+	}
+	fmt.Fprintln(w)
+}
+
+// sortedKeys returns a map's keys in order, for stable output
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// quoteLabel quotes a label value using the escapes the Prometheus text
+// format allows (\\, \" and \n), dropping other control characters
+func quoteLabel(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch {
+		case r == '\\':
+			b.WriteString(`\\`)
+		case r == '"':
+			b.WriteString(`\"`)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r < 0x20 || r == 0x7f:
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// formatFloat renders a float the way Prometheus clients do
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
@@ -0,0 +1,119 @@
+package metrics
+
+import (
+	"bytes"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHistogramBuckets(t *testing.T) {
+	v := NewHistogramVec("test_seconds", "Test", "op", []float64{0.1, 1}, 10)
+	v.Observe("read", 50*time.Millisecond)
+	v.Observe("read", 500*time.Millisecond)
+	v.Observe("read", 2*time.Second)
+
+	var buf bytes.Buffer
+	v.write(&buf)
+	out := buf.String()
+
+	for _, want := range []string{
+		"# TYPE test_seconds histogram",
+		`test_seconds_bucket{op="read",le="0.1"} 1`,
+		`test_seconds_bucket{op="read",le="1"} 2`,
+		`test_seconds_bucket{op="read",le="+Inf"} 3`,
+		`test_seconds_sum{op="read"} 2.55`,
+		`test_seconds_count{op="read"} 3`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+}
+
+func TestHistogramUnlabelled(t *testing.T) {
+	v := NewHistogramVec("tick_seconds", "Tick", "", []float64{1}, 1)
+	v.ObserveValue("", 0.5)
+	v.ObserveValue("", 0.7)
+
+	var buf bytes.Buffer
+	v.write(&buf)
+	if !strings.Contains(buf.String(), `tick_seconds_bucket{le="1"} 2`) || !strings.Contains(buf.String(), "tick_seconds_count 2") {
+		t.Errorf("unexpected output:\n%s", buf.String())
+	}
+}
+
+func TestHistogramLabelLimit(t *testing.T) {
+	v := NewHistogramVec("cmd_seconds", "Cmd", "command", []float64{1}, 3)
+	for i := 0; i < 10; i++ {
+		v.ObserveValue(fmt.Sprintf("junk%d", i), 0.1)
+	}
+	v.ObserveValue("junk0", 0.1)
+
+	if v.Count("junk0") != 2 {
+		t.Error("Tracked labels should keep counting")
+	}
+	if v.Count(OtherLabel) != 7 {
+		t.Errorf("other = %d, want 7", v.Count(OtherLabel))
+	}
+	if len(v.series) != 4 {
+		t.Errorf("series = %d, want 3 plus other", len(v.series))
+	}
+}
+
+func TestGaugeVecReplace(t *testing.T) {
+	g := NewGaugeVec("room_players", "Players", "room", 2)
+	g.Replace(map[string]float64{"dojo": 5, "subway": 1, "rooftop": 3, "club": 1})
+
+	if g.Get("dojo") != 5 || g.Get("rooftop") != 3 {
+		t.Error("Busiest rooms should keep their own series")
+	}
+	if g.Get(OtherLabel) != 2 {
+		t.Errorf("other = %v, want 2", g.Get(OtherLabel))
+	}
+
+	g.Replace(map[string]float64{"subway": 1})
+	if g.Get("dojo") != 0 {
+		t.Error("Replace should drop rooms no longer present")
+	}
+}
+
+func TestQuoteLabel(t *testing.T) {
+	if got := quoteLabel("a\"b\\c\nd\x1be"); got != `"a\"b\\c\nde"` {
+		t.Errorf("quoteLabel = %s", got)
+	}
+}
+
+func TestHandlerRunsCollectors(t *testing.T) {
+	RegisterCollector(func() {
+		SetActivity(2, 1, 7, 12345)
+		SetPlayerLocations(map[string]float64{"dojo": 2}, map[string]float64{"dojo": 2})
+	})
+	ObserveTick(30*time.Millisecond, 2*time.Millisecond)
+
+	w := httptest.NewRecorder()
+	Handler().ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	body := w.Body.String()
+
+	for _, want := range []string{
+		"matrix_instances_active 2",
+		"matrix_arenas_active 1",
+		"matrix_auction_listings 7",
+		"matrix_money_in_circulation 12345",
+		`matrix_room_players{room="dojo"} 2`,
+		`matrix_area_players{area="dojo"} 2`,
+		"# TYPE matrix_tick_duration_seconds histogram",
+		"# TYPE matrix_command_duration_seconds histogram",
+		"# TYPE matrix_eventbus_queue_wait_seconds histogram",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("missing %q", want)
+		}
+	}
+	// Pre-existing series keep their format
+	if !strings.Contains(body, "matrix_errors_total ") || !strings.Contains(body, "matrix_players_online ") {
+		t.Error("existing metrics missing")
+	}
+}
@@ -51,6 +51,12 @@ type Metrics struct {
 	NPCsCount  int64
 	ItemsCount int64
 
+	// Activity gauges, refreshed by collectors on each scrape
+	ActiveInstances    int64
+	ActiveArenas       int64
+	AuctionListings    int64
+	MoneyInCirculation int64
+
 	// Server metrics
 	StartTime    time.Time
 	LastUpdate   time.Time
@@ -64,6 +70,55 @@ var M = &Metrics{
 	StartTime:      time.Now(),
 }
 
+// Label limits keep series counts bounded however many distinct values
+// callers pass; the excess is reported under OtherLabel
+const (
+	maxCommandLabels = 128
+	maxRoomLabels    = 200
+	maxAreaLabels    = 50
+)
+
+// Histograms and labelled gauges
+var (
+	CommandLatency = NewHistogramVec("matrix_command_duration_seconds",
+		"Time to handle a player command", "command", LatencyBuckets, maxCommandLabels)
+	TickDuration = NewHistogramVec("matrix_tick_duration_seconds",
+		"Time spent in one world update tick", "", LatencyBuckets, 1)
+	TickLag = NewHistogramVec("matrix_tick_lag_seconds",
+		"Delay between a tick's scheduled and actual start", "", LatencyBuckets, 1)
+	SaveDuration = NewHistogramVec("matrix_save_duration_seconds",
+		"Time to persist state", "kind", LatencyBuckets, 8)
+	EventQueueWait = NewHistogramVec("matrix_eventbus_queue_wait_seconds",
+		"Time events wait in the event bus queue before dispatch", "", QueueWaitBuckets, 1)
+
+	RoomPlayers = NewGaugeVec("matrix_room_players", "Players online per occupied room", "room", maxRoomLabels)
+	AreaPlayers = NewGaugeVec("matrix_area_players", "Players online per area", "area", maxAreaLabels)
+)
+
+// collectors refresh gauges before each scrape
+var (
+	collectorsMu sync.Mutex
+	collectors   []func()
+)
+
+// RegisterCollector adds a function that refreshes gauges. Collectors run
+// at the start of every scrape, so they should be cheap.
+func RegisterCollector(fn func()) {
+	collectorsMu.Lock()
+	collectors = append(collectors, fn)
+	collectorsMu.Unlock()
+}
+
+// collect runs every registered collector
+func collect() {
+	collectorsMu.Lock()
+	fns := append([]func(){}, collectors...)
+	collectorsMu.Unlock()
+	for _, fn := range fns {
+		fn()
+	}
+}
+
 // IncrConnections increments connection counters
 func IncrConnections() {
 	M.mu.Lock()
@@ -104,10 +159,50 @@ func DecrPlayers() {
 func RecordCommand(cmdType string) {
 	M.mu.Lock()
 	M.CommandsExecuted++
+	if _, ok := M.CommandsByType[cmdType]; !ok && len(M.CommandsByType) >= maxCommandLabels {
+		cmdType = OtherLabel
+	}
 	M.CommandsByType[cmdType]++
 	M.mu.Unlock()
 }
 
+// ObserveCommand records how long a command took to handle
+func ObserveCommand(cmdType string, d time.Duration) {
+	CommandLatency.Observe(cmdType, d)
+}
+
+// ObserveTick records a world update's duration and how late it started
+func ObserveTick(duration, lag time.Duration) {
+	TickDuration.Observe("", duration)
+	TickLag.Observe("", lag)
+}
+
+// ObserveSave records a save; kind is "player" or "world"
+func ObserveSave(kind string, d time.Duration) {
+	SaveDuration.Observe(kind, d)
+}
+
+// ObserveEventQueueWait records how long an event waited for a bus worker
+func ObserveEventQueueWait(d time.Duration) {
+	EventQueueWait.Observe("", d)
+}
+
+// SetPlayerLocations sets the players-per-room and players-per-area gauges
+func SetPlayerLocations(rooms, areas map[string]float64) {
+	RoomPlayers.Replace(rooms)
+	AreaPlayers.Replace(areas)
+}
+
+// SetActivity sets the instance, arena, auction and money gauges
+func SetActivity(instances, arenas, listings, money int64) {
+	M.mu.Lock()
+	M.ActiveInstances = instances
+	M.ActiveArenas = arenas
+	M.AuctionListings = listings
+	M.MoneyInCirculation = money
+	M.mu.Unlock()
+}
+
 // RecordRateLimited records a rate-limited command
 func RecordRateLimited() {
 	M.mu.Lock()
@@ -208,6 +303,8 @@ func SetWorldCounts(rooms, npcs, items int64) {
 // Handler returns an HTTP handler for /metrics endpoint
 func Handler() http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		collect()
+
 		M.mu.RLock()
 		defer M.mu.RUnlock()
 
@@ -291,6 +388,33 @@ func Handler() http.Handler {
 
 		fmt.Fprintf(w, "# HELP matrix_errors_total Total errors\n")
 		fmt.Fprintf(w, "# TYPE matrix_errors_total counter\n")
-		fmt.Fprintf(w, "matrix_errors_total %d\n", M.ErrorCount)
+		fmt.Fprintf(w, "matrix_errors_total %d\n\n", M.ErrorCount)
+
+		// Activity gauges
+		fmt.Fprintf(w, "# HELP matrix_instances_active Dungeon instances in progress\n")
+		fmt.Fprintf(w, "# TYPE matrix_instances_active gauge\n")
+		fmt.Fprintf(w, "matrix_instances_active %d\n\n", M.ActiveInstances)
+
+		fmt.Fprintf(w, "# HELP matrix_arenas_active PvP arenas waiting or in progress\n")
+		fmt.Fprintf(w, "# TYPE matrix_arenas_active gauge\n")
+		fmt.Fprintf(w, "matrix_arenas_active %d\n\n", M.ActiveArenas)
+
+		fmt.Fprintf(w, "# HELP matrix_auction_listings Open auction listings\n")
+		fmt.Fprintf(w, "# TYPE matrix_auction_listings gauge\n")
+		fmt.Fprintf(w, "matrix_auction_listings %d\n\n", M.AuctionListings)
+
+		fmt.Fprintf(w, "# HELP matrix_money_in_circulation Money held by online players\n")
+		fmt.Fprintf(w, "# TYPE matrix_money_in_circulation gauge\n")
+		fmt.Fprintf(w, "matrix_money_in_circulation %d\n\n", M.MoneyInCirculation)
+
+		RoomPlayers.write(w)
+		AreaPlayers.write(w)
+
+		// Latency histograms
+		CommandLatency.write(w)
+		TickDuration.write(w)
+		TickLag.write(w)
+		SaveDuration.write(w)
+		EventQueueWait.write(w)
 	})
 }
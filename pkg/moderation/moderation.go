@@ -0,0 +1,366 @@
+// Package moderation manages bans and sanctions for Matrix MUD.
+// Supports account bans, IP and CIDR bans, global mutes and freezes,
+// each either timed or permanent, with optional JSON persistence.
+package moderation
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SanctionType defines what a sanction restricts
+type SanctionType string
+
+const (
+	SanctionBan    SanctionType = "ban"    // Cannot connect or log in
+	SanctionMute   SanctionType = "mute"   // Cannot speak on any channel
+	SanctionFreeze SanctionType = "freeze" // Cannot move or act
+)
+
+// TargetKind defines what a sanction applies to
+type TargetKind string
+
+const (
+	TargetAccount TargetKind = "account"
+	TargetIP      TargetKind = "ip"
+	TargetCIDR    TargetKind = "cidr"
+)
+
+// Sanction represents a single moderation action against an account or address
+type Sanction struct {
+	ID        int64        `json:"id"`
+	Type      SanctionType `json:"type"`
+	Kind      TargetKind   `json:"kind"`
+	Target    string       `json:"target"`
+	Reason    string       `json:"reason"`
+	IssuedBy  string       `json:"issued_by"`
+	IssuedAt  time.Time    `json:"issued_at"`
+	ExpiresAt time.Time    `json:"expires_at,omitempty"` // Zero means permanent
+}
+
+// Permanent returns true if the sanction never expires
+func (s *Sanction) Permanent() bool {
+	return s.ExpiresAt.IsZero()
+}
+
+// Active returns true if the sanction is in effect at the given time
+func (s *Sanction) Active(now time.Time) bool {
+	return s.Permanent() || now.Before(s.ExpiresAt)
+}
+
+// Describe returns a short human-readable summary of the sanction's duration
+func (s *Sanction) Describe() string {
+	if s.Permanent() {
+		return "permanent"
+	}
+	remaining := time.Until(s.ExpiresAt).Round(time.Second)
+	if remaining < 0 {
+		remaining = 0
+	}
+	return fmt.Sprintf("%s remaining", remaining)
+}
+
+// Manager tracks active sanctions
+type Manager struct {
+	mu        sync.RWMutex
+	sanctions map[int64]*Sanction
+	nextID    int64
+	path      string // Empty disables persistence
+}
+
+// NewManager creates a new sanction manager.
+// If path is non-empty, sanctions are persisted to that JSON file on every change.
+func NewManager(path string) *Manager {
+	return &Manager{
+		sanctions: make(map[int64]*Sanction),
+		nextID:    1,
+		path:      path,
+	}
+}
+
+// Load reads sanctions from a JSON file and enables persistence to it.
+// A missing file is not an error.
+func (m *Manager) Load(path string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.path = path
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read sanctions: %w", err)
+	}
+
+	var list []*Sanction
+	if err := json.Unmarshal(data, &list); err != nil {
+		return fmt.Errorf("failed to parse sanctions: %w", err)
+	}
+
+	m.sanctions = make(map[int64]*Sanction)
+	for _, s := range list {
+		m.sanctions[s.ID] = s
+		if s.ID >= m.nextID {
+			m.nextID = s.ID + 1
+		}
+	}
+	return nil
+}
+
+// save writes sanctions to disk. Caller must hold the lock.
+func (m *Manager) save() error {
+	if m.path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(m.sortedLocked(), "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(m.path, data, 0600) // Owner read/write only
+}
+
+// sortedLocked returns all sanctions ordered by ID. Caller must hold the lock.
+func (m *Manager) sortedLocked() []*Sanction {
+	list := make([]*Sanction, 0, len(m.sanctions))
+	for _, s := range m.sanctions {
+		list = append(list, s)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list
+}
+
+// normalizeTarget validates and canonicalizes a sanction target
+func normalizeTarget(kind TargetKind, target string) (string, error) {
+	target = strings.TrimSpace(target)
+	if target == "" {
+		return "", fmt.Errorf("target required")
+	}
+
+	switch kind {
+	case TargetAccount:
+		return strings.ToLower(target), nil
+	case TargetIP:
+		ip := net.ParseIP(target)
+		if ip == nil {
+			return "", fmt.Errorf("invalid IP address '%s'", target)
+		}
+		return ip.String(), nil
+	case TargetCIDR:
+		_, network, err := net.ParseCIDR(target)
+		if err != nil {
+			return "", fmt.Errorf("invalid CIDR range '%s'", target)
+		}
+		return network.String(), nil
+	}
+	return "", fmt.Errorf("unknown target kind '%s'", kind)
+}
+
+// Add issues a new sanction. A duration of 0 makes it permanent.
+// An existing active sanction of the same type on the same target is replaced.
+func (m *Manager) Add(typ SanctionType, kind TargetKind, target, reason, issuedBy string, duration time.Duration) (*Sanction, error) {
+	if typ != SanctionBan && kind != TargetAccount {
+		return nil, fmt.Errorf("%s can only be applied to accounts", typ)
+	}
+
+	normalized, err := normalizeTarget(kind, target)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for id, s := range m.sanctions {
+		if s.Type == typ && s.Kind == kind && s.Target == normalized {
+			delete(m.sanctions, id)
+		}
+	}
+
+	now := time.Now()
+	s := &Sanction{
+		ID:       m.nextID,
+		Type:     typ,
+		Kind:     kind,
+		Target:   normalized,
+		Reason:   reason,
+		IssuedBy: issuedBy,
+		IssuedAt: now,
+	}
+	if duration > 0 {
+		s.ExpiresAt = now.Add(duration)
+	}
+	m.nextID++
+	m.sanctions[s.ID] = s
+
+	if err := m.save(); err != nil {
+		return s, fmt.Errorf("sanction issued but not saved: %w", err)
+	}
+	return s, nil
+}
+
+// Lift removes a sanction of the given type on the given target.
+// Returns the removed sanction, or an error if none matched.
+func (m *Manager) Lift(typ SanctionType, kind TargetKind, target string) (*Sanction, error) {
+	normalized, err := normalizeTarget(kind, target)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for id, s := range m.sanctions {
+		if s.Type == typ && s.Kind == kind && s.Target == normalized {
+			delete(m.sanctions, id)
+			if err := m.save(); err != nil {
+				return s, fmt.Errorf("sanction lifted but not saved: %w", err)
+			}
+			return s, nil
+		}
+	}
+	return nil, fmt.Errorf("no %s found for %s", typ, target)
+}
+
+// LiftByID removes a sanction by its ID
+func (m *Manager) LiftByID(id int64) (*Sanction, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s, ok := m.sanctions[id]
+	if !ok {
+		return nil, fmt.Errorf("sanction #%d not found", id)
+	}
+	delete(m.sanctions, id)
+	if err := m.save(); err != nil {
+		return s, fmt.Errorf("sanction lifted but not saved: %w", err)
+	}
+	return s, nil
+}
+
+// findAccount returns the active sanction of a type for an account, or nil
+func (m *Manager) findAccount(typ SanctionType, name string) *Sanction {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	name = strings.ToLower(name)
+	now := time.Now()
+	for _, s := range m.sanctions {
+		if s.Type == typ && s.Kind == TargetAccount && s.Target == name && s.Active(now) {
+			return s
+		}
+	}
+	return nil
+}
+
+// CheckAccount returns the active ban on an account, or nil
+func (m *Manager) CheckAccount(name string) *Sanction {
+	return m.findAccount(SanctionBan, name)
+}
+
+// CheckIP returns the active IP or CIDR ban covering an address, or nil
+func (m *Manager) CheckIP(addr string) *Sanction {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return nil
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	now := time.Now()
+	for _, s := range m.sanctions {
+		if s.Type != SanctionBan || !s.Active(now) {
+			continue
+		}
+		switch s.Kind {
+		case TargetIP:
+			if ip.Equal(net.ParseIP(s.Target)) {
+				return s
+			}
+		case TargetCIDR:
+			if _, network, err := net.ParseCIDR(s.Target); err == nil && network.Contains(ip) {
+				return s
+			}
+		}
+	}
+	return nil
+}
+
+// IsMuted returns the active mute on an account, or nil
+func (m *Manager) IsMuted(name string) *Sanction {
+	return m.findAccount(SanctionMute, name)
+}
+
+// IsFrozen returns the active freeze on an account, or nil
+func (m *Manager) IsFrozen(name string) *Sanction {
+	return m.findAccount(SanctionFreeze, name)
+}
+
+// List returns all active sanctions ordered by ID
+func (m *Manager) List() []*Sanction {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	now := time.Now()
+	var active []*Sanction
+	for _, s := range m.sortedLocked() {
+		if s.Active(now) {
+			active = append(active, s)
+		}
+	}
+	return active
+}
+
+// Cleanup removes expired sanctions (call periodically)
+func (m *Manager) Cleanup() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	removed := 0
+	for id, s := range m.sanctions {
+		if !s.Active(now) {
+			delete(m.sanctions, id)
+			removed++
+		}
+	}
+	if removed > 0 {
+		m.save()
+	}
+	return removed
+}
+
+// ParseDuration parses a sanction duration such as "30m", "12h", "7d" or "perm".
+// Returns 0 for permanent sanctions.
+func ParseDuration(s string) (time.Duration, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	switch s {
+	case "", "perm", "permanent", "forever":
+		return 0, nil
+	}
+
+	if strings.HasSuffix(s, "d") {
+		days, err := strconv.Atoi(strings.TrimSuffix(s, "d"))
+		if err != nil || days <= 0 {
+			return 0, fmt.Errorf("invalid duration '%s'", s)
+		}
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid duration '%s'", s)
+	}
+	return d, nil
+}
+
+// GlobalModeration is the global sanction manager instance
+var GlobalModeration = NewManager("")
@@ -0,0 +1,173 @@
+package moderation
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAccountBan(t *testing.T) {
+	m := NewManager("")
+
+	if m.CheckAccount("neo") != nil {
+		t.Error("Account should not be banned initially")
+	}
+
+	s, err := m.Add(SanctionBan, TargetAccount, "Neo", "griefing", "admin", 0)
+	if err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if !s.Permanent() {
+		t.Error("Zero duration should be permanent")
+	}
+	if m.CheckAccount("NEO") == nil {
+		t.Error("Ban should match case-insensitively")
+	}
+
+	if _, err := m.Lift(SanctionBan, TargetAccount, "neo"); err != nil {
+		t.Fatalf("Lift failed: %v", err)
+	}
+	if m.CheckAccount("neo") != nil {
+		t.Error("Ban should be lifted")
+	}
+}
+
+func TestTimedBanExpires(t *testing.T) {
+	m := NewManager("")
+	m.Add(SanctionBan, TargetAccount, "trinity", "spam", "admin", 50*time.Millisecond)
+
+	if m.CheckAccount("trinity") == nil {
+		t.Fatal("Timed ban should be active")
+	}
+
+	time.Sleep(60 * time.Millisecond)
+
+	if m.CheckAccount("trinity") != nil {
+		t.Error("Timed ban should have expired")
+	}
+	if removed := m.Cleanup(); removed != 1 {
+		t.Errorf("Cleanup removed %d, want 1", removed)
+	}
+}
+
+func TestIPAndCIDRBans(t *testing.T) {
+	m := NewManager("")
+
+	if _, err := m.Add(SanctionBan, TargetIP, "not-an-ip", "", "admin", 0); err == nil {
+		t.Error("Invalid IP should be rejected")
+	}
+	if _, err := m.Add(SanctionBan, TargetCIDR, "10.0.0.0/99", "", "admin", 0); err == nil {
+		t.Error("Invalid CIDR should be rejected")
+	}
+
+	m.Add(SanctionBan, TargetIP, "192.168.1.5", "", "admin", 0)
+	m.Add(SanctionBan, TargetCIDR, "10.1.0.0/16", "", "admin", 0)
+
+	tests := []struct {
+		addr   string
+		banned bool
+	}{
+		{"192.168.1.5", true},
+		{"192.168.1.6", false},
+		{"10.1.200.3", true},
+		{"10.2.0.1", false},
+		{"garbage", false},
+	}
+	for _, tt := range tests {
+		if got := m.CheckIP(tt.addr) != nil; got != tt.banned {
+			t.Errorf("CheckIP(%s) = %v, want %v", tt.addr, got, tt.banned)
+		}
+	}
+}
+
+func TestMuteAndFreeze(t *testing.T) {
+	m := NewManager("")
+
+	if _, err := m.Add(SanctionMute, TargetIP, "1.2.3.4", "", "admin", 0); err == nil {
+		t.Error("Mute should only apply to accounts")
+	}
+
+	m.Add(SanctionMute, TargetAccount, "cypher", "spam", "admin", time.Hour)
+	m.Add(SanctionFreeze, TargetAccount, "cypher", "investigation", "admin", 0)
+
+	if m.IsMuted("cypher") == nil {
+		t.Error("Player should be muted")
+	}
+	if m.IsFrozen("cypher") == nil {
+		t.Error("Player should be frozen")
+	}
+	if m.CheckAccount("cypher") != nil {
+		t.Error("Mute/freeze should not count as a ban")
+	}
+	if len(m.List()) != 2 {
+		t.Errorf("List() returned %d, want 2", len(m.List()))
+	}
+}
+
+func TestAddReplacesExisting(t *testing.T) {
+	m := NewManager("")
+	m.Add(SanctionBan, TargetAccount, "smith", "first", "admin", time.Hour)
+	m.Add(SanctionBan, TargetAccount, "smith", "second", "admin", 0)
+
+	if len(m.List()) != 1 {
+		t.Fatalf("Expected 1 sanction after re-ban, got %d", len(m.List()))
+	}
+	if s := m.CheckAccount("smith"); s.Reason != "second" || !s.Permanent() {
+		t.Error("Re-ban should replace the earlier sanction")
+	}
+}
+
+func TestPersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sanctions.json")
+
+	m := NewManager(path)
+	m.Add(SanctionBan, TargetCIDR, "172.16.0.0/12", "abuse", "admin", 0)
+	s, _ := m.Add(SanctionMute, TargetAccount, "mouse", "caps", "admin", time.Hour)
+
+	loaded := NewManager("")
+	if err := loaded.Load(path); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if loaded.CheckIP("172.20.1.1") == nil {
+		t.Error("CIDR ban should survive reload")
+	}
+	if loaded.IsMuted("mouse") == nil {
+		t.Error("Mute should survive reload")
+	}
+
+	next, _ := loaded.Add(SanctionFreeze, TargetAccount, "tank", "", "admin", 0)
+	if next.ID <= s.ID {
+		t.Errorf("IDs should continue after reload, got %d after %d", next.ID, s.ID)
+	}
+
+	if err := NewManager("").Load(filepath.Join(t.TempDir(), "missing.json")); err != nil {
+		t.Errorf("Missing file should not be an error: %v", err)
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"perm", 0, false},
+		{"", 0, false},
+		{"30m", 30 * time.Minute, false},
+		{"12h", 12 * time.Hour, false},
+		{"7d", 7 * 24 * time.Hour, false},
+		{"0d", 0, true},
+		{"soon", 0, true},
+		{"-5m", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseDuration(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseDuration(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseDuration(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
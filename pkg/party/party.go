@@ -410,12 +410,7 @@ func (m *Manager) CalculateXPShare(playerName string, baseXP int) map[string]int
 	party.mu.RLock()
 	defer party.mu.RUnlock()
 
-	// Party bonus: 10% per additional member
-	memberCount := len(party.Members)
-	bonusMultiplier := 1.0 + float64(memberCount-1)*0.1
-
-	totalXP := int(float64(baseXP) * bonusMultiplier)
-	shareXP := totalXP / memberCount
+	shareXP := SplitXP(baseXP, len(party.Members))
 
 	result := make(map[string]int)
 	for _, member := range party.Members {
@@ -425,5 +420,16 @@ func (m *Manager) CalculateXPShare(playerName string, baseXP int) map[string]int
 	return result
 }
 
+// SplitXP returns each member's share of baseXP split between members
+// players, with a party bonus of 10% per additional member
+func SplitXP(baseXP, members int) int {
+	if members < 1 {
+		return baseXP
+	}
+	bonusMultiplier := 1.0 + float64(members-1)*0.1
+	totalXP := int(float64(baseXP) * bonusMultiplier)
+	return totalXP / members
+}
+
 // GlobalParty is a global party manager instance
 var GlobalParty = NewManager()
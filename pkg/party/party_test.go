@@ -417,6 +417,15 @@ func TestCalculateXPShare(t *testing.T) {
 	}
 }
 
+func TestSplitXP(t *testing.T) {
+	tests := []struct{ members, want int }{{0, 100}, {1, 100}, {2, 55}, {3, 40}}
+	for _, tt := range tests {
+		if got := SplitXP(100, tt.members); got != tt.want {
+			t.Errorf("SplitXP(100, %d) = %d, want %d", tt.members, got, tt.want)
+		}
+	}
+}
+
 func TestGlobalParty(t *testing.T) {
 	if GlobalParty == nil {
 		t.Error("GlobalParty should be initialized")
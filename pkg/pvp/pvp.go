@@ -9,6 +9,9 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/yourusername/matrix-mud/pkg/game"
+	"github.com/yourusername/matrix-mud/pkg/sim"
 )
 
 // ArenaType defines the type of arena match
@@ -50,6 +53,8 @@ type ArenaPlayer struct {
 	HP           int
 	MaxHP        int
 	Damage       int
+	Strength     int
+	AC           int
 	Kills        int
 	Deaths       int
 	Assists      int
@@ -329,6 +334,8 @@ func (m *Manager) createArena(arenaType ArenaType, entries []*QueueEntry) *Arena
 			HP:       100,
 			MaxHP:    100,
 			Damage:   10,
+			Strength: 10,
+			AC:       10,
 			IsAlive:  true,
 			JoinedAt: time.Now(),
 		}
@@ -366,8 +373,9 @@ func (m *Manager) StartArena(arenaID string) error {
 	return nil
 }
 
-// AttackPlayer handles combat in an arena
-func (m *Manager) AttackPlayer(arenaID, attackerName, targetName string) (string, error) {
+// AttackPlayer handles combat in an arena. The attack is resolved by the
+// shared combat engine, so arena fighters hit, miss and crit like everyone else.
+func (m *Manager) AttackPlayer(rng sim.RNG, arenaID, attackerName, targetName string) (string, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -402,8 +410,13 @@ func (m *Manager) AttackPlayer(arenaID, attackerName, targetName string) (string
 		return "", fmt.Errorf("you cannot attack your teammate")
 	}
 
+	result := game.Attack(rng, attacker.combatant(), target.combatant())
+	if !result.Hit {
+		return fmt.Sprintf("You swing at %s but miss.", target.Name), nil
+	}
+
 	// Deal damage
-	damage := attacker.Damage
+	damage := result.Damage
 	target.HP -= damage
 	target.LastDamageBy = attacker.Name
 
@@ -428,6 +441,11 @@ func (m *Manager) AttackPlayer(arenaID, attackerName, targetName string) (string
 	return msg, nil
 }
 
+// combatant describes an arena fighter for the combat engine
+func (p *ArenaPlayer) combatant() game.Combatant {
+	return game.Combatant{Name: p.Name, Strength: p.Strength, Damage: p.Damage, AC: p.AC}
+}
+
 // checkMatchEnd checks if the match should end
 func (m *Manager) checkMatchEnd(arena *Arena) bool {
 	switch arena.Type {
@@ -599,6 +617,20 @@ func calculateTier(rating int) RankTier {
 	}
 }
 
+// ActiveArenas returns the number of arenas waiting for players or in progress
+func (m *Manager) ActiveArenas() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	count := 0
+	for _, arena := range m.Arenas {
+		if arena.State != StateEnded {
+			count++
+		}
+	}
+	return count
+}
+
 // GetPlayerArena returns the arena a player is in
 func (m *Manager) GetPlayerArena(playerName string) *Arena {
 	m.mu.RLock()
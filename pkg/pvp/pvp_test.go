@@ -3,8 +3,13 @@ package pvp
 import (
 	"strings"
 	"testing"
+
+	"github.com/yourusername/matrix-mud/pkg/sim"
 )
 
+// rng rolls the arena attacks; a fixed seed keeps them stable
+var rng = sim.NewRand(1)
+
 func TestNewManager(t *testing.T) {
 	m := NewManager()
 	if m == nil {
@@ -150,20 +155,28 @@ func TestAttackPlayer(t *testing.T) {
 	arenaID, _ := m.QueueForArena("Player2", ArenaDuel, 1)
 	m.StartArena(arenaID)
 
-	msg, err := m.AttackPlayer(arenaID, "Player1", "Player2")
+	msg, err := m.AttackPlayer(rng, arenaID, "Player1", "Player2")
 	if err != nil {
 		t.Fatalf("AttackPlayer failed: %v", err)
 	}
 
-	if !strings.Contains(msg, "hit") && !strings.Contains(msg, "killed") {
+	// Attacks are rolled by the combat engine and can miss
+	target := m.Arenas[arenaID].Players["player2"]
+	if strings.Contains(msg, "miss") {
+		if target.HP != target.MaxHP {
+			t.Errorf("a miss dealt damage: HP %d", target.HP)
+		}
+	} else if !strings.Contains(msg, "hit") && !strings.Contains(msg, "killed") {
 		t.Errorf("Unexpected attack message: %s", msg)
+	} else if target.HP >= target.MaxHP {
+		t.Errorf("a hit dealt no damage: %s", msg)
 	}
 }
 
 func TestAttackPlayerNotInArena(t *testing.T) {
 	m := NewManager()
 
-	_, err := m.AttackPlayer("fake_arena", "Player1", "Player2")
+	_, err := m.AttackPlayer(rng, "fake_arena", "Player1", "Player2")
 	if err == nil {
 		t.Error("Should fail for invalid arena")
 	}
@@ -193,7 +206,7 @@ func TestAttackTeammate(t *testing.T) {
 		}
 	}
 
-	_, err := m.AttackPlayer(arenaID, teammate1, teammate2)
+	_, err := m.AttackPlayer(rng, arenaID, teammate1, teammate2)
 	if err == nil {
 		t.Error("Should not allow attacking teammates")
 	}
@@ -208,7 +221,7 @@ func TestMatchEnd(t *testing.T) {
 
 	// Kill player2 (attack until dead)
 	for i := 0; i < 20; i++ {
-		msg, _ := m.AttackPlayer(arenaID, "Player1", "Player2")
+		msg, _ := m.AttackPlayer(rng, arenaID, "Player1", "Player2")
 		if strings.Contains(msg, "MATCH OVER") {
 			break
 		}
@@ -233,7 +246,7 @@ func TestRatingChange(t *testing.T) {
 
 	// Kill loser
 	for i := 0; i < 20; i++ {
-		m.AttackPlayer(arenaID, "Winner", "Loser")
+		m.AttackPlayer(rng, arenaID, "Winner", "Loser")
 	}
 
 	winnerStats := m.GetOrCreateStats("Winner")
@@ -545,3 +558,20 @@ func TestFFAMatch(t *testing.T) {
 		t.Errorf("FFA arena should have 4 players, got %d", len(arena.Players))
 	}
 }
+
+func TestActiveArenas(t *testing.T) {
+	m := NewManager()
+	m.QueueForArena("Player1", ArenaDuel, 1)
+	arenaID, _ := m.QueueForArena("Player2", ArenaDuel, 1)
+	if arenaID == "" {
+		t.Fatal("Match should be created")
+	}
+	if m.ActiveArenas() != 1 {
+		t.Errorf("ActiveArenas = %d, want 1", m.ActiveArenas())
+	}
+
+	m.Arenas[arenaID].State = StateEnded
+	if m.ActiveArenas() != 0 {
+		t.Errorf("Ended arena counted: %d", m.ActiveArenas())
+	}
+}
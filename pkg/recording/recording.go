@@ -0,0 +1,390 @@
+// Package recording writes player sessions to asciicast v2 files
+// (https://docs.asciinema.org/manual/asciicast/v2/) so they can be replayed
+// in the admin console or with "asciinema play". A Manager owns the
+// recordings directory, the recordings in progress and the retention policy.
+package recording
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Defaults for new managers
+const (
+	DefaultWidth    = 80
+	DefaultHeight   = 24
+	DefaultMaxBytes = 16 << 20 // Recordings stop at this size
+)
+
+// RedactedInput replaces input that must not be stored, such as passwords
+const RedactedInput = "[redacted]"
+
+// Extension is the file extension of recordings
+const Extension = ".cast"
+
+// Header is the first line of an asciicast v2 file
+type Header struct {
+	Version   int               `json:"version"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	Timestamp int64             `json:"timestamp"`
+	Title     string            `json:"title,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+}
+
+// Info describes a recording on disk
+type Info struct {
+	Name     string // File name
+	Player   string
+	Title    string
+	Started  time.Time
+	Duration time.Duration // Approximate: last write minus start
+	Size     int64
+	Active   bool // Still being written
+}
+
+// Recorder appends events to one recording
+type Recorder struct {
+	mu       sync.Mutex
+	file     *os.File
+	name     string
+	player   string
+	start    time.Time
+	size     int64
+	maxBytes int64
+	closed   bool
+}
+
+// Name returns the recording's file name
+func (r *Recorder) Name() string {
+	return r.name
+}
+
+// Output records text sent to the player
+func (r *Recorder) Output(s string) {
+	r.event("o", s)
+}
+
+// Input records a line the player typed
+func (r *Recorder) Input(s string) {
+	r.event("i", s)
+}
+
+// Redacted records that the player typed something secret, without
+// storing what it was
+func (r *Recorder) Redacted() {
+	r.event("i", RedactedInput)
+}
+
+// Closed reports whether the recording has stopped
+func (r *Recorder) Closed() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.closed
+}
+
+// event writes one [time, type, data] line, stopping the recording once it
+// reaches the size limit
+func (r *Recorder) event(kind, data string) {
+	if data == "" {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return
+	}
+
+	line, err := json.Marshal([]interface{}{
+		json.Number(fmt.Sprintf("%.6f", time.Since(r.start).Seconds())), kind, data,
+	})
+	if err != nil {
+		return
+	}
+	line = append(line, '\n')
+	if r.size+int64(len(line)) > r.maxBytes {
+		notice, _ := json.Marshal([]interface{}{
+			json.Number(fmt.Sprintf("%.6f", time.Since(r.start).Seconds())), "o", "\r\n[recording size limit reached]\r\n",
+		})
+		r.file.Write(append(notice, '\n'))
+		r.closeLocked()
+		return
+	}
+	n, err := r.file.Write(line)
+	r.size += int64(n)
+	if err != nil {
+		r.closeLocked()
+	}
+}
+
+// Close stops the recording
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.closeLocked()
+}
+
+func (r *Recorder) closeLocked() error {
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+	return r.file.Close()
+}
+
+// Manager creates recordings in a directory and enforces retention
+type Manager struct {
+	dir       string
+	retention time.Duration // 0 keeps recordings forever
+	maxBytes  int64
+
+	mu     sync.Mutex
+	active map[string]*Recorder // Lower-case player name -> recording
+}
+
+// NewManager creates a manager for dir. Recordings older than retention are
+// deleted by Prune; a retention of 0 keeps them forever.
+func NewManager(dir string, retention time.Duration) *Manager {
+	return &Manager{
+		dir:       dir,
+		retention: retention,
+		maxBytes:  DefaultMaxBytes,
+		active:    make(map[string]*Recorder),
+	}
+}
+
+// SetMaxBytes changes the size at which recordings stop
+func (m *Manager) SetMaxBytes(n int64) {
+	m.mu.Lock()
+	m.maxBytes = n
+	m.mu.Unlock()
+}
+
+// Start begins recording a player's session. title describes why, e.g.
+// "Neo (opt-in)". A player has at most one recording at a time; starting
+// again returns the one in progress.
+func (m *Manager) Start(player, title string) (*Recorder, error) {
+	key := strings.ToLower(player)
+	if key == "" || strings.ContainsAny(key, `/\.`) {
+		return nil, fmt.Errorf("invalid player name %q", player)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if r, ok := m.active[key]; ok && !r.Closed() {
+		return r, nil
+	}
+
+	if err := os.MkdirAll(m.dir, 0700); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	base := key + "-" + now.UTC().Format("20060102-150405")
+	var file *os.File
+	var name string
+	for i := 1; file == nil; i++ {
+		name = base + Extension
+		if i > 1 {
+			name = fmt.Sprintf("%s-%d%s", base, i, Extension)
+		}
+		f, err := os.OpenFile(filepath.Join(m.dir, name), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if os.IsExist(err) && i < 100 {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		file = f
+	}
+
+	header, _ := json.Marshal(Header{
+		Version:   2,
+		Width:     DefaultWidth,
+		Height:    DefaultHeight,
+		Timestamp: now.Unix(),
+		Title:     title,
+		Env:       map[string]string{"TERM": "xterm-256color"},
+	})
+	header = append(header, '\n')
+	if _, err := file.Write(header); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return nil, err
+	}
+
+	r := &Recorder{
+		file:     file,
+		name:     name,
+		player:   key,
+		start:    now,
+		size:     int64(len(header)),
+		maxBytes: m.maxBytes,
+	}
+	m.active[key] = r
+	return r, nil
+}
+
+// Active returns the player's recording in progress, or nil
+func (m *Manager) Active(player string) *Recorder {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	r, ok := m.active[strings.ToLower(player)]
+	if !ok || r.Closed() {
+		return nil
+	}
+	return r
+}
+
+// Stop ends the player's recording, if any
+func (m *Manager) Stop(player string) {
+	key := strings.ToLower(player)
+	m.mu.Lock()
+	r := m.active[key]
+	delete(m.active, key)
+	m.mu.Unlock()
+	if r != nil {
+		r.Close()
+	}
+}
+
+// StopAll ends every recording, for shutdown
+func (m *Manager) StopAll() {
+	m.mu.Lock()
+	active := m.active
+	m.active = make(map[string]*Recorder)
+	m.mu.Unlock()
+	for _, r := range active {
+		r.Close()
+	}
+}
+
+// List returns the recordings on disk, newest first
+func (m *Manager) List() ([]Info, error) {
+	entries, err := os.ReadDir(m.dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	active := make(map[string]bool, len(m.active))
+	for _, r := range m.active {
+		if !r.Closed() {
+			active[r.name] = true
+		}
+	}
+	m.mu.Unlock()
+
+	var infos []Info
+	for _, e := range entries {
+		if e.IsDir() || !validName(e.Name()) {
+			continue
+		}
+		fi, err := e.Info()
+		if err != nil {
+			continue
+		}
+		info := Info{Name: e.Name(), Size: fi.Size(), Active: active[e.Name()]}
+		info.Player, _, _ = strings.Cut(e.Name(), "-")
+		if h, err := readHeader(filepath.Join(m.dir, e.Name())); err == nil {
+			info.Title = h.Title
+			info.Started = time.Unix(h.Timestamp, 0)
+			info.Duration = fi.ModTime().Sub(info.Started).Round(time.Second)
+		}
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		if !infos[i].Started.Equal(infos[j].Started) {
+			return infos[i].Started.After(infos[j].Started)
+		}
+		return infos[i].Name > infos[j].Name
+	})
+	return infos, nil
+}
+
+// readHeader reads the header line of a recording
+func readHeader(path string) (Header, error) {
+	var h Header
+	f, err := os.Open(path)
+	if err != nil {
+		return h, err
+	}
+	defer f.Close()
+	line, err := bufio.NewReader(f).ReadBytes('\n')
+	if err != nil {
+		return h, err
+	}
+	err = json.Unmarshal(line, &h)
+	return h, err
+}
+
+// Path returns the path of a recording by file name. Only recording file
+// names are accepted, so it cannot reach other files.
+func (m *Manager) Path(name string) (string, error) {
+	if !validName(name) {
+		return "", fmt.Errorf("invalid recording name %q", name)
+	}
+	path := filepath.Join(m.dir, name)
+	if _, err := os.Stat(path); err != nil {
+		return "", fmt.Errorf("recording %q not found", name)
+	}
+	return path, nil
+}
+
+// Delete removes a finished recording
+func (m *Manager) Delete(name string) error {
+	path, err := m.Path(name)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, r := range m.active {
+		if r.name == name && !r.Closed() {
+			return fmt.Errorf("recording %q is still in progress", name)
+		}
+	}
+	return os.Remove(path)
+}
+
+// Prune deletes finished recordings older than the retention period and
+// returns how many were removed
+func (m *Manager) Prune() (int, error) {
+	if m.retention <= 0 {
+		return 0, nil
+	}
+	infos, err := m.List()
+	if err != nil {
+		return 0, err
+	}
+	cutoff := time.Now().Add(-m.retention)
+	removed := 0
+	for _, info := range infos {
+		if info.Active {
+			continue
+		}
+		fi, err := os.Stat(filepath.Join(m.dir, info.Name))
+		if err != nil || fi.ModTime().After(cutoff) {
+			continue
+		}
+		if os.Remove(filepath.Join(m.dir, info.Name)) == nil {
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+// validName reports whether name looks like a recording file name
+func validName(name string) bool {
+	return name == filepath.Base(name) && !strings.ContainsAny(name, `/\`) &&
+		strings.HasSuffix(name, Extension) && strings.Contains(name, "-") && !strings.HasPrefix(name, ".")
+}
@@ -0,0 +1,168 @@
+package recording
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// readCast parses a recording into its header and events
+func readCast(t *testing.T, path string) (Header, [][]interface{}) {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	var h Header
+	if !scanner.Scan() || json.Unmarshal(scanner.Bytes(), &h) != nil {
+		t.Fatal("bad header")
+	}
+	var events [][]interface{}
+	for scanner.Scan() {
+		var e []interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil || len(e) != 3 {
+			t.Fatalf("bad event line %q: %v", scanner.Text(), err)
+		}
+		events = append(events, e)
+	}
+	return h, events
+}
+
+func TestRecorderWritesAsciicast(t *testing.T) {
+	dir := t.TempDir()
+	m := NewManager(dir, 0)
+	r, err := m.Start("Neo", "Neo (opt-in)")
+	if err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if again, _ := m.Start("neo", "other"); again != r {
+		t.Error("second Start should return the recording in progress")
+	}
+
+	r.Output("\x1b[32mWake up...\x1b[0m\r\n> ")
+	time.Sleep(10 * time.Millisecond)
+	r.Input("look\r")
+	r.Redacted()
+	r.Output("")
+	m.Stop("NEO")
+	r.Output("after stop")
+
+	if !strings.HasPrefix(r.Name(), "neo-") || !strings.HasSuffix(r.Name(), Extension) {
+		t.Errorf("file name = %q", r.Name())
+	}
+	h, events := readCast(t, filepath.Join(dir, r.Name()))
+	if h.Version != 2 || h.Width != DefaultWidth || h.Title != "Neo (opt-in)" || h.Timestamp == 0 {
+		t.Errorf("header = %+v", h)
+	}
+	if len(events) != 3 {
+		t.Fatalf("events = %v", events)
+	}
+	if events[0][1] != "o" || events[0][2] != "\x1b[32mWake up...\x1b[0m\r\n> " {
+		t.Errorf("output event = %v", events[0])
+	}
+	if events[1][1] != "i" || events[1][2] != "look\r" || events[1][0].(float64) < 0.01 {
+		t.Errorf("input event = %v", events[1])
+	}
+	if events[2][2] != RedactedInput {
+		t.Errorf("redacted event = %v", events[2])
+	}
+	if m.Active("neo") != nil {
+		t.Error("recording still active after Stop")
+	}
+}
+
+func TestRecorderSizeLimit(t *testing.T) {
+	dir := t.TempDir()
+	m := NewManager(dir, 0)
+	m.SetMaxBytes(300)
+	r, _ := m.Start("neo", "")
+	for i := 0; i < 20; i++ {
+		r.Output(strings.Repeat("x", 50))
+	}
+	if !r.Closed() {
+		t.Fatal("recording should stop at the size limit")
+	}
+	_, events := readCast(t, filepath.Join(dir, r.Name()))
+	if last := events[len(events)-1]; !strings.Contains(last[2].(string), "size limit") {
+		t.Errorf("last event = %v", last)
+	}
+}
+
+func TestListPathDelete(t *testing.T) {
+	dir := t.TempDir()
+	m := NewManager(dir, 0)
+	done, _ := m.Start("trinity", "Trinity (admin)")
+	done.Output("hello")
+	m.Stop("trinity")
+	live, _ := m.Start("neo", "")
+	os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("x"), 0600)
+
+	infos, err := m.List()
+	if err != nil || len(infos) != 2 {
+		t.Fatalf("List = %+v, %v", infos, err)
+	}
+	byPlayer := map[string]Info{}
+	for _, info := range infos {
+		byPlayer[info.Player] = info
+	}
+	if !byPlayer["neo"].Active || byPlayer["trinity"].Active || byPlayer["trinity"].Title != "Trinity (admin)" {
+		t.Errorf("infos = %+v", infos)
+	}
+
+	for _, bad := range []string{"notes.txt", "../" + done.Name(), "missing-1.cast"} {
+		if _, err := m.Path(bad); err == nil {
+			t.Errorf("Path(%q) should fail", bad)
+		}
+	}
+	if err := m.Delete(live.Name()); err == nil {
+		t.Error("deleting a recording in progress should fail")
+	}
+	if err := m.Delete(done.Name()); err != nil {
+		t.Errorf("Delete failed: %v", err)
+	}
+	if _, err := m.Path(done.Name()); err == nil {
+		t.Error("deleted recording still found")
+	}
+	m.StopAll()
+	if m.Active("neo") != nil {
+		t.Error("StopAll left a recording active")
+	}
+}
+
+func TestPrune(t *testing.T) {
+	dir := t.TempDir()
+	m := NewManager(dir, 24*time.Hour)
+
+	old, _ := m.Start("neo", "")
+	m.Stop("neo")
+	past := time.Now().Add(-48 * time.Hour)
+	os.Chtimes(filepath.Join(dir, old.Name()), past, past)
+	recent, _ := m.Start("trinity", "")
+	m.Stop("trinity")
+	live, _ := m.Start("morpheus", "")
+	os.Chtimes(filepath.Join(dir, live.Name()), past, past)
+
+	n, err := m.Prune()
+	if err != nil || n != 1 {
+		t.Fatalf("Prune = %d, %v", n, err)
+	}
+	if _, err := m.Path(old.Name()); err == nil {
+		t.Error("expired recording kept")
+	}
+	for _, name := range []string{recent.Name(), live.Name()} {
+		if _, err := m.Path(name); err != nil {
+			t.Errorf("%s removed: %v", name, err)
+		}
+	}
+
+	if n, _ := NewManager(dir, 0).Prune(); n != 0 {
+		t.Error("retention 0 should keep everything")
+	}
+}
@@ -0,0 +1,436 @@
+package script
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// --- Lexer ---
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokSep           // Newline or ;
+	tokIdent
+	tokNumber
+	tokString
+	tokOp
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	num  int
+	line int
+}
+
+var keywords = map[string]bool{
+	"let": true, "if": true, "then": true, "elseif": true, "else": true, "end": true,
+	"while": true, "do": true, "return": true, "and": true, "or": true, "not": true,
+	"true": true, "false": true, "nil": true,
+}
+
+// lex splits source into tokens
+func lex(src string) ([]token, error) {
+	var toks []token
+	line := 1
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == '\n':
+			toks = append(toks, token{kind: tokSep, text: "\\n", line: line})
+			line++
+			i++
+		case c == ';':
+			toks = append(toks, token{kind: tokSep, text: ";", line: line})
+			i++
+		case c == ' ' || c == '\t' || c == '\r':
+			i++
+		case c == '#':
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case c >= '0' && c <= '9':
+			j := i
+			for j < len(src) && src[j] >= '0' && src[j] <= '9' {
+				j++
+			}
+			n, err := strconv.Atoi(src[i:j])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: number %s out of range", line, src[i:j])
+			}
+			toks = append(toks, token{kind: tokNumber, text: src[i:j], num: n, line: line})
+			i = j
+		case c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z':
+			j := i
+			for j < len(src) && (src[j] == '_' || src[j] >= 'a' && src[j] <= 'z' || src[j] >= 'A' && src[j] <= 'Z' || src[j] >= '0' && src[j] <= '9') {
+				j++
+			}
+			toks = append(toks, token{kind: tokIdent, text: src[i:j], line: line})
+			i = j
+		case c == '"' || c == '\'':
+			var b strings.Builder
+			j := i + 1
+			for ; j < len(src) && src[j] != c; j++ {
+				if src[j] == '\n' {
+					return nil, fmt.Errorf("line %d: unterminated string", line)
+				}
+				if src[j] == '\\' && j+1 < len(src) {
+					j++
+					switch src[j] {
+					case 'n':
+						b.WriteString("\r\n")
+					case 't':
+						b.WriteByte('\t')
+					default:
+						b.WriteByte(src[j])
+					}
+					continue
+				}
+				b.WriteByte(src[j])
+			}
+			if j >= len(src) {
+				return nil, fmt.Errorf("line %d: unterminated string", line)
+			}
+			toks = append(toks, token{kind: tokString, text: b.String(), line: line})
+			i = j + 1
+		default:
+			op := ""
+			if i+1 < len(src) {
+				switch two := src[i : i+2]; two {
+				case "==", "!=", "<=", ">=", "..":
+					op = two
+				}
+			}
+			if op == "" {
+				if !strings.ContainsRune("+-*/%<>=(),", rune(c)) {
+					return nil, fmt.Errorf("line %d: unexpected character %q", line, c)
+				}
+				op = string(c)
+			}
+			toks = append(toks, token{kind: tokOp, text: op, line: line})
+			i += len(op)
+		}
+	}
+	toks = append(toks, token{kind: tokEOF, line: line})
+	return toks, nil
+}
+
+// --- AST ---
+
+type stmt interface{}
+
+type letStmt struct {
+	line  int
+	name  string
+	value expr
+}
+
+type ifStmt struct {
+	line  int
+	conds []expr   // if and elseif conditions
+	thens [][]stmt // Matching bodies
+	els   []stmt
+}
+
+type whileStmt struct {
+	line int
+	cond expr
+	body []stmt
+}
+
+type returnStmt struct{ line int }
+
+type exprStmt struct {
+	line int
+	x    expr
+}
+
+type expr interface{}
+
+type literal struct{ v Value }
+
+type variable struct {
+	line int
+	name string
+}
+
+type call struct {
+	line int
+	name string
+	args []expr
+}
+
+type unary struct {
+	line int
+	op   string
+	x    expr
+}
+
+type binary struct {
+	line int
+	op   string
+	l, r expr
+}
+
+// --- Parser ---
+
+type parser struct {
+	toks []token
+	pos  int
+}
+
+// Parse compiles source into a Program
+func Parse(src string) (*Program, error) {
+	toks, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{toks: toks}
+	body, err := p.block()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, fmt.Errorf("line %d: unexpected %q", t.line, t.text)
+	}
+	return &Program{body: body}, nil
+}
+
+func (p *parser) peek() token {
+	return p.toks[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.toks[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+// isWord reports whether the next token is the keyword or operator s
+func (p *parser) isWord(s string) bool {
+	t := p.peek()
+	return (t.kind == tokIdent || t.kind == tokOp) && t.text == s
+}
+
+func (p *parser) expect(s string) error {
+	if !p.isWord(s) {
+		t := p.peek()
+		return fmt.Errorf("line %d: expected %q, found %q", t.line, s, t.text)
+	}
+	p.next()
+	return nil
+}
+
+// block parses statements up to end, else, elseif or the end of input
+func (p *parser) block() ([]stmt, error) {
+	var body []stmt
+	for {
+		for p.peek().kind == tokSep {
+			p.next()
+		}
+		t := p.peek()
+		if t.kind == tokEOF || p.isWord("end") || p.isWord("else") || p.isWord("elseif") {
+			return body, nil
+		}
+		s, err := p.statement()
+		if err != nil {
+			return nil, err
+		}
+		body = append(body, s)
+		if t := p.peek(); t.kind != tokSep && t.kind != tokEOF && !p.isWord("end") && !p.isWord("else") && !p.isWord("elseif") {
+			return nil, fmt.Errorf("line %d: unexpected %q after statement", t.line, t.text)
+		}
+	}
+}
+
+func (p *parser) statement() (stmt, error) {
+	t := p.peek()
+	switch {
+	case p.isWord("let"):
+		p.next()
+		name := p.next()
+		if name.kind != tokIdent || keywords[name.text] {
+			return nil, fmt.Errorf("line %d: expected a variable name after let", name.line)
+		}
+		if err := p.expect("="); err != nil {
+			return nil, err
+		}
+		v, err := p.expr()
+		return &letStmt{line: t.line, name: name.text, value: v}, err
+
+	case p.isWord("if"):
+		p.next()
+		s := &ifStmt{line: t.line}
+		for {
+			cond, err := p.expr()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect("then"); err != nil {
+				return nil, err
+			}
+			body, err := p.block()
+			if err != nil {
+				return nil, err
+			}
+			s.conds = append(s.conds, cond)
+			s.thens = append(s.thens, body)
+			if !p.isWord("elseif") {
+				break
+			}
+			p.next()
+		}
+		if p.isWord("else") {
+			p.next()
+			body, err := p.block()
+			if err != nil {
+				return nil, err
+			}
+			s.els = body
+		}
+		return s, p.expect("end")
+
+	case p.isWord("while"):
+		p.next()
+		cond, err := p.expr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect("do"); err != nil {
+			return nil, err
+		}
+		body, err := p.block()
+		if err != nil {
+			return nil, err
+		}
+		return &whileStmt{line: t.line, cond: cond, body: body}, p.expect("end")
+
+	case p.isWord("return"):
+		p.next()
+		return &returnStmt{line: t.line}, nil
+
+	case t.kind == tokIdent && !keywords[t.text] && p.toks[p.pos+1].kind == tokOp && p.toks[p.pos+1].text == "=":
+		p.next()
+		p.next()
+		v, err := p.expr()
+		return &letStmt{line: t.line, name: t.text, value: v}, err
+	}
+
+	x, err := p.expr()
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := x.(*call); !ok {
+		return nil, fmt.Errorf("line %d: expression is not a statement", t.line)
+	}
+	return &exprStmt{line: t.line, x: x}, nil
+}
+
+// Binary operator precedence, lowest first
+var precedence = [][]string{
+	{"or"},
+	{"and"},
+	{"==", "!=", "<", "<=", ">", ">="},
+	{".."},
+	{"+", "-"},
+	{"*", "/", "%"},
+}
+
+func (p *parser) expr() (expr, error) {
+	return p.binaryLevel(0)
+}
+
+func (p *parser) binaryLevel(level int) (expr, error) {
+	if level == len(precedence) {
+		return p.unaryExpr()
+	}
+	l, err := p.binaryLevel(level + 1)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		matched := false
+		for _, op := range precedence[level] {
+			if p.isWord(op) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return l, nil
+		}
+		p.next()
+		r, err := p.binaryLevel(level + 1)
+		if err != nil {
+			return nil, err
+		}
+		l = &binary{line: t.line, op: t.text, l: l, r: r}
+	}
+}
+
+func (p *parser) unaryExpr() (expr, error) {
+	t := p.peek()
+	if p.isWord("not") || p.isWord("-") {
+		p.next()
+		x, err := p.unaryExpr()
+		return &unary{line: t.line, op: t.text, x: x}, err
+	}
+	return p.primary()
+}
+
+func (p *parser) primary() (expr, error) {
+	t := p.next()
+	switch t.kind {
+	case tokNumber:
+		return &literal{v: t.num}, nil
+	case tokString:
+		return &literal{v: t.text}, nil
+	case tokOp:
+		if t.text == "(" {
+			x, err := p.expr()
+			if err != nil {
+				return nil, err
+			}
+			return x, p.expect(")")
+		}
+	case tokIdent:
+		switch t.text {
+		case "true":
+			return &literal{v: true}, nil
+		case "false":
+			return &literal{v: false}, nil
+		case "nil":
+			return &literal{v: nil}, nil
+		}
+		if keywords[t.text] {
+			break
+		}
+		if !p.isWord("(") {
+			return &variable{line: t.line, name: t.text}, nil
+		}
+		p.next()
+		c := &call{line: t.line, name: t.text}
+		for !p.isWord(")") {
+			arg, err := p.expr()
+			if err != nil {
+				return nil, err
+			}
+			c.args = append(c.args, arg)
+			if !p.isWord(",") {
+				break
+			}
+			p.next()
+		}
+		return c, p.expect(")")
+	}
+	if t.kind == tokEOF {
+		return nil, fmt.Errorf("line %d: unexpected end of script", t.line)
+	}
+	return nil, fmt.Errorf("line %d: unexpected %q", t.line, t.text)
+}
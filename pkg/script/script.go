@@ -0,0 +1,377 @@
+// Package script is the small embedded language builders use to attach
+// behavior to rooms, NPCs and items. Scripts are sandboxed: they can only
+// read the variables and call the functions the host provides, and every
+// run is bounded by a step limit, a time limit and a string size limit.
+//
+// The language has integers, strings, booleans and nil:
+//
+//	# Comments start with #
+//	let visits = flag("oracle_visits")
+//	if visits == nil then
+//	  say("I've been expecting you, " .. actor .. ".")
+//	  setflag("oracle_visits", 1)
+//	elseif visits < 3 then
+//	  setflag("oracle_visits", visits + 1)
+//	else
+//	  return
+//	end
+//	let n = 3
+//	while n > 0 do n = n - 1 end
+//
+// Operators are or, and, not, == != < <= > >=, .. (concatenation),
+// + - * / % and unary minus. Only nil and false are false.
+package script
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Value is a script value: nil, bool, int or string
+type Value interface{}
+
+// Func is a host function callable from scripts
+type Func func(args []Value) (Value, error)
+
+// Limits bound a single run of a script
+type Limits struct {
+	MaxSteps  int           // Statements and expression nodes evaluated
+	Timeout   time.Duration // Wall time, checked as steps are taken
+	MaxString int           // Longest string a script may build
+}
+
+// DefaultLimits keep a script well inside one world tick
+var DefaultLimits = Limits{
+	MaxSteps:  10000,
+	Timeout:   10 * time.Millisecond,
+	MaxString: 4096,
+}
+
+// Errors that end a run early
+var (
+	ErrStepLimit = errors.New("step limit exceeded")
+	ErrTimeout   = errors.New("time limit exceeded")
+)
+
+// Program is a parsed script, safe to run many times concurrently
+type Program struct {
+	body []stmt
+}
+
+// Env is what a run can see: read-only variables such as actor and self,
+// and the functions of the host API
+type Env struct {
+	Vars   map[string]Value
+	Funcs  map[string]Func
+	Limits Limits // Zero fields fall back to DefaultLimits
+}
+
+// errReturn unwinds a return statement
+var errReturn = errors.New("return")
+
+type run struct {
+	env      *Env
+	locals   map[string]Value
+	steps    int
+	limits   Limits
+	deadline time.Time
+}
+
+// Run executes the program in env
+func (p *Program) Run(env *Env) error {
+	_, err := p.RunSteps(env)
+	return err
+}
+
+// RunSteps executes the program in env and reports the steps it took, so a
+// host can budget the work of many runs
+func (p *Program) RunSteps(env *Env) (int, error) {
+	limits := env.Limits
+	if limits.MaxSteps <= 0 {
+		limits.MaxSteps = DefaultLimits.MaxSteps
+	}
+	if limits.Timeout <= 0 {
+		limits.Timeout = DefaultLimits.Timeout
+	}
+	if limits.MaxString <= 0 {
+		limits.MaxString = DefaultLimits.MaxString
+	}
+	r := &run{env: env, locals: make(map[string]Value), limits: limits, deadline: time.Now().Add(limits.Timeout)}
+	err := r.block(p.body)
+	if err == errReturn {
+		err = nil
+	}
+	return r.steps, err
+}
+
+// step counts work and enforces the limits
+func (r *run) step() error {
+	r.steps++
+	if r.steps > r.limits.MaxSteps {
+		return ErrStepLimit
+	}
+	if r.steps%64 == 0 && time.Now().After(r.deadline) {
+		return ErrTimeout
+	}
+	return nil
+}
+
+func (r *run) block(body []stmt) error {
+	for _, s := range body {
+		if err := r.stmt(s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *run) stmt(s stmt) error {
+	if err := r.step(); err != nil {
+		return err
+	}
+	switch s := s.(type) {
+	case *letStmt:
+		v, err := r.eval(s.value)
+		if err != nil {
+			return err
+		}
+		r.locals[s.name] = v
+	case *ifStmt:
+		for i, cond := range s.conds {
+			v, err := r.eval(cond)
+			if err != nil {
+				return err
+			}
+			if Truthy(v) {
+				return r.block(s.thens[i])
+			}
+		}
+		return r.block(s.els)
+	case *whileStmt:
+		for {
+			v, err := r.eval(s.cond)
+			if err != nil {
+				return err
+			}
+			if !Truthy(v) {
+				return nil
+			}
+			if err := r.block(s.body); err != nil {
+				return err
+			}
+		}
+	case *returnStmt:
+		return errReturn
+	case *exprStmt:
+		_, err := r.eval(s.x)
+		return err
+	}
+	return nil
+}
+
+func (r *run) eval(x expr) (Value, error) {
+	if err := r.step(); err != nil {
+		return nil, err
+	}
+	switch x := x.(type) {
+	case *literal:
+		return x.v, nil
+	case *variable:
+		if v, ok := r.locals[x.name]; ok {
+			return v, nil
+		}
+		if v, ok := r.env.Vars[x.name]; ok {
+			return v, nil
+		}
+		return nil, fmt.Errorf("line %d: unknown variable %s", x.line, x.name)
+	case *call:
+		fn, ok := r.env.Funcs[x.name]
+		if !ok {
+			return nil, fmt.Errorf("line %d: unknown function %s", x.line, x.name)
+		}
+		args := make([]Value, len(x.args))
+		for i, a := range x.args {
+			v, err := r.eval(a)
+			if err != nil {
+				return nil, err
+			}
+			args[i] = v
+		}
+		v, err := fn(args)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s: %w", x.line, x.name, err)
+		}
+		return v, nil
+	case *unary:
+		v, err := r.eval(x.x)
+		if err != nil {
+			return nil, err
+		}
+		if x.op == "not" {
+			return !Truthy(v), nil
+		}
+		n, ok := v.(int)
+		if !ok {
+			return nil, fmt.Errorf("line %d: cannot negate %s", x.line, TypeName(v))
+		}
+		return -n, nil
+	case *binary:
+		return r.binary(x)
+	}
+	return nil, fmt.Errorf("unknown expression %T", x)
+}
+
+func (r *run) binary(x *binary) (Value, error) {
+	l, err := r.eval(x.l)
+	if err != nil {
+		return nil, err
+	}
+	// and/or short-circuit and return one of their operands
+	switch x.op {
+	case "and":
+		if !Truthy(l) {
+			return l, nil
+		}
+		return r.eval(x.r)
+	case "or":
+		if Truthy(l) {
+			return l, nil
+		}
+		return r.eval(x.r)
+	}
+	rv, err := r.eval(x.r)
+	if err != nil {
+		return nil, err
+	}
+
+	switch x.op {
+	case "==":
+		return l == rv, nil
+	case "!=":
+		return l != rv, nil
+	case "..":
+		s := String(l) + String(rv)
+		if len(s) > r.limits.MaxString {
+			return nil, fmt.Errorf("line %d: string longer than %d bytes", x.line, r.limits.MaxString)
+		}
+		return s, nil
+	}
+
+	if ls, ok := l.(string); ok {
+		rs, ok := rv.(string)
+		if !ok {
+			return nil, fmt.Errorf("line %d: cannot use %s on string and %s", x.line, x.op, TypeName(rv))
+		}
+		switch x.op {
+		case "<":
+			return ls < rs, nil
+		case "<=":
+			return ls <= rs, nil
+		case ">":
+			return ls > rs, nil
+		case ">=":
+			return ls >= rs, nil
+		}
+		return nil, fmt.Errorf("line %d: cannot use %s on strings (use .. to join)", x.line, x.op)
+	}
+
+	a, aok := l.(int)
+	b, bok := rv.(int)
+	if !aok || !bok {
+		return nil, fmt.Errorf("line %d: cannot use %s on %s and %s", x.line, x.op, TypeName(l), TypeName(rv))
+	}
+	switch x.op {
+	case "+":
+		return a + b, nil
+	case "-":
+		return a - b, nil
+	case "*":
+		return a * b, nil
+	case "/", "%":
+		if b == 0 {
+			return nil, fmt.Errorf("line %d: division by zero", x.line)
+		}
+		if x.op == "/" {
+			return a / b, nil
+		}
+		return a % b, nil
+	case "<":
+		return a < b, nil
+	case "<=":
+		return a <= b, nil
+	case ">":
+		return a > b, nil
+	case ">=":
+		return a >= b, nil
+	}
+	return nil, fmt.Errorf("line %d: unknown operator %s", x.line, x.op)
+}
+
+// Truthy reports whether v counts as true: everything but nil and false
+func Truthy(v Value) bool {
+	switch v := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	}
+	return true
+}
+
+// String formats v for output and concatenation; nil is ""
+func String(v Value) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case int:
+		return strconv.Itoa(v)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	return fmt.Sprint(v)
+}
+
+// TypeName names v's type for error messages
+func TypeName(v Value) string {
+	switch v.(type) {
+	case nil:
+		return "nil"
+	case bool:
+		return "boolean"
+	case int:
+		return "number"
+	case string:
+		return "string"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// Arg helpers for host functions
+
+// StringArg returns argument i as a string, or an error naming the function
+func StringArg(args []Value, i int) (string, error) {
+	if i >= len(args) {
+		return "", fmt.Errorf("missing argument %d", i+1)
+	}
+	s, ok := args[i].(string)
+	if !ok {
+		return "", fmt.Errorf("argument %d must be a string, not %s", i+1, TypeName(args[i]))
+	}
+	return s, nil
+}
+
+// IntArg returns argument i as a number
+func IntArg(args []Value, i int) (int, error) {
+	if i >= len(args) {
+		return 0, fmt.Errorf("missing argument %d", i+1)
+	}
+	n, ok := args[i].(int)
+	if !ok {
+		return 0, fmt.Errorf("argument %d must be a number, not %s", i+1, TypeName(args[i]))
+	}
+	return n, nil
+}
@@ -0,0 +1,125 @@
+package script
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// runScript runs src with a send() function and returns what it sent
+func runScript(t *testing.T, src string, vars map[string]Value) (string, error) {
+	t.Helper()
+	prog, err := Parse(src)
+	if err != nil {
+		t.Fatalf("Parse(%q): %v", src, err)
+	}
+	var out []string
+	env := &Env{
+		Vars: vars,
+		Funcs: map[string]Func{
+			"send": func(args []Value) (Value, error) {
+				out = append(out, String(args[0]))
+				return nil, nil
+			},
+		},
+	}
+	err = prog.Run(env)
+	return strings.Join(out, "|"), err
+}
+
+func TestLanguage(t *testing.T) {
+	tests := []struct {
+		name, src, want string
+	}{
+		{"arithmetic", `send(1 + 2 * 3 - 8 / 4 % 3)`, "5"},
+		{"precedence", `send((1 + 2) * 3)`, "9"},
+		{"concat", `send("Wake up, " .. actor .. ". " .. 3)`, "Wake up, Neo. 3"},
+		{"if else", "if actor == \"Neo\" then send(\"one\") else send(\"other\") end", "one"},
+		{"elseif", "let n = 5\nif n < 3 then send(\"low\")\nelseif n < 10 then send(\"mid\")\nelse send(\"high\") end", "mid"},
+		{"while", "let n = 3; let s = \"\"\nwhile n > 0 do\n  s = s .. n\n  n = n - 1\nend\nsend(s)", "321"},
+		{"and or", `send(nil or "fallback"); send(false and 1); send(not nil)`, "fallback|false|true"},
+		{"return", "send(\"a\")\nreturn\nsend(\"b\")", "a"},
+		{"comments", "# a comment\nsend('x') # trailing", "x"},
+		{"escapes", `send("say \"hi\"\tnow")`, "say \"hi\"\tnow"},
+		{"negation", `send(-2 + 5)`, "3"},
+		{"string compare", `send("a" < "b")`, "true"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := runScript(t, tt.src, map[string]Value{"actor": "Neo"})
+			if err != nil {
+				t.Fatalf("run: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, src := range []string{
+		`send("unterminated)`,
+		"if true then send(1)",
+		"while true send(1) end",
+		"1 + 2",
+		"let = 3",
+		"send(1) send(2)",
+		"send(1 +)",
+		"x = @",
+	} {
+		if _, err := Parse(src); err == nil {
+			t.Errorf("Parse(%q) should fail", src)
+		}
+	}
+}
+
+func TestRuntimeErrors(t *testing.T) {
+	for src, want := range map[string]string{
+		`send(missing)`:           "unknown variable missing",
+		`explode()`:               "unknown function explode",
+		`send(1 / 0)`:             "division by zero",
+		`send("a" + 1)`:           "cannot use +",
+		"let x = 1\nsend(-\"a\")": "line 2: cannot negate string",
+	} {
+		_, err := runScript(t, src, nil)
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%q: error %v, want %q", src, err, want)
+		}
+	}
+}
+
+func TestLimits(t *testing.T) {
+	prog, err := Parse("while true do end")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := prog.Run(&Env{}); !errors.Is(err, ErrStepLimit) {
+		t.Errorf("infinite loop: %v, want step limit", err)
+	}
+	if steps, _ := prog.RunSteps(&Env{}); steps != DefaultLimits.MaxSteps+1 {
+		t.Errorf("steps = %d, want %d", steps, DefaultLimits.MaxSteps+1)
+	}
+
+	slow := func([]Value) (Value, error) {
+		time.Sleep(time.Millisecond)
+		return nil, nil
+	}
+	err = prog.Run(&Env{Limits: Limits{MaxSteps: 1 << 30, Timeout: 5 * time.Millisecond}})
+	if !errors.Is(err, ErrTimeout) {
+		t.Errorf("long loop: %v, want time limit", err)
+	}
+
+	prog, _ = Parse("while true do slow() end")
+	start := time.Now()
+	err = prog.Run(&Env{Funcs: map[string]Func{"slow": slow}, Limits: Limits{MaxSteps: 1 << 30, Timeout: 20 * time.Millisecond}})
+	if !errors.Is(err, ErrTimeout) || time.Since(start) > time.Second {
+		t.Errorf("slow host calls: %v after %v", err, time.Since(start))
+	}
+
+	_, err = runScript(t, "let s = \"xx\"\nwhile true do s = s .. s end", nil)
+	if err == nil || !strings.Contains(err.Error(), "string longer") {
+		t.Errorf("string growth: %v", err)
+	}
+}
@@ -0,0 +1,103 @@
+// Package sim provides the clock and random source the game simulation runs
+// on. The server uses the wall clock and a seeded generator; tests swap in a
+// ManualClock and a fixed seed so a whole scenario replays the same way
+// every time, and admins can reproduce a reported bug by restarting with the
+// seed the server logged.
+package sim
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Clock tells the simulation what time it is
+type Clock interface {
+	Now() time.Time
+}
+
+// RNG is the random source for combat rolls, loot and generation
+type RNG interface {
+	// Intn returns a number in [0, n). It panics if n <= 0, like math/rand.
+	Intn(n int) int
+}
+
+// WallClock is the real clock
+type WallClock struct{}
+
+// Now returns the current time
+func (WallClock) Now() time.Time {
+	return time.Now()
+}
+
+// ManualClock only moves when told to, for tests
+type ManualClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewManualClock creates a clock stopped at t
+func NewManualClock(t time.Time) *ManualClock {
+	return &ManualClock{now: t}
+}
+
+// Now returns the clock's current time
+func (c *ManualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Advance moves the clock forward by d
+func (c *ManualClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	c.mu.Unlock()
+}
+
+// Set moves the clock to t
+func (c *ManualClock) Set(t time.Time) {
+	c.mu.Lock()
+	c.now = t
+	c.mu.Unlock()
+}
+
+// Rand is a seeded RNG that is safe for concurrent use and remembers its
+// seed so it can be reported and replayed
+type Rand struct {
+	mu   sync.Mutex
+	seed int64
+	r    *rand.Rand
+}
+
+// NewRand creates a generator from seed
+func NewRand(seed int64) *Rand {
+	return &Rand{seed: seed, r: rand.New(rand.NewSource(seed))}
+}
+
+// Intn returns a number in [0, n)
+func (r *Rand) Intn(n int) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.r.Intn(n)
+}
+
+// Seed returns the seed the generator was last started from
+func (r *Rand) Seed() int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.seed
+}
+
+// Reseed restarts the generator from seed
+func (r *Rand) Reseed(seed int64) {
+	r.mu.Lock()
+	r.seed = seed
+	r.r = rand.New(rand.NewSource(seed))
+	r.mu.Unlock()
+}
+
+// RandomSeed picks a seed for a server started without one
+func RandomSeed() int64 {
+	return time.Now().UnixNano()
+}
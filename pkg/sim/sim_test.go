@@ -0,0 +1,54 @@
+package sim
+
+import (
+	"testing"
+	"time"
+)
+
+func TestManualClock(t *testing.T) {
+	start := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	c := NewManualClock(start)
+	if !c.Now().Equal(start) {
+		t.Fatalf("Now() = %v, want %v", c.Now(), start)
+	}
+	c.Advance(1500 * time.Millisecond)
+	if got := c.Now().Sub(start); got != 1500*time.Millisecond {
+		t.Errorf("after Advance, elapsed = %v", got)
+	}
+	c.Set(start)
+	if !c.Now().Equal(start) {
+		t.Errorf("after Set, Now() = %v", c.Now())
+	}
+}
+
+func TestRandReplaysFromSeed(t *testing.T) {
+	a, b := NewRand(42), NewRand(42)
+	first := make([]int, 20)
+	for i := range first {
+		first[i] = a.Intn(100)
+		if got := b.Intn(100); got != first[i] {
+			t.Fatalf("roll %d: %d != %d with the same seed", i, got, first[i])
+		}
+	}
+
+	a.Reseed(42)
+	for i, want := range first {
+		if got := a.Intn(100); got != want {
+			t.Fatalf("after Reseed, roll %d = %d, want %d", i, got, want)
+		}
+	}
+	if a.Seed() != 42 {
+		t.Errorf("Seed() = %d", a.Seed())
+	}
+
+	a.Reseed(7)
+	same := true
+	for _, want := range first {
+		if a.Intn(100) != want {
+			same = false
+		}
+	}
+	if same {
+		t.Error("a different seed produced the same rolls")
+	}
+}
@@ -0,0 +1,133 @@
+package skills
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/yourusername/matrix-mud/pkg/sim"
+)
+
+// Formula is a parsed damage or heal expression: terms joined by + and -,
+// each a product of factors joined by *. A factor is a number, dice such as
+// 2d6, or a caster stat (str, level). For example "1d8+str-1" or
+// "3d10+level*2".
+type Formula []term
+
+type term struct {
+	sign    int
+	factors []factor
+}
+
+type factor struct {
+	num        int
+	dice, size int    // NdM when dice > 0
+	stat       string // Caster stat when set
+}
+
+// stats are the caster values a formula may use
+var stats = map[string]bool{"str": true, "level": true}
+
+// ParseFormula parses an expression; an empty string gives a nil Formula
+func ParseFormula(src string) (Formula, error) {
+	src = strings.ToLower(strings.ReplaceAll(src, " ", ""))
+	if src == "" {
+		return nil, nil
+	}
+	var f Formula
+	sign := 1
+	start := 0
+	for i := 0; i <= len(src); i++ {
+		if i < len(src) && src[i] != '+' && src[i] != '-' {
+			continue
+		}
+		if i == 0 && src[i] == '-' {
+			sign = -1
+			start = 1
+			continue
+		}
+		t, err := parseTerm(src[start:i])
+		if err != nil {
+			return nil, err
+		}
+		t.sign = sign
+		f = append(f, t)
+		if i < len(src) {
+			sign = 1
+			if src[i] == '-' {
+				sign = -1
+			}
+		}
+		start = i + 1
+	}
+	return f, nil
+}
+
+func parseTerm(s string) (term, error) {
+	if s == "" {
+		return term{}, fmt.Errorf("missing term")
+	}
+	var t term
+	for _, part := range strings.Split(s, "*") {
+		fa, err := parseFactor(part)
+		if err != nil {
+			return term{}, err
+		}
+		t.factors = append(t.factors, fa)
+	}
+	return t, nil
+}
+
+func parseFactor(s string) (factor, error) {
+	if stats[s] {
+		return factor{stat: s}, nil
+	}
+	if n, sides, ok := strings.Cut(s, "d"); ok {
+		count := 1
+		if n != "" {
+			c, err := strconv.Atoi(n)
+			if err != nil || c < 1 {
+				return factor{}, fmt.Errorf("bad dice count in %q", s)
+			}
+			count = c
+		}
+		size, err := strconv.Atoi(sides)
+		if err != nil || size < 1 {
+			return factor{}, fmt.Errorf("bad dice size in %q", s)
+		}
+		return factor{dice: count, size: size}, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return factor{}, fmt.Errorf("unknown value %q", s)
+	}
+	return factor{num: n}, nil
+}
+
+// Roll evaluates the formula with rng for dice and vars for caster stats.
+// Results below zero are returned as zero.
+func (f Formula) Roll(rng sim.RNG, vars map[string]int) int {
+	total := 0
+	for _, t := range f {
+		v := 1
+		for _, fa := range t.factors {
+			switch {
+			case fa.stat != "":
+				v *= vars[fa.stat]
+			case fa.dice > 0:
+				sum := 0
+				for i := 0; i < fa.dice; i++ {
+					sum += rng.Intn(fa.size) + 1
+				}
+				v *= sum
+			default:
+				v *= fa.num
+			}
+		}
+		total += t.sign * v
+	}
+	if total < 0 {
+		return 0
+	}
+	return total
+}
@@ -0,0 +1,206 @@
+// Package skills provides the data-driven class skill system for Matrix MUD.
+// Skills are loaded from data/skills.json and define who can learn them,
+// what they cost, their cooldown, what they target and the damage, healing
+// and status effects they apply.
+package skills
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yourusername/matrix-mud/pkg/cooldown"
+	"github.com/yourusername/matrix-mud/pkg/game"
+	"github.com/yourusername/matrix-mud/pkg/logging"
+)
+
+// Targeting modes
+const (
+	TargetSelf  = "self"  // The caster
+	TargetAlly  = "ally"  // A player in the room, the caster by default
+	TargetEnemy = "enemy" // One NPC in the room, the combat target by default
+	TargetRoom  = "room"  // Every NPC in the room
+)
+
+// Skill describes a castable class skill
+type Skill struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Classes     []string `json:"classes"`
+	Level       int      `json:"level"`            // Minimum level to learn
+	Innate      bool     `json:"innate,omitempty"` // Known without learning once the level is reached
+	Cost        int      `json:"cost"`             // MP
+	Cooldown    string   `json:"cooldown"`         // Go duration, e.g. "5s"
+	Target      string   `json:"target"`
+	Damage      string   `json:"damage,omitempty"`      // Formula, e.g. "1d10+4"
+	DamageType  string   `json:"damage_type,omitempty"` // kinetic (default), code, emp or psychic
+	Heal        string   `json:"heal,omitempty"`        // Formula, e.g. "10+level"
+	Effect      string   `json:"effect,omitempty"`      // Status effect applied to each target
+	Threat      int      `json:"threat,omitempty"`      // Extra threat on each enemy hit
+	Revive      bool     `json:"revive,omitempty"`      // Brings a ghost ally back to life
+	Message     string   `json:"message,omitempty"`
+
+	cooldown time.Duration
+	damage   Formula
+	heal     Formula
+}
+
+// CooldownDuration returns the skill's cooldown
+func (s *Skill) CooldownDuration() time.Duration {
+	return s.cooldown
+}
+
+// DamageFormula returns the parsed damage formula, nil if the skill deals none
+func (s *Skill) DamageFormula() Formula {
+	return s.damage
+}
+
+// HealFormula returns the parsed heal formula, nil if the skill heals none
+func (s *Skill) HealFormula() Formula {
+	return s.heal
+}
+
+// AvailableTo reports whether a class can learn the skill
+func (s *Skill) AvailableTo(class string) bool {
+	for _, c := range s.Classes {
+		if strings.EqualFold(c, class) {
+			return true
+		}
+	}
+	return false
+}
+
+// SkillsData is the JSON structure for skills.json
+type SkillsData struct {
+	Skills map[string]*Skill `json:"skills"`
+}
+
+// Manager holds the skill definitions
+type Manager struct {
+	Skills map[string]*Skill
+}
+
+// NewManager creates a manager with the default skills and loads
+// data/skills.json over them
+func NewManager() *Manager {
+	m := &Manager{Skills: make(map[string]*Skill)}
+	for id, s := range defaultSkills() {
+		if err := m.add(id, s); err != nil {
+			logging.Error().Err(err).Str("skill", id).Msg("Invalid default skill")
+		}
+	}
+	m.Load("data/skills.json")
+	return m
+}
+
+// Load replaces the skills with those in path, keeping the current ones if
+// the file cannot be read. Invalid skills are logged and skipped.
+func (m *Manager) Load(path string) error {
+	file, err := os.ReadFile(path)
+	if err != nil {
+		logging.Warn().Err(err).Str("path", path).Msg("Could not read skills file, using defaults")
+		return err
+	}
+	var data SkillsData
+	if err := json.Unmarshal(file, &data); err != nil {
+		logging.Warn().Err(err).Str("path", path).Msg("Could not parse skills file, using defaults")
+		return err
+	}
+	m.Skills = make(map[string]*Skill, len(data.Skills))
+	for id, s := range data.Skills {
+		if err := m.add(id, s); err != nil {
+			logging.Warn().Err(err).Str("skill", id).Msg("Skipping invalid skill")
+		}
+	}
+	logging.Info().Int("count", len(m.Skills)).Msg("Loaded skills")
+	return nil
+}
+
+// add validates a skill, registers its cooldown and stores it
+func (m *Manager) add(id string, s *Skill) error {
+	s.ID = strings.ToLower(id)
+	if s.Name == "" {
+		s.Name = s.ID
+	}
+	switch s.Target {
+	case TargetSelf, TargetAlly, TargetEnemy, TargetRoom:
+	default:
+		return fmt.Errorf("unknown target %q", s.Target)
+	}
+	if !game.ValidDamageType(s.DamageType) {
+		return fmt.Errorf("unknown damage type %q", s.DamageType)
+	}
+	d, err := time.ParseDuration(s.Cooldown)
+	if err != nil {
+		return fmt.Errorf("cooldown: %w", err)
+	}
+	s.cooldown = d
+	if s.damage, err = ParseFormula(s.Damage); err != nil {
+		return fmt.Errorf("damage: %w", err)
+	}
+	if s.heal, err = ParseFormula(s.Heal); err != nil {
+		return fmt.Errorf("heal: %w", err)
+	}
+	if s.Revive && s.Target != TargetAlly {
+		return fmt.Errorf("revive skills must target an ally")
+	}
+	if s.damage == nil && s.heal == nil && s.Effect == "" && s.Threat == 0 && !s.Revive {
+		return fmt.Errorf("skill does nothing")
+	}
+	cooldown.SetCooldown(s.ID, d)
+	m.Skills[s.ID] = s
+	return nil
+}
+
+// Get returns a skill by ID or name, ignoring case
+func (m *Manager) Get(name string) *Skill {
+	name = strings.ToLower(name)
+	if s, ok := m.Skills[name]; ok {
+		return s
+	}
+	for _, s := range m.Skills {
+		if strings.ToLower(s.Name) == name {
+			return s
+		}
+	}
+	return nil
+}
+
+// ForClass returns the skills a class can learn, by level and then ID
+func (m *Manager) ForClass(class string) []*Skill {
+	var list []*Skill
+	for _, s := range m.Skills {
+		if s.AvailableTo(class) {
+			list = append(list, s)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Level != list[j].Level {
+			return list[i].Level < list[j].Level
+		}
+		return list[i].ID < list[j].ID
+	})
+	return list
+}
+
+// defaultSkills are the original class skills, used when skills.json is missing
+func defaultSkills() map[string]*Skill {
+	return map[string]*Skill{
+		"glitch": {Name: "Glitch", Description: "Disrupt an enemy's code.", Classes: []string{"Hacker"},
+			Level: 1, Innate: true, Cost: 5, Cooldown: "5s", Target: TargetEnemy, Damage: "1d10+4", DamageType: game.DamageCode,
+			Message: "Logic bomb hits {target} for {amount} damage!"},
+		"smash": {Name: "Smash", Description: "A powerful physical attack.", Classes: []string{"Rebel"},
+			Level: 1, Innate: true, Cost: 5, Cooldown: "3s", Target: TargetEnemy, Damage: "1d8+str-1",
+			Message: "Smash hits {target} for {amount} damage!"},
+		"patch": {Name: "Patch", Description: "Rewrite damaged code to heal.", Classes: []string{"Hacker", "Operator"},
+			Level: 1, Innate: true, Cost: 5, Cooldown: "15s", Target: TargetAlly, Heal: "10",
+			Message: "Patched {target} for {amount} HP."},
+	}
+}
+
+// GlobalSkills holds the skill definitions loaded at startup
+var GlobalSkills = NewManager()
@@ -0,0 +1,111 @@
+package skills
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/yourusername/matrix-mud/pkg/cooldown"
+	"github.com/yourusername/matrix-mud/pkg/sim"
+)
+
+// maxRNG always rolls the highest value
+type maxRNG struct{}
+
+func (maxRNG) Intn(n int) int { return n - 1 }
+
+func TestFormulaRoll(t *testing.T) {
+	vars := map[string]int{"str": 12, "level": 3}
+	tests := []struct {
+		src  string
+		want int
+	}{
+		{"10", 10},
+		{"1d10+4", 14},
+		{"1d8+str-1", 19},
+		{"3d10+level*2", 36},
+		{"2*level", 6},
+		{"d6", 6},
+		{"-5+2", 0},
+		{" 1d4 + 1 ", 5},
+	}
+	for _, tt := range tests {
+		f, err := ParseFormula(tt.src)
+		if err != nil {
+			t.Fatalf("ParseFormula(%q): %v", tt.src, err)
+		}
+		if got := f.Roll(maxRNG{}, vars); got != tt.want {
+			t.Errorf("%q rolled %d, want %d", tt.src, got, tt.want)
+		}
+	}
+
+	f, _ := ParseFormula("1d10+4")
+	rng := sim.NewRand(7)
+	for i := 0; i < 200; i++ {
+		if got := f.Roll(rng, nil); got < 5 || got > 14 {
+			t.Fatalf("1d10+4 rolled %d", got)
+		}
+	}
+
+	if f, err := ParseFormula(""); f != nil || err != nil {
+		t.Errorf("empty formula = %v, %v", f, err)
+	}
+}
+
+func TestFormulaErrors(t *testing.T) {
+	for _, src := range []string{"1d", "0d6", "wis", "1++2", "2*", "1d-4"} {
+		if _, err := ParseFormula(src); err == nil {
+			t.Errorf("ParseFormula(%q) should fail", src)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "skills.json")
+	data := `{"skills": {
+		"zap": {"name": "Zap", "classes": ["Hacker"], "level": 2, "cost": 3, "cooldown": "7s", "target": "enemy", "damage": "1d4"},
+		"bad_target": {"classes": ["Hacker"], "cooldown": "1s", "target": "everyone", "damage": "1"},
+		"bad_formula": {"classes": ["Hacker"], "cooldown": "1s", "target": "enemy", "damage": "1d"},
+		"bad_type": {"classes": ["Hacker"], "cooldown": "1s", "target": "enemy", "damage": "1", "damage_type": "fire"},
+		"no_op": {"classes": ["Hacker"], "cooldown": "1s", "target": "self"}
+	}}`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m := NewManager()
+	if err := m.Load(path); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(m.Skills) != 1 {
+		t.Fatalf("loaded %d skills, want only zap", len(m.Skills))
+	}
+	zap := m.Get("ZAP")
+	if zap == nil || zap.CooldownDuration() != 7*time.Second {
+		t.Fatalf("zap = %+v", zap)
+	}
+	if cooldown.Duration("zap") != 7*time.Second {
+		t.Errorf("zap cooldown not registered: %v", cooldown.Duration("zap"))
+	}
+
+	// A missing file keeps what was loaded
+	if err := m.Load(filepath.Join(t.TempDir(), "missing.json")); err == nil || m.Get("zap") == nil {
+		t.Error("missing file should keep the current skills")
+	}
+}
+
+func TestDefaultsAndForClass(t *testing.T) {
+	m := NewManager() // No data/ directory here, so only the defaults
+	for _, id := range []string{"glitch", "smash", "patch"} {
+		if m.Get(id) == nil {
+			t.Errorf("default skill %s missing", id)
+		}
+	}
+	hacker := m.ForClass("hacker")
+	if len(hacker) != 2 || hacker[0].ID != "glitch" || hacker[1].ID != "patch" {
+		t.Errorf("ForClass(hacker) = %v", hacker)
+	}
+	if m.Get("Smash").AvailableTo("Hacker") {
+		t.Error("smash should be Rebel only")
+	}
+}
@@ -228,7 +228,8 @@ func (m *Manager) CancelTrade(playerName string) error {
 	return nil
 }
 
-// AddItem adds an item to a player's trade offer
+// AddItem adds an item to a player's trade offer. Adding an item already
+// offered changes its quantity.
 func (m *Manager) AddItem(playerName, itemID, itemName string, quantity int) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -256,11 +257,18 @@ func (m *Manager) AddItem(playerName, itemID, itemName string, quantity int) err
 		Quantity: quantity,
 	}
 
+	offer := &trade.TargetOffer
 	if strings.ToLower(trade.Initiator) == name {
-		trade.InitiatorOffer.Items = append(trade.InitiatorOffer.Items, item)
-	} else {
-		trade.TargetOffer.Items = append(trade.TargetOffer.Items, item)
+		offer = &trade.InitiatorOffer
+	}
+	for i := range offer.Items {
+		if offer.Items[i].ItemID == itemID {
+			offer.Items[i] = item
+			trade.UpdatedAt = time.Now()
+			return nil
+		}
 	}
+	offer.Items = append(offer.Items, item)
 
 	trade.UpdatedAt = time.Now()
 	return nil
@@ -589,6 +597,13 @@ func (m *Manager) Buyout(buyerName, listingID string) error {
 	return nil
 }
 
+// GetListing returns an auction listing by ID, or nil
+func (m *Manager) GetListing(listingID string) *AuctionListing {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.Auctions[listingID]
+}
+
 // CancelListing cancels an auction listing
 func (m *Manager) CancelListing(playerName, listingID string) error {
 	m.mu.Lock()
@@ -667,6 +682,21 @@ func (m *Manager) SearchAuctions(query, category string, maxPrice int) []*Auctio
 	return results
 }
 
+// ActiveListings returns the number of unsold, unexpired auction listings
+func (m *Manager) ActiveListings() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	now := time.Now()
+	count := 0
+	for _, listing := range m.Auctions {
+		if !listing.Sold && now.Before(listing.ExpiresAt) {
+			count++
+		}
+	}
+	return count
+}
+
 // GetPlayerListings returns a player's auction listings
 func (m *Manager) GetPlayerListings(playerName string) []*AuctionListing {
 	m.mu.RLock()
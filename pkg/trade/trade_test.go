@@ -128,6 +128,21 @@ func TestAddItem(t *testing.T) {
 	}
 }
 
+func TestAddItemQuantity(t *testing.T) {
+	m := NewManager()
+
+	m.InitiateTrade("Player1", "Player2")
+	m.AcceptTrade("Player2")
+
+	m.AddItem("Player2", "trash_1", "Digital Trash", 5)
+	m.AddItem("Player2", "trash_1", "Digital Trash", 3)
+
+	trade := m.GetTrade("Player2")
+	if len(trade.TargetOffer.Items) != 1 || trade.TargetOffer.Items[0].Quantity != 3 {
+		t.Errorf("Re-adding an item should change its quantity: %+v", trade.TargetOffer.Items)
+	}
+}
+
 func TestAddItemNotTrading(t *testing.T) {
 	m := NewManager()
 
@@ -404,6 +419,19 @@ func TestBuyoutNoBuyoutPrice(t *testing.T) {
 	}
 }
 
+func TestGetListing(t *testing.T) {
+	m := NewManager()
+
+	listing, _ := m.CreateListing("Seller", "sword", "Steel Sword", 1, 100, 200, 24*time.Hour, "weapons")
+
+	if got := m.GetListing(listing.ID); got != listing {
+		t.Error("GetListing should return the listing")
+	}
+	if m.GetListing("missing") != nil {
+		t.Error("GetListing should return nil for unknown ID")
+	}
+}
+
 func TestCancelListing(t *testing.T) {
 	m := NewManager()
 
@@ -583,3 +611,17 @@ func TestTruncate(t *testing.T) {
 		}
 	}
 }
+
+func TestActiveListings(t *testing.T) {
+	m := NewManager()
+	m.CreateListing("Seller", "sword", "Steel Sword", 1, 100, 200, 24*time.Hour, "weapons")
+	sold, _ := m.CreateListing("Seller", "coat", "Leather Coat", 1, 100, 200, 24*time.Hour, "armor")
+	expired, _ := m.CreateListing("Seller", "phone", "Phone", 1, 10, 20, 24*time.Hour, "misc")
+
+	m.Buyout("Buyer", sold.ID)
+	expired.ExpiresAt = time.Now().Add(-time.Minute)
+
+	if got := m.ActiveListings(); got != 1 {
+		t.Errorf("ActiveListings = %d, want 1", got)
+	}
+}
@@ -0,0 +1,359 @@
+// Package watchdog measures world ticks. Slow ticks are reported with a
+// per-phase breakdown, and when the world falls too far behind schedule a
+// goroutine dump and a CPU profile are captured to disk automatically.
+package watchdog
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime/pprof"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Capture defaults
+const (
+	DefaultCPUProfileDuration = 10 * time.Second
+	DefaultProfileCooldown    = 10 * time.Minute
+	DefaultMaxProfiles        = 20
+)
+
+// Config configures a Watchdog
+type Config struct {
+	Interval           time.Duration // Expected time between ticks
+	SlowThreshold      time.Duration // Ticks running longer are reported
+	ProfileThreshold   time.Duration // Lag behind schedule that triggers a capture; 0 disables
+	ProfileDir         string        // Where captures are written
+	CPUProfileDuration time.Duration // How long the CPU profile samples
+	ProfileCooldown    time.Duration // Minimum gap between captures
+	MaxProfiles        int           // Oldest files are deleted beyond this many
+
+	OnSlowTick func(Report)  // Called for each slow tick
+	OnCapture  func(Capture) // Called when a capture finishes
+}
+
+// Phase is the time spent in one named part of a tick
+type Phase struct {
+	Name     string
+	Duration time.Duration
+}
+
+// Tick is a tick in progress. The world marks the end of each phase.
+type Tick struct {
+	Scheduled time.Time // When the ticker fired
+	Start     time.Time // When work began
+	Phases    []Phase
+	mark      time.Time
+}
+
+// Mark ends the current phase and starts the next. It is a no-op on a nil
+// Tick, so instrumented code can also run unwatched.
+func (t *Tick) Mark(name string) {
+	if t == nil {
+		return
+	}
+	now := time.Now()
+	t.Phases = append(t.Phases, Phase{Name: name, Duration: now.Sub(t.mark)})
+	t.mark = now
+}
+
+// Report describes a finished tick
+type Report struct {
+	Duration time.Duration // Time spent working
+	Lag      time.Duration // How late the tick started
+	Phases   []Phase
+}
+
+// String formats the phases as "respawn=1ms aggro=3ms ..."
+func (r Report) String() string {
+	parts := make([]string, len(r.Phases))
+	for i, p := range r.Phases {
+		parts[i] = fmt.Sprintf("%s=%s", p.Name, p.Duration.Round(time.Microsecond))
+	}
+	return strings.Join(parts, " ")
+}
+
+// Capture is the result of an automatic profile capture
+type Capture struct {
+	Reason string
+	Files  []string // Paths written
+	Err    error    // First error, if any part failed
+}
+
+// ProfileFile is a captured profile on disk
+type ProfileFile struct {
+	Name    string
+	Size    int64
+	ModTime time.Time
+}
+
+// Watchdog watches world ticks
+type Watchdog struct {
+	config Config
+
+	mu          sync.Mutex
+	current     *Tick
+	capturing   bool
+	lastCapture time.Time
+	started     bool
+
+	captures sync.WaitGroup
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// New creates a watchdog, filling in defaults for unset capture settings
+func New(config Config) *Watchdog {
+	if config.CPUProfileDuration <= 0 {
+		config.CPUProfileDuration = DefaultCPUProfileDuration
+	}
+	if config.ProfileCooldown <= 0 {
+		config.ProfileCooldown = DefaultProfileCooldown
+	}
+	if config.MaxProfiles <= 0 {
+		config.MaxProfiles = DefaultMaxProfiles
+	}
+	return &Watchdog{
+		config: config,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+}
+
+// Start begins checking for ticks that are stuck, so a capture is taken
+// while the world is still blocked rather than after it recovers
+func (w *Watchdog) Start() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.started || w.config.ProfileThreshold <= 0 {
+		return
+	}
+	w.started = true
+	go w.monitor()
+}
+
+// Stop ends monitoring, cuts short a CPU profile in progress and waits for
+// captures to finish writing
+func (w *Watchdog) Stop() {
+	select {
+	case <-w.stop:
+		return
+	default:
+	}
+	close(w.stop)
+
+	w.mu.Lock()
+	started := w.started
+	w.mu.Unlock()
+	if started {
+		<-w.done
+	}
+	w.captures.Wait()
+}
+
+// Begin starts timing a tick the ticker fired at scheduled
+func (w *Watchdog) Begin(scheduled time.Time) *Tick {
+	now := time.Now()
+	t := &Tick{Scheduled: scheduled, Start: now, mark: now}
+	w.mu.Lock()
+	w.current = t
+	w.mu.Unlock()
+	return t
+}
+
+// End finishes a tick, reports it if slow and captures profiles if the
+// world has fallen behind by the profile threshold
+func (w *Watchdog) End(t *Tick) Report {
+	now := time.Now()
+	report := Report{
+		Duration: now.Sub(t.Start),
+		Lag:      t.Start.Sub(t.Scheduled),
+		Phases:   t.Phases,
+	}
+
+	w.mu.Lock()
+	if w.current == t {
+		w.current = nil
+	}
+	w.mu.Unlock()
+
+	if w.config.SlowThreshold > 0 && report.Duration >= w.config.SlowThreshold && w.config.OnSlowTick != nil {
+		w.config.OnSlowTick(report)
+	}
+	if behind := now.Sub(t.Scheduled); w.config.ProfileThreshold > 0 && behind >= w.config.ProfileThreshold {
+		w.trigger(fmt.Sprintf("tick finished %s behind schedule", behind.Round(time.Millisecond)))
+	}
+	return report
+}
+
+// monitor periodically checks the tick in progress
+func (w *Watchdog) monitor() {
+	defer close(w.done)
+
+	interval := w.config.Interval
+	if interval <= 0 || interval > w.config.ProfileThreshold {
+		interval = w.config.ProfileThreshold
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+		}
+		w.mu.Lock()
+		var behind time.Duration
+		if w.current != nil {
+			behind = time.Since(w.current.Scheduled)
+		}
+		w.mu.Unlock()
+		if behind >= w.config.ProfileThreshold {
+			w.trigger(fmt.Sprintf("tick still running %s after it was due", behind.Round(time.Millisecond)))
+		}
+	}
+}
+
+// trigger starts a capture unless one is running or happened recently
+func (w *Watchdog) trigger(reason string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.capturing || (!w.lastCapture.IsZero() && time.Since(w.lastCapture) < w.config.ProfileCooldown) {
+		return
+	}
+	select {
+	case <-w.stop:
+		return
+	default:
+	}
+	w.capturing = true
+	w.lastCapture = time.Now()
+	w.captures.Add(1)
+	go w.capture(reason)
+}
+
+// capture writes a goroutine dump, then samples a CPU profile
+func (w *Watchdog) capture(reason string) {
+	result := Capture{Reason: reason}
+	defer func() {
+		w.prune()
+		w.mu.Lock()
+		w.capturing = false
+		w.mu.Unlock()
+		if w.config.OnCapture != nil {
+			w.config.OnCapture(result)
+		}
+		w.captures.Done()
+	}()
+
+	if err := os.MkdirAll(w.config.ProfileDir, 0700); err != nil {
+		result.Err = err
+		return
+	}
+	stamp := time.Now().UTC().Format("20060102-150405")
+
+	// The goroutine dump comes first: it shows what the tick is blocked on
+	goroutines := filepath.Join(w.config.ProfileDir, "goroutine-"+stamp+".txt")
+	if err := writeProfile(goroutines, func(f *os.File) error {
+		return pprof.Lookup("goroutine").WriteTo(f, 2)
+	}); err != nil {
+		result.Err = err
+	} else {
+		result.Files = append(result.Files, goroutines)
+	}
+
+	cpu := filepath.Join(w.config.ProfileDir, "cpu-"+stamp+".pprof")
+	if err := writeProfile(cpu, func(f *os.File) error {
+		// Fails if a CPU profile is already running, e.g. from /debug/pprof
+		if err := pprof.StartCPUProfile(f); err != nil {
+			return err
+		}
+		select {
+		case <-time.After(w.config.CPUProfileDuration):
+		case <-w.stop:
+		}
+		pprof.StopCPUProfile()
+		return nil
+	}); err != nil {
+		if result.Err == nil {
+			result.Err = err
+		}
+	} else {
+		result.Files = append(result.Files, cpu)
+	}
+}
+
+// writeProfile creates path and fills it with write, removing it on failure
+func writeProfile(path string, write func(*os.File) error) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	err = write(f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+	}
+	return err
+}
+
+// Profiles lists captured profiles, newest first
+func (w *Watchdog) Profiles() []ProfileFile {
+	entries, err := os.ReadDir(w.config.ProfileDir)
+	if err != nil {
+		return nil
+	}
+	var files []ProfileFile
+	for _, e := range entries {
+		if e.IsDir() || !isProfileName(e.Name()) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, ProfileFile{Name: e.Name(), Size: info.Size(), ModTime: info.ModTime()})
+	}
+	sort.Slice(files, func(i, j int) bool {
+		if !files[i].ModTime.Equal(files[j].ModTime) {
+			return files[i].ModTime.After(files[j].ModTime)
+		}
+		return files[i].Name > files[j].Name
+	})
+	return files
+}
+
+// ProfilePath returns the path of a captured profile by file name. Only
+// names the watchdog writes are accepted, so it cannot reach other files.
+func (w *Watchdog) ProfilePath(name string) (string, error) {
+	if !isProfileName(name) {
+		return "", fmt.Errorf("invalid profile name %q", name)
+	}
+	path := filepath.Join(w.config.ProfileDir, name)
+	if _, err := os.Stat(path); err != nil {
+		return "", fmt.Errorf("profile %q not found", name)
+	}
+	return path, nil
+}
+
+// isProfileName reports whether name looks like a file capture writes
+func isProfileName(name string) bool {
+	if name != filepath.Base(name) || strings.ContainsAny(name, `/\`) {
+		return false
+	}
+	return (strings.HasPrefix(name, "goroutine-") && strings.HasSuffix(name, ".txt")) ||
+		(strings.HasPrefix(name, "cpu-") && strings.HasSuffix(name, ".pprof"))
+}
+
+// prune deletes the oldest captures beyond MaxProfiles
+func (w *Watchdog) prune() {
+	files := w.Profiles()
+	for i := w.config.MaxProfiles; i < len(files); i++ {
+		os.Remove(filepath.Join(w.config.ProfileDir, files[i].Name))
+	}
+}
@@ -0,0 +1,177 @@
+package watchdog
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTickPhases(t *testing.T) {
+	w := New(Config{Interval: 500 * time.Millisecond})
+	tick := w.Begin(time.Now().Add(-20 * time.Millisecond))
+	time.Sleep(5 * time.Millisecond)
+	tick.Mark("respawn")
+	tick.Mark("combat")
+	r := w.End(tick)
+
+	if len(r.Phases) != 2 || r.Phases[0].Name != "respawn" || r.Phases[1].Name != "combat" {
+		t.Fatalf("phases = %+v", r.Phases)
+	}
+	if r.Phases[0].Duration < 5*time.Millisecond {
+		t.Errorf("respawn phase = %v, want >= 5ms", r.Phases[0].Duration)
+	}
+	if r.Lag < 20*time.Millisecond {
+		t.Errorf("lag = %v, want >= 20ms", r.Lag)
+	}
+	if r.Duration < r.Phases[0].Duration {
+		t.Errorf("duration %v shorter than a phase", r.Duration)
+	}
+	if s := r.String(); !strings.HasPrefix(s, "respawn=") || !strings.Contains(s, " combat=") {
+		t.Errorf("String() = %q", s)
+	}
+
+	// Marking a nil tick is harmless
+	var none *Tick
+	none.Mark("respawn")
+}
+
+func TestSlowTickReported(t *testing.T) {
+	var reports []Report
+	w := New(Config{
+		SlowThreshold: 10 * time.Millisecond,
+		OnSlowTick:    func(r Report) { reports = append(reports, r) },
+	})
+
+	w.End(w.Begin(time.Now()))
+	if len(reports) != 0 {
+		t.Fatalf("fast tick reported: %+v", reports)
+	}
+
+	tick := w.Begin(time.Now())
+	time.Sleep(15 * time.Millisecond)
+	tick.Mark("agent_ai")
+	w.End(tick)
+	if len(reports) != 1 || reports[0].Phases[0].Name != "agent_ai" {
+		t.Fatalf("reports = %+v", reports)
+	}
+}
+
+func TestCaptureOnLag(t *testing.T) {
+	dir := t.TempDir()
+	captured := make(chan Capture, 2)
+	w := New(Config{
+		ProfileThreshold:   50 * time.Millisecond,
+		ProfileDir:         dir,
+		CPUProfileDuration: 20 * time.Millisecond,
+		OnCapture:          func(c Capture) { captured <- c },
+	})
+	defer w.Stop()
+
+	// A tick that started a second late puts the world behind
+	w.End(w.Begin(time.Now().Add(-time.Second)))
+
+	var c Capture
+	select {
+	case c = <-captured:
+	case <-time.After(3 * time.Second):
+		t.Fatal("no capture")
+	}
+	if c.Err != nil {
+		t.Fatalf("capture failed: %v", c.Err)
+	}
+	if len(c.Files) != 2 {
+		t.Fatalf("files = %v", c.Files)
+	}
+	dump, err := os.ReadFile(c.Files[0])
+	if err != nil || !strings.Contains(string(dump), "goroutine") {
+		t.Errorf("goroutine dump missing or empty: %v", err)
+	}
+	if info, err := os.Stat(c.Files[1]); err != nil || info.Size() == 0 {
+		t.Errorf("CPU profile missing or empty: %v", err)
+	}
+
+	// The cooldown suppresses another capture straight away
+	w.End(w.Begin(time.Now().Add(-time.Second)))
+	select {
+	case c := <-captured:
+		t.Errorf("captured again during cooldown: %+v", c)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	if got := w.Profiles(); len(got) != 2 {
+		t.Errorf("Profiles() = %+v", got)
+	}
+}
+
+func TestCaptureStuckTick(t *testing.T) {
+	captured := make(chan Capture, 1)
+	w := New(Config{
+		Interval:           10 * time.Millisecond,
+		ProfileThreshold:   30 * time.Millisecond,
+		ProfileDir:         t.TempDir(),
+		CPUProfileDuration: time.Hour, // Cut short by Stop
+		OnCapture:          func(c Capture) { captured <- c },
+	})
+	w.Start()
+
+	// The tick never ends; the monitor notices while it is still running
+	w.Begin(time.Now())
+	time.Sleep(100 * time.Millisecond)
+
+	done := make(chan struct{})
+	go func() {
+		w.Stop()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(3 * time.Second):
+		t.Fatal("Stop did not cut the CPU profile short")
+	}
+
+	select {
+	case c := <-captured:
+		if !strings.Contains(c.Reason, "still running") {
+			t.Errorf("reason = %q", c.Reason)
+		}
+	default:
+		t.Fatal("stuck tick was not captured")
+	}
+	w.Stop() // Idempotent
+}
+
+func TestPruneAndProfilePath(t *testing.T) {
+	dir := t.TempDir()
+	w := New(Config{ProfileDir: dir, MaxProfiles: 2})
+
+	base := time.Now().Add(-time.Hour)
+	for i, name := range []string{"goroutine-1.txt", "cpu-1.pprof", "goroutine-2.txt", "cpu-2.pprof"} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte("x"), 0600); err != nil {
+			t.Fatal(err)
+		}
+		mtime := base.Add(time.Duration(i) * time.Minute)
+		os.Chtimes(path, mtime, mtime)
+	}
+	os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("keep"), 0600)
+
+	w.prune()
+	got := w.Profiles()
+	if len(got) != 2 || got[0].Name != "cpu-2.pprof" || got[1].Name != "goroutine-2.txt" {
+		t.Fatalf("after prune = %+v", got)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "notes.txt")); err != nil {
+		t.Error("prune removed a file it did not write")
+	}
+
+	if _, err := w.ProfilePath("cpu-2.pprof"); err != nil {
+		t.Errorf("ProfilePath(cpu-2.pprof): %v", err)
+	}
+	for _, bad := range []string{"cpu-1.pprof", "notes.txt", "../cpu-2.pprof", "sub/cpu-2.pprof"} {
+		if _, err := w.ProfilePath(bad); err == nil {
+			t.Errorf("ProfilePath(%q) should fail", bad)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package world
+
+import (
+	"slices"
+	"strings"
+)
+
+// Room flags change what players and NPCs can do in a room
+const (
+	FlagSafe       = "safe"        // No fighting, no aggro, and Agents can't follow you in
+	FlagNoCombat   = "no_combat"   // No one can start a fight
+	FlagDark       = "dark"        // Unseen without a light or the awakened sight
+	FlagNoRecall   = "no_recall"   // Recall doesn't work from here
+	FlagNoTeleport = "no_teleport" // Phones and teleports don't connect, in or out
+	FlagPvP        = "pvp"         // Players can attack each other
+)
+
+// Flags lists every room flag
+var Flags = []string{FlagSafe, FlagNoCombat, FlagDark, FlagNoRecall, FlagNoTeleport, FlagPvP}
+
+// Environment types describe what kind of place a room is
+const (
+	EnvStreet      = "street"      // City streets and alleys
+	EnvRooftop     = "rooftop"     // Open rooftops above the city
+	EnvIndoors     = "indoors"     // Buildings in the Matrix
+	EnvUnderground = "underground" // Subways, sewers and tunnels
+	EnvConstruct   = "construct"   // Loading programs and training constructs
+	EnvShip        = "ship"        // Hovercraft in the real world
+	EnvZion        = "zion"        // The last human city
+)
+
+// Environments lists every environment type
+var Environments = []string{EnvStreet, EnvRooftop, EnvIndoors, EnvUnderground, EnvConstruct, EnvShip, EnvZion}
+
+// ValidFlag reports whether f is a room flag
+func ValidFlag(f string) bool {
+	return slices.Contains(Flags, f)
+}
+
+// ValidEnvironment reports whether env is an environment type; "" is unset
+func ValidEnvironment(env string) bool {
+	return env == "" || slices.Contains(Environments, env)
+}
+
+// Outdoors reports whether an environment is under the Matrix's sky, where
+// the day and night cycle shows. Rooms with no environment count as outdoors.
+func Outdoors(env string) bool {
+	return env == "" || env == EnvStreet || env == EnvRooftop
+}
+
+// NormalizeFlag turns a builder's spelling of a flag, such as "no-recall",
+// into its name
+func NormalizeFlag(f string) string {
+	f = strings.ReplaceAll(strings.ToLower(f), "-", "_")
+	switch f {
+	case "nocombat":
+		return FlagNoCombat
+	case "norecall":
+		return FlagNoRecall
+	case "noteleport", "no_phone", "nophone":
+		return FlagNoTeleport
+	}
+	return f
+}
@@ -0,0 +1,35 @@
+package world
+
+import "testing"
+
+func TestRoomFlags(t *testing.T) {
+	for _, f := range Flags {
+		if !ValidFlag(f) {
+			t.Errorf("ValidFlag(%q) = false", f)
+		}
+	}
+	if ValidFlag("haunted") {
+		t.Error("unknown flag should be invalid")
+	}
+
+	tests := map[string]string{
+		"no-recall": FlagNoRecall,
+		"NoCombat":  FlagNoCombat,
+		"no-phone":  FlagNoTeleport,
+		"safe":      FlagSafe,
+	}
+	for in, want := range tests {
+		if got := NormalizeFlag(in); got != want {
+			t.Errorf("NormalizeFlag(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestEnvironments(t *testing.T) {
+	if !ValidEnvironment("") || !ValidEnvironment(EnvShip) || ValidEnvironment("moon") {
+		t.Error("ValidEnvironment should accept unset and known environments only")
+	}
+	if !Outdoors("") || !Outdoors(EnvStreet) || Outdoors(EnvShip) || Outdoors(EnvUnderground) {
+		t.Error("only streets, rooftops and unset rooms are outdoors")
+	}
+}
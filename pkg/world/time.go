@@ -1,6 +1,7 @@
 // Package world provides world simulation systems for Matrix MUD.
 // This includes the day/night cycle that affects world descriptions
-// and creates atmospheric time progression in the game.
+// and creates atmospheric time progression in the game, and the flags and
+// environment types that describe rooms.
 package world
 
 import (
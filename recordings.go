@@ -0,0 +1,364 @@
+// Package main records player sessions to asciicast v2 files, either because
+// the player opted in with "record on" or because an operator started it from
+// the admin console, and serves the console pages for them:
+//
+//	GET /recordings        - List recordings and start or stop one
+//	POST /recordings/start - Record an online player's session
+//	POST /recordings/stop  - Stop recording a player
+//	POST /recordings/delete - Delete a finished recording
+//	GET /recordings/play   - Play a recording in the browser (?name=)
+//	GET /recordings/file   - Download the .cast file (?name=)
+//
+// Passwords are read without echo, so the recorder stores "[redacted]" in
+// their place rather than the typed input.
+package main
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/yourusername/matrix-mud/pkg/db"
+	"github.com/yourusername/matrix-mud/pkg/logging"
+	"github.com/yourusername/matrix-mud/pkg/moderation"
+	"github.com/yourusername/matrix-mud/pkg/recording"
+)
+
+// sessionRecordings manages recordings, or is nil before startRecordings runs
+var sessionRecordings *recording.Manager
+
+// startRecordings opens RECORDING_DIR and deletes recordings past
+// RECORDING_RETENTION now and every hour
+func startRecordings() {
+	retention, err := moderation.ParseDuration(Config.RecordingRetention)
+	if err != nil {
+		logging.Error().Err(err).Str("retention", Config.RecordingRetention).Msg("Invalid RECORDING_RETENTION, keeping recordings for 30 days")
+		retention = 30 * 24 * time.Hour
+	}
+	m := recording.NewManager(Config.RecordingDir, retention)
+	sessionRecordings = m
+
+	go func() {
+		for {
+			if n, err := m.Prune(); err != nil {
+				logging.Error().Err(err).Str("dir", Config.RecordingDir).Msg("Failed to prune session recordings")
+			} else if n > 0 {
+				logging.Info().Int("removed", n).Msg("Pruned expired session recordings")
+			}
+			time.Sleep(time.Hour)
+		}
+	}()
+}
+
+// startSessionRecording records everything the client is sent from now on.
+// reason is shown in the recording title, e.g. "opt-in".
+func startSessionRecording(c *Client, p *Player, reason string) error {
+	if sessionRecordings == nil {
+		return fmt.Errorf("session recording is not available")
+	}
+	rec, err := sessionRecordings.Start(p.Name, fmt.Sprintf("%s (%s)", p.Name, reason))
+	if err != nil {
+		logging.Error().Err(err).Str("player", p.Name).Msg("Failed to start session recording")
+		return err
+	}
+	c.recorder.Store(rec)
+	logging.Info().Str("player", p.Name).Str("file", rec.Name()).Str("reason", reason).Msg("Session recording started")
+	return nil
+}
+
+// stopSessionRecording ends the player's recording, if any
+func stopSessionRecording(c *Client, p *Player) {
+	if sessionRecordings == nil {
+		return
+	}
+	c.recorder.Store(nil)
+	sessionRecordings.Stop(p.Name)
+}
+
+// stopAllRecordings closes every recording during shutdown
+func stopAllRecordings() {
+	if sessionRecordings != nil {
+		sessionRecordings.StopAll()
+	}
+}
+
+// isRecording reports whether the player's session is being recorded
+func isRecording(p *Player) bool {
+	return sessionRecordings != nil && sessionRecordings.Active(p.Name) != nil
+}
+
+// handleRecordCommand implements "record [on|off]", the player's opt-in.
+// The choice is saved, so later sessions are recorded too.
+func handleRecordCommand(w *World, c *Client, p *Player, arg string) string {
+	switch strings.ToLower(strings.TrimSpace(arg)) {
+	case "":
+		switch {
+		case p.RecordSessions:
+			return "Your sessions are recorded. Type 'record off' to stop.\r\n"
+		case isRecording(p):
+			return "This session is being recorded by the Operators.\r\n"
+		default:
+			return "Your sessions are not recorded. Type 'record on' to record them for playtesting.\r\n"
+		}
+	case "on":
+		if err := startSessionRecording(c, p, "opt-in"); err != nil {
+			return "Recording is not available right now.\r\n"
+		}
+		p.RecordSessions = true
+		w.SavePlayer(p)
+		return "Recording ON - this and future sessions are saved for playtesting. Passwords are never recorded.\r\n"
+	case "off":
+		p.RecordSessions = false
+		w.SavePlayer(p)
+		stopSessionRecording(c, p)
+		return "Recording OFF.\r\n"
+	default:
+		return "Usage: record [on|off]\r\n"
+	}
+}
+
+// recordingsUnavailable writes an error when recording is not running
+func recordingsUnavailable(w http.ResponseWriter) bool {
+	if sessionRecordings == nil {
+		http.Error(w, "Session recording is not running", http.StatusServiceUnavailable)
+		return true
+	}
+	return false
+}
+
+// adminRecordings lists recordings with forms to start and stop them
+func adminRecordings(w http.ResponseWriter, r *http.Request) {
+	if !checkAdminAuth(w, r) || recordingsUnavailable(w) {
+		return
+	}
+
+	page := fmt.Sprintf(`<html><head><title>Construct Recordings</title>
+	<style>
+		body { background: #111; color: #0f0; font-family: monospace; padding: 20px; }
+		table { border-collapse: collapse; width: 100%%; }
+		th, td { border: 1px solid #333; padding: 8px; text-align: left; }
+		th { background: #222; }
+		a { color: #0f0; }
+		input, button { background: #000; color: #0f0; border: 1px solid #333; font-family: monospace; }
+		form { display: inline; }
+		.btn { background: #300; color: #fff; }
+	</style>
+	</head><body>
+	<h1>/// RECORDINGS ///</h1>
+	<p><a href="/">&laquo; back</a></p>
+	<h3>Record a Session</h3>
+	<form method="POST" action="/recordings/start">%s
+		<input name="name" placeholder="online player">
+		<button type="submit">START</button>
+	</form>
+	<form method="POST" action="/recordings/stop">%s
+		<input name="name" placeholder="player">
+		<button type="submit" class="btn">STOP</button>
+	</form>
+	<p>Recordings older than %s are deleted automatically.</p>
+	<h3>Recordings</h3>`, csrfField(), csrfField(), html.EscapeString(Config.RecordingRetention))
+
+	infos, err := sessionRecordings.List()
+	if err != nil {
+		page += `<p>Failed to read recordings: ` + html.EscapeString(err.Error()) + `</p>`
+	}
+	if len(infos) == 0 {
+		page += `<p>No recordings.</p>`
+	} else {
+		page += `<table><tr><th>Started</th><th>Player</th><th>Title</th><th>Length</th><th>Size</th><th>Action</th></tr>`
+		for _, info := range infos {
+			name := url.QueryEscape(info.Name)
+			length := info.Duration.String()
+			action := fmt.Sprintf(`<form method="POST" action="/recordings/delete">%s<input type="hidden" name="file" value="%s"><button type="submit" class="btn">DELETE</button></form>`,
+				csrfField(), html.EscapeString(info.Name))
+			if info.Active {
+				length = "recording..."
+				action = ""
+			}
+			page += fmt.Sprintf(`<tr><td>%s</td><td>%s</td><td>%s</td><td>%s</td><td>%d KB</td><td>
+				<a href="/recordings/play?name=%s">PLAY</a> <a href="/recordings/file?name=%s">DOWNLOAD</a> %s</td></tr>`,
+				info.Started.Format("2006-01-02 15:04:05"), html.EscapeString(info.Player), html.EscapeString(info.Title),
+				length, (info.Size+1023)/1024, name, name, action)
+		}
+		page += `</table>`
+	}
+	page += `</body></html>`
+	w.Write([]byte(page))
+}
+
+// adminRecordingStart records an online player's session.
+// Accepts POST form field: name.
+func adminRecordingStart(w http.ResponseWriter, r *http.Request) {
+	if !checkAdminPost(w, r) || recordingsUnavailable(w) {
+		return
+	}
+	p := adminTarget(w, r)
+	if p == nil {
+		return
+	}
+	if p.Conn == nil {
+		http.Error(w, fmt.Sprintf("User %s is not connected", p.Name), http.StatusBadRequest)
+		return
+	}
+	if err := startSessionRecording(p.Conn, p, "recorded by "+consoleActor()); err != nil {
+		http.Error(w, "Failed to start recording: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	auditConsole(r, db.AuditAdminAction, "start session recording: "+p.Name)
+	http.Redirect(w, r, "/recordings", http.StatusSeeOther)
+}
+
+// adminRecordingStop stops recording a player. Accepts POST form field: name.
+func adminRecordingStop(w http.ResponseWriter, r *http.Request) {
+	if !checkAdminPost(w, r) || recordingsUnavailable(w) {
+		return
+	}
+	name := strings.TrimSpace(r.FormValue("name"))
+	if name == "" {
+		http.Error(w, "Missing 'name' parameter", http.StatusBadRequest)
+		return
+	}
+	if p := findOnlinePlayer(adminWorld, name); p != nil && p.Conn != nil {
+		stopSessionRecording(p.Conn, p)
+	} else {
+		sessionRecordings.Stop(name)
+	}
+	auditConsole(r, db.AuditAdminAction, "stop session recording: "+name)
+	http.Redirect(w, r, "/recordings", http.StatusSeeOther)
+}
+
+// adminRecordingDelete deletes a finished recording. Accepts POST form field: file.
+func adminRecordingDelete(w http.ResponseWriter, r *http.Request) {
+	if !checkAdminPost(w, r) || recordingsUnavailable(w) {
+		return
+	}
+	name := r.FormValue("file")
+	if err := sessionRecordings.Delete(name); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	auditConsole(r, db.AuditDelete, "delete session recording: "+name)
+	http.Redirect(w, r, "/recordings", http.StatusSeeOther)
+}
+
+// adminRecordingFile serves a recording as an asciicast file
+func adminRecordingFile(w http.ResponseWriter, r *http.Request) {
+	if !checkAdminAuth(w, r) || recordingsUnavailable(w) {
+		return
+	}
+	name := r.URL.Query().Get("name")
+	path, err := sessionRecordings.Path(name)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/x-asciicast")
+	if r.URL.Query().Get("inline") == "" {
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+	}
+	http.ServeFile(w, r, path)
+}
+
+// adminRecordingPlay shows a recording in a small built-in player. It
+// understands the output the game sends (text, colours and screen clears),
+// which is all a session contains, so no external player is needed.
+func adminRecordingPlay(w http.ResponseWriter, r *http.Request) {
+	if !checkAdminAuth(w, r) || recordingsUnavailable(w) {
+		return
+	}
+	name := r.URL.Query().Get("name")
+	if _, err := sessionRecordings.Path(name); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	page := `<html><head><title>Playback: ` + html.EscapeString(name) + `</title>
+	<style>
+		body { background: #111; color: #0f0; font-family: monospace; padding: 20px; }
+		a { color: #0f0; }
+		button, select { background: #000; color: #0f0; border: 1px solid #333; font-family: monospace; }
+		#screen { background: #000; color: #ccc; border: 1px solid #333; padding: 10px; width: 82ch; height: 30em; overflow-y: auto; white-space: pre-wrap; }
+	</style>
+	</head><body>
+	<h1>/// PLAYBACK ///</h1>
+	<p><a href="/recordings">&laquo; recordings</a> ` + html.EscapeString(name) + `</p>
+	<p><button id="play">PLAY</button> <button id="restart">RESTART</button>
+	speed <select id="speed"><option>1</option><option>2</option><option>4</option><option>8</option></select>
+	<span id="clock">0.0s</span></p>
+	<pre id="screen"></pre>
+	<script>
+	(function() {
+		var colors = {30:"#000",31:"#f44",32:"#0f0",33:"#ff0",34:"#48f",35:"#f4f",36:"#0ff",37:"#ccc",
+			90:"#666",91:"#f66",92:"#6f6",93:"#ff6",94:"#66f",95:"#f6f",96:"#6ff",97:"#fff"};
+		var screen = document.getElementById("screen"), clock = document.getElementById("clock");
+		var playBtn = document.getElementById("play"), speed = document.getElementById("speed");
+		var events = [], index = 0, at = 0, timer = null, style = {};
+
+		function reset() { screen.textContent = ""; index = 0; at = 0; style = {}; clock.textContent = "0.0s"; }
+		function span(text) {
+			if (!text) return;
+			var s = document.createElement("span");
+			s.textContent = text;
+			if (style.color) s.style.color = style.color;
+			if (style.bold) s.style.fontWeight = "bold";
+			screen.appendChild(s);
+		}
+		function sgr(params) {
+			(params || "0").split(";").forEach(function(p) {
+				var n = parseInt(p || "0", 10);
+				if (n === 0) style = {};
+				else if (n === 1) style.bold = true;
+				else if (n === 22) style.bold = false;
+				else if (n === 39) style.color = null;
+				else if (colors[n]) style.color = colors[n];
+			});
+		}
+		function write(data) {
+			var re = /\x1b\[([0-9;?]*)([A-Za-z])/g, last = 0, m;
+			while ((m = re.exec(data)) !== null) {
+				span(data.slice(last, m.index));
+				last = re.lastIndex;
+				if (m[2] === "m") sgr(m[1]);
+				else if (m[2] === "J" && m[1] === "2") screen.textContent = "";
+			}
+			span(data.slice(last).replace(/\r\n/g, "\n").replace(/\r/g, "").replace(/[\x00-\x08\x0b-\x1f\x7f]/g, ""));
+			screen.scrollTop = screen.scrollHeight;
+		}
+		function step() {
+			timer = null;
+			while (index < events.length && events[index][0] <= at) {
+				if (events[index][1] === "o") write(events[index][2]);
+				index++;
+			}
+			clock.textContent = at.toFixed(1) + "s";
+			if (index >= events.length) { playBtn.textContent = "PLAY"; return; }
+			// Skip long idle gaps
+			var wait = Math.min(events[index][0] - at, 2);
+			at = events[index][0];
+			timer = setTimeout(step, wait * 1000 / parseFloat(speed.value));
+		}
+		playBtn.onclick = function() {
+			if (timer) { clearTimeout(timer); timer = null; playBtn.textContent = "PLAY"; return; }
+			if (index >= events.length) reset();
+			playBtn.textContent = "PAUSE";
+			step();
+		};
+		document.getElementById("restart").onclick = function() {
+			if (timer) clearTimeout(timer);
+			timer = null; reset(); playBtn.textContent = "PAUSE"; step();
+		};
+		fetch("/recordings/file?inline=1&name=` + url.QueryEscape(name) + `", {credentials: "same-origin"})
+			.then(function(r) { return r.text(); })
+			.then(function(text) {
+				text.split("\n").slice(1).forEach(function(line) {
+					if (line) { try { events.push(JSON.parse(line)); } catch (e) {} }
+				});
+			});
+	})();
+	</script>
+	</body></html>`
+	w.Write([]byte(page))
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/matrix-mud/pkg/db"
+	"github.com/yourusername/matrix-mud/pkg/recording"
+)
+
+// withRecordings records into a temporary directory for one test
+func withRecordings(t *testing.T) *recording.Manager {
+	t.Helper()
+	orig := sessionRecordings
+	t.Cleanup(func() { sessionRecordings = orig })
+	sessionRecordings = recording.NewManager(t.TempDir(), 0)
+	return sessionRecordings
+}
+
+// castFile reads a recording by name
+func castFile(t *testing.T, m *recording.Manager, name string) string {
+	t.Helper()
+	path, err := m.Path(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestRecordingRedactsPasswords(t *testing.T) {
+	m := withRecordings(t)
+	conn := newMockConn("hunter2-secret\r\n")
+	client := &Client{conn: conn, reader: bufio.NewReader(conn)}
+	player := &Player{Name: "Neo"}
+
+	if err := startSessionRecording(client, player, "test"); err != nil {
+		t.Fatal(err)
+	}
+	name := m.Active("neo").Name()
+	client.Write("Password: ")
+	pass, err := client.readPassword()
+	if err != nil || pass != "hunter2-secret" {
+		t.Fatalf("readPassword = %q, %v", pass, err)
+	}
+	client.echoInput("look")
+	client.Write("You see a dojo.\r\n")
+	stopSessionRecording(client, player)
+	client.Write("not recorded")
+
+	cast := castFile(t, m, name)
+	if strings.Contains(cast, "hunter2") {
+		t.Fatalf("password leaked into recording:\n%s", cast)
+	}
+	for _, want := range []string{`"i","[redacted]"`, `"o","Password: "`, `"i","look\r"`, `"o","look\r\n"`, "You see a dojo."} {
+		if !strings.Contains(cast, want) {
+			t.Errorf("recording missing %s:\n%s", want, cast)
+		}
+	}
+	if strings.Contains(cast, "not recorded") {
+		t.Error("output recorded after stop")
+	}
+}
+
+func TestRecordCommand(t *testing.T) {
+	m := withRecordings(t)
+	t.Cleanup(func() { os.Remove("data/players/recording_test.json") })
+	w := &World{Players: map[*Client]*Player{}}
+	client := &Client{conn: newMockConn("")}
+	player := &Player{Name: "recording_test", Conn: client}
+
+	if got := handleRecordCommand(w, client, player, ""); !strings.Contains(got, "not recorded") {
+		t.Errorf("status = %q", got)
+	}
+	if got := handleRecordCommand(w, client, player, "on"); !strings.Contains(got, "Recording ON") {
+		t.Errorf("record on = %q", got)
+	}
+	if !player.RecordSessions || m.Active(player.Name) == nil {
+		t.Fatal("record on did not start a recording")
+	}
+	if got := handleRecordCommand(w, client, player, "off"); got != "Recording OFF.\r\n" {
+		t.Errorf("record off = %q", got)
+	}
+	if player.RecordSessions || m.Active(player.Name) != nil || client.recorder.Load() != nil {
+		t.Error("record off left recording running")
+	}
+	if got := handleRecordCommand(w, client, player, "maybe"); !strings.HasPrefix(got, "Usage") {
+		t.Errorf("bad arg = %q", got)
+	}
+}
+
+func TestAdminRecordings(t *testing.T) {
+	player, repo := setupAdminActions(t)
+	m := withRecordings(t)
+	player.Conn = &Client{conn: newMockConn("")}
+
+	if w := adminPost(adminRecordingStart, url.Values{"name": {"neo"}}); w.Code != http.StatusSeeOther {
+		t.Fatalf("start = %d %s", w.Code, w.Body.String())
+	}
+	rec := m.Active("neo")
+	if rec == nil {
+		t.Fatal("admin start did not record")
+	}
+	name := rec.Name()
+	player.Conn.Write("Morpheus says: hello\r\n")
+
+	get := func(handler http.HandlerFunc, target string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", target, nil)
+		req.SetBasicAuth("testadmin", "testpass")
+		w := httptest.NewRecorder()
+		handler(w, req)
+		return w
+	}
+	if page := get(adminRecordings, "/recordings").Body.String(); !strings.Contains(page, name) || !strings.Contains(page, "recording...") {
+		t.Errorf("list page missing active recording:\n%s", page)
+	}
+	if w := adminPost(adminRecordingDelete, url.Values{"file": {name}}); w.Code != http.StatusBadRequest {
+		t.Errorf("deleting an active recording = %d", w.Code)
+	}
+
+	adminPost(adminRecordingStop, url.Values{"name": {"Neo"}})
+	if m.Active("neo") != nil {
+		t.Fatal("admin stop did not stop the recording")
+	}
+
+	w := get(adminRecordingFile, "/recordings/file?name="+url.QueryEscape(name))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "Morpheus says: hello") {
+		t.Errorf("download = %d %q", w.Code, w.Body.String())
+	}
+	if w := get(adminRecordingPlay, "/recordings/play?name="+url.QueryEscape(name)); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "/recordings/file?inline=1") {
+		t.Errorf("play page = %d", w.Code)
+	}
+	if w := get(adminRecordingFile, "/recordings/file?name=..%2Fsanctions.json"); w.Code != http.StatusNotFound {
+		t.Errorf("path traversal = %d", w.Code)
+	}
+
+	if w := adminPost(adminRecordingDelete, url.Values{"file": {name}}); w.Code != http.StatusSeeOther {
+		t.Errorf("delete = %d", w.Code)
+	}
+	if _, err := m.Path(name); err == nil {
+		t.Error("recording not deleted")
+	}
+
+	if logs, _ := repo.Find(db.AuditFilter{Query: "session recording"}); len(logs) != 3 {
+		t.Errorf("audit entries = %d, want start, stop and delete", len(logs))
+	}
+}
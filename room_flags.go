@@ -0,0 +1,209 @@
+// Package main handles room flags and environments:
+//
+//	edit flag <flag> [on|off]  - Builders: set or clear a room flag
+//	edit env <type>            - Builders: set a room's environment
+//
+// Builders are the players listed in ADMIN_PLAYERS; they see every room's
+// flags and environment when they look.
+//
+// Safe and no-combat rooms stop fights, and safe rooms also keep aggressive
+// NPCs and Agents away. Dark rooms can't be seen without a light or the
+// awakened sight. No-recall rooms block recall, no-teleport rooms block
+// phone calls and teleports, and in PvP rooms players can attack each
+// other. The flags and environment types are defined in pkg/world.
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/yourusername/matrix-mud/pkg/effects"
+	"github.com/yourusername/matrix-mud/pkg/game"
+	"github.com/yourusername/matrix-mud/pkg/world"
+)
+
+// pvpPrefix marks a Player.Target that names a player rather than an NPC
+const pvpPrefix = "@"
+
+// HasFlag reports whether a room has a flag
+func (r *Room) HasFlag(flag string) bool {
+	return r != nil && slices.Contains(r.Flags, flag)
+}
+
+// peaceBlocks returns why no one can fight in a room, or ""
+func peaceBlocks(room *Room) string {
+	switch {
+	case room.HasFlag(world.FlagSafe):
+		return "This is a safe zone. Violence is not permitted here."
+	case room.HasFlag(world.FlagNoCombat):
+		return "Fighting is not allowed here."
+	}
+	return ""
+}
+
+// carriesLight reports whether a player carries or wears a light
+func carriesLight(p *Player) bool {
+	for _, item := range p.Inventory {
+		if item.Light {
+			return true
+		}
+	}
+	for _, item := range p.Equipment {
+		if item != nil && item.Light {
+			return true
+		}
+	}
+	return false
+}
+
+// canSee reports whether a player can see in a room: it isn't dark, or they
+// have a light or the awakened sight
+func canSee(p *Player, room *Room) bool {
+	return !room.HasFlag(world.FlagDark) || p.Awakened || carriesLight(p)
+}
+
+// darkLook is what a player sees looking around, or at target, in the dark
+func darkLook(p *Player, room *Room, target string) string {
+	if target != "" {
+		return "It's too dark to see that."
+	}
+	desc := "\r\nIt is pitch black. You can't see a thing.\r\nExits: "
+	for _, dir := range visibleExits(p, room) {
+		desc += fmt.Sprintf("[%s] ", exitLabel(dir, room.Exits[dir]))
+	}
+	return desc + "\r\n"
+}
+
+// roomTags marks a room's safe and PvP flags in its title for every player
+func roomTags(room *Room) string {
+	tags := ""
+	if room.HasFlag(world.FlagSafe) {
+		tags += Cyan + " [SAFE]" + White
+	}
+	if room.HasFlag(world.FlagPvP) {
+		tags += Red + " [PVP]" + White
+	}
+	return tags
+}
+
+// builderDetails shows builders a room's flags and environment
+func builderDetails(room *Room) string {
+	flags := "none"
+	if len(room.Flags) > 0 {
+		flags = strings.Join(room.Flags, ", ")
+	}
+	env := room.Environment
+	if env == "" {
+		env = "unset"
+	}
+	return fmt.Sprintf("\r\n%s[Flags: %s | Environment: %s]%s", Yellow, flags, env, Green)
+}
+
+// Outdoors reports whether a player is under the Matrix's sky, where the
+// day and night cycle shows
+func (w *World) Outdoors(p *Player) bool {
+	w.mutex.RLock()
+	defer w.mutex.RUnlock()
+	room := w.Rooms[p.RoomID]
+	return room != nil && world.Outdoors(room.Environment)
+}
+
+// EditFlag sets or clears a flag on the builder's room, e.g.
+// "edit flag dark on". The world lock must be held.
+func (w *World) EditFlag(p *Player, flag, value string) string {
+	if !isBuilder(p) {
+		return "Only builders can change room flags."
+	}
+	room := w.Rooms[p.RoomID]
+	flag = world.NormalizeFlag(flag)
+	if !world.ValidFlag(flag) {
+		return fmt.Sprintf("Unknown flag '%s'. Flags: %s", flag, strings.Join(world.Flags, ", "))
+	}
+	room.Flags = slices.DeleteFunc(room.Flags, func(f string) bool { return f == flag })
+	if value == "off" || value == "no" || value == "false" {
+		return fmt.Sprintf("Room flag %s cleared.", flag)
+	}
+	room.Flags = append(room.Flags, flag)
+	slices.Sort(room.Flags)
+	return fmt.Sprintf("Room flag %s set.", flag)
+}
+
+// EditEnvironment sets the builder's room's environment type. The world
+// lock must be held.
+func (w *World) EditEnvironment(p *Player, env string) string {
+	if !isBuilder(p) {
+		return "Only builders can change a room's environment."
+	}
+	if env == "none" {
+		env = ""
+	}
+	if !world.ValidEnvironment(env) {
+		return fmt.Sprintf("Unknown environment '%s'. Environments: %s", env, strings.Join(world.Environments, ", "))
+	}
+	w.Rooms[p.RoomID].Environment = env
+	return fmt.Sprintf("Room environment set to '%s'.", env)
+}
+
+// startPvP starts a fight between two players in a PvP room. A player who
+// is not already fighting fights back. The world lock must be held.
+func (w *World) startPvP(p, target *Player) string {
+	if target == p {
+		return "You can't attack yourself."
+	}
+	if target.Ghost {
+		return fmt.Sprintf("%s is a ghost.", target.Name)
+	}
+	now := w.now()
+	p.State, p.Target = "COMBAT", pvpPrefix+target.Name
+	p.LastAttack = now.Add(-2 * time.Second)
+	if target.State != "COMBAT" {
+		target.State, target.Target, target.LastAttack = "COMBAT", pvpPrefix+p.Name, now
+	}
+	if target.Conn != nil {
+		target.Conn.Write(Matrixify(fmt.Sprintf("\r\n%s%s attacks you!%s\r\n> ", Red, p.Name, Green)))
+	}
+	return fmt.Sprintf("Engaging %s!", target.Name)
+}
+
+// resolvePvPRound resolves one round of a player's attack on another. The
+// fight ends if either leaves or the room stops allowing PvP. The world
+// lock must be held.
+func (w *World) resolvePvPRound(p *Player) {
+	room := w.Rooms[p.RoomID]
+	var target *Player
+	for _, other := range w.Players {
+		if other.RoomID == p.RoomID && pvpPrefix+other.Name == p.Target {
+			target = other
+		}
+	}
+	if target == nil || target.Ghost || !room.HasFlag(world.FlagPvP) || peaceBlocks(room) != "" {
+		p.State, p.Target = "IDLE", ""
+		p.Conn.Write(Matrixify("\r\nTarget lost.\r\n> "))
+		return
+	}
+	now := w.now()
+	if p.Effects.Controlled(effects.Stun, now) {
+		p.Conn.Write(Matrixify("\r\nYou are stunned and cannot attack!\r\n"))
+		return
+	}
+	var mine, theirs string
+	if result := game.Attack(w.rng(), w.playerCombatant(p, now), w.playerCombatant(target, now)); result.Hit {
+		target.HP -= result.Damage
+		mine = fmt.Sprintf("You hit %s for %d damage!", target.Name, result.Damage)
+		theirs = fmt.Sprintf("%s hits you for %d damage!", p.Name, result.Damage)
+		if target.HP <= 0 {
+			theirs += "\r\n" + w.playerDeath(target, p.Name)
+			mine += fmt.Sprintf("\r\n%s falls!", target.Name)
+			p.State, p.Target = "IDLE", ""
+		}
+	} else {
+		mine = missMessage(result, "You", target.Name)
+		theirs = missMessage(result, p.Name, "you")
+	}
+	p.Conn.Write(Matrixify("\r\n" + mine + "\r\n"))
+	if target.Conn != nil {
+		target.Conn.Write(Matrixify("\r\n" + theirs + "\r\n"))
+	}
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourusername/matrix-mud/pkg/watchdog"
+	"github.com/yourusername/matrix-mud/pkg/world"
+)
+
+func TestSafeAndNoCombatRooms(t *testing.T) {
+	w, p := setupItems(t)
+	room := w.Rooms["dojo"]
+	room.Flags = []string{world.FlagSafe}
+
+	if got := w.StartCombat(p, "smith"); got != "This is a safe zone. Violence is not permitted here." || p.State != "IDLE" {
+		t.Errorf("kill in a safe room = %q, state %s", got, p.State)
+	}
+	if got := w.CastSkill(p, "smash", "smith"); !strings.Contains(got, "safe zone") {
+		t.Errorf("attack skill in a safe room = %q", got)
+	}
+
+	// Aggressive NPCs leave players in safe rooms alone
+	room.NPCMap["smith"].Aggro = true
+	wd := watchdog.New(watchdog.Config{})
+	w.UpdateTick(wd.Begin(time.Now()))
+	if p.State != "IDLE" {
+		t.Errorf("aggro in a safe room: state %s/%s", p.State, p.Target)
+	}
+
+	// A fight already under way breaks off when the room turns peaceful
+	room.Flags = []string{world.FlagNoCombat}
+	p.State, p.Target = "COMBAT", "brown"
+	w.ResolveCombatRound(p)
+	if p.State != "IDLE" || !strings.Contains(p.Conn.conn.(*mockConn).output(), "The fight breaks off.") {
+		t.Errorf("fight in a no-combat room: state %s", p.State)
+	}
+	if got := w.StartCombat(p, "brown"); got != "Fighting is not allowed here." {
+		t.Errorf("kill in a no-combat room = %q", got)
+	}
+}
+
+func TestDarkRooms(t *testing.T) {
+	w, p := setupItems(t)
+	w.Rooms["dojo"].Flags = []string{world.FlagDark}
+	w.Rooms["dojo"].Exits["north"] = &Exit{To: "street"}
+
+	if got := w.Look(p, ""); !strings.Contains(got, "pitch black") || strings.Contains(got, "Agent Smith") || !strings.Contains(got, "[north]") {
+		t.Errorf("look in the dark = %q", got)
+	}
+	if got := w.Look(p, "smith"); got != "It's too dark to see that." {
+		t.Errorf("look at an NPC in the dark = %q", got)
+	}
+
+	p.Inventory = append(p.Inventory, &Item{ID: "flashlight", Name: "Flashlight", Light: true})
+	if got := w.Look(p, ""); strings.Contains(got, "pitch black") || !strings.Contains(got, "Agent Smith") {
+		t.Errorf("look with a light = %q", got)
+	}
+
+	p.Inventory = nil
+	p.Awakened = true
+	if got := w.Look(p, ""); strings.Contains(got, "pitch black") {
+		t.Errorf("the awakened should see in the dark: %q", got)
+	}
+}
+
+func TestNoRecallAndNoTeleport(t *testing.T) {
+	w, p := setupItems(t)
+	w.Rooms["street"] = &Room{ID: "street", Description: "A street.", Exits: map[string]*Exit{}, ItemMap: map[string]*Item{}, NPCMap: map[string]*NPC{},
+		HasPhone: true, Flags: []string{world.FlagNoRecall, world.FlagNoTeleport}}
+	w.Rooms["dojo"].HasPhone = true
+	p.RoomID = "street"
+	p.DiscoveredPhones = []string{"dojo", "street"}
+
+	if got := w.Recall(p); !strings.Contains(got, "blocks your recall") || p.RoomID != "street" {
+		t.Errorf("recall from a no-recall room = %q", got)
+	}
+	if got := w.CallPhone(p, "dojo"); !strings.Contains(got, "line is dead") || p.RoomID != "street" {
+		t.Errorf("call out of a no-teleport room = %q", got)
+	}
+	if err := w.Teleport(p, "dojo"); err != errNoTeleport || p.RoomID != "street" {
+		t.Errorf("teleport out of a no-teleport room = %v", err)
+	}
+
+	// Calls into a no-teleport room don't connect either
+	p.RoomID = "dojo"
+	if got := w.CallPhone(p, "street"); !strings.Contains(got, "nothing answers") || p.RoomID != "dojo" {
+		t.Errorf("call into a no-teleport room = %q", got)
+	}
+	if err := w.Teleport(p, "street"); err != errNoTeleport || p.RoomID != "dojo" {
+		t.Errorf("teleport into a no-teleport room = %v", err)
+	}
+}
+
+func TestPvPRooms(t *testing.T) {
+	w, neo := setupItems(t)
+	trinity := &Player{Name: "Trinity", Class: "Hacker", Level: 4, RoomID: "dojo", HP: 1, MaxHP: 100, State: "IDLE",
+		Equipment: map[string]*Item{}, Conn: &Client{conn: newMockConn("")}}
+	w.Players[trinity.Conn] = trinity
+
+	if got := w.StartCombat(neo, "trinity"); got != "Not here." {
+		t.Errorf("attack a player outside a PvP room = %q", got)
+	}
+
+	w.Rooms["dojo"].Flags = []string{world.FlagPvP}
+	if got := w.StartCombat(neo, "trinity"); got != "Engaging Trinity!" || neo.Target != "@Trinity" || trinity.Target != "@Neo" {
+		t.Fatalf("attack a player in a PvP room = %q, targets %q/%q", got, neo.Target, trinity.Target)
+	}
+	if !strings.Contains(trinity.Conn.conn.(*mockConn).output(), "Neo attacks you!") {
+		t.Error("the target should be told who attacked them")
+	}
+
+	for i := 0; i < 50 && !trinity.Ghost; i++ {
+		w.ResolveCombatRound(neo)
+	}
+	if !trinity.Ghost || neo.State != "IDLE" || !strings.Contains(neo.Conn.conn.(*mockConn).output(), "Trinity falls!") {
+		t.Errorf("PvP kill: ghost %t, state %s", trinity.Ghost, neo.State)
+	}
+}
+
+func TestEditRoomFlags(t *testing.T) {
+	w, p := setupItems(t)
+	room := w.Rooms["dojo"]
+
+	// Only builders can set flags, and only they see them in look
+	withModeration(t, "")
+	if got := w.EditRoom(p, "flag", "dark"); got != "Only builders can change room flags." || len(room.Flags) != 0 {
+		t.Errorf("edit flag as a player = %q", got)
+	}
+	if got := w.EditRoom(p, "env", "ship"); got != "Only builders can change a room's environment." {
+		t.Errorf("edit env as a player = %q", got)
+	}
+	if got := w.Look(p, ""); strings.Contains(got, "[Flags:") {
+		t.Errorf("players should not see room flags: %q", got)
+	}
+
+	withModeration(t, "Neo")
+	if got := w.EditRoom(p, "flag", "no-recall"); got != "Room flag no_recall set." {
+		t.Errorf("edit flag = %q", got)
+	}
+	w.EditRoom(p, "flag", "dark on")
+	if strings.Join(room.Flags, ",") != "dark,no_recall" {
+		t.Errorf("flags = %v", room.Flags)
+	}
+	if got := w.EditRoom(p, "flag", "dark off"); got != "Room flag dark cleared." || room.HasFlag(world.FlagDark) {
+		t.Errorf("clear flag = %q, flags %v", got, room.Flags)
+	}
+	if got := w.EditRoom(p, "flag", "haunted"); !strings.HasPrefix(got, "Unknown flag 'haunted'") {
+		t.Errorf("edit unknown flag = %q", got)
+	}
+	if got := w.EditRoom(p, "env", "ship"); got != "Room environment set to 'ship'." || room.Environment != world.EnvShip {
+		t.Errorf("edit env = %q", got)
+	}
+	if got := w.EditRoom(p, "env", "moon"); !strings.HasPrefix(got, "Unknown environment 'moon'") || room.Environment != world.EnvShip {
+		t.Errorf("edit unknown env = %q", got)
+	}
+	if w.Outdoors(p) {
+		t.Error("a ship is not outdoors")
+	}
+	if got := w.Look(p, ""); !strings.Contains(got, "[Flags: no_recall | Environment: ship]") {
+		t.Errorf("builders should see room flags: %q", got)
+	}
+}
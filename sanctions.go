@@ -0,0 +1,324 @@
+// Package main implements moderation commands and sanction enforcement.
+// Bans are enforced at accept time and at login; mutes and freezes are
+// enforced in the command loop, and mutes also in the chat manager's send
+// path. Sanctions themselves live in pkg/moderation.
+package main
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/yourusername/matrix-mud/pkg/chat"
+	"github.com/yourusername/matrix-mud/pkg/db"
+	"github.com/yourusername/matrix-mud/pkg/logging"
+	"github.com/yourusername/matrix-mud/pkg/moderation"
+)
+
+// muteCommands are the communication commands blocked by a mute. Chat
+// channel messages are also stopped by chatMuteCheck in the chat manager's
+// send path, so a mute covers every channel whichever command reaches it.
+var muteCommands = map[string]bool{
+	"say": true, "gossip": true, "chat": true,
+	"tell": true, "whisper": true, "t": true,
+	"/g": true, "/global": true, "/t": true, "/trade": true,
+	"/h": true, "/help": true, "/chat": true,
+}
+
+func init() {
+	chat.GlobalChat.SetSendCheck(chatMuteCheck)
+}
+
+// chatMuteCheck stops a muted player's channel messages
+func chatMuteCheck(name string) error {
+	if s := moderation.GlobalModeration.IsMuted(name); s != nil {
+		return fmt.Errorf("%sYou have been silenced (%s).%s", Yellow, s.Describe(), Reset)
+	}
+	return nil
+}
+
+// frozenCommands are the commands a frozen player may still use.
+// Communication commands remain available unless the player is also muted.
+var frozenCommands = map[string]bool{
+	"look": true, "l": true, "score": true, "sc": true,
+	"inv": true, "i": true, "who": true, "time": true,
+	"help": true, "?": true, "quit": true,
+}
+
+// isAdmin reports whether a player is listed in ADMIN_PLAYERS
+func isAdmin(p *Player) bool {
+	if p == nil {
+		return false
+	}
+	for _, name := range strings.Split(Config.AdminPlayers, ",") {
+		if strings.EqualFold(strings.TrimSpace(name), p.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+// remoteIP extracts the host portion of a network address
+func remoteIP(addr string) string {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	return host
+}
+
+// connectionSanction returns the ban covering a remote address, or nil.
+// WebSocket bridge sessions are checked against the browser's address.
+// Other loopback connections are never blocked.
+func connectionSanction(addr string) *moderation.Sanction {
+	ip := net.ParseIP(browserAddr(addr))
+	if ip == nil || ip.IsLoopback() {
+		return nil
+	}
+	return moderation.GlobalModeration.CheckIP(ip.String())
+}
+
+// sanctionMessage formats the notice shown to a banned connection
+func sanctionMessage(s *moderation.Sanction) string {
+	msg := fmt.Sprintf("\r\n%s[ACCESS REVOKED]%s Your signal has been blocked from the Construct (%s).\r\n", Red, Reset, s.Describe())
+	if s.Reason != "" {
+		msg += fmt.Sprintf("Reason: %s\r\n", s.Reason)
+	}
+	return msg
+}
+
+// sanctionBlocksCommand returns a message if a mute or freeze prevents cmd, or ""
+func sanctionBlocksCommand(p *Player, cmd string) string {
+	if muteCommands[cmd] {
+		if err := chatMuteCheck(p.Name); err != nil {
+			return err.Error() + "\r\n"
+		}
+		return ""
+	}
+	if !frozenCommands[cmd] {
+		if s := moderation.GlobalModeration.IsFrozen(p.Name); s != nil {
+			return fmt.Sprintf("%sYou are frozen in place by the Operators (%s).%s\r\n", Cyan, s.Describe(), Reset)
+		}
+	}
+	return ""
+}
+
+// ejectPlayer disconnects an online player by name with a message.
+// Returns true if the player was connected.
+func ejectPlayer(w *World, name, msg string) bool {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	for client, p := range w.Players {
+		if strings.EqualFold(p.Name, name) {
+			if client != nil && client.conn != nil {
+				client.Write(msg)
+				client.conn.Close()
+			}
+			delete(w.Players, client)
+			return true
+		}
+	}
+	return false
+}
+
+// ejectBannedAddresses disconnects online players whose address is now banned
+func ejectBannedAddresses(w *World) []string {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	var ejected []string
+	for client, p := range w.Players {
+		if client == nil || client.conn == nil {
+			continue
+		}
+		if s := connectionSanction(client.conn.RemoteAddr().String()); s != nil {
+			client.Write(sanctionMessage(s))
+			client.conn.Close()
+			delete(w.Players, client)
+			ejected = append(ejected, p.Name)
+		}
+	}
+	return ejected
+}
+
+// findOnlinePlayer returns a connected player by name, or nil
+func findOnlinePlayer(w *World, name string) *Player {
+	w.mutex.RLock()
+	defer w.mutex.RUnlock()
+
+	for _, p := range w.Players {
+		if strings.EqualFold(p.Name, name) {
+			return p
+		}
+	}
+	return nil
+}
+
+// parseSanctionTarget interprets the target of a ban command.
+// "ip <addr>" and "ip <cidr>" select address bans; anything else is an
+// account, including "ip" followed by something that is not an address, so
+// an account named "ip" can still be sanctioned.
+func parseSanctionTarget(args []string) (moderation.TargetKind, string, []string, error) {
+	if len(args) == 0 {
+		return "", "", nil, fmt.Errorf("target required")
+	}
+	if strings.EqualFold(args[0], "ip") && len(args) > 1 {
+		if _, _, err := net.ParseCIDR(args[1]); err == nil {
+			return moderation.TargetCIDR, args[1], args[2:], nil
+		}
+		if net.ParseIP(args[1]) != nil {
+			return moderation.TargetIP, args[1], args[2:], nil
+		}
+	}
+	return moderation.TargetAccount, args[0], args[1:], nil
+}
+
+// issueSanction applies a sanction, records it in the audit log and enforces
+// it against anyone already online. rest holds an optional duration followed
+// by the reason.
+func issueSanction(w *World, issuer string, typ moderation.SanctionType, kind moderation.TargetKind, target string, rest []string) (*moderation.Sanction, error) {
+	var duration time.Duration
+	reason := ""
+	if len(rest) > 0 {
+		if d, err := moderation.ParseDuration(rest[0]); err == nil {
+			duration = d
+			rest = rest[1:]
+		}
+		reason = strings.Join(rest, " ")
+	}
+
+	s, err := moderation.GlobalModeration.Add(typ, kind, target, reason, issuer, duration)
+	if s == nil {
+		return nil, err
+	}
+	if err != nil {
+		logging.Error().Err(err).Int64("sanction", s.ID).Msg("Sanction not persisted")
+	}
+
+	action := db.AuditAdminAction
+	if typ == moderation.SanctionBan {
+		action = db.AuditBan
+	}
+	recordAudit(issuer, action, fmt.Sprintf("%s %s %s (%s) reason=%q", typ, s.Kind, s.Target, s.Describe(), s.Reason), "")
+	logging.Info().Str("by", issuer).Str("type", string(typ)).Str("target", s.Target).Msg("Sanction issued")
+
+	switch {
+	case typ == moderation.SanctionBan && kind == moderation.TargetAccount:
+		ejectPlayer(w, s.Target, sanctionMessage(s))
+	case typ == moderation.SanctionBan:
+		ejectBannedAddresses(w)
+	case typ == moderation.SanctionFreeze:
+		if p := findOnlinePlayer(w, s.Target); p != nil {
+			w.mutex.Lock()
+			p.State = "IDLE"
+			p.Target = ""
+			w.mutex.Unlock()
+			if p.Conn != nil {
+				p.Conn.Write(fmt.Sprintf("\r\n%sThe Operators have frozen you in place.%s\r\n> ", Cyan, Reset))
+			}
+		}
+	case typ == moderation.SanctionMute:
+		if p := findOnlinePlayer(w, s.Target); p != nil && p.Conn != nil {
+			p.Conn.Write(fmt.Sprintf("\r\n%sYou have been silenced by the Operators.%s\r\n> ", Yellow, Reset))
+		}
+	}
+	return s, nil
+}
+
+// liftSanction removes a sanction and records it in the audit log
+func liftSanction(w *World, issuer string, typ moderation.SanctionType, kind moderation.TargetKind, target string) (*moderation.Sanction, error) {
+	s, err := moderation.GlobalModeration.Lift(typ, kind, target)
+	if s == nil {
+		return nil, err
+	}
+	if err != nil {
+		logging.Error().Err(err).Int64("sanction", s.ID).Msg("Sanction lift not persisted")
+	}
+
+	action := db.AuditAdminAction
+	if typ == moderation.SanctionBan {
+		action = db.AuditUnban
+	}
+	recordAudit(issuer, action, fmt.Sprintf("lift %s %s %s", typ, s.Kind, s.Target), "")
+
+	if kind == moderation.TargetAccount && typ != moderation.SanctionBan {
+		if p := findOnlinePlayer(w, s.Target); p != nil && p.Conn != nil {
+			p.Conn.Write(fmt.Sprintf("\r\n%sYour %s has been lifted.%s\r\n> ", Green, typ, Reset))
+		}
+	}
+	return s, nil
+}
+
+// formatSanctions lists active sanctions for staff
+func formatSanctions() string {
+	list := moderation.GlobalModeration.List()
+	if len(list) == 0 {
+		return "No active sanctions.\r\n"
+	}
+
+	var sb strings.Builder
+	sb.WriteString("=== ACTIVE SANCTIONS ===\r\n")
+	for _, s := range list {
+		sb.WriteString(fmt.Sprintf("  #%d %s %s %s [%s] by %s", s.ID, s.Type, s.Kind, s.Target, s.Describe(), s.IssuedBy))
+		if s.Reason != "" {
+			sb.WriteString(" - " + s.Reason)
+		}
+		sb.WriteString("\r\n")
+	}
+	return sb.String()
+}
+
+// handleSanctionCommand handles the staff moderation commands:
+// ban, unban, mute, unmute, freeze, unfreeze and sanctions.
+// args preserves the original case so reasons are recorded as typed.
+func handleSanctionCommand(w *World, admin *Player, cmd string, args []string) string {
+	if !isAdmin(admin) {
+		return "Unknown.\r\n"
+	}
+
+	if cmd == "sanctions" {
+		return formatSanctions()
+	}
+
+	typ := moderation.SanctionBan
+	switch strings.TrimPrefix(cmd, "un") {
+	case "mute":
+		typ = moderation.SanctionMute
+	case "freeze":
+		typ = moderation.SanctionFreeze
+	}
+	lifting := strings.HasPrefix(cmd, "un")
+
+	kind, target, rest, err := parseSanctionTarget(args)
+	if err != nil {
+		switch {
+		case cmd == "unban":
+			return "Usage: unban <player|ip <address|cidr>>\r\n"
+		case lifting:
+			return fmt.Sprintf("Usage: %s <player>\r\n", cmd)
+		case cmd == "ban":
+			return "Usage: ban <player|ip <address|cidr>> [duration] [reason]\r\nDurations: 30m, 12h, 7d, perm\r\n"
+		}
+		return fmt.Sprintf("Usage: %s <player> [duration] [reason]\r\nDurations: 30m, 12h, 7d, perm\r\n", cmd)
+	}
+
+	if kind == moderation.TargetAccount && strings.EqualFold(target, admin.Name) {
+		return "You cannot sanction yourself.\r\n"
+	}
+
+	if lifting {
+		s, err := liftSanction(w, admin.Name, typ, kind, target)
+		if s == nil {
+			return Red + err.Error() + Reset + "\r\n"
+		}
+		return fmt.Sprintf("%sLifted %s on %s.%s\r\n", Green, s.Type, s.Target, Reset)
+	}
+
+	s, err := issueSanction(w, admin.Name, typ, kind, target, rest)
+	if s == nil {
+		return Red + err.Error() + Reset + "\r\n"
+	}
+	return fmt.Sprintf("%sIssued %s #%d on %s (%s).%s\r\n", Green, s.Type, s.ID, s.Target, s.Describe(), Reset)
+}
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/matrix-mud/pkg/chat"
+	"github.com/yourusername/matrix-mud/pkg/moderation"
+)
+
+// withModeration swaps in a fresh sanction manager and admin list for a test
+func withModeration(t *testing.T, admins string) {
+	t.Helper()
+	origMod := moderation.GlobalModeration
+	origAdmins := Config.AdminPlayers
+	moderation.GlobalModeration = moderation.NewManager("")
+	Config.AdminPlayers = admins
+	t.Cleanup(func() {
+		moderation.GlobalModeration = origMod
+		Config.AdminPlayers = origAdmins
+	})
+}
+
+// TestIsAdmin verifies the ADMIN_PLAYERS list is matched case-insensitively
+func TestIsAdmin(t *testing.T) {
+	withModeration(t, "Morpheus, trinity")
+
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"morpheus", true},
+		{"Trinity", true},
+		{"neo", false},
+	}
+	for _, tt := range tests {
+		if got := isAdmin(&Player{Name: tt.name}); got != tt.want {
+			t.Errorf("isAdmin(%s) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+	if isAdmin(nil) {
+		t.Error("nil player should not be admin")
+	}
+}
+
+// TestSanctionBlocksCommand verifies mutes and freezes gate the right commands
+func TestSanctionBlocksCommand(t *testing.T) {
+	withModeration(t, "")
+	p := &Player{Name: "cypher"}
+
+	if msg := sanctionBlocksCommand(p, "say"); msg != "" {
+		t.Errorf("Unsanctioned player blocked: %q", msg)
+	}
+
+	moderation.GlobalModeration.Add(moderation.SanctionMute, moderation.TargetAccount, "cypher", "", "test", 0)
+	if sanctionBlocksCommand(p, "say") == "" {
+		t.Error("Muted player should not be able to say")
+	}
+	if sanctionBlocksCommand(p, "n") != "" {
+		t.Error("Mute should not block movement")
+	}
+
+	moderation.GlobalModeration.Add(moderation.SanctionFreeze, moderation.TargetAccount, "cypher", "", "test", 0)
+	if sanctionBlocksCommand(p, "n") == "" {
+		t.Error("Frozen player should not be able to move")
+	}
+	if sanctionBlocksCommand(p, "look") != "" {
+		t.Error("Frozen player should still be able to look")
+	}
+}
+
+// TestHandleSanctionCommand verifies staff-only access and issue/lift flow
+func TestHandleSanctionCommand(t *testing.T) {
+	withModeration(t, "morpheus")
+	w := &World{Players: make(map[*Client]*Player)}
+	admin := &Player{Name: "Morpheus"}
+
+	if got := handleSanctionCommand(w, &Player{Name: "neo"}, "ban", []string{"smith"}); got != "Unknown.\r\n" {
+		t.Errorf("Non-admin should get Unknown, got %q", got)
+	}
+
+	if got := handleSanctionCommand(w, admin, "ban", nil); !strings.Contains(got, "Usage") {
+		t.Errorf("Missing target should show usage, got %q", got)
+	}
+	if got := handleSanctionCommand(w, admin, "ban", []string{"morpheus"}); !strings.Contains(got, "yourself") {
+		t.Errorf("Self-ban should be refused, got %q", got)
+	}
+
+	got := handleSanctionCommand(w, admin, "ban", []string{"Smith", "7d", "Rogue", "program"})
+	if !strings.Contains(got, "Issued ban") {
+		t.Fatalf("Ban failed: %q", got)
+	}
+	s := moderation.GlobalModeration.CheckAccount("smith")
+	if s == nil || s.Permanent() || s.Reason != "Rogue program" {
+		t.Errorf("Unexpected sanction: %+v", s)
+	}
+
+	handleSanctionCommand(w, admin, "ban", []string{"ip", "10.0.0.0/8"})
+	if moderation.GlobalModeration.CheckIP("10.9.9.9") == nil {
+		t.Error("CIDR ban not applied")
+	}
+
+	// "ip" without an address is an account name
+	if got := handleSanctionCommand(w, admin, "ban", []string{"ip", "1h", "Spam"}); !strings.Contains(got, "Issued ban") {
+		t.Errorf("Ban of account ip failed: %q", got)
+	}
+	if s := moderation.GlobalModeration.CheckAccount("ip"); s == nil || s.Reason != "Spam" {
+		t.Errorf("Account ip should be banned for spam, got %+v", s)
+	}
+
+	if got := handleSanctionCommand(w, admin, "unban", []string{"smith"}); !strings.Contains(got, "Lifted") {
+		t.Errorf("Unban failed: %q", got)
+	}
+	if moderation.GlobalModeration.CheckAccount("smith") != nil {
+		t.Error("Ban should be lifted")
+	}
+}
+
+// TestConnectionSanctionSkipsLoopback verifies bridge connections are not blocked
+func TestConnectionSanctionSkipsLoopback(t *testing.T) {
+	withModeration(t, "")
+	moderation.GlobalModeration.Add(moderation.SanctionBan, moderation.TargetCIDR, "127.0.0.0/8", "", "test", 0)
+	moderation.GlobalModeration.Add(moderation.SanctionBan, moderation.TargetIP, "203.0.113.7", "", "test", 0)
+
+	if connectionSanction("127.0.0.1:5555") != nil {
+		t.Error("Loopback should never be blocked")
+	}
+	if connectionSanction("203.0.113.7:4000") == nil {
+		t.Error("Banned address should be blocked")
+	}
+}
+
+// TestBridgedSessionBan verifies web client sessions are checked, and
+// ejected, by the browser's address rather than the bridge's loopback one
+func TestBridgedSessionBan(t *testing.T) {
+	withModeration(t, "")
+	bridgedAddrs.Store("127.0.0.1:12345", "203.0.113.9")
+	t.Cleanup(func() { bridgedAddrs.Delete("127.0.0.1:12345") })
+	p := &Player{Name: "Cypher", Conn: &Client{conn: newMockConn("")}}
+	w := &World{Players: map[*Client]*Player{p.Conn: p}}
+
+	if got := browserAddr(p.Conn.conn.RemoteAddr().String()); got != "203.0.113.9" {
+		t.Errorf("address of a bridged session = %q", got)
+	}
+	if ejected := ejectBannedAddresses(w); len(ejected) != 0 {
+		t.Errorf("ejected %v before any ban", ejected)
+	}
+	moderation.GlobalModeration.Add(moderation.SanctionBan, moderation.TargetIP, "203.0.113.9", "", "test", 0)
+	if ejected := ejectBannedAddresses(w); len(ejected) != 1 || ejected[0] != "Cypher" || !p.Conn.conn.(*mockConn).closed {
+		t.Errorf("bridged session not ejected: %v", ejected)
+	}
+}
+
+// TestMuteCoversEveryChannel verifies a mute silences every chat command
+// and every chat channel
+func TestMuteCoversEveryChannel(t *testing.T) {
+	withModeration(t, "")
+	p := &Player{Name: "Mouse"}
+	var channels []string
+	for id := range chat.GlobalChat.Channels {
+		chat.GlobalChat.JoinChannel(p.Name, id)
+		channels = append(channels, id)
+	}
+	t.Cleanup(func() {
+		for _, id := range channels {
+			chat.GlobalChat.LeaveChannel(p.Name, id)
+		}
+	})
+	moderation.GlobalModeration.Add(moderation.SanctionMute, moderation.TargetAccount, "mouse", "", "test", 0)
+
+	for cmd := range muteCommands {
+		if !strings.Contains(sanctionBlocksCommand(p, cmd), "silenced") {
+			t.Errorf("mute should block %s", cmd)
+		}
+	}
+	for _, id := range channels {
+		if _, err := chat.GlobalChat.SendMessage(p.Name, id, "hello"); err == nil || !strings.Contains(err.Error(), "silenced") {
+			t.Errorf("mute should block channel %s: %v", id, err)
+		}
+	}
+}
+
+// TestAdminSanctionHandlers verifies issuing and lifting from the console
+func TestAdminSanctionHandlers(t *testing.T) {
+	withModeration(t, "")
+	origUser, origPass, origWorld := Config.AdminUser, Config.AdminPass, adminWorld
+	defer func() {
+		Config.AdminUser, Config.AdminPass, adminWorld = origUser, origPass, origWorld
+	}()
+	Config.AdminUser = "testadmin"
+	Config.AdminPass = "testpass"
+	adminWorld = &World{Players: make(map[*Client]*Player)}
+
+	post := func(handler http.HandlerFunc, path string, form url.Values) *httptest.ResponseRecorder {
+		form.Set("csrf", csrfToken())
+		req := httptest.NewRequest("POST", path, strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.SetBasicAuth("testadmin", "testpass")
+		w := httptest.NewRecorder()
+		handler(w, req)
+		return w
+	}
+
+	req := httptest.NewRequest("GET", "/sanctions/add", nil)
+	req.SetBasicAuth("testadmin", "testpass")
+	w := httptest.NewRecorder()
+	adminSanctionAdd(w, req)
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET should be rejected, got %d", w.Code)
+	}
+
+	w = post(adminSanctionAdd, "/sanctions/add", url.Values{
+		"type": {"mute"}, "kind": {"account"}, "target": {"mouse"}, "duration": {"1h"}, "reason": {"caps lock"},
+	})
+	if w.Code != http.StatusSeeOther {
+		t.Fatalf("Expected redirect, got %d: %s", w.Code, w.Body.String())
+	}
+	s := moderation.GlobalModeration.IsMuted("mouse")
+	if s == nil || s.Reason != "caps lock" {
+		t.Fatalf("Mute not issued: %+v", s)
+	}
+
+	req = httptest.NewRequest("GET", "/sanctions", nil)
+	req.SetBasicAuth("testadmin", "testpass")
+	w = httptest.NewRecorder()
+	adminSanctions(w, req)
+	if !strings.Contains(w.Body.String(), "mouse") {
+		t.Error("Sanctions page should list the mute")
+	}
+
+	if w := post(adminSanctionLift, "/sanctions/lift", url.Values{"id": {"999"}}); w.Code != http.StatusNotFound {
+		t.Errorf("Unknown ID should 404, got %d", w.Code)
+	}
+	post(adminSanctionLift, "/sanctions/lift", url.Values{"id": {"1"}})
+	if moderation.GlobalModeration.IsMuted("mouse") != nil {
+		t.Error("Mute should be lifted")
+	}
+}
@@ -0,0 +1,672 @@
+// Package main connects builder scripts (pkg/script) to the world. Scripts
+// are attached in world.json and items.json under "scripts", keyed by
+// trigger:
+//
+//	Rooms: enter, say, say:<keyword>
+//	NPCs:  greet, death, tick, give
+//	Items: use, wear
+//
+// Triggers are queued and run in the world tick with the world lock held,
+// so scripts never race player commands. Each tick has a budget for script
+// work; what doesn't fit waits for the next tick. The API they can call is
+// documented in docs/SCRIPTING.md.
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yourusername/matrix-mud/pkg/effects"
+	"github.com/yourusername/matrix-mud/pkg/logging"
+	"github.com/yourusername/matrix-mud/pkg/quest"
+	"github.com/yourusername/matrix-mud/pkg/script"
+)
+
+// maxPendingScripts caps the triggers waiting for the next tick; beyond it
+// new triggers are dropped so a runaway script chain cannot stall the world
+const maxPendingScripts = 1000
+
+// scriptTickSteps and scriptTickTime bound the script work in one tick,
+// across every trigger, so scripts can't hold the world lock for long
+const (
+	scriptTickSteps = 50000
+	scriptTickTime  = 50 * time.Millisecond
+)
+
+// scriptEvent is one trigger waiting to run
+type scriptEvent struct {
+	owner   string // "room dojo", "npc oracle", "item red_pill"
+	self    string // ID of the room, NPC or item
+	trigger string
+	code    string
+	player  *Player // Who caused it; nil for tick triggers
+	roomID  string
+	npc     *NPC // Set for NPC scripts
+	arg     string
+}
+
+// scriptQueue holds triggers until the next tick
+type scriptQueue struct {
+	mu       sync.Mutex
+	pending  []scriptEvent
+	spawned  int // Counter for the IDs of spawned NPCs
+	nextTick int // Where the next round of NPC tick triggers starts
+}
+
+// scriptBudget is the script work left in a tick
+type scriptBudget struct {
+	steps    int
+	deadline time.Time
+}
+
+// spent reports whether the tick's script work is used up
+func (b *scriptBudget) spent() bool {
+	return b.steps <= 0 || time.Now().After(b.deadline)
+}
+
+// scriptPrograms caches parsed scripts by source
+var scriptPrograms = struct {
+	mu    sync.Mutex
+	progs map[string]*script.Program
+}{progs: make(map[string]*script.Program)}
+
+// compileScript parses code, reusing an earlier parse of the same source
+func compileScript(code string) (*script.Program, error) {
+	scriptPrograms.mu.Lock()
+	defer scriptPrograms.mu.Unlock()
+	if prog, ok := scriptPrograms.progs[code]; ok {
+		return prog, nil
+	}
+	prog, err := script.Parse(code)
+	if err != nil {
+		return nil, err
+	}
+	scriptPrograms.progs[code] = prog
+	return prog, nil
+}
+
+// matchTrigger reports whether a script key fires for event. "say:<keyword>"
+// only fires when the message contains the keyword.
+func matchTrigger(key, event, arg string) bool {
+	name, keyword, hasKeyword := strings.Cut(key, ":")
+	if name != event {
+		return false
+	}
+	return !hasKeyword || strings.Contains(strings.ToLower(arg), strings.ToLower(strings.TrimSpace(keyword)))
+}
+
+// queueScripts queues the scripts in scripts that fire for event
+func (w *World) queueScripts(scripts map[string]string, ev scriptEvent) {
+	for key, code := range scripts {
+		if !matchTrigger(key, ev.trigger, ev.arg) {
+			continue
+		}
+		ev.code = code
+		w.scripts.mu.Lock()
+		if len(w.scripts.pending) >= maxPendingScripts {
+			w.scripts.mu.Unlock()
+			logging.Warn().Str("owner", ev.owner).Str("trigger", key).Msg("Script queue full, dropping trigger")
+			return
+		}
+		w.scripts.pending = append(w.scripts.pending, ev)
+		w.scripts.mu.Unlock()
+	}
+}
+
+// fireRoomScripts queues a room's triggers for event
+func (w *World) fireRoomScripts(room *Room, event string, p *Player, arg string) {
+	if room == nil || len(room.Scripts) == 0 {
+		return
+	}
+	w.queueScripts(room.Scripts, scriptEvent{owner: "room " + room.ID, self: room.ID, trigger: event, player: p, roomID: room.ID, arg: arg})
+}
+
+// fireNPCScripts queues an NPC's triggers for event
+func (w *World) fireNPCScripts(npc *NPC, event string, p *Player, arg string) {
+	if npc == nil || len(npc.Scripts) == 0 {
+		return
+	}
+	w.queueScripts(npc.Scripts, scriptEvent{owner: "npc " + npc.ID, self: npc.ID, trigger: event, player: p, roomID: npc.RoomID, npc: npc, arg: arg})
+}
+
+// fireItemScripts queues an item's triggers for event
+func (w *World) fireItemScripts(item *Item, event string, p *Player) {
+	if item == nil || len(item.Scripts) == 0 {
+		return
+	}
+	w.queueScripts(item.Scripts, scriptEvent{owner: "item " + item.ID, self: item.ID, trigger: event, player: p, roomID: p.RoomID, arg: item.ID})
+}
+
+// playerEntered fires the enter trigger of the room p just arrived in, by
+// any means, and the greet triggers of the NPCs there. The world lock must
+// be held.
+func (w *World) playerEntered(p *Player, from string) {
+	room := w.Rooms[p.RoomID]
+	if room == nil {
+		return
+	}
+	w.fireRoomScripts(room, "enter", p, from)
+	for _, npc := range room.NPCMap {
+		if !npc.IsDead {
+			w.fireNPCScripts(npc, "greet", p, "")
+		}
+	}
+}
+
+// runScripts runs what was queued since the last tick and then the NPC tick
+// triggers, within the tick's script budget. Queued triggers the budget
+// doesn't reach, and those queued by these scripts, wait for the next tick.
+// The world lock must be held.
+func (w *World) runScripts() {
+	budget := scriptBudget{steps: scriptTickSteps, deadline: time.Now().Add(scriptTickTime)}
+
+	w.scripts.mu.Lock()
+	pending := w.scripts.pending
+	w.scripts.pending = nil
+	w.scripts.mu.Unlock()
+
+	ran := 0
+	for ; ran < len(pending) && !budget.spent(); ran++ {
+		budget.steps -= w.runScript(pending[ran])
+	}
+	if ran < len(pending) {
+		w.scripts.mu.Lock()
+		w.scripts.pending = append(pending[ran:], w.scripts.pending...)
+		w.scripts.mu.Unlock()
+	}
+
+	w.runTickScripts(&budget)
+}
+
+// runTickScripts runs NPC tick triggers with what is left of the budget.
+// They run directly rather than through the queue, so they can't crowd out
+// the triggers players cause, and each tick starts where the last one ran
+// out so every NPC gets its turn.
+func (w *World) runTickScripts(budget *scriptBudget) {
+	var ticks []scriptEvent
+	for _, room := range w.Rooms {
+		for _, npc := range room.NPCMap {
+			if code, ok := npc.Scripts["tick"]; ok && !npc.IsDead {
+				ticks = append(ticks, scriptEvent{owner: "npc " + npc.ID, self: npc.ID, trigger: "tick", code: code, roomID: npc.RoomID, npc: npc})
+			}
+		}
+	}
+	if len(ticks) == 0 {
+		return
+	}
+	sort.Slice(ticks, func(i, j int) bool {
+		if ticks[i].roomID != ticks[j].roomID {
+			return ticks[i].roomID < ticks[j].roomID
+		}
+		return ticks[i].self < ticks[j].self
+	})
+
+	start := w.scripts.nextTick % len(ticks)
+	ran := 0
+	for ; ran < len(ticks) && !budget.spent(); ran++ {
+		budget.steps -= w.runScript(ticks[(start+ran)%len(ticks)])
+	}
+	w.scripts.nextTick = (start + ran) % len(ticks)
+}
+
+// runScript runs one trigger, delivers what it sent and returns the steps
+// it took
+func (w *World) runScript(ev scriptEvent) int {
+	prog, err := compileScript(ev.code)
+	if err != nil {
+		logging.Warn().Err(err).Str("owner", ev.owner).Str("trigger", ev.trigger).Msg("Script does not parse")
+		return 0
+	}
+	host := &scriptHost{w: w, ev: ev, out: make(map[*Player]*strings.Builder)}
+	steps, err := prog.RunSteps(host.env())
+	if err != nil {
+		logging.Warn().Err(err).Str("owner", ev.owner).Str("trigger", ev.trigger).Msg("Script failed")
+	}
+	host.flush()
+	return steps
+}
+
+// checkScripts parses every script in the world and item templates at load
+// so builders see mistakes in the log straight away
+func (w *World) checkScripts() {
+	check := func(owner string, scripts map[string]string) {
+		for key, code := range scripts {
+			if _, err := compileScript(code); err != nil {
+				logging.Error().Err(err).Str("owner", owner).Str("trigger", key).Msg("Script does not parse")
+			}
+		}
+	}
+	for _, room := range w.Rooms {
+		check("room "+room.ID, room.Scripts)
+		for _, npc := range room.NPCMap {
+			check("npc "+npc.ID, npc.Scripts)
+		}
+		for _, item := range room.ItemMap {
+			check("item "+item.ID, item.Scripts)
+		}
+	}
+	for id, tmpl := range w.ItemTemplates {
+		check("item "+id, tmpl.Scripts)
+	}
+}
+
+// scriptHost implements the script API for one run
+type scriptHost struct {
+	w   *World
+	ev  scriptEvent
+	out map[*Player]*strings.Builder // Output gathered per player
+}
+
+// write queues text for a player, sent when the script finishes
+func (h *scriptHost) write(p *Player, text string) {
+	if p == nil {
+		return
+	}
+	b, ok := h.out[p]
+	if !ok {
+		b = &strings.Builder{}
+		h.out[p] = b
+	}
+	b.WriteString(text + "\r\n")
+}
+
+func (h *scriptHost) flush() {
+	for p, b := range h.out {
+		if p.Conn != nil {
+			p.Conn.Write(Matrixify("\r\n" + b.String() + "> "))
+		}
+	}
+}
+
+// room returns the room the script acts in: the actor's, else its own
+func (h *scriptHost) room() *Room {
+	if h.ev.player != nil {
+		return h.w.Rooms[h.ev.player.RoomID]
+	}
+	if h.ev.npc != nil {
+		return h.w.Rooms[h.ev.npc.RoomID]
+	}
+	return h.w.Rooms[h.ev.roomID]
+}
+
+// toRoom queues text for everyone in room except the actor
+func (h *scriptHost) toRoom(room *Room, text string) {
+	if room == nil {
+		return
+	}
+	for _, p := range h.w.Players {
+		if p.RoomID == room.ID && p != h.ev.player {
+			h.write(p, text)
+		}
+	}
+}
+
+// actor returns the player who caused the trigger, or an error for
+// functions that need one
+func (h *scriptHost) actor() (*Player, error) {
+	if h.ev.player == nil {
+		return nil, fmt.Errorf("no actor for a %s trigger", h.ev.trigger)
+	}
+	return h.ev.player, nil
+}
+
+func (h *scriptHost) env() *script.Env {
+	vars := map[string]script.Value{
+		"self":  h.ev.self,
+		"event": h.ev.trigger,
+		"arg":   h.ev.arg,
+		"actor": nil,
+		"room":  h.ev.roomID,
+	}
+	if h.ev.player != nil {
+		vars["actor"] = h.ev.player.Name
+		vars["room"] = h.ev.player.RoomID
+	}
+	return &script.Env{
+		Vars: vars,
+		Funcs: map[string]script.Func{
+			"send":       h.send,
+			"echo":       h.echo,
+			"say":        h.say,
+			"move":       h.move,
+			"give":       h.give,
+			"take":       h.take,
+			"has":        h.has,
+			"spawn":      h.spawn,
+			"setflag":    h.setFlag,
+			"clearflag":  h.clearFlag,
+			"flag":       h.flag,
+			"startquest": h.startQuest,
+			"level":      h.level,
+			"random":     h.random,
+			"contains":   h.contains,
+			"lower":      h.lower,
+		},
+	}
+}
+
+// send(msg) tells the actor msg
+func (h *scriptHost) send(args []script.Value) (script.Value, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("takes one argument")
+	}
+	h.write(h.ev.player, script.String(args[0]))
+	return nil, nil
+}
+
+// echo(msg) tells everyone else in the room msg
+func (h *scriptHost) echo(args []script.Value) (script.Value, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("takes one argument")
+	}
+	h.toRoom(h.room(), script.String(args[0]))
+	return nil, nil
+}
+
+// say(msg) makes the NPC running the script speak to its room
+func (h *scriptHost) say(args []script.Value) (script.Value, error) {
+	if h.ev.npc == nil {
+		return nil, fmt.Errorf("only NPC scripts can say; use echo")
+	}
+	msg, err := script.StringArg(args, 0)
+	if err != nil {
+		return nil, err
+	}
+	line := fmt.Sprintf("%s%s says: \"%s\"%s", White, h.ev.npc.Name, msg, Green)
+	room := h.w.Rooms[h.ev.npc.RoomID]
+	if h.ev.player != nil && h.ev.player.RoomID == h.ev.npc.RoomID {
+		h.write(h.ev.player, line)
+	}
+	h.toRoom(room, line)
+	return nil, nil
+}
+
+// move(room_id) moves the actor, firing the new room's triggers next tick
+func (h *scriptHost) move(args []script.Value) (script.Value, error) {
+	p, err := h.actor()
+	if err != nil {
+		return nil, err
+	}
+	dest, err := script.StringArg(args, 0)
+	if err != nil {
+		return nil, err
+	}
+	room := h.w.Rooms[dest]
+	if room == nil {
+		return nil, fmt.Errorf("no room %q", dest)
+	}
+	from := p.RoomID
+	p.RoomID = dest
+	p.State = "IDLE"
+	h.write(p, room.Description)
+	h.w.playerEntered(p, from)
+	return nil, nil
+}
+
+// give(item_id) gives the actor a new item made from a template. It
+// returns false if their inventory is full.
+func (h *scriptHost) give(args []script.Value) (script.Value, error) {
+	p, err := h.actor()
+	if err != nil {
+		return nil, err
+	}
+	id, err := script.StringArg(args, 0)
+	if err != nil {
+		return nil, err
+	}
+	tmpl := h.w.ItemTemplates[id]
+	if tmpl == nil {
+		return nil, fmt.Errorf("no item template %q", id)
+	}
+	item := h.w.newItem(tmpl, 1)
+	if !canCarry(p, item) {
+		return false, nil
+	}
+	p.Inventory = addItem(p.Inventory, item)
+	return true, nil
+}
+
+// scriptItemMatches reports whether item is id or an instance of template id
+func scriptItemMatches(item *Item, id string) bool {
+	return item.TemplateID() == id || item.ID == id || strings.HasPrefix(item.ID, id+"_")
+}
+
+// take(item_id) removes one matching item from the actor's inventory and
+// reports whether there was one
+func (h *scriptHost) take(args []script.Value) (script.Value, error) {
+	p, err := h.actor()
+	if err != nil {
+		return nil, err
+	}
+	id, err := script.StringArg(args, 0)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range p.Inventory {
+		if scriptItemMatches(item, id) {
+			if h.w.take(pick{item, 1}) == item {
+				p.Inventory = removeItem(p.Inventory, item)
+			}
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// has(item_id) reports whether the actor carries or wears a matching item
+func (h *scriptHost) has(args []script.Value) (script.Value, error) {
+	p, err := h.actor()
+	if err != nil {
+		return nil, err
+	}
+	id, err := script.StringArg(args, 0)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range p.Inventory {
+		if scriptItemMatches(item, id) {
+			return true, nil
+		}
+	}
+	for _, item := range p.Equipment {
+		if item != nil && scriptItemMatches(item, id) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// spawn(npc_id[, room_id]) copies an NPC defined anywhere in the world into
+// a room (the script's by default) and returns the copy's ID. Spawned NPCs
+// do not respawn when killed.
+func (h *scriptHost) spawn(args []script.Value) (script.Value, error) {
+	id, err := script.StringArg(args, 0)
+	if err != nil {
+		return nil, err
+	}
+	room := h.room()
+	if len(args) > 1 {
+		roomID, err := script.StringArg(args, 1)
+		if err != nil {
+			return nil, err
+		}
+		room = h.w.Rooms[roomID]
+		if room == nil {
+			return nil, fmt.Errorf("no room %q", roomID)
+		}
+	}
+	if room == nil {
+		return nil, fmt.Errorf("no room to spawn in")
+	}
+
+	var proto *NPC
+	for _, r := range h.w.Rooms {
+		if npc, ok := r.NPCMap[id]; ok {
+			proto = npc
+			break
+		}
+	}
+	if proto == nil {
+		for _, npc := range h.w.DeadNPCs {
+			if npc.ID == id {
+				proto = npc
+				break
+			}
+		}
+	}
+	if proto == nil {
+		return nil, fmt.Errorf("no NPC %q", id)
+	}
+
+	h.w.scripts.mu.Lock()
+	h.w.scripts.spawned++
+	n := h.w.scripts.spawned
+	h.w.scripts.mu.Unlock()
+
+	npc := *proto
+	npc.ID = fmt.Sprintf("%s_spawn%d", proto.ID, n)
+	npc.RoomID = room.ID
+	npc.OriginalRoom = room.ID
+	npc.HP = npc.MaxHP
+	npc.State = "IDLE"
+	npc.IsDead = false
+	npc.Spawned = true
+	npc.Effects = effects.Set{}
+	npc.Threat = nil
+	npc.CombatTarget = ""
+	if room.NPCMap == nil {
+		room.NPCMap = make(map[string]*NPC)
+	}
+	room.NPCMap[npc.ID] = &npc
+	h.toRoom(room, fmt.Sprintf("%s appears.", npc.Name))
+	if h.ev.player != nil && h.ev.player.RoomID == room.ID {
+		h.write(h.ev.player, fmt.Sprintf("%s appears.", npc.Name))
+	}
+	return npc.ID, nil
+}
+
+// setflag(name[, value]) stores a flag on the actor; the value defaults to true
+func (h *scriptHost) setFlag(args []script.Value) (script.Value, error) {
+	p, err := h.actor()
+	if err != nil {
+		return nil, err
+	}
+	name, err := script.StringArg(args, 0)
+	if err != nil {
+		return nil, err
+	}
+	var value script.Value = true
+	if len(args) > 1 {
+		value = args[1]
+	}
+	if value == nil {
+		delete(p.Flags, name)
+		return nil, nil
+	}
+	if p.Flags == nil {
+		p.Flags = make(map[string]string)
+	}
+	p.Flags[name] = script.String(value)
+	return nil, nil
+}
+
+// clearflag(name) removes a flag from the actor
+func (h *scriptHost) clearFlag(args []script.Value) (script.Value, error) {
+	p, err := h.actor()
+	if err != nil {
+		return nil, err
+	}
+	name, err := script.StringArg(args, 0)
+	if err != nil {
+		return nil, err
+	}
+	delete(p.Flags, name)
+	return nil, nil
+}
+
+// flag(name) returns the actor's flag, or nil. Numbers and booleans come
+// back as they were stored.
+func (h *scriptHost) flag(args []script.Value) (script.Value, error) {
+	p, err := h.actor()
+	if err != nil {
+		return nil, err
+	}
+	name, err := script.StringArg(args, 0)
+	if err != nil {
+		return nil, err
+	}
+	s, ok := p.Flags[name]
+	if !ok {
+		return nil, nil
+	}
+	if n, err := strconv.Atoi(s); err == nil && strconv.Itoa(n) == s {
+		return n, nil
+	}
+	if b, err := strconv.ParseBool(s); err == nil && (s == "true" || s == "false") {
+		return b, nil
+	}
+	return s, nil
+}
+
+// startquest(quest_id) starts a quest for the actor, telling them its
+// opening dialogue, and reports whether it started
+func (h *scriptHost) startQuest(args []script.Value) (script.Value, error) {
+	p, err := h.actor()
+	if err != nil {
+		return nil, err
+	}
+	id, err := script.StringArg(args, 0)
+	if err != nil {
+		return nil, err
+	}
+	if ok, _ := quest.GlobalQuests.CanStart(p.Name, id, p.Level); !ok {
+		return false, nil
+	}
+	dialogue, err := quest.GlobalQuests.StartQuest(p.Name, id)
+	if err != nil || dialogue == "" {
+		return false, err
+	}
+	h.write(p, Yellow+dialogue+Green)
+	return true, nil
+}
+
+// level() returns the actor's level
+func (h *scriptHost) level(args []script.Value) (script.Value, error) {
+	p, err := h.actor()
+	if err != nil {
+		return nil, err
+	}
+	return p.Level, nil
+}
+
+// random(n) rolls a number from 1 to n with the world's random source
+func (h *scriptHost) random(args []script.Value) (script.Value, error) {
+	n, err := script.IntArg(args, 0)
+	if err != nil {
+		return nil, err
+	}
+	if n < 1 {
+		return nil, fmt.Errorf("needs a positive number")
+	}
+	return h.w.rng().Intn(n) + 1, nil
+}
+
+// contains(s, sub) reports whether s contains sub, ignoring case
+func (h *scriptHost) contains(args []script.Value) (script.Value, error) {
+	if len(args) != 2 {
+		return nil, fmt.Errorf("takes two arguments")
+	}
+	return strings.Contains(strings.ToLower(script.String(args[0])), strings.ToLower(script.String(args[1]))), nil
+}
+
+// lower(s) returns s in lower case
+func (h *scriptHost) lower(args []script.Value) (script.Value, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("takes one argument")
+	}
+	return strings.ToLower(script.String(args[0])), nil
+}
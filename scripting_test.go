@@ -0,0 +1,290 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// setupScripting creates a world with a scripted dojo, a street and the
+// Oracle, and Neo standing in the street
+func setupScripting(t *testing.T) (*World, *Player) {
+	t.Helper()
+	oracle := &NPC{ID: "oracle", Name: "The Oracle", RoomID: "dojo", OriginalRoom: "dojo", HP: 10, MaxHP: 10, State: "IDLE",
+		Scripts: map[string]string{
+			"greet": `
+let visits = flag("oracle_visits")
+if visits == nil then
+  say("I've been expecting you, " .. actor .. ".")
+  setflag("oracle_visits", 1)
+else
+  setflag("oracle_visits", visits + 1)
+  say("Back again? That makes " .. (visits + 1) .. ".")
+end`,
+			"give": `
+if contains(arg, "cookie") then
+  say("Thank you.")
+  give("pill")
+  startquest("missing_quest")
+else
+  say("I don't want that.")
+end`,
+			"death": `spawn("oracle"); echo("The Oracle flickers back.")`,
+		}}
+	w := &World{
+		Rooms: map[string]*Room{
+			"street": {ID: "street", Description: "A street.", Exits: map[string]*Exit{"south": {To: "dojo"}}, ItemMap: map[string]*Item{}, NPCMap: map[string]*NPC{}},
+			"dojo": {ID: "dojo", Description: "A dojo.", Exits: map[string]*Exit{"north": {To: "street"}}, ItemMap: map[string]*Item{}, NPCMap: map[string]*NPC{"oracle": oracle},
+				Scripts: map[string]string{
+					"enter":           `send("The floor creaks as you arrive from " .. arg .. ".")`,
+					"say:open sesame": `send("A hidden door swings open."); move("vault")`,
+					"say":             `if contains(arg, "hello") then echo(actor .. " waves.") end`,
+				}},
+			"vault": {ID: "vault", Description: "A vault full of code.", Exits: map[string]*Exit{}, ItemMap: map[string]*Item{}, NPCMap: map[string]*NPC{}},
+		},
+		Players: map[*Client]*Player{},
+		ItemTemplates: map[string]*Item{
+			"pill":   {ID: "pill", Name: "Red Pill", Type: "consumable", Effect: "heal", Value: 1},
+			"cookie": {ID: "cookie", Name: "Cookie"},
+			"mirror": {ID: "mirror", Name: "Mirror", Scripts: map[string]string{"use": `send("You see " .. actor .. " at level " .. level() .. "."); take(self)`}},
+		},
+	}
+	neo := &Player{Name: "Neo", RoomID: "street", HP: 10, MaxHP: 10, Level: 3, State: "IDLE", Equipment: map[string]*Item{},
+		Conn: &Client{conn: newMockConn("")}}
+	w.Players[neo.Conn] = neo
+	return w, neo
+}
+
+func TestScriptsRunOnTick(t *testing.T) {
+	w, neo := setupScripting(t)
+	out := neo.Conn.conn.(*mockConn)
+
+	w.MovePlayer(neo, "south")
+	if got := out.output(); strings.Contains(got, "creaks") {
+		t.Fatalf("script ran before the tick: %q", got)
+	}
+	w.Update()
+	got := out.output()
+	if !strings.Contains(got, "The floor creaks as you arrive from street.") {
+		t.Errorf("enter trigger output = %q", got)
+	}
+	if !strings.Contains(got, `The Oracle says: "I've been expecting you, Neo."`) {
+		t.Errorf("greet trigger output = %q", got)
+	}
+	if neo.Flags["oracle_visits"] != "1" {
+		t.Errorf("flags = %v", neo.Flags)
+	}
+
+	// Flags keep their numbers across visits
+	w.MovePlayer(neo, "north")
+	w.MovePlayer(neo, "south")
+	w.Update()
+	if got := out.output(); !strings.Contains(got, "That makes 2.") {
+		t.Errorf("second greet = %q", got)
+	}
+}
+
+func TestSayKeywordTrigger(t *testing.T) {
+	w, neo := setupScripting(t)
+	trinity := &Player{Name: "Trinity", RoomID: "dojo", State: "IDLE", Conn: &Client{conn: newMockConn("")}}
+	w.Players[trinity.Conn] = trinity
+	neo.RoomID = "dojo"
+
+	w.HandleSay(neo, "hello there")
+	w.Update()
+	if got := trinity.Conn.conn.(*mockConn).output(); !strings.Contains(got, "Neo waves.") {
+		t.Errorf("say trigger for others = %q", got)
+	}
+	if neo.RoomID != "dojo" {
+		t.Fatal("plain say moved the player")
+	}
+
+	w.HandleSay(neo, "Open Sesame!")
+	w.Update()
+	if neo.RoomID != "vault" {
+		t.Errorf("keyword trigger left Neo in %s", neo.RoomID)
+	}
+	if got := neo.Conn.conn.(*mockConn).output(); !strings.Contains(got, "A hidden door swings open.") || !strings.Contains(got, "A vault full of code.") {
+		t.Errorf("keyword trigger output = %q", got)
+	}
+}
+
+func TestGiveAndDeathTriggers(t *testing.T) {
+	w, neo := setupScripting(t)
+	neo.RoomID = "dojo"
+	cookie := *w.ItemTemplates["cookie"]
+	cookie.ID = "cookie_42"
+	neo.Inventory = []*Item{&cookie}
+
+	if got := w.GiveItem(neo, "cookie", "oracle"); !strings.Contains(got, "You give") {
+		t.Fatalf("give = %q", got)
+	}
+	w.Update()
+	if len(neo.Inventory) != 1 || !strings.HasPrefix(neo.Inventory[0].ID, "pill_") {
+		t.Errorf("inventory after give = %+v", neo.Inventory)
+	}
+	if got := neo.Conn.conn.(*mockConn).output(); !strings.Contains(got, "Thank you.") {
+		t.Errorf("give output = %q", got)
+	}
+
+	// Killing the Oracle spawns a copy, which does not respawn itself
+	kill := func(npc *NPC) {
+		npc.HP = 1
+		for i := 0; i < 50 && !npc.IsDead; i++ {
+			neo.State = "COMBAT"
+			neo.Target = npc.ID
+			w.ResolveCombatRound(neo)
+		}
+		if !npc.IsDead {
+			t.Fatalf("%s survived", npc.ID)
+		}
+	}
+	kill(w.Rooms["dojo"].NPCMap["oracle"])
+	w.Update()
+	var spawned *NPC
+	for id, npc := range w.Rooms["dojo"].NPCMap {
+		if strings.HasPrefix(id, "oracle_spawn") {
+			spawned = npc
+		}
+	}
+	if spawned == nil || !spawned.Spawned || spawned.HP != spawned.MaxHP {
+		t.Fatalf("spawned NPC = %+v", spawned)
+	}
+
+	kill(spawned)
+	for _, npc := range w.DeadNPCs {
+		if npc == spawned {
+			t.Error("a spawned NPC was queued to respawn")
+		}
+	}
+}
+
+func TestItemUseTrigger(t *testing.T) {
+	w, neo := setupScripting(t)
+	mirror := *w.ItemTemplates["mirror"]
+	mirror.ID = "mirror_7"
+	neo.Inventory = []*Item{&mirror}
+
+	if got := w.UseItem(neo, "mirror"); !strings.Contains(got, "You use") {
+		t.Fatalf("use = %q", got)
+	}
+	w.Update()
+	if got := neo.Conn.conn.(*mockConn).output(); !strings.Contains(got, "You see Neo at level 3.") {
+		t.Errorf("use output = %q", got)
+	}
+	if len(neo.Inventory) != 0 {
+		t.Errorf("take(self) left %+v", neo.Inventory)
+	}
+}
+
+func TestRunawayScriptIsStopped(t *testing.T) {
+	w, neo := setupScripting(t)
+	w.Rooms["street"].NPCMap["loop"] = &NPC{ID: "loop", Name: "Loop", RoomID: "street",
+		Scripts: map[string]string{"tick": `send("never"); while true do end`}}
+	w.Rooms["street"].Scripts = map[string]string{"enter": `explode(`}
+
+	w.Update() // Must return despite the infinite loop
+	if got := neo.Conn.conn.(*mockConn).output(); strings.Contains(got, "never") {
+		t.Errorf("tick script without an actor reached a player: %q", got)
+	}
+
+	w.playerEntered(neo, "dojo")
+	w.Update() // The parse error is logged, not fatal
+}
+
+func TestEnterTriggerOnEveryMove(t *testing.T) {
+	w, neo := setupScripting(t)
+	out := neo.Conn.conn.(*mockConn)
+	arrivals := func() int {
+		w.Update()
+		return strings.Count(out.output(), "The floor creaks")
+	}
+
+	w.Recall(neo)
+	if arrivals() != 1 {
+		t.Error("recall should fire the enter trigger")
+	}
+	neo.RoomID = "street"
+	if err := w.Teleport(neo, "dojo"); err != nil || arrivals() != 2 {
+		t.Errorf("teleport should fire the enter trigger: %v", err)
+	}
+	neo.RoomID = "street"
+	w.Rooms["street"].HasPhone, w.Rooms["dojo"].HasPhone = true, true
+	neo.DiscoveredPhones = []string{"dojo"}
+	w.CallPhone(neo, "dojo")
+	if arrivals() != 3 {
+		t.Error("a phone call should fire the enter trigger")
+	}
+}
+
+func TestScriptTickBudget(t *testing.T) {
+	w, neo := setupScripting(t)
+	out := neo.Conn.conn.(*mockConn)
+	busy := `let n = 1000
+while n > 0 do n = n - 1 end
+say("done")`
+	for i := 0; i < 30; i++ {
+		id := fmt.Sprintf("bot%02d", i)
+		w.Rooms["street"].NPCMap[id] = &NPC{ID: id, Name: "Bot" + id[3:], RoomID: "street", HP: 10, MaxHP: 10, Scripts: map[string]string{"tick": busy}}
+	}
+	w.Rooms["dojo"].Scripts["say"] = "let n = 1000\nwhile n > 0 do n = n - 1 end\nsend(\"Heard.\")"
+	w.Rooms["street"].Scripts = map[string]string{"enter": `send("Welcome back.")`}
+
+	// One tick can't run every busy script
+	w.Update()
+	if got := strings.Count(out.output(), `says: "done"`); got == 0 || got == 30 {
+		t.Errorf("first tick ran %d of 30 tick scripts", got)
+	}
+
+	// Player triggers run ahead of the NPCs' tick triggers
+	neo.RoomID = "dojo"
+	w.MovePlayer(neo, "north")
+	w.Update()
+	if !strings.Contains(out.output(), "Welcome back.") {
+		t.Error("enter trigger crowded out by tick triggers")
+	}
+
+	// Every NPC gets its turn as the ticks go by
+	for i := 0; i < 30 && !strings.Contains(out.output(), "Bot29 says"); i++ {
+		w.Update()
+	}
+	for i := 0; i < 30; i++ {
+		if name := fmt.Sprintf("Bot%02d", i); !strings.Contains(out.output(), name+" says") {
+			t.Errorf("%s's tick trigger never ran", name)
+		}
+	}
+
+	// Queued triggers the budget doesn't reach wait for the next tick
+	neo.RoomID = "dojo"
+	for i := 0; i < 20; i++ {
+		w.HandleSay(neo, "hi")
+	}
+	w.Update()
+	if got := strings.Count(out.output(), "Heard."); got == 0 || got == 20 {
+		t.Errorf("first tick ran %d of 20 say triggers", got)
+	}
+	for i := 0; i < 20 && strings.Count(out.output(), "Heard.") < 20; i++ {
+		w.Update()
+	}
+	if got := strings.Count(out.output(), "Heard."); got != 20 {
+		t.Errorf("say triggers run = %d, want 20", got)
+	}
+}
+
+func TestMatchTrigger(t *testing.T) {
+	tests := []struct {
+		key, event, arg string
+		want            bool
+	}{
+		{"say", "say", "anything", true},
+		{"say:sesame", "say", "open SESAME", true},
+		{"say:sesame", "say", "hello", false},
+		{"enter", "say", "", false},
+		{"tick", "tick", "", true},
+	}
+	for _, tt := range tests {
+		if got := matchTrigger(tt.key, tt.event, tt.arg); got != tt.want {
+			t.Errorf("matchTrigger(%q, %q, %q) = %v", tt.key, tt.event, tt.arg, got)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+// Package main implements scheduled shutdowns and reboots.
+// A countdown warns every connected player before the server stops; the
+// main goroutine performs the actual save and exit.
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/yourusername/matrix-mud/pkg/logging"
+)
+
+// shutdownRequests carries a completed countdown to main. True requests a reboot.
+var shutdownRequests = make(chan bool, 1)
+
+// shutdownWarnings are the remaining times at which players are warned
+var shutdownWarnings = []time.Duration{
+	10 * time.Minute, 5 * time.Minute, 2 * time.Minute, time.Minute,
+	30 * time.Second, 10 * time.Second, 5 * time.Second,
+	3 * time.Second, 2 * time.Second, time.Second,
+}
+
+// shutdownSchedule tracks the pending countdown, if any
+type shutdownSchedule struct {
+	mu     sync.Mutex
+	at     time.Time
+	reboot bool
+	cancel chan struct{}
+}
+
+var pendingShutdown shutdownSchedule
+
+// shutdownVerb names the scheduled action for player messages
+func shutdownVerb(reboot bool) string {
+	if reboot {
+		return "reboot"
+	}
+	return "shutdown"
+}
+
+// broadcastAll sends a message to every connected player
+func broadcastAll(w *World, msg string) {
+	w.mutex.RLock()
+	defer w.mutex.RUnlock()
+
+	for _, p := range w.Players {
+		if p != nil && p.Conn != nil {
+			p.Conn.Write(msg)
+		}
+	}
+}
+
+// scheduleShutdown starts a countdown to shutdown or reboot.
+// Only one countdown may be pending at a time.
+func scheduleShutdown(w *World, delay time.Duration, reboot bool) error {
+	if delay <= 0 {
+		return fmt.Errorf("delay must be positive")
+	}
+
+	pendingShutdown.mu.Lock()
+	defer pendingShutdown.mu.Unlock()
+
+	if pendingShutdown.cancel != nil {
+		return fmt.Errorf("a %s is already scheduled for %s",
+			shutdownVerb(pendingShutdown.reboot), pendingShutdown.at.Format("15:04:05"))
+	}
+
+	cancel := make(chan struct{})
+	pendingShutdown.at = time.Now().Add(delay)
+	pendingShutdown.reboot = reboot
+	pendingShutdown.cancel = cancel
+
+	broadcastAll(w, fmt.Sprintf("\r\n%s[OPERATOR] Construct %s in %s.%s\r\n> ",
+		Yellow, shutdownVerb(reboot), delay.Round(time.Second), Reset))
+	logging.Info().Dur("delay", delay).Bool("reboot", reboot).Msg("Shutdown scheduled")
+
+	go runShutdownCountdown(w, pendingShutdown.at, reboot, cancel)
+	return nil
+}
+
+// runShutdownCountdown broadcasts warnings until the deadline, then signals main
+func runShutdownCountdown(w *World, at time.Time, reboot bool, cancel chan struct{}) {
+	for _, warning := range shutdownWarnings {
+		wait := time.Until(at.Add(-warning))
+		if wait < 0 {
+			continue
+		}
+		select {
+		case <-cancel:
+			return
+		case <-time.After(wait):
+		}
+		broadcastAll(w, fmt.Sprintf("\r\n%s[OPERATOR] Construct %s in %s.%s\r\n> ",
+			Yellow, shutdownVerb(reboot), warning, Reset))
+	}
+
+	select {
+	case <-cancel:
+		return
+	case <-time.After(time.Until(at)):
+	}
+
+	pendingShutdown.mu.Lock()
+	pendingShutdown.cancel = nil
+	pendingShutdown.mu.Unlock()
+
+	select {
+	case shutdownRequests <- reboot:
+	default: // A shutdown is already in progress
+	}
+}
+
+// cancelShutdown stops a pending countdown. Returns false if none was scheduled.
+func cancelShutdown(w *World) bool {
+	pendingShutdown.mu.Lock()
+	defer pendingShutdown.mu.Unlock()
+
+	if pendingShutdown.cancel == nil {
+		return false
+	}
+	close(pendingShutdown.cancel)
+	pendingShutdown.cancel = nil
+
+	broadcastAll(w, fmt.Sprintf("\r\n%s[OPERATOR] Scheduled %s canceled.%s\r\n> ",
+		Green, shutdownVerb(pendingShutdown.reboot), Reset))
+	logging.Info().Msg("Scheduled shutdown canceled")
+	return true
+}
+
+// shutdownStatus reports the pending countdown, if any
+func shutdownStatus() (at time.Time, reboot bool, pending bool) {
+	pendingShutdown.mu.Lock()
+	defer pendingShutdown.mu.Unlock()
+	return pendingShutdown.at, pendingShutdown.reboot, pendingShutdown.cancel != nil
+}
+
+// restartServer launches a fresh copy of the server binary with the same
+// arguments. Called after the listener has been closed and state saved.
+func restartServer() {
+	cmd := exec.Command(os.Args[0], os.Args[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = os.Environ()
+	if err := cmd.Start(); err != nil {
+		logging.Error().Err(err).Msg("Reboot failed to start new server process")
+		return
+	}
+	logging.Info().Int("pid", cmd.Process.Pid).Msg("Reboot: new server process started")
+}
@@ -0,0 +1,63 @@
+// Package main implements the staff controls for the simulation's random
+// source, used to reproduce reported bugs:
+//
+//	seed      - Show the seed combat, loot and generation rolls come from
+//	seed <n>  - Restart the random source from seed n
+//
+// The seed comes from SIM_SEED or is picked at startup, and is logged and
+// shown on the admin dashboard.
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/yourusername/matrix-mud/pkg/db"
+	"github.com/yourusername/matrix-mud/pkg/logging"
+	"github.com/yourusername/matrix-mud/pkg/sim"
+)
+
+// simSeed returns SIM_SEED, or a fresh seed when it is unset or invalid
+func simSeed() int64 {
+	if Config.SimSeed != "" {
+		seed, err := strconv.ParseInt(Config.SimSeed, 10, 64)
+		if err == nil {
+			return seed
+		}
+		logging.Error().Err(err).Str("seed", Config.SimSeed).Msg("Invalid SIM_SEED, picking a random seed")
+	}
+	return sim.RandomSeed()
+}
+
+// seedStatus describes the world's seed for the admin dashboard
+func seedStatus(w *World) string {
+	if seed, ok := w.Seed(); ok {
+		return strconv.FormatInt(seed, 10)
+	}
+	return "none (custom random source)"
+}
+
+// handleSeedCommand implements "seed [n]"
+func handleSeedCommand(w *World, admin *Player, arg string) string {
+	if !isAdmin(admin) {
+		return "Unknown.\r\n"
+	}
+	arg = strings.TrimSpace(arg)
+	if arg == "" {
+		return fmt.Sprintf("Simulation seed: %s\r\n", seedStatus(w))
+	}
+
+	seed, err := strconv.ParseInt(arg, 10, 64)
+	if err != nil {
+		return "Usage: seed [number]\r\n"
+	}
+	r, ok := w.rng().(*sim.Rand)
+	if !ok {
+		return "This world's random source cannot be reseeded.\r\n"
+	}
+	r.Reseed(seed)
+	logging.Info().Str("admin", admin.Name).Int64("seed", seed).Msg("Simulation reseeded")
+	recordAudit(admin.Name, db.AuditAdminAction, fmt.Sprintf("reseed simulation: %d", seed), clientIP(admin.Conn))
+	return fmt.Sprintf("%sSimulation reseeded with %d. Rolls from now on replay that seed.%s\r\n", Cyan, seed, Reset)
+}
@@ -0,0 +1,349 @@
+// Package main connects the data-driven skill system (pkg/skills) to the
+// world. This file handles the cast, learn and practice commands:
+//
+//	cast <skill> [target]  - Use a known skill
+//	learn [skill]          - List class skills, or learn one
+//	practice [skill]       - Spend a practice session on a known skill
+//
+// Skills are defined in data/skills.json. Innate skills are known as soon
+// as the player reaches their level; the rest must be learned. Proficiency
+// (0-100) sets the chance that a cast works, and practice raises it.
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/yourusername/matrix-mud/pkg/cooldown"
+	"github.com/yourusername/matrix-mud/pkg/effects"
+	"github.com/yourusername/matrix-mud/pkg/game"
+	"github.com/yourusername/matrix-mud/pkg/logging"
+	"github.com/yourusername/matrix-mud/pkg/skills"
+)
+
+const (
+	InnateProficiency  = 50  // Proficiency of an innate skill never practiced
+	LearnedProficiency = 25  // Proficiency of a freshly learned skill
+	PracticeGain       = 10  // Proficiency gained per practice session
+	MaxProficiency     = 100 // Practice stops here
+	PracticesPerLevel  = 2   // Practice sessions earned per level
+)
+
+// checkSkills logs skills that apply effects with no definition
+func (w *World) checkSkills() {
+	for _, s := range skills.GlobalSkills.Skills {
+		if s.Effect != "" && effects.GlobalEffects.Get(s.Effect) == nil {
+			logging.Warn().Str("skill", s.ID).Str("effect", s.Effect).Msg("Skill applies an undefined effect")
+		}
+	}
+}
+
+// knowsSkill reports whether a player can cast a skill
+func knowsSkill(p *Player, s *skills.Skill) bool {
+	if !s.AvailableTo(p.Class) || p.Level < s.Level {
+		return false
+	}
+	if _, ok := p.Skills[s.ID]; ok {
+		return true
+	}
+	return s.Innate
+}
+
+// skillProficiency returns a player's proficiency in a known skill
+func skillProficiency(p *Player, s *skills.Skill) int {
+	if prof, ok := p.Skills[s.ID]; ok {
+		return prof
+	}
+	if s.Innate {
+		return InnateProficiency
+	}
+	return 0
+}
+
+// castChance returns the percent chance a cast works: 50% at no
+// proficiency up to 100% when mastered
+func castChance(p *Player, s *skills.Skill) int {
+	return 50 + skillProficiency(p, s)/2
+}
+
+// CastSkill uses one of the player's skills. Enemy skills default to the
+// current combat target and room skills hit every NPC present. A failed
+// proficiency roll costs half the MP and starts no cooldown.
+func (w *World) CastSkill(p *Player, skillName string, targetName string) string {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	skill := skills.GlobalSkills.Get(skillName)
+	if skill == nil || !knowsSkill(p, skill) {
+		return "You don't know that skill."
+	}
+	if msg := w.controlBlocked(p, "cast"); msg != "" {
+		return msg
+	}
+	if remaining := cooldown.GlobalCD.TimeRemainingAt(p.Name, skill.ID, w.now()); remaining > 0 {
+		return fmt.Sprintf("%s%s is on cooldown (%.1fs remaining)%s", Yellow, skill.ID, remaining.Seconds(), Reset)
+	}
+	if p.MP < skill.Cost {
+		return "Not enough MP."
+	}
+	room := w.Rooms[p.RoomID]
+	if room == nil {
+		return "You can't do that here."
+	}
+	if msg := peaceBlocks(room); msg != "" && (skill.Target == skills.TargetEnemy || skill.Target == skills.TargetRoom) {
+		return msg
+	}
+
+	var allies []*Player
+	var enemies []*NPC
+	switch skill.Target {
+	case skills.TargetSelf:
+		allies = []*Player{p}
+	case skills.TargetAlly:
+		ally := p
+		if targetName != "" {
+			ally = w.findPlayerInRoom(room.ID, targetName)
+		}
+		if ally == nil {
+			return "Cast at whom?"
+		}
+		if skill.Revive && !ally.Ghost {
+			if ally == p {
+				return "Cast at whom?"
+			}
+			return fmt.Sprintf("%s is not dead.", ally.Name)
+		}
+		if !skill.Revive && ally.Ghost {
+			return fmt.Sprintf("%s is a ghost. Only a reboot can reach them.", ally.Name)
+		}
+		allies = []*Player{ally}
+	case skills.TargetEnemy:
+		if targetName == "" && p.State == "COMBAT" {
+			targetName = p.Target
+		}
+		npc := findNPCInRoom(room, targetName)
+		if npc == nil {
+			return "Cast at whom?"
+		}
+		if npc.Vendor {
+			return "Protected."
+		}
+		enemies = []*NPC{npc}
+	case skills.TargetRoom:
+		// Room skills spare merchants, which can't be attacked
+		for _, npc := range room.NPCMap {
+			if !npc.IsDead && !npc.Vendor {
+				enemies = append(enemies, npc)
+			}
+		}
+		if len(enemies) == 0 {
+			return "There is nothing here to hit."
+		}
+	}
+
+	if w.rng().Intn(100) >= castChance(p, skill) {
+		p.MP -= skill.Cost / 2
+		return fmt.Sprintf("Your %s fizzles.", skill.Name)
+	}
+	p.MP -= skill.Cost
+	cooldown.GlobalCD.UseAt(p.Name, skill.ID, w.now())
+
+	vars := map[string]int{"str": p.Strength, "level": p.Level}
+	effect := effects.GlobalEffects.Get(skill.Effect)
+	now := w.now()
+	var out []string
+	for _, ally := range allies {
+		name := ally.Name
+		if ally == p {
+			name = "yourself"
+		}
+		notice := fmt.Sprintf("%s casts %s on you.", p.Name, skill.Name)
+		if skill.Revive {
+			notice += "\r\n" + w.resurrect(ally)
+		}
+		if heal := skill.HealFormula(); heal != nil {
+			amount := heal.Roll(w.rng(), vars)
+			ally.HP += amount
+			if ally.HP > ally.MaxHP {
+				ally.HP = ally.MaxHP
+			}
+			w.healThreat(p, ally, amount)
+			out = append(out, skillMessage(skill, name, amount))
+		} else if skill.Message != "" {
+			out = append(out, skillMessage(skill, name, 0))
+		}
+		if effect != nil {
+			ally.Effects.Apply(effect, now, p.Name)
+			if ally == p {
+				out = append(out, fmt.Sprintf("You are %s.", effect.Name))
+			} else {
+				out = append(out, fmt.Sprintf("%s is %s.", ally.Name, effect.Name))
+			}
+		}
+		if ally != p && ally.Conn != nil {
+			ally.Conn.Write(Matrixify("\r\n" + notice + "\r\n> "))
+		}
+	}
+	_, bonus := p.Effects.Modifiers(now)
+	for _, npc := range enemies {
+		threat := skill.Threat
+		if damage := skill.DamageFormula(); damage != nil {
+			dmg := damage.Roll(w.rng(), vars) + bonus
+			if dmg < 0 {
+				dmg = 0
+			}
+			dmg = game.Resist(dmg, skill.DamageType, npcResist(npc))
+			npc.HP -= dmg
+			threat += dmg
+			out = append(out, skillMessage(skill, npc.Name, dmg))
+			if disabled := w.disableNPC(npc, skill.DamageType, now, p.Name); disabled != "" {
+				out = append(out, disabled)
+			}
+		} else if skill.Message != "" && skill.HealFormula() == nil {
+			out = append(out, skillMessage(skill, npc.Name, 0))
+		}
+		w.addThreat(npc, p, threat)
+		npc.State = "COMBAT"
+		if effect != nil && npc.HP > 0 {
+			out = append(out, w.afflictNPC(npc, effect, now, p.Name))
+		}
+		if p.State != "COMBAT" {
+			p.State = "COMBAT"
+			p.Target = npc.ID
+			p.LastAttack = now
+		}
+		if npc.HP <= 0 {
+			out = append(out, w.killNPC(p, npc, room))
+		}
+	}
+	return strings.Join(out, "\r\n")
+}
+
+// skillMessage fills in a skill's message, or a default for its kind
+func skillMessage(s *skills.Skill, target string, amount int) string {
+	msg := s.Message
+	if msg == "" {
+		if s.HealFormula() != nil {
+			msg = s.Name + " heals {target} for {amount} HP."
+		} else {
+			msg = s.Name + " hits {target} for {amount} damage!"
+		}
+	}
+	return strings.NewReplacer("{target}", target, "{amount}", fmt.Sprint(amount)).Replace(msg)
+}
+
+// findNPCInRoom finds a living NPC by ID or part of its name
+func findNPCInRoom(room *Room, name string) *NPC {
+	if name == "" {
+		return nil
+	}
+	if npc, ok := room.NPCMap[name]; ok {
+		return npc
+	}
+	name = strings.ToLower(name)
+	for _, npc := range room.NPCMap {
+		if strings.Contains(strings.ToLower(npc.Name), name) {
+			return npc
+		}
+	}
+	return nil
+}
+
+// findPlayerInRoom finds a player in a room by name prefix
+func (w *World) findPlayerInRoom(roomID, name string) *Player {
+	name = strings.ToLower(name)
+	for _, other := range w.Players {
+		if other.RoomID == roomID && strings.HasPrefix(strings.ToLower(other.Name), name) {
+			return other
+		}
+	}
+	return nil
+}
+
+// LearnSkill lists the skills a player's class can learn, or learns one
+// the player has the level for
+func (w *World) LearnSkill(p *Player, name string) string {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if name == "" {
+		return skillList(p)
+	}
+	skill := skills.GlobalSkills.Get(name)
+	if skill == nil || !skill.AvailableTo(p.Class) {
+		return fmt.Sprintf("%ss cannot learn that.", p.Class)
+	}
+	if knowsSkill(p, skill) {
+		return fmt.Sprintf("You already know %s.", skill.Name)
+	}
+	if p.Level < skill.Level {
+		return fmt.Sprintf("You need to be level %d to learn %s.", skill.Level, skill.Name)
+	}
+	if p.Skills == nil {
+		p.Skills = make(map[string]int)
+	}
+	p.Skills[skill.ID] = LearnedProficiency
+	return fmt.Sprintf("%sYou learn %s.%s Practice it to cast it more reliably.", Green, skill.Name, Reset)
+}
+
+// PracticeSkill spends a practice session to raise proficiency in a known skill
+func (w *World) PracticeSkill(p *Player, name string) string {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if name == "" {
+		return fmt.Sprintf("You have %d practice sessions.\r\n%s", p.Practices, skillList(p))
+	}
+	skill := skills.GlobalSkills.Get(name)
+	if skill == nil || !knowsSkill(p, skill) {
+		return "You don't know that skill."
+	}
+	prof := skillProficiency(p, skill)
+	if prof >= MaxProficiency {
+		return fmt.Sprintf("You have mastered %s.", skill.Name)
+	}
+	if p.Practices <= 0 {
+		return "You have no practice sessions left. Gain a level to earn more."
+	}
+	p.Practices--
+	prof += PracticeGain
+	if prof > MaxProficiency {
+		prof = MaxProficiency
+	}
+	if p.Skills == nil {
+		p.Skills = make(map[string]int)
+	}
+	p.Skills[skill.ID] = prof
+	return fmt.Sprintf("You practice %s. Proficiency: %d%% (%d sessions left)", skill.Name, prof, p.Practices)
+}
+
+// skillList formats the player's class skills and how to get each
+func skillList(p *Player) string {
+	var sb strings.Builder
+	sb.WriteString(Green + "=== " + strings.ToUpper(p.Class) + " SKILLS ===" + Reset + "\r\n")
+	list := skills.GlobalSkills.ForClass(p.Class)
+	if len(list) == 0 {
+		sb.WriteString("  None.\r\n")
+	}
+	for _, s := range list {
+		status := ""
+		switch {
+		case knowsSkill(p, s):
+			status = fmt.Sprintf("%sknown%s (%d%%)", Green, Reset, skillProficiency(p, s))
+		case p.Level >= s.Level:
+			status = Yellow + "learnable" + Reset
+		default:
+			status = fmt.Sprintf("level %d", s.Level)
+		}
+		sb.WriteString(fmt.Sprintf("  %-12s %3d MP %4s  %-6s %s\r\n", s.ID, s.Cost, s.CooldownDuration(), s.Target, status))
+	}
+	return sb.String()
+}
+
+// newSkillsAt lists the skills a class can first learn at a level
+func newSkillsAt(class string, level int) []string {
+	var ids []string
+	for _, s := range skills.GlobalSkills.ForClass(class) {
+		if s.Level == level {
+			ids = append(ids, s.ID)
+		}
+	}
+	return ids
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourusername/matrix-mud/pkg/cooldown"
+	"github.com/yourusername/matrix-mud/pkg/sim"
+)
+
+// setupSkills creates a dojo with two agents and a player of the given class
+func setupSkills(t *testing.T, name, class string, level int) (*World, *Player) {
+	t.Helper()
+	w := &World{
+		Rooms: map[string]*Room{
+			"dojo": {ID: "dojo", Description: "A dojo.", Exits: map[string]*Exit{}, ItemMap: map[string]*Item{}, NPCMap: map[string]*NPC{
+				"smith": {ID: "smith", Name: "Agent Smith", RoomID: "dojo", HP: 200, MaxHP: 200, AC: 10, Damage: 5, XP: 500, DropMoney: 20},
+				"brown": {ID: "brown", Name: "Agent Brown", RoomID: "dojo", HP: 200, MaxHP: 200, AC: 10, Damage: 5, XP: 500, DropMoney: 20},
+			}},
+		},
+		Players: map[*Client]*Player{},
+	}
+	p := &Player{Name: name, Class: class, Level: level, RoomID: "dojo", HP: 50, MaxHP: 100, MP: 100, MaxMP: 100,
+		Strength: 10, State: "IDLE", Equipment: map[string]*Item{}, Conn: &Client{conn: newMockConn("")}}
+	w.Players[p.Conn] = p
+	cooldown.GlobalCD.Reset(name)
+	t.Cleanup(func() { cooldown.GlobalCD.Reset(name) })
+	return w, p
+}
+
+func TestCastInnateSkillAndKill(t *testing.T) {
+	w, p := setupSkills(t, "SkillHacker", "Hacker", 1)
+	p.Skills = map[string]int{"glitch": MaxProficiency}
+	smith := w.Rooms["dojo"].NPCMap["smith"]
+
+	got := w.CastSkill(p, "glitch", "smith")
+	if !strings.Contains(got, "Logic bomb hits Agent Smith") {
+		t.Fatalf("glitch = %q", got)
+	}
+	if p.MP != 95 || smith.HP >= 200 || p.State != "COMBAT" || p.Target != "smith" {
+		t.Errorf("after glitch: MP %d, smith HP %d, state %s/%s", p.MP, smith.HP, p.State, p.Target)
+	}
+	if got := w.CastSkill(p, "glitch", "smith"); !strings.Contains(got, "cooldown") {
+		t.Errorf("second glitch = %q, want cooldown", got)
+	}
+
+	// A kill by skill goes through the same reward path as melee
+	cooldown.GlobalCD.Reset(p.Name)
+	smith.HP = 1
+	got = w.CastSkill(p, "glitch", "")
+	if !strings.Contains(got, "collapses") || !strings.Contains(got, "You gain 500 XP and 20 Fragments.") {
+		t.Errorf("kill = %q", got)
+	}
+	if p.XP != 500 || p.Money != 20 || p.State != "IDLE" || len(w.DeadNPCs) != 1 {
+		t.Errorf("after kill: XP %d, money %d, state %s, dead %d", p.XP, p.Money, p.State, len(w.DeadNPCs))
+	}
+}
+
+func TestSkillCooldownUsesWorldClock(t *testing.T) {
+	w, p := setupSkills(t, "SkillClock", "Hacker", 1)
+	p.Skills = map[string]int{"glitch": MaxProficiency}
+	clock := sim.NewManualClock(time.Unix(1000, 0))
+	w.Clock = clock
+
+	w.CastSkill(p, "glitch", "smith")
+	clock.Advance(4 * time.Second)
+	if got := w.CastSkill(p, "glitch", "smith"); !strings.Contains(got, "(1.0s remaining)") {
+		t.Errorf("glitch 4s later = %q, want cooldown", got)
+	}
+	clock.Advance(time.Second)
+	if got := w.CastSkill(p, "glitch", "smith"); strings.Contains(got, "cooldown") {
+		t.Errorf("glitch after the world clock passed the cooldown = %q", got)
+	}
+}
+
+func TestCastRequirements(t *testing.T) {
+	w, p := setupSkills(t, "SkillRebel", "Rebel", 1)
+
+	for skill, want := range map[string]string{
+		"glitch":   "don't know",   // Another class's skill
+		"rampage":  "don't know",   // Level too low
+		"nonsense": "don't know",   // Not a skill
+		"smash":    "Cast at whom", // No target
+	} {
+		if got := w.CastSkill(p, skill, ""); !strings.Contains(got, want) {
+			t.Errorf("cast %s = %q, want %q", skill, got, want)
+		}
+	}
+
+	p.MP = 2
+	if got := w.CastSkill(p, "smash", "smith"); got != "Not enough MP." {
+		t.Errorf("low MP cast = %q", got)
+	}
+}
+
+func TestLearnAndPractice(t *testing.T) {
+	w, p := setupSkills(t, "SkillRebel2", "Rebel", 4)
+
+	list := w.LearnSkill(p, "")
+	for _, want := range []string{"smash", "(50%)", "fortify", "learnable", "rampage", "level 5"} {
+		if !strings.Contains(list, want) {
+			t.Errorf("learn list missing %q:\n%s", want, list)
+		}
+	}
+	if got := w.LearnSkill(p, "rampage"); !strings.Contains(got, "level 5") {
+		t.Errorf("learn rampage = %q", got)
+	}
+	if got := w.LearnSkill(p, "glitch"); !strings.Contains(got, "cannot learn") {
+		t.Errorf("learn glitch = %q", got)
+	}
+	if got := w.LearnSkill(p, "fortify"); !strings.Contains(got, "You learn Fortify") || p.Skills["fortify"] != LearnedProficiency {
+		t.Fatalf("learn fortify = %q, skills %v", got, p.Skills)
+	}
+	if got := w.LearnSkill(p, "fortify"); !strings.Contains(got, "already know") {
+		t.Errorf("relearn = %q", got)
+	}
+
+	if got := w.PracticeSkill(p, "fortify"); !strings.Contains(got, "no practice sessions") {
+		t.Errorf("practice with none = %q", got)
+	}
+	p.XP = p.Level * 1000
+	w.gainXP(p, 0)
+	if p.Level != 5 || p.Practices != PracticesPerLevel {
+		t.Fatalf("level up: level %d, practices %d", p.Level, p.Practices)
+	}
+	w.PracticeSkill(p, "fortify")
+	got := w.PracticeSkill(p, "smash")
+	if p.Skills["fortify"] != LearnedProficiency+PracticeGain || p.Skills["smash"] != InnateProficiency+PracticeGain {
+		t.Errorf("proficiency after practice = %v (%q)", p.Skills, got)
+	}
+	if p.Practices != 0 {
+		t.Errorf("practices left = %d", p.Practices)
+	}
+}
+
+func TestSkillTargets(t *testing.T) {
+	w, p := setupSkills(t, "SkillRebel3", "Rebel", 8)
+	p.Skills = map[string]int{"fortify": MaxProficiency, "rampage": MaxProficiency}
+
+	// Self effects last and change armor class
+	if got := w.CastSkill(p, "fortify", ""); !strings.Contains(got, "You are Fortified.") {
+		t.Fatalf("fortify = %q", got)
+	}
+	if ac, _ := p.Effects.Modifiers(w.now()); ac != 4 {
+		t.Errorf("fortified AC bonus = %d", ac)
+	}
+
+	// Room skills hit every NPC but merchants
+	merchant := &NPC{ID: "merchant", Name: "Merchant", RoomID: "dojo", HP: 50, MaxHP: 50, Vendor: true}
+	w.Rooms["dojo"].NPCMap["merchant"] = merchant
+	got := w.CastSkill(p, "rampage", "")
+	if !strings.Contains(got, "Agent Smith") || !strings.Contains(got, "Agent Brown") || strings.Contains(got, "Merchant") {
+		t.Errorf("rampage = %q", got)
+	}
+	for _, npc := range w.Rooms["dojo"].NPCMap {
+		if npc.HP == npc.MaxHP != npc.Vendor {
+			t.Errorf("%s: HP %d/%d", npc.Name, npc.HP, npc.MaxHP)
+		}
+	}
+	cooldown.GlobalCD.Reset(p.Name)
+	p.Skills["smash"] = MaxProficiency
+	if got := w.CastSkill(p, "smash", "merchant"); got != "Protected." || merchant.HP != merchant.MaxHP {
+		t.Errorf("smash a merchant = %q", got)
+	}
+
+	// Ally skills heal another player in the room
+	w2, op := setupSkills(t, "SkillOperator", "Operator", 1)
+	op.Skills = map[string]int{"patch": MaxProficiency}
+	ally := &Player{Name: "Trinity", RoomID: "dojo", HP: 5, MaxHP: 100, Conn: &Client{conn: newMockConn("")}}
+	w2.Players[ally.Conn] = ally
+	if got := w2.CastSkill(op, "patch", "trin"); !strings.Contains(got, "Patched Trinity for 10 HP.") || ally.HP != 15 {
+		t.Errorf("patch ally = %q, HP %d", got, ally.HP)
+	}
+	if out := ally.Conn.conn.(*mockConn).output(); !strings.Contains(out, "SkillOperator casts Patch on you.") {
+		t.Errorf("ally told = %q", out)
+	}
+}
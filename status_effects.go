@@ -0,0 +1,171 @@
+// Package main applies status effects (pkg/effects) to players and NPCs.
+// Skills and items apply effects; the world tick advances them, applying
+// bleeding, regeneration and corruption and announcing effects that wear
+// off. Control effects are checked where players act:
+//
+//	stun    - no attacking, casting or moving
+//	root    - no moving or fleeing
+//	silence - no casting
+//
+// Effects with a damage type are resisted: resistance is the chance to
+// ward the effect off, and it reduces the effect's periodic damage.
+//
+// Active effects are listed by score, look <target> and the REST API.
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/yourusername/matrix-mud/pkg/effects"
+	"github.com/yourusername/matrix-mud/pkg/game"
+)
+
+// controlBlocked returns why a player cannot do action ("cast", "move" or
+// "flee"; anything else is only stopped by stun) under their control
+// effects, or "" if they can
+func (w *World) controlBlocked(p *Player, action string) string {
+	now := w.now()
+	if p.Effects.Controlled(effects.Stun, now) {
+		return "You are stunned!"
+	}
+	switch action {
+	case "cast":
+		if p.Effects.Controlled(effects.Silence, now) {
+			return "You are silenced and cannot cast."
+		}
+	case "move", "flee":
+		if p.Effects.Controlled(effects.Root, now) {
+			return "You are rooted in place!"
+		}
+	}
+	return ""
+}
+
+// applyItemEffect applies the status effect named by an item's Effect, if
+// it names one, and returns the message for the player
+func (w *World) applyItemEffect(p *Player, item *Item) string {
+	def := effects.GlobalEffects.Get(item.Effect)
+	if def == nil {
+		return ""
+	}
+	p.Effects.Apply(def, w.now(), p.Name)
+	return fmt.Sprintf("You are %s.", def.Name)
+}
+
+// updateEffects advances every player's and NPC's effects to now. The
+// world lock must be held.
+func (w *World) updateEffects() {
+	now := w.now()
+	for _, p := range w.Players {
+		ticks, expired := p.Effects.Advance(now)
+		if len(ticks) == 0 && len(expired) == 0 {
+			continue
+		}
+		output, cause := "", ""
+		for _, t := range ticks {
+			t = resistTick(t, playerResist(p))
+			if t.HP == 0 && t.MP == 0 {
+				continue // Fully resisted
+			}
+			if t.HP < 0 {
+				cause = t.Def.Name
+			}
+			p.HP += t.HP
+			if p.HP > p.MaxHP {
+				p.HP = p.MaxHP
+			}
+			p.MP += t.MP
+			if p.MP > p.MaxMP {
+				p.MP = p.MaxMP
+			}
+			if p.MP < 0 {
+				p.MP = 0
+			}
+			output += "\r\n" + tickMessage(t, "You")
+		}
+		for _, def := range expired {
+			output += fmt.Sprintf("\r\n%s%s wears off.%s", Yellow, def.Name, Reset)
+		}
+		if p.HP <= 0 && !p.Ghost {
+			output += "\r\n" + w.playerDeath(p, cause)
+		}
+		if p.Conn != nil {
+			p.Conn.Write(Matrixify(output + "\r\n> "))
+		}
+	}
+	for _, room := range w.Rooms {
+		for _, npc := range room.NPCMap {
+			ticks, _ := npc.Effects.Advance(now)
+			source := ""
+			for _, t := range ticks {
+				t = resistTick(t, npcResist(npc))
+				if t.HP == 0 && t.MP == 0 {
+					continue // Fully resisted
+				}
+				npc.HP += t.HP
+				if npc.HP > npc.MaxHP {
+					npc.HP = npc.MaxHP
+				}
+				w.Broadcast(room.ID, nil, "\r\n"+tickMessage(t, npc.Name)+"\r\n> ")
+				if t.HP < 0 {
+					source = t.Source
+				}
+			}
+			if npc.HP <= 0 {
+				w.npcDiesOfEffects(npc, room, source)
+			}
+		}
+	}
+}
+
+// resistTick reduces the damage of a typed effect's tick by resistance
+func resistTick(t effects.Tick, resist map[string]int) effects.Tick {
+	if t.HP < 0 && t.Def.Type != "" {
+		t.HP = -game.Resist(-t.HP, t.Def.Type, resist)
+	}
+	return t
+}
+
+// tickMessage describes one periodic effect tick, e.g. "You take 3 damage
+// from Bleeding." or "Agent Smith takes 2 damage and loses 3 MP from
+// Corrupted."
+func tickMessage(t effects.Tick, who string) string {
+	s := "s"
+	if who == "You" {
+		s = ""
+	}
+	var parts []string
+	if t.HP < 0 {
+		parts = append(parts, fmt.Sprintf("take%s %d damage", s, -t.HP))
+	} else if t.HP > 0 {
+		parts = append(parts, fmt.Sprintf("regain%s %d HP", s, t.HP))
+	}
+	if t.MP < 0 {
+		parts = append(parts, fmt.Sprintf("lose%s %d MP", s, -t.MP))
+	} else if t.MP > 0 {
+		parts = append(parts, fmt.Sprintf("regain%s %d MP", s, t.MP))
+	}
+	color := Green
+	if t.HP < 0 || t.MP < 0 {
+		color = Red
+	}
+	return fmt.Sprintf("%s%s %s from %s.%s", color, who, strings.Join(parts, " and "), t.Def.Name, Reset)
+}
+
+// npcDiesOfEffects handles an NPC killed by a periodic effect. The player
+// who applied the effect gets the kill if they are still online.
+func (w *World) npcDiesOfEffects(npc *NPC, room *Room, source string) {
+	for _, p := range w.Players {
+		if p.Name == source {
+			msg := w.killNPC(p, npc, room)
+			if p.Conn != nil {
+				p.Conn.Write(Matrixify("\r\n" + msg + "\r\n> "))
+			}
+			return
+		}
+	}
+	w.Broadcast(room.ID, nil, fmt.Sprintf("\r\n%s collapses.\r\n> ", npc.Name))
+	w.removeDeadNPC(npc, room)
+	w.fireNPCScripts(npc, "death", nil, "")
+}
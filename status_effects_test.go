@@ -0,0 +1,159 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourusername/matrix-mud/pkg/effects"
+	"github.com/yourusername/matrix-mud/pkg/sim"
+)
+
+// setupEffects creates a dojo world on a manual clock with Neo and an agent
+func setupEffects(t *testing.T) (*World, *Player, *NPC, *sim.ManualClock) {
+	t.Helper()
+	w, p := setupSkills(t, "EffectNeo", "Rebel", 4)
+	clock := sim.NewManualClock(time.Unix(1000, 0))
+	w.Clock = clock
+	w.RNG = sim.NewRand(1)
+	w.Rooms["dojo"].Exits["north"] = &Exit{To: "street"}
+	w.Rooms["street"] = &Room{ID: "street", Exits: map[string]*Exit{"south": {To: "dojo"}}, ItemMap: map[string]*Item{}, NPCMap: map[string]*NPC{}}
+	return w, p, w.Rooms["dojo"].NPCMap["smith"], clock
+}
+
+// effectDef returns a definition from data/effects.json
+func effectDef(t *testing.T, id string) *effects.Def {
+	t.Helper()
+	def := effects.GlobalEffects.Get(id)
+	if def == nil {
+		t.Fatalf("effect %s is not defined", id)
+	}
+	return def
+}
+
+func TestPeriodicEffectsOnTick(t *testing.T) {
+	w, p, smith, clock := setupEffects(t)
+	out := p.Conn.conn.(*mockConn)
+
+	p.Effects.Apply(effectDef(t, "bleeding"), clock.Now(), "Agent Smith")
+	p.Effects.Apply(effectDef(t, "bleeding"), clock.Now(), "Agent Smith")
+	clock.Advance(2 * time.Second)
+	w.updateEffects()
+	if p.HP != 46 {
+		t.Errorf("HP after two stacks of bleeding = %d, want 46", p.HP)
+	}
+	if got := out.output(); !strings.Contains(got, "You take 4 damage from Bleeding.") {
+		t.Errorf("bleed output = %q", got)
+	}
+
+	clock.Advance(10 * time.Second)
+	w.updateEffects()
+	if got := out.output(); !strings.Contains(got, "Bleeding wears off.") {
+		t.Errorf("expiry output = %q", got)
+	}
+	if p.Effects.String(clock.Now()) != "" {
+		t.Errorf("effects left: %s", p.Effects.String(clock.Now()))
+	}
+
+	// An NPC killed by an effect rewards whoever applied it
+	smith.HP = 1
+	smith.Effects.Apply(effectDef(t, "corrupted"), clock.Now(), p.Name)
+	clock.Advance(3 * time.Second)
+	w.updateEffects()
+	if _, ok := w.Rooms["dojo"].NPCMap["smith"]; ok || p.XP != 500 {
+		t.Errorf("corrupted smith: still in room %v, XP %d", ok, p.XP)
+	}
+
+	// Dying to an effect leaves a ghost with no effects
+	p.HP = 1
+	p.Effects.Apply(effectDef(t, "bleeding"), clock.Now(), "")
+	clock.Advance(2 * time.Second)
+	w.updateEffects()
+	if !p.Ghost || p.RoomID != "dojo" || p.HP != 0 || len(p.Effects.List(clock.Now())) != 0 {
+		t.Errorf("after death: ghost %v, room %s, HP %d, effects %v", p.Ghost, p.RoomID, p.HP, p.Effects.Names(clock.Now()))
+	}
+}
+
+func TestControlEffects(t *testing.T) {
+	w, p, smith, clock := setupEffects(t)
+	p.Skills = map[string]int{"smash": MaxProficiency}
+
+	p.Effects.Apply(effectDef(t, "silenced"), clock.Now(), "")
+	if got := w.CastSkill(p, "smash", "smith"); !strings.Contains(got, "silenced") {
+		t.Errorf("silenced cast = %q", got)
+	}
+	if got := w.MovePlayer(p, "north"); strings.Contains(got, "rooted") || p.RoomID != "street" {
+		t.Errorf("silence should not stop moving: %q", got)
+	}
+	w.MovePlayer(p, "south")
+
+	p.Effects.Apply(effectDef(t, "rooted"), clock.Now(), "")
+	if got := w.MovePlayer(p, "north"); !strings.Contains(got, "rooted") || p.RoomID != "dojo" {
+		t.Errorf("rooted move = %q, room %s", got, p.RoomID)
+	}
+	p.State = "COMBAT"
+	if got := w.StopCombat(p); !strings.Contains(got, "rooted") || p.State != "COMBAT" {
+		t.Errorf("rooted flee = %q", got)
+	}
+
+	// Stunned players lose their attack; stunned NPCs lose theirs
+	p.Effects.Clear()
+	p.Effects.Apply(effectDef(t, "stunned"), clock.Now(), "")
+	smith.Effects.Apply(effectDef(t, "stunned"), clock.Now(), p.Name)
+	p.Target = "smith"
+	hp, php := smith.HP, p.HP
+	w.ResolveCombatRound(p)
+	w.npcCombatRounds(clock.Now())
+	got := p.Conn.conn.(*mockConn).output()
+	if !strings.Contains(got, "You are stunned and cannot attack!") || !strings.Contains(got, "Agent Smith is stunned.") || smith.HP != hp || p.HP != php {
+		t.Errorf("stunned round = %q", got)
+	}
+	if got := w.CastSkill(p, "smash", "smith"); got != "You are stunned!" {
+		t.Errorf("stunned cast = %q", got)
+	}
+
+	clock.Advance(4 * time.Second)
+	if got := w.CastSkill(p, "smash", "smith"); !strings.Contains(got, "Smash hits") {
+		t.Errorf("cast after stun = %q", got)
+	}
+}
+
+func TestEffectsDisplayAndSources(t *testing.T) {
+	w, p, _, clock := setupEffects(t)
+	p.Skills = map[string]int{"fortify": MaxProficiency, "concuss": MaxProficiency}
+
+	// Skills apply effects to themselves and their targets
+	w.CastSkill(p, "fortify", "")
+	if got := w.CastSkill(p, "concuss", "smith"); !strings.Contains(got, "Agent Smith is Stunned.") {
+		t.Errorf("concuss = %q", got)
+	}
+	if got := w.ShowScore(p); !strings.Contains(got, "Effects: Fortified (20s)") {
+		t.Errorf("score = %q", got)
+	}
+	if got := w.Look(p, "agent smith"); !strings.Contains(got, "Effects: Stunned (3s)") {
+		t.Errorf("look smith = %q", got)
+	}
+	other := &Player{Name: "Trinity", RoomID: "dojo", Level: 9, Class: "Operator", HP: 20, MaxHP: 20, Conn: &Client{conn: newMockConn("")}}
+	w.Players[other.Conn] = other
+	other.Effects.Apply(effectDef(t, "regenerating"), clock.Now(), "")
+	if got := w.Look(p, "trin"); !strings.Contains(got, "Effects: Regenerating (12s)") {
+		t.Errorf("look trinity = %q", got)
+	}
+
+	// Consumables apply the effect they name
+	kit := &Item{ID: "repair_kit_1", Name: "Nanite Repair Kit", Type: "consumable", Effect: "regenerating"}
+	p.Inventory = []*Item{kit}
+	if got := w.UseItem(p, "repair_kit_1"); !strings.Contains(got, "You are Regenerating.") {
+		t.Errorf("use kit = %q", got)
+	}
+
+	// Weapons apply their effect on hit
+	p.Equipment["hand"] = &Item{ID: "code_blade", Name: "Code Blade", Damage: 50, Effect: "bleeding"}
+	p.Target = "brown"
+	brown := w.Rooms["dojo"].NPCMap["brown"]
+	brown.AC = 0
+	w.ResolveCombatRound(p)
+	if !brown.Effects.Has("bleeding", clock.Now()) {
+		t.Error("weapon effect not applied on hit")
+	}
+}
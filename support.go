@@ -0,0 +1,343 @@
+// Package main implements the live support commands for staff:
+//
+//	snoop <player>  - Mirror a player's screen into your session
+//	switch <npc>    - Puppet an NPC in your room: say, emote and move as it
+//	return          - End the snoop and the switch
+//
+// Both are limited to ADMIN_PLAYERS and recorded in the audit log. Other
+// admins cannot be snooped, which also rules out snoop loops.
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/yourusername/matrix-mud/pkg/db"
+)
+
+// support tracks active snoops and switched NPCs, keyed by the staff
+// member's client
+var support = struct {
+	mu       sync.Mutex
+	snooping map[*Client]*Client // Snooper -> snooped client
+	names    map[*Client]string  // Snooped client -> player name, for the indicator
+	switched map[*Client]*NPC    // Admin -> puppeted NPC
+}{
+	snooping: make(map[*Client]*Client),
+	names:    make(map[*Client]string),
+	switched: make(map[*Client]*NPC),
+}
+
+// snoopPrefix marks mirrored lines in the snooper's session
+func snoopPrefix(name string) string {
+	return Cyan + "[snoop " + name + "] " + Reset
+}
+
+// mirrorToSnoopers copies output sent to c to everyone snooping it, with
+// each line marked so it cannot be mistaken for the snooper's own screen
+func (c *Client) mirrorToSnoopers(msg string) {
+	snoopers := c.snoopers.Load()
+	if snoopers == nil || len(*snoopers) == 0 || msg == "" {
+		return
+	}
+	support.mu.Lock()
+	prefix := snoopPrefix(support.names[c])
+	support.mu.Unlock()
+
+	lines := strings.Split(strings.TrimSuffix(msg, "\r\n"), "\r\n")
+	var b strings.Builder
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" || line == "> " {
+			continue
+		}
+		b.WriteString("\r\n" + prefix + line)
+	}
+	if b.Len() == 0 {
+		return
+	}
+	mirrored := []byte(b.String() + "\r\n")
+	for _, s := range *snoopers {
+		s.conn.Write(mirrored)
+	}
+}
+
+// updateSnoopersLocked rebuilds the snooper list stored on target.
+// support.mu must be held.
+func updateSnoopersLocked(target *Client) {
+	var list []*Client
+	for snooper, t := range support.snooping {
+		if t == target {
+			list = append(list, snooper)
+		}
+	}
+	if len(list) == 0 {
+		target.snoopers.Store(nil)
+		delete(support.names, target)
+		return
+	}
+	target.snoopers.Store(&list)
+}
+
+// startSnoop mirrors target's output to admin, replacing any earlier snoop
+func startSnoop(admin, target *Player) {
+	support.mu.Lock()
+	defer support.mu.Unlock()
+	if old, ok := support.snooping[admin.Conn]; ok {
+		delete(support.snooping, admin.Conn)
+		updateSnoopersLocked(old)
+	}
+	support.snooping[admin.Conn] = target.Conn
+	support.names[target.Conn] = target.Name
+	updateSnoopersLocked(target.Conn)
+}
+
+// stopSnoop ends admin's snoop and returns the name of who was snooped
+func stopSnoop(admin *Client) string {
+	support.mu.Lock()
+	defer support.mu.Unlock()
+	target, ok := support.snooping[admin]
+	if !ok {
+		return ""
+	}
+	name := support.names[target]
+	delete(support.snooping, admin)
+	updateSnoopersLocked(target)
+	return name
+}
+
+// snoopTarget returns the name of the player admin is snooping, or ""
+func snoopTarget(admin *Client) string {
+	support.mu.Lock()
+	defer support.mu.Unlock()
+	if target, ok := support.snooping[admin]; ok {
+		return support.names[target]
+	}
+	return ""
+}
+
+// switchedNPC returns the NPC admin is puppeting, or nil
+func switchedNPC(admin *Client) *NPC {
+	support.mu.Lock()
+	defer support.mu.Unlock()
+	return support.switched[admin]
+}
+
+// endSupportSessions clears every snoop and switch involving a client that
+// is disconnecting, telling anyone who was snooping it
+func endSupportSessions(c *Client, p *Player) {
+	support.mu.Lock()
+	var orphaned []*Client
+	for snooper, target := range support.snooping {
+		if target == c {
+			orphaned = append(orphaned, snooper)
+			delete(support.snooping, snooper)
+		}
+	}
+	if target, ok := support.snooping[c]; ok {
+		delete(support.snooping, c)
+		updateSnoopersLocked(target)
+	}
+	updateSnoopersLocked(c)
+	delete(support.switched, c)
+	support.mu.Unlock()
+
+	for _, snooper := range orphaned {
+		snooper.Write("\r\n" + SystemMsg(p.Name+" disconnected. Snoop ended.") + "> ")
+	}
+}
+
+// handleSnoopCommand implements "snoop [player|off]"
+func handleSnoopCommand(w *World, admin *Player, arg string) string {
+	if !isAdmin(admin) {
+		return "Unknown.\r\n"
+	}
+	arg = strings.TrimSpace(arg)
+	switch strings.ToLower(arg) {
+	case "":
+		if name := snoopTarget(admin.Conn); name != "" {
+			return fmt.Sprintf("You are snooping %s. Type 'return' to stop.\r\n", name)
+		}
+		return "Usage: snoop <player>   (return to stop)\r\n"
+	case "off":
+		return endSnoop(admin)
+	}
+
+	target := findOnlinePlayer(w, arg)
+	switch {
+	case target == nil || target.Conn == nil:
+		return fmt.Sprintf("%s is not online.\r\n", arg)
+	case target == admin:
+		return "You cannot snoop yourself.\r\n"
+	case isAdmin(target):
+		return "You cannot snoop another admin.\r\n"
+	}
+
+	startSnoop(admin, target)
+	recordAudit(admin.Name, db.AuditAdminAction, "snoop "+target.Name, clientIP(admin.Conn))
+	return fmt.Sprintf("%sSnooping %s. Their output appears marked %s. Type 'return' to stop.%s\r\n",
+		Cyan, target.Name, "[snoop "+target.Name+"]", Reset)
+}
+
+// endSnoop stops admin's snoop, if any
+func endSnoop(admin *Player) string {
+	name := stopSnoop(admin.Conn)
+	if name == "" {
+		return "You are not snooping anyone.\r\n"
+	}
+	recordAudit(admin.Name, db.AuditAdminAction, "snoop ended: "+name, clientIP(admin.Conn))
+	return fmt.Sprintf("You stop snooping %s.\r\n", name)
+}
+
+// handleSwitchCommand implements "switch <npc>" for an NPC in the admin's room
+func handleSwitchCommand(w *World, admin *Player, arg string) string {
+	if !isAdmin(admin) {
+		return "Unknown.\r\n"
+	}
+	name := strings.ToLower(strings.TrimSpace(arg))
+	if name == "" {
+		if npc := switchedNPC(admin.Conn); npc != nil {
+			return fmt.Sprintf("You are switched into %s. Type 'return' to stop.\r\n", npc.Name)
+		}
+		return "Usage: switch <npc>   (return to stop)\r\n"
+	}
+
+	w.mutex.RLock()
+	var npc *NPC
+	if room := w.Rooms[admin.RoomID]; room != nil {
+		for _, n := range room.NPCMap {
+			if !n.IsDead && (strings.Contains(strings.ToLower(n.Name), name) || n.ID == name) {
+				npc = n
+				break
+			}
+		}
+	}
+	w.mutex.RUnlock()
+	if npc == nil {
+		return "Not here.\r\n"
+	}
+
+	support.mu.Lock()
+	for c, other := range support.switched {
+		if other == npc && c != admin.Conn {
+			support.mu.Unlock()
+			return fmt.Sprintf("%s is already being puppeted.\r\n", npc.Name)
+		}
+	}
+	support.switched[admin.Conn] = npc
+	support.mu.Unlock()
+
+	recordAudit(admin.Name, db.AuditAdminAction, fmt.Sprintf("switch into %s (%s)", npc.Name, npc.ID), clientIP(admin.Conn))
+	return fmt.Sprintf("%sYou are now %s. say, emote and movement act as the NPC; 'return' to stop.%s\r\n", Cyan, npc.Name, Reset)
+}
+
+// endSwitch releases the admin's NPC, if any
+func endSwitch(admin *Player) string {
+	support.mu.Lock()
+	npc := support.switched[admin.Conn]
+	delete(support.switched, admin.Conn)
+	support.mu.Unlock()
+	if npc == nil {
+		return ""
+	}
+	recordAudit(admin.Name, db.AuditAdminAction, fmt.Sprintf("return from %s (%s)", npc.Name, npc.ID), clientIP(admin.Conn))
+	return fmt.Sprintf("You leave %s and return to your body.\r\n", npc.Name)
+}
+
+// handleReturnCommand ends the admin's switch and snoop
+func handleReturnCommand(admin *Player) string {
+	if !isAdmin(admin) {
+		return "Unknown.\r\n"
+	}
+	response := endSwitch(admin)
+	if snoopTarget(admin.Conn) != "" {
+		response += endSnoop(admin)
+	}
+	if response == "" {
+		return "You are not switched or snooping.\r\n"
+	}
+	return response
+}
+
+// switchDirections maps movement commands to exit names
+var switchDirections = map[string]string{
+	"north": "north", "n": "north",
+	"south": "south", "s": "south",
+	"east": "east", "e": "east",
+	"west": "west", "w": "west",
+	"up": "up", "u": "up",
+	"down": "down", "dn": "down",
+}
+
+// handleSwitchedCommand runs say, emote and movement as the admin's
+// puppeted NPC. It reports false for other commands, which the admin
+// then issues as themselves.
+func handleSwitchedCommand(w *World, admin *Player, cmd, arg string) (string, bool) {
+	npc := switchedNPC(admin.Conn)
+	if npc == nil {
+		return "", false
+	}
+	dir, isMove := switchDirections[cmd]
+	if cmd != "say" && cmd != "emote" && !isMove && !((cmd == "look" || cmd == "l") && arg == "") {
+		return "", false
+	}
+	as := fmt.Sprintf("%s[as %s]%s ", Cyan, npc.Name, Reset)
+
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if npc.IsDead {
+		support.mu.Lock()
+		delete(support.switched, admin.Conn)
+		support.mu.Unlock()
+		return fmt.Sprintf("%s has died. You return to your body.\r\n", npc.Name), true
+	}
+
+	switch cmd {
+	case "say":
+		if arg == "" {
+			return as + "Say what?\r\n", true
+		}
+		w.Broadcast(npc.RoomID, admin, fmt.Sprintf("\r\n%s%s says: \"%s\"%s\r\n> ", White, npc.Name, arg, Green))
+		return fmt.Sprintf("%s%s says: \"%s\"\r\n", as, npc.Name, arg), true
+	case "emote":
+		if arg == "" {
+			return as + "Emote what?\r\n", true
+		}
+		w.Broadcast(npc.RoomID, admin, fmt.Sprintf("\r\n%s%s %s%s\r\n> ", White, npc.Name, arg, Green))
+		return fmt.Sprintf("%s%s %s\r\n", as, npc.Name, arg), true
+	case "look", "l":
+		room := w.Rooms[npc.RoomID]
+		if room == nil {
+			return as + "The NPC is nowhere.\r\n", true
+		}
+		var here []string
+		for _, p := range w.Players {
+			if p.RoomID == room.ID {
+				here = append(here, p.Name)
+			}
+		}
+		var exits []string
+		for _, dir := range visibleExits(admin, room) {
+			exits = append(exits, exitLabel(dir, room.Exits[dir]))
+		}
+		return fmt.Sprintf("%s%s (%s)\r\n%s\r\nExits: %s\r\nPlayers: %s\r\n", as, room.ID, npc.Name, room.Description,
+			strings.Join(exits, ", "), strings.Join(here, ", ")), true
+	}
+
+	from := w.Rooms[npc.RoomID]
+	if from == nil {
+		return as + "No exit.\r\n", true
+	}
+	// The NPC keeps to the exits its controller can see, and doors stop it
+	exit, ok := from.Exits[dir]
+	if !ok || !seesExit(admin, from.ID, dir, exit) || w.Rooms[exit.To] == nil {
+		return as + "No exit.\r\n", true
+	}
+	if exit.Closed {
+		return fmt.Sprintf("%sThe %s is closed.\r\n", as, exit.Door), true
+	}
+	next := exit.To
+	w.moveNPC(npc, from, w.Rooms[next], dir)
+	return fmt.Sprintf("%s%s walks %s to %s.\r\n", as, npc.Name, dir, next), true
+}
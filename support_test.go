@@ -0,0 +1,177 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/yourusername/matrix-mud/pkg/db"
+)
+
+// setupSupport creates a world with two admins and a player in the dojo,
+// next to a street, with an NPC to puppet
+func setupSupport(t *testing.T) (*World, *Player, *Player, *Player, *db.AuditRepository) {
+	t.Helper()
+	withModeration(t, "morpheus,trinity")
+	repo := withAuditLog(t)
+
+	morpheus := &Player{Name: "Morpheus", RoomID: "dojo", Conn: &Client{conn: newMockConn("")}}
+	trinity := &Player{Name: "Trinity", RoomID: "street", Conn: &Client{conn: newMockConn("")}}
+	neo := &Player{Name: "Neo", RoomID: "street", Conn: &Client{conn: newMockConn("")}}
+	oracle := &NPC{ID: "oracle", Name: "The Oracle", RoomID: "dojo"}
+	w := &World{
+		Rooms: map[string]*Room{
+			"dojo":   {ID: "dojo", Description: "A dojo.", Exits: map[string]*Exit{"north": {To: "street"}}, NPCMap: map[string]*NPC{"oracle": oracle}, NPCs: []*NPC{oracle}},
+			"street": {ID: "street", Description: "A street.", Exits: map[string]*Exit{"south": {To: "dojo"}}, NPCMap: map[string]*NPC{}},
+		},
+		Players: map[*Client]*Player{morpheus.Conn: morpheus, trinity.Conn: trinity, neo.Conn: neo},
+	}
+	t.Cleanup(func() {
+		for _, p := range []*Player{morpheus, trinity, neo} {
+			endSupportSessions(p.Conn, p)
+		}
+	})
+	return w, morpheus, trinity, neo, repo
+}
+
+func supportOutput(p *Player) string {
+	return p.Conn.conn.(*mockConn).output()
+}
+
+func TestSnoop(t *testing.T) {
+	w, morpheus, trinity, neo, repo := setupSupport(t)
+
+	if got := handleSnoopCommand(w, neo, "morpheus"); got != "Unknown.\r\n" {
+		t.Errorf("non-admin snoop = %q", got)
+	}
+	if got := handleSnoopCommand(w, morpheus, "trinity"); !strings.Contains(got, "another admin") {
+		t.Errorf("snooping an admin = %q", got)
+	}
+	if got := handleSnoopCommand(w, morpheus, "morpheus"); !strings.Contains(got, "yourself") {
+		t.Errorf("snooping yourself = %q", got)
+	}
+	if got := handleSnoopCommand(w, morpheus, "smith"); !strings.Contains(got, "not online") {
+		t.Errorf("snooping nobody = %q", got)
+	}
+
+	if got := handleSnoopCommand(w, morpheus, "neo"); !strings.Contains(got, "Snooping Neo") {
+		t.Fatalf("snoop = %q", got)
+	}
+	neo.Conn.Write("You see a street.\r\n> ")
+	neo.Conn.echoInput("look")
+	mirrored := supportOutput(morpheus)
+	if !strings.Contains(mirrored, "[snoop Neo] "+Reset+"You see a street.") {
+		t.Errorf("mirrored output missing indicator:\n%q", mirrored)
+	}
+	if !strings.Contains(mirrored, "[snoop Neo] "+Reset+"> look") {
+		t.Errorf("mirrored input missing:\n%q", mirrored)
+	}
+	if strings.Contains(supportOutput(trinity), "You see a street") {
+		t.Error("output mirrored to an admin who is not snooping")
+	}
+
+	if got := handleReturnCommand(morpheus); got != "You stop snooping Neo.\r\n" {
+		t.Errorf("return = %q", got)
+	}
+	before := supportOutput(morpheus)
+	neo.Conn.Write("after return\r\n")
+	if supportOutput(morpheus) != before {
+		t.Error("output still mirrored after return")
+	}
+
+	logs, _ := repo.Find(db.AuditFilter{PlayerName: "Morpheus"})
+	if len(logs) != 2 {
+		t.Errorf("audit entries = %d, want snoop and snoop ended", len(logs))
+	}
+}
+
+func TestSnoopEndsWhenTargetLeaves(t *testing.T) {
+	w, morpheus, _, neo, _ := setupSupport(t)
+	handleSnoopCommand(w, morpheus, "neo")
+
+	endSupportSessions(neo.Conn, neo)
+	if !strings.Contains(supportOutput(morpheus), "Neo disconnected. Snoop ended.") {
+		t.Errorf("snooper not told:\n%q", supportOutput(morpheus))
+	}
+	if snoopTarget(morpheus.Conn) != "" || neo.Conn.snoopers.Load() != nil {
+		t.Error("snoop not cleared")
+	}
+}
+
+func TestSwitch(t *testing.T) {
+	w, morpheus, trinity, neo, repo := setupSupport(t)
+
+	if got := handleSwitchCommand(w, neo, "oracle"); got != "Unknown.\r\n" {
+		t.Errorf("non-admin switch = %q", got)
+	}
+	if got := handleSwitchCommand(w, morpheus, "smith"); got != "Not here.\r\n" {
+		t.Errorf("switch to absent NPC = %q", got)
+	}
+	if got := handleSwitchCommand(w, morpheus, "oracle"); !strings.Contains(got, "You are now The Oracle") {
+		t.Fatalf("switch = %q", got)
+	}
+
+	// Commands other than say, emote, look and movement are the admin's own
+	if _, ok := handleSwitchedCommand(w, morpheus, "score", ""); ok {
+		t.Error("score should not be handled as the NPC")
+	}
+
+	// Move the Oracle north to the street, where Neo and Trinity are
+	if got, ok := handleSwitchedCommand(w, morpheus, "n", ""); !ok || !strings.Contains(got, "walks north to street") {
+		t.Fatalf("move = %q, %v", got, ok)
+	}
+	if w.Rooms["street"].NPCMap["oracle"] == nil || w.Rooms["dojo"].NPCMap["oracle"] != nil {
+		t.Fatal("NPC did not move")
+	}
+	if !strings.Contains(supportOutput(neo), "The Oracle arrives.") {
+		t.Errorf("arrival not announced:\n%q", supportOutput(neo))
+	}
+
+	handleSwitchedCommand(w, morpheus, "say", "Would you like a cookie?")
+	handleSwitchedCommand(w, morpheus, "emote", "smiles knowingly.")
+	for _, p := range []*Player{neo, trinity} {
+		out := supportOutput(p)
+		if !strings.Contains(out, `The Oracle says: "Would you like a cookie?"`) || !strings.Contains(out, "The Oracle smiles knowingly.") {
+			t.Errorf("%s did not hear the NPC:\n%q", p.Name, out)
+		}
+	}
+	if morpheus.RoomID != "dojo" {
+		t.Error("admin's body moved")
+	}
+	if got, _ := handleSwitchedCommand(w, morpheus, "look", ""); !strings.Contains(got, "A street.") {
+		t.Errorf("look as NPC = %q", got)
+	}
+
+	// Closed doors and hidden exits hold the NPC as they would a player
+	w.Rooms["street"].Exits["east"] = &Exit{To: "dojo", Door: "gate", Closed: true}
+	w.Rooms["street"].Exits["down"] = &Exit{To: "dojo", Hidden: true}
+	if got, _ := handleSwitchedCommand(w, morpheus, "look", ""); !strings.Contains(got, "Exits: east: closed gate, south\r\n") {
+		t.Errorf("look as NPC should hide hidden exits = %q", got)
+	}
+	if got, _ := handleSwitchedCommand(w, morpheus, "e", ""); !strings.Contains(got, "The gate is closed.") {
+		t.Errorf("move through a closed door = %q", got)
+	}
+	if got, _ := handleSwitchedCommand(w, morpheus, "dn", ""); !strings.Contains(got, "No exit.") || w.Rooms["street"].NPCMap["oracle"] == nil {
+		t.Errorf("move through a hidden exit = %q", got)
+	}
+
+	// Only one admin can puppet an NPC at a time
+	trinity.RoomID = "street"
+	if got := handleSwitchCommand(w, trinity, "oracle"); !strings.Contains(got, "already being puppeted") {
+		t.Errorf("second switch = %q", got)
+	}
+
+	if got := handleReturnCommand(morpheus); !strings.Contains(got, "return to your body") {
+		t.Errorf("return = %q", got)
+	}
+	if _, ok := handleSwitchedCommand(w, morpheus, "say", "hello"); ok {
+		t.Error("say still handled as the NPC after return")
+	}
+	if got := handleReturnCommand(morpheus); got != "You are not switched or snooping.\r\n" {
+		t.Errorf("second return = %q", got)
+	}
+
+	logs, _ := repo.Find(db.AuditFilter{PlayerName: "Morpheus"})
+	if len(logs) != 2 {
+		t.Errorf("audit entries = %d, want switch and return", len(logs))
+	}
+}
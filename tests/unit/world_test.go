@@ -8,15 +8,15 @@ import (
 
 // Room represents a room structure for testing JSON loading
 type Room struct {
-	ID          string            `json:"ID"`
-	Description string            `json:"Description"`
-	Exits       map[string]string `json:"Exits"`
-	Symbol      string            `json:"Symbol"`
-	Color       string            `json:"Color"`
-	Items       []Item            `json:"Items"`
-	NPCs        []NPC             `json:"NPCs"`
-	ItemMap     map[string]*Item  `json:"ItemMap"`
-	NPCMap      map[string]*NPC   `json:"NPCMap"`
+	ID          string           `json:"ID"`
+	Description string           `json:"Description"`
+	Exits       map[string]any   `json:"Exits"` // Room ID, or an exit object with a door
+	Symbol      string           `json:"Symbol"`
+	Color       string           `json:"Color"`
+	Items       []Item           `json:"Items"`
+	NPCs        []NPC            `json:"NPCs"`
+	ItemMap     map[string]*Item `json:"ItemMap"`
+	NPCMap      map[string]*NPC  `json:"NPCMap"`
 }
 
 // Item represents an item for testing
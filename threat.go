@@ -0,0 +1,266 @@
+// Package main runs group combat through NPC threat tables. Every NPC in a
+// fight remembers how much threat each player has built up against it and
+// attacks whoever holds the most:
+//
+//	assist <name>  - Attack the NPC a party member is fighting
+//
+// Damage adds threat one for one, healing adds half the amount healed to
+// every NPC fighting the healed player, and skills such as taunt add extra.
+// Rebels draw half as much again from everything they do, so they hold an
+// NPC's attention as tanks. An NPC only turns on a new target once that
+// player's threat is more than ThreatSwitchPercent above its current one.
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/yourusername/matrix-mud/pkg/effects"
+	"github.com/yourusername/matrix-mud/pkg/game"
+	"github.com/yourusername/matrix-mud/pkg/party"
+)
+
+const (
+	ThreatSwitchPercent = 10                 // Lead needed to pull an NPC off its target
+	NPCAttackInterval   = game.RoundInterval // Time between an NPC's attacks
+)
+
+// threatMultiplier returns the percent of threat a class generates
+func threatMultiplier(class string) int {
+	switch class {
+	case "Rebel":
+		return 150
+	case "Operator":
+		return 80
+	}
+	return 100
+}
+
+// addThreat adds threat against p to an NPC's table and puts the NPC into
+// combat. An amount of 0 only adds p to the table.
+func (w *World) addThreat(npc *NPC, p *Player, amount int) {
+	if npc.Threat == nil {
+		npc.Threat = make(map[string]int)
+	}
+	npc.Threat[p.Name] += amount * threatMultiplier(p.Class) / 100
+	if npc.CombatTarget == "" {
+		npc.CombatTarget = p.Name
+	}
+	npc.State = "COMBAT"
+}
+
+// healThreat adds threat for healer on every NPC in the room fighting ally
+func (w *World) healThreat(healer, ally *Player, amount int) {
+	room := w.Rooms[ally.RoomID]
+	if room == nil || amount <= 0 {
+		return
+	}
+	for _, npc := range room.NPCMap {
+		if _, ok := npc.Threat[ally.Name]; ok && !npc.IsDead {
+			w.addThreat(npc, healer, amount/2)
+		}
+	}
+}
+
+// dropThreat removes a player from the threat tables of the NPCs in their
+// room, when they stop fighting or die
+func (w *World) dropThreat(p *Player) {
+	room := w.Rooms[p.RoomID]
+	if room == nil {
+		return
+	}
+	for _, npc := range room.NPCMap {
+		delete(npc.Threat, p.Name)
+		if npc.CombatTarget == p.Name {
+			npc.CombatTarget = ""
+		}
+	}
+}
+
+// threatString lists an NPC's threat table, highest first, e.g.
+// "Morpheus 45, Neo 30"
+func threatString(npc *NPC) string {
+	names := threatOrder(npc)
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = fmt.Sprintf("%s %d", name, npc.Threat[name])
+	}
+	return strings.Join(parts, ", ")
+}
+
+// threatOrder returns the names on an NPC's threat table, highest threat
+// first and then by name
+func threatOrder(npc *NPC) []string {
+	names := make([]string, 0, len(npc.Threat))
+	for name := range npc.Threat {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if npc.Threat[names[i]] != npc.Threat[names[j]] {
+			return npc.Threat[names[i]] > npc.Threat[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+// npcTarget drops players who have left the room from an NPC's threat
+// table and returns the player it should attack, switching from its
+// current target only when another player has a clear lead
+func (w *World) npcTarget(npc *NPC, room *Room) *Player {
+	present := make(map[string]*Player)
+	for _, p := range w.Players {
+		if p.RoomID == room.ID && p.HP > 0 {
+			present[p.Name] = p
+		}
+	}
+	for name := range npc.Threat {
+		if present[name] == nil {
+			delete(npc.Threat, name)
+		}
+	}
+	order := threatOrder(npc)
+	if len(order) == 0 {
+		npc.CombatTarget = ""
+		return nil
+	}
+	top := order[0]
+	current, ok := npc.Threat[npc.CombatTarget]
+	if ok && npc.Threat[top]*100 <= current*(100+ThreatSwitchPercent) {
+		return present[npc.CombatTarget]
+	}
+	if npc.CombatTarget != top {
+		if ok {
+			w.Broadcast(room.ID, nil, fmt.Sprintf("\r\n%s%s turns on %s!%s\r\n> ", Red, npc.Name, top, Reset))
+		}
+		npc.CombatTarget = top
+	}
+	return present[top]
+}
+
+// npcCombatRounds gives every NPC in a fight its attack once its interval
+// has passed. NPCs act in room and ID order so seeded fights replay exactly.
+func (w *World) npcCombatRounds(now time.Time) {
+	type fighter struct {
+		npc  *NPC
+		room *Room
+	}
+	var fighters []fighter
+	for _, room := range w.Rooms {
+		for _, npc := range room.NPCMap {
+			if len(npc.Threat) > 0 && !npc.IsDead && npc.HP > 0 {
+				fighters = append(fighters, fighter{npc, room})
+			}
+		}
+	}
+	sort.Slice(fighters, func(i, j int) bool {
+		if fighters[i].room.ID != fighters[j].room.ID {
+			return fighters[i].room.ID < fighters[j].room.ID
+		}
+		return fighters[i].npc.ID < fighters[j].npc.ID
+	})
+	for _, f := range fighters {
+		if now.Sub(f.npc.LastAttack) <= NPCAttackInterval || peaceBlocks(f.room) != "" {
+			continue
+		}
+		target := w.npcTarget(f.npc, f.room)
+		if target == nil {
+			f.npc.State = "IDLE"
+			continue
+		}
+		f.npc.LastAttack = now
+		w.npcCombatRound(f.npc, target, now)
+	}
+}
+
+// npcCombatRound resolves one NPC attack on a player. A player who is not
+// already fighting fights back.
+func (w *World) npcCombatRound(npc *NPC, p *Player, now time.Time) {
+	if p.State != "COMBAT" {
+		p.State = "COMBAT"
+		p.Target = npc.ID
+		p.LastAttack = now
+	}
+	var output string
+	if npc.Effects.Controlled(effects.Stun, now) {
+		output = fmt.Sprintf("%s is stunned.", npc.Name)
+	} else if result := game.Attack(w.rng(), w.npcCombatant(npc, now), w.playerCombatant(p, now)); result.Hit {
+		p.HP -= result.Damage
+		if result.Critical {
+			output = fmt.Sprintf("%sCRITICAL!%s %s strikes you for %d damage!", Yellow, Reset, npc.Name, result.Damage)
+		} else {
+			output = fmt.Sprintf("%s hits you for %d damage!", npc.Name, result.Damage)
+		}
+		if p.HP <= 0 {
+			output += "\r\n" + w.playerDeath(p, npc.Name)
+		}
+	} else {
+		output = missMessage(result, npc.Name, "you")
+	}
+	if p.Conn != nil {
+		p.Conn.Write(Matrixify("\r\n" + output + "\r\n"))
+	}
+}
+
+// playersByName returns the online players sorted by name, so combat
+// rounds resolve in the same order every tick
+func (w *World) playersByName() []*Player {
+	list := make([]*Player, 0, len(w.Players))
+	for _, p := range w.Players {
+		list = append(list, p)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// creditGroup returns the players who share a kill of npc: the killer and
+// the living party members in the same room who took part in the fight, so
+// are on the NPC's threat table
+func (w *World) creditGroup(p *Player, npc *NPC) []*Player {
+	group := []*Player{p}
+	for _, member := range party.GlobalParty.GetMembers(p.Name) {
+		if strings.EqualFold(member, p.Name) {
+			continue
+		}
+		for _, other := range w.Players {
+			if !strings.EqualFold(other.Name, member) || other.RoomID != p.RoomID || other.Ghost {
+				continue
+			}
+			if _, fought := npc.Threat[other.Name]; fought {
+				group = append(group, other)
+			}
+		}
+	}
+	return group
+}
+
+// Assist joins a party member's fight, attacking the NPC they are fighting
+func (w *World) Assist(p *Player, name string) string {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if name == "" {
+		return "Assist whom?"
+	}
+	ally := w.findPlayerInRoom(p.RoomID, name)
+	if ally == nil || ally == p {
+		return "They aren't here."
+	}
+	if !party.GlobalParty.AreInSameParty(p.Name, ally.Name) {
+		return fmt.Sprintf("%s is not in your party.", ally.Name)
+	}
+	room := w.Rooms[p.RoomID]
+	npc, ok := room.NPCMap[ally.Target]
+	if ally.State != "COMBAT" || !ok || npc.IsDead {
+		return fmt.Sprintf("%s is not fighting anything.", ally.Name)
+	}
+	p.State = "COMBAT"
+	p.Target = npc.ID
+	p.LastAttack = w.now().Add(-2 * time.Second)
+	w.addThreat(npc, p, 0)
+	if ally.Conn != nil {
+		ally.Conn.Write(Matrixify(fmt.Sprintf("\r\n%s assists you against %s!\r\n> ", p.Name, npc.Name)))
+	}
+	return fmt.Sprintf("You assist %s against %s!", ally.Name, npc.Name)
+}
@@ -0,0 +1,262 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourusername/matrix-mud/pkg/party"
+	"github.com/yourusername/matrix-mud/pkg/sim"
+)
+
+// addFighter puts another player in the dojo
+func addFighter(w *World, name, class string, strength int) *Player {
+	p := &Player{Name: name, Class: class, Level: 4, RoomID: "dojo", HP: 100, MaxHP: 100, MP: 100, MaxMP: 100,
+		Strength: strength, BaseAC: 10, State: "IDLE", Equipment: map[string]*Item{}, Conn: &Client{conn: newMockConn("")}}
+	w.Players[p.Conn] = p
+	return p
+}
+
+// formParty puts the players in a party led by the first
+func formParty(t *testing.T, players ...*Player) {
+	t.Helper()
+	leader := players[0].Name
+	if _, err := party.GlobalParty.Create(leader); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { party.GlobalParty.Disband(leader) })
+	for _, p := range players[1:] {
+		if err := party.GlobalParty.Invite(leader, p.Name); err != nil {
+			t.Fatal(err)
+		}
+		if err := party.GlobalParty.Accept(p.Name, leader); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// setupFight builds a dojo of agents and Neo, a Rebel tank, on a manual clock
+func setupFight(t *testing.T, agents int) (*World, *Player, []*NPC, *sim.ManualClock) {
+	t.Helper()
+	w, tank := setupSkills(t, "Neo", "Rebel", 4)
+	clock := sim.NewManualClock(time.Unix(1000, 0))
+	w.Clock = clock
+	w.RNG = sim.NewRand(42)
+	room := w.Rooms["dojo"]
+	room.NPCMap = map[string]*NPC{}
+	var npcs []*NPC
+	for i := 0; i < agents; i++ {
+		id := string(rune('a' + i))
+		npc := &NPC{ID: "agent_" + id, Name: "Agent " + strings.ToUpper(id), RoomID: "dojo", HP: 40, MaxHP: 40, AC: 10, Damage: 5, XP: 300, DropMoney: 30}
+		room.NPCMap[npc.ID] = npc
+		npcs = append(npcs, npc)
+	}
+	tank.HP, tank.MaxHP, tank.Strength, tank.BaseAC = 100, 100, 14, 10
+	return w, tank, npcs, clock
+}
+
+// fight runs world ticks until every agent is dead and returns how many it took
+func fight(t *testing.T, w *World, clock *sim.ManualClock, npcs []*NPC) int {
+	t.Helper()
+	for i := 1; i <= 400; i++ {
+		clock.Advance(500 * time.Millisecond)
+		w.Update()
+		alive := 0
+		for _, npc := range npcs {
+			if !npc.IsDead {
+				alive++
+			}
+		}
+		if alive == 0 {
+			return i
+		}
+	}
+	t.Fatal("fight did not finish")
+	return 0
+}
+
+func TestBalanceOneOnOne(t *testing.T) {
+	w, tank, npcs, clock := setupFight(t, 1)
+	w.StartCombat(tank, "agent_a")
+	ticks := fight(t, w, clock, npcs)
+	if tank.Ghost || tank.HP >= tank.MaxHP {
+		t.Errorf("tank should win hurt: ghost %v, HP %d", tank.Ghost, tank.HP)
+	}
+	if tank.XP != 300 || tank.Money != 30 {
+		t.Errorf("solo reward: XP %d, money %d", tank.XP, tank.Money)
+	}
+	if ticks < 20 {
+		t.Errorf("1v1 took %d ticks; agents are too weak", ticks)
+	}
+}
+
+func TestBalanceThreeOnOne(t *testing.T) {
+	w, tank, npcs, clock := setupFight(t, 1)
+	solo := setupSoloTicks(t)
+	trinity := addFighter(w, "Trinity", "Operator", 12)
+	morpheus := addFighter(w, "Morpheus", "Hacker", 12)
+	formParty(t, tank, trinity, morpheus)
+
+	w.StartCombat(tank, "agent_a")
+	if got := w.Assist(trinity, "neo"); !strings.Contains(got, "You assist Neo against Agent A!") {
+		t.Fatalf("assist = %q", got)
+	}
+	w.Assist(morpheus, "neo")
+	ticks := fight(t, w, clock, npcs)
+
+	if ticks*3 > solo*2 {
+		t.Errorf("3v1 took %d ticks, 1v1 %d; the group should win at least 1.5 times as fast", ticks, solo)
+	}
+	// The Rebel's threat bonus keeps the agent on the tank
+	if taken := tank.MaxHP - tank.HP; taken <= (trinity.MaxHP-trinity.HP)+(morpheus.MaxHP-morpheus.HP) {
+		t.Errorf("damage taken: tank %d, trinity %d, morpheus %d", tank.MaxHP-tank.HP, trinity.MaxHP-trinity.HP, morpheus.MaxHP-morpheus.HP)
+	}
+	// 300 XP with a 20% party bonus, split three ways
+	for _, p := range []*Player{tank, trinity, morpheus} {
+		if p.XP != 120 || p.State != "IDLE" {
+			t.Errorf("%s: XP %d, state %s", p.Name, p.XP, p.State)
+		}
+	}
+	if tank.Money != 10 || trinity.Money != 10 || morpheus.Money != 10 {
+		t.Errorf("money split: %d, %d, %d", tank.Money, trinity.Money, morpheus.Money)
+	}
+}
+
+// setupSoloTicks returns how long the tank takes to beat an agent alone
+func setupSoloTicks(t *testing.T) int {
+	t.Helper()
+	w, tank, npcs, clock := setupFight(t, 1)
+	w.StartCombat(tank, "agent_a")
+	return fight(t, w, clock, npcs)
+}
+
+func TestBalanceThreeOnThree(t *testing.T) {
+	w, tank, npcs, clock := setupFight(t, 3)
+	trinity := addFighter(w, "Trinity", "Operator", 12)
+	morpheus := addFighter(w, "Morpheus", "Hacker", 12)
+	formParty(t, tank, trinity, morpheus)
+
+	w.StartCombat(tank, "agent_a")
+	w.StartCombat(trinity, "agent_b")
+	w.StartCombat(morpheus, "agent_c")
+	fight(t, w, clock, npcs)
+
+	for _, p := range []*Player{tank, trinity, morpheus} {
+		if p.Ghost {
+			t.Errorf("%s died in an even fight", p.Name)
+		}
+		// Each fought only their own agent, so takes that kill alone
+		if p.XP != 300 {
+			t.Errorf("%s: XP %d, want 300", p.Name, p.XP)
+		}
+	}
+	for _, npc := range npcs {
+		if len(npc.Threat) != 0 || npc.CombatTarget != "" {
+			t.Errorf("%s kept threat %v after dying", npc.Name, npc.Threat)
+		}
+	}
+}
+
+func TestThreatSwitchAndHealing(t *testing.T) {
+	w, tank, npcs, clock := setupFight(t, 1)
+	agent := npcs[0]
+	trinity := addFighter(w, "Trinity", "Operator", 12)
+	room := w.Rooms["dojo"]
+
+	w.addThreat(agent, tank, 20) // 30 with the Rebel bonus
+	if agent.CombatTarget != "Neo" || agent.Threat["Neo"] != 30 {
+		t.Fatalf("threat = %v, target %s", agent.Threat, agent.CombatTarget)
+	}
+	// Healing the tank draws half the healing as threat
+	w.healThreat(trinity, tank, 80)
+	if agent.Threat["Trinity"] != 32 {
+		t.Errorf("heal threat = %d, want 32", agent.Threat["Trinity"])
+	}
+	// A lead of 10% or less does not pull the agent off its target
+	if got := w.npcTarget(agent, room); got != tank {
+		t.Errorf("target with a small lead = %v", got.Name)
+	}
+	w.addThreat(agent, trinity, 5) // 36 vs 30
+	if got := w.npcTarget(agent, room); got != trinity {
+		t.Errorf("target with a clear lead = %v", got.Name)
+	}
+	if got := tank.Conn.conn.(*mockConn).output(); !strings.Contains(got, "Agent A turns on Trinity!") {
+		t.Errorf("switch message = %q", got)
+	}
+	if got := w.Look(tank, "agent a"); !strings.Contains(got, "Threat: Trinity 36, Neo 30") {
+		t.Errorf("look = %q", got)
+	}
+
+	// Leaving the fight drops a player from the table
+	trinity.State = "COMBAT"
+	w.StopCombat(trinity)
+	clock.Advance(2 * time.Second)
+	w.npcCombatRounds(clock.Now())
+	if _, ok := agent.Threat["Trinity"]; ok || agent.CombatTarget != "Neo" {
+		t.Errorf("after flee: threat %v, target %s", agent.Threat, agent.CombatTarget)
+	}
+	if tank.State != "COMBAT" || tank.Target != "agent_a" {
+		t.Errorf("tank should fight back: %s/%s", tank.State, tank.Target)
+	}
+}
+
+func TestAssistAndTaunt(t *testing.T) {
+	w, tank, npcs, _ := setupFight(t, 1)
+	trinity := addFighter(w, "Trinity", "Operator", 12)
+
+	if got := w.Assist(trinity, "neo"); got != "Neo is not in your party." {
+		t.Errorf("assist stranger = %q", got)
+	}
+	formParty(t, tank, trinity)
+	if got := w.Assist(trinity, "neo"); got != "Neo is not fighting anything." {
+		t.Errorf("assist idle = %q", got)
+	}
+
+	trinity.Skills = map[string]int{"strike": MaxProficiency}
+	w.StartCombat(trinity, "agent_a")
+	w.addThreat(npcs[0], trinity, 40)
+	tank.Skills = map[string]int{"taunt": MaxProficiency}
+	if got := w.CastSkill(tank, "taunt", "agent"); !strings.Contains(got, "You taunt Agent A!") {
+		t.Fatalf("taunt = %q", got)
+	}
+	if got := w.npcTarget(npcs[0], w.Rooms["dojo"]); got != tank {
+		t.Errorf("taunted agent attacks %v, threat %v", got.Name, npcs[0].Threat)
+	}
+}
+
+func TestPartyLootSplit(t *testing.T) {
+	w, tank, npcs, _ := setupFight(t, 1)
+	w.ItemTemplates = map[string]*Item{
+		"red_pill":  {ID: "red_pill", Name: "Red Pill", Type: "consumable"},
+		"blue_pill": {ID: "blue_pill", Name: "Blue Pill", Type: "consumable"},
+	}
+	trinity := addFighter(w, "Trinity", "Operator", 12)
+	absent := addFighter(w, "Dozer", "Rebel", 12)
+	absent.RoomID = "street"
+	idle := addFighter(w, "Tank", "Rebel", 12)
+	ghost := addFighter(w, "Mouse", "Rebel", 12)
+	ghost.Ghost = true
+	formParty(t, tank, trinity, absent, idle, ghost)
+	npcs[0].Loot = []string{"red_pill", "blue_pill"}
+	w.addThreat(npcs[0], trinity, 1)
+	w.addThreat(npcs[0], ghost, 1)
+
+	// Only the two members who fought share the XP, with their party bonus;
+	// the idle member, the ghost and the absent member get nothing
+	out := w.killNPC(tank, npcs[0], w.Rooms["dojo"])
+	if !strings.Contains(out, "You gain 165 XP and 15 Fragments.") {
+		t.Errorf("killer = %q", out)
+	}
+	for _, p := range []*Player{absent, idle, ghost} {
+		if p.XP != 0 || p.Money != 0 || len(p.Inventory) != 0 {
+			t.Errorf("%s got XP %d, money %d, loot %v", p.Name, p.XP, p.Money, p.Inventory)
+		}
+	}
+	corpses := corpsesIn(w.Rooms["dojo"])
+	if len(tank.Inventory) != 1 || len(trinity.Inventory) != 1 || len(corpses) != 1 || len(corpses[0].Contents) != 0 {
+		t.Errorf("loot: tank %d, trinity %d, room %v", len(tank.Inventory), len(trinity.Inventory), w.Rooms["dojo"].ItemMap)
+	}
+	if got := trinity.Conn.conn.(*mockConn).output(); !strings.Contains(got, "You gain 165 XP and 15 Fragments.") || !strings.Contains(got, "Neo receives") {
+		t.Errorf("trinity saw %q", got)
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/yourusername/matrix-mud/pkg/watchdog"
+)
+
+func TestUpdateTickPhases(t *testing.T) {
+	w := &World{Rooms: map[string]*Room{}, Players: map[*Client]*Player{}}
+	wd := watchdog.New(watchdog.Config{})
+	tick := wd.Begin(time.Now())
+	w.UpdateTick(tick)
+	r := wd.End(tick)
+
+	var names []string
+	for _, p := range r.Phases {
+		names = append(names, p.Name)
+	}
+	if got := strings.Join(names, ","); got != "lock,respawn,aggro,combat,effects,corpses,heat_decay,agent_ai,scripts" {
+		t.Errorf("phases = %s", got)
+	}
+}
+
+func TestParseThreshold(t *testing.T) {
+	cases := map[string]time.Duration{
+		"250ms": 250 * time.Millisecond,
+		"2s":    2 * time.Second,
+		"off":   0,
+		"0":     0,
+		"soon":  time.Second, // Invalid, falls back
+	}
+	for value, want := range cases {
+		if got := parseThreshold("TEST", value, time.Second); got != want {
+			t.Errorf("parseThreshold(%q) = %v, want %v", value, got, want)
+		}
+	}
+}
+
+func TestProfilingRoutesRequireAuth(t *testing.T) {
+	setupAdminActions(t)
+	mux := http.NewServeMux()
+	registerProfilingRoutes(mux)
+
+	for _, path := range []string{"/debug/pprof/", "/debug/pprof/goroutine?debug=1", "/debug/pprof/cmdline", "/profiles", "/profiles/file?name=cpu-1.pprof"} {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("GET %s without auth = %d, want 401", path, w.Code)
+		}
+	}
+
+	req := httptest.NewRequest("GET", "/debug/pprof/goroutine?debug=1", nil)
+	req.SetBasicAuth("testadmin", "testpass")
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "goroutine") {
+		t.Errorf("authenticated goroutine profile = %d", w.Code)
+	}
+}
+
+func TestAdminProfiles(t *testing.T) {
+	setupAdminActions(t)
+	dir := t.TempDir()
+	orig := tickWatchdog
+	t.Cleanup(func() { tickWatchdog = orig })
+	tickWatchdog = watchdog.New(watchdog.Config{ProfileDir: dir})
+	os.WriteFile(filepath.Join(dir, "goroutine-20240601-120000.txt"), []byte("goroutine 1 [running]"), 0600)
+	os.WriteFile(filepath.Join(dir, "secret.txt"), []byte("hidden"), 0600)
+
+	get := func(handler http.HandlerFunc, target string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", target, nil)
+		req.SetBasicAuth("testadmin", "testpass")
+		w := httptest.NewRecorder()
+		handler(w, req)
+		return w
+	}
+
+	page := get(adminProfiles, "/profiles").Body.String()
+	if !strings.Contains(page, "goroutine-20240601-120000.txt") || strings.Contains(page, "secret.txt") {
+		t.Errorf("profile list wrong:\n%s", page)
+	}
+
+	w := get(adminProfileFile, "/profiles/file?name=goroutine-20240601-120000.txt")
+	if w.Code != http.StatusOK || w.Body.String() != "goroutine 1 [running]" {
+		t.Errorf("download = %d %q", w.Code, w.Body.String())
+	}
+	for _, name := range []string{"secret.txt", "..%2Fsecret.txt"} {
+		if w := get(adminProfileFile, "/profiles/file?name="+name); w.Code != http.StatusNotFound {
+			t.Errorf("download %s = %d, want 404", name, w.Code)
+		}
+	}
+}
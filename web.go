@@ -5,12 +5,14 @@ import (
 	"net"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/gorilla/websocket"
 	"github.com/yourusername/matrix-mud/pkg/admin"
 	"github.com/yourusername/matrix-mud/pkg/logging"
 	"github.com/yourusername/matrix-mud/pkg/metrics"
+	"github.com/yourusername/matrix-mud/pkg/moderation"
 )
 
 var upgrader = websocket.Upgrader{CheckOrigin: checkWebSocketOrigin}
@@ -77,7 +79,27 @@ func serveHome(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte(htmlClient))
 }
 
+// bridgedAddrs maps the local address of each WebSocket bridge connection
+// to the telnet port, which the server sees as the session's remote address,
+// to the browser's IP
+var bridgedAddrs sync.Map
+
+// browserAddr returns the browser's IP behind a bridged session's address,
+// or addr's own host if it isn't a bridge connection
+func browserAddr(addr string) string {
+	if ip, ok := bridgedAddrs.Load(addr); ok {
+		return ip.(string)
+	}
+	return remoteIP(addr)
+}
+
 func handleWebSocket(w http.ResponseWriter, r *http.Request) {
+	// The bridge dials the telnet port from localhost, so address bans
+	// must be enforced here against the browser's address
+	if s := moderation.GlobalModeration.CheckIP(remoteIP(r.RemoteAddr)); s != nil {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
 	ws, _ := upgrader.Upgrade(w, r, nil)
 	if ws == nil {
 		return
@@ -89,6 +111,8 @@ func handleWebSocket(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	defer tcpConn.Close()
+	bridgedAddrs.Store(tcpConn.LocalAddr().String(), remoteIP(r.RemoteAddr))
+	defer bridgedAddrs.Delete(tcpConn.LocalAddr().String())
 	go func() {
 		buf := make([]byte, 4096)
 		for {
@@ -0,0 +1,313 @@
+// Package main registers outgoing webhooks from WEBHOOKS_FILE and serves the
+// admin console pages that manage them:
+//
+//	GET /webhooks          - List webhooks, recent deliveries and dead letters
+//	POST /webhooks/add     - Register a webhook
+//	POST /webhooks/toggle  - Enable or disable a webhook
+//	POST /webhooks/remove  - Delete a webhook
+//	POST /webhooks/test    - Send a test event to a webhook
+//	POST /webhooks/replay  - Redeliver a dead letter
+//	POST /webhooks/discard - Drop a dead letter
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"html"
+	"net/http"
+	"strings"
+
+	"github.com/yourusername/matrix-mud/pkg/db"
+	"github.com/yourusername/matrix-mud/pkg/events"
+	"github.com/yourusername/matrix-mud/pkg/logging"
+)
+
+// webhookServerID identifies this server in webhook payloads
+const webhookServerID = "matrix-mud"
+
+// webhookManager delivers bus events to registered webhooks, or is nil
+// before startWebhooks runs
+var webhookManager *events.WebhookManager
+
+// startWebhooks loads webhook definitions and the dead-letter queue from
+// Config and starts delivering events from the global bus
+func startWebhooks() {
+	wm := events.NewWebhookManager(events.GlobalEventBus, webhookServerID)
+	if err := wm.Load(Config.WebhooksFile, Config.WebhookDLQFile); err != nil {
+		logging.Error().Err(err).Str("path", Config.WebhooksFile).Msg("Failed to load webhooks")
+	}
+	wm.Start()
+	webhookManager = wm
+	logging.Info().Int("webhooks", len(wm.ListWebhooks())).Int("dead_letters", len(wm.DeadLetters())).Msg("Webhooks started")
+}
+
+// newWebhookID returns a random identifier for a webhook created in the console
+func newWebhookID() string {
+	b := make([]byte, 4)
+	rand.Read(b)
+	return "wh_" + hex.EncodeToString(b)
+}
+
+// saveWebhooks persists webhook definitions after a console change
+func saveWebhooks() {
+	if err := webhookManager.SaveWebhooks(); err != nil {
+		logging.Error().Err(err).Str("path", Config.WebhooksFile).Msg("Failed to save webhooks")
+	}
+}
+
+// adminWebhooks lists webhooks with their recent deliveries and the
+// dead-letter queue
+func adminWebhooks(w http.ResponseWriter, r *http.Request) {
+	if !checkAdminAuth(w, r) {
+		return
+	}
+	renderWebhooks(w, "")
+}
+
+// renderWebhooks writes the webhook page, showing notice above the tables
+func renderWebhooks(w http.ResponseWriter, notice string) {
+	page := `<html><head><title>Construct Webhooks</title>
+	<style>
+		body { background: #111; color: #0f0; font-family: monospace; padding: 20px; }
+		table { border-collapse: collapse; width: 100%; }
+		th, td { border: 1px solid #333; padding: 8px; text-align: left; }
+		th { background: #222; }
+		input, button { background: #000; color: #0f0; border: 1px solid #333; font-family: monospace; }
+		form { display: inline; }
+		.btn { background: #300; color: #fff; }
+		.new { border: 1px solid #0f0; padding: 10px; }
+	</style>
+	</head><body>
+	<h1>/// WEBHOOKS ///</h1>
+	<p><a href="/">&laquo; back</a></p>`
+
+	if webhookManager == nil {
+		page += `<p>Webhooks are not running.</p></body></html>`
+		w.Write([]byte(page))
+		return
+	}
+
+	if notice != "" {
+		page += `<p class="new">` + html.EscapeString(notice) + `</p>`
+	}
+
+	page += fmt.Sprintf(`<h3>Add Webhook</h3>
+	<form method="POST" action="/webhooks/add">%s
+		<input name="name" placeholder="name">
+		<input name="url" placeholder="https://..." size="40">
+		<input name="secret" placeholder="signing secret">
+		<input name="events" placeholder="player.*,auction.sold" size="30">
+		<label><input type="checkbox" name="enabled" value="1" checked> enabled</label>
+		<button type="submit" class="btn">ADD</button>
+	</form>
+	<p>Leave events empty to receive everything; category.* matches a whole category.</p>
+	<h3>Webhooks</h3>
+	<table>
+		<tr><th>ID</th><th>Name</th><th>URL</th><th>Events</th><th>Status</th><th>Last Delivery</th><th>Action</th></tr>`,
+		csrfField())
+
+	for _, wh := range webhookManager.ListWebhooks() {
+		filter := "all"
+		if len(wh.Events) > 0 {
+			types := make([]string, len(wh.Events))
+			for i, et := range wh.Events {
+				types[i] = string(et)
+			}
+			filter = strings.Join(types, ", ")
+		}
+		status, toggle := "disabled", "ENABLE"
+		if wh.Enabled {
+			status, toggle = "enabled", "DISABLE"
+		}
+		last := "never"
+		if deliveries := webhookManager.GetDeliveriesForWebhook(wh.ID, 1); len(deliveries) > 0 {
+			d := deliveries[0]
+			last = d.Timestamp.Format("2006-01-02 15:04") + " ok"
+			if !d.Success {
+				last = d.Timestamp.Format("2006-01-02 15:04") + " " + d.Error
+			}
+		}
+		id := html.EscapeString(wh.ID)
+		page += fmt.Sprintf(`<tr><td>%s</td><td>%s</td><td>%s</td><td>%s</td><td>%s</td><td>%s</td><td>
+			<form method="POST" action="/webhooks/test">%s<input type="hidden" name="id" value="%s"><button type="submit">TEST</button></form>
+			<form method="POST" action="/webhooks/toggle">%s<input type="hidden" name="id" value="%s"><button type="submit">%s</button></form>
+			<form method="POST" action="/webhooks/remove">%s<input type="hidden" name="id" value="%s"><button type="submit" class="btn">DELETE</button></form>
+			</td></tr>`,
+			id, html.EscapeString(wh.Name), html.EscapeString(wh.URL), html.EscapeString(filter), status, html.EscapeString(last),
+			csrfField(), id, csrfField(), id, toggle, csrfField(), id)
+	}
+
+	page += `</table>
+	<h3>Dead Letters</h3>
+	<p>Events that failed every retry. Replay makes one more attempt.</p>
+	<table>
+		<tr><th>Failed</th><th>Webhook</th><th>Event</th><th>Attempts</th><th>Error</th><th>Action</th></tr>`
+
+	deadLetters := webhookManager.DeadLetters()
+	for i := len(deadLetters) - 1; i >= 0; i-- {
+		dl := deadLetters[i]
+		id := html.EscapeString(dl.ID)
+		page += fmt.Sprintf(`<tr><td>%s</td><td>%s</td><td>%s</td><td>%d</td><td>%s</td><td>
+			<form method="POST" action="/webhooks/replay">%s<input type="hidden" name="id" value="%s"><button type="submit">REPLAY</button></form>
+			<form method="POST" action="/webhooks/discard">%s<input type="hidden" name="id" value="%s"><button type="submit" class="btn">DISCARD</button></form>
+			</td></tr>`,
+			dl.FailedAt.Format("2006-01-02 15:04:05"), html.EscapeString(dl.WebhookID), html.EscapeString(string(dl.Event.Type)),
+			dl.Attempts, html.EscapeString(dl.LastError), csrfField(), id, csrfField(), id)
+	}
+
+	page += `</table></body></html>`
+	w.Write([]byte(page))
+}
+
+// webhookAvailable writes an error and returns false if webhooks are not running
+func webhookAvailable(w http.ResponseWriter) bool {
+	if webhookManager == nil {
+		http.Error(w, "Webhooks are not running", http.StatusServiceUnavailable)
+		return false
+	}
+	return true
+}
+
+// adminWebhookAdd registers a webhook from the admin console.
+// Accepts POST form fields: name, url, secret, events (comma-separated), enabled.
+func adminWebhookAdd(w http.ResponseWriter, r *http.Request) {
+	if !checkAdminPost(w, r) || !webhookAvailable(w) {
+		return
+	}
+
+	url := strings.TrimSpace(r.FormValue("url"))
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		http.Error(w, "URL must start with http:// or https://", http.StatusBadRequest)
+		return
+	}
+	wh := &events.WebhookConfig{
+		ID:      newWebhookID(),
+		Name:    strings.TrimSpace(r.FormValue("name")),
+		URL:     url,
+		Secret:  r.FormValue("secret"),
+		Enabled: r.FormValue("enabled") != "",
+	}
+	if wh.Name == "" {
+		wh.Name = wh.ID
+	}
+	for _, t := range strings.Split(r.FormValue("events"), ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			wh.Events = append(wh.Events, events.EventType(t))
+		}
+	}
+	if err := webhookManager.AddWebhook(wh); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	saveWebhooks()
+
+	auditConsole(r, db.AuditAdminAction, fmt.Sprintf("add webhook %s (%s)", wh.ID, wh.URL))
+	http.Redirect(w, r, "/webhooks", http.StatusSeeOther)
+}
+
+// adminWebhookToggle enables a disabled webhook or disables an enabled one.
+// Accepts POST form field: id.
+func adminWebhookToggle(w http.ResponseWriter, r *http.Request) {
+	if !checkAdminPost(w, r) || !webhookAvailable(w) {
+		return
+	}
+
+	id := r.FormValue("id")
+	wh := webhookManager.GetWebhook(id)
+	if wh == nil {
+		http.Error(w, "Webhook not found", http.StatusNotFound)
+		return
+	}
+	action := "enable"
+	if wh.Enabled {
+		action = "disable"
+		webhookManager.DisableWebhook(id)
+	} else {
+		webhookManager.EnableWebhook(id)
+	}
+	saveWebhooks()
+
+	auditConsole(r, db.AuditAdminAction, action+" webhook "+id)
+	http.Redirect(w, r, "/webhooks", http.StatusSeeOther)
+}
+
+// adminWebhookRemove deletes a webhook.
+// Accepts POST form field: id.
+func adminWebhookRemove(w http.ResponseWriter, r *http.Request) {
+	if !checkAdminPost(w, r) || !webhookAvailable(w) {
+		return
+	}
+
+	id := r.FormValue("id")
+	if webhookManager.GetWebhook(id) == nil {
+		http.Error(w, "Webhook not found", http.StatusNotFound)
+		return
+	}
+	webhookManager.RemoveWebhook(id)
+	saveWebhooks()
+
+	auditConsole(r, db.AuditDelete, "remove webhook "+id)
+	http.Redirect(w, r, "/webhooks", http.StatusSeeOther)
+}
+
+// adminWebhookTest sends a test event and shows the result.
+// Accepts POST form field: id.
+func adminWebhookTest(w http.ResponseWriter, r *http.Request) {
+	if !checkAdminPost(w, r) || !webhookAvailable(w) {
+		return
+	}
+
+	id := r.FormValue("id")
+	delivery, err := webhookManager.SendTestEvent(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	auditConsole(r, db.AuditAdminAction, "test webhook "+id)
+	renderWebhooks(w, deliveryNotice("Test event to "+id, delivery))
+}
+
+// adminWebhookReplay redelivers a dead letter and shows the result.
+// Accepts POST form field: id.
+func adminWebhookReplay(w http.ResponseWriter, r *http.Request) {
+	if !checkAdminPost(w, r) || !webhookAvailable(w) {
+		return
+	}
+
+	id := r.FormValue("id")
+	delivery, err := webhookManager.ReplayDeadLetter(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	auditConsole(r, db.AuditAdminAction, fmt.Sprintf("replay dead letter %s to webhook %s", id, delivery.WebhookID))
+	renderWebhooks(w, deliveryNotice("Replay of "+id, delivery))
+}
+
+// adminWebhookDiscard drops a dead letter.
+// Accepts POST form field: id.
+func adminWebhookDiscard(w http.ResponseWriter, r *http.Request) {
+	if !checkAdminPost(w, r) || !webhookAvailable(w) {
+		return
+	}
+
+	id := r.FormValue("id")
+	if err := webhookManager.DiscardDeadLetter(id); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	auditConsole(r, db.AuditDelete, "discard dead letter "+id)
+	http.Redirect(w, r, "/webhooks", http.StatusSeeOther)
+}
+
+// deliveryNotice describes the outcome of a single console-triggered delivery
+func deliveryNotice(what string, d *events.WebhookDelivery) string {
+	if d.Success {
+		return fmt.Sprintf("%s delivered (HTTP %d in %dms).", what, d.ResponseCode, d.Duration.Milliseconds())
+	}
+	return fmt.Sprintf("%s failed: %s", what, d.Error)
+}
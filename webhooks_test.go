@@ -0,0 +1,135 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/matrix-mud/pkg/db"
+	"github.com/yourusername/matrix-mud/pkg/events"
+)
+
+// withWebhooks installs a webhook manager persisting to a temp directory
+func withWebhooks(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	origManager, origFile, origDLQ := webhookManager, Config.WebhooksFile, Config.WebhookDLQFile
+	t.Cleanup(func() {
+		webhookManager, Config.WebhooksFile, Config.WebhookDLQFile = origManager, origFile, origDLQ
+	})
+	Config.WebhooksFile = filepath.Join(dir, "webhooks.json")
+	Config.WebhookDLQFile = filepath.Join(dir, "dlq.json")
+
+	webhookManager = events.NewWebhookManager(events.NewEventBus(1), webhookServerID)
+	if err := webhookManager.Load(Config.WebhooksFile, Config.WebhookDLQFile); err != nil {
+		t.Fatal(err)
+	}
+	return Config.WebhooksFile
+}
+
+// TestAdminWebhooks exercises the webhook console against a local endpoint
+func TestAdminWebhooks(t *testing.T) {
+	_, repo := setupAdminActions(t)
+	file := withWebhooks(t)
+
+	var received []string
+	endpoint := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = append(received, r.Header.Get("X-Event-Type"))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer endpoint.Close()
+
+	if w := adminPost(adminWebhookAdd, url.Values{"url": {"ftp://nope"}}); w.Code != http.StatusBadRequest {
+		t.Errorf("Bad URL: status %d, want 400", w.Code)
+	}
+	w := adminPost(adminWebhookAdd, url.Values{
+		"name": {"ops"}, "url": {endpoint.URL}, "secret": {"shh"}, "events": {"player.*, auction.sold"}, "enabled": {"1"},
+	})
+	if w.Code != http.StatusSeeOther {
+		t.Fatalf("Add: status %d: %s", w.Code, w.Body.String())
+	}
+	hooks := webhookManager.ListWebhooks()
+	if len(hooks) != 1 || hooks[0].Name != "ops" || !hooks[0].Enabled || len(hooks[0].Events) != 2 {
+		t.Fatalf("Webhook not registered: %+v", hooks)
+	}
+	id := hooks[0].ID
+	if data, err := os.ReadFile(file); err != nil || !strings.Contains(string(data), endpoint.URL) {
+		t.Errorf("Webhook not saved: %v", err)
+	}
+
+	w = adminPost(adminWebhookTest, url.Values{"id": {id}})
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "delivered") {
+		t.Errorf("Test: status %d", w.Code)
+	}
+	if len(received) != 1 || received[0] != string(events.EventWebhookTest) {
+		t.Errorf("Endpoint received %v", received)
+	}
+
+	if w := adminPost(adminWebhookToggle, url.Values{"id": {id}}); w.Code != http.StatusSeeOther {
+		t.Errorf("Toggle: status %d", w.Code)
+	}
+	if webhookManager.GetWebhook(id).Enabled {
+		t.Error("Webhook should be disabled")
+	}
+
+	req := httptest.NewRequest("GET", "/webhooks", nil)
+	req.SetBasicAuth("testadmin", "testpass")
+	rec := httptest.NewRecorder()
+	adminWebhooks(rec, req)
+	if !strings.Contains(rec.Body.String(), "ENABLE") || !strings.Contains(rec.Body.String(), "player.*, auction.sold") {
+		t.Error("Page should list the disabled webhook and its filter")
+	}
+
+	if w := adminPost(adminWebhookRemove, url.Values{"id": {id}}); w.Code != http.StatusSeeOther {
+		t.Errorf("Remove: status %d", w.Code)
+	}
+	if len(webhookManager.ListWebhooks()) != 0 {
+		t.Error("Webhook should be removed")
+	}
+
+	if logs, _ := repo.Find(db.AuditFilter{Query: "webhook " + id}); len(logs) != 4 {
+		t.Errorf("Expected add, test, toggle and remove audited, got %d", len(logs))
+	}
+}
+
+// TestAdminWebhookDeadLetters verifies replay and discard from the console
+func TestAdminWebhookDeadLetters(t *testing.T) {
+	setupAdminActions(t)
+	withWebhooks(t)
+
+	endpoint := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer endpoint.Close()
+
+	// Seed the queue through the persisted file, as after a restart
+	dlq := `[{"id":"dl1","webhook_id":"ops","event":{"id":"e1","type":"auction.sold"},"attempts":3,"last_error":"HTTP 500"},
+		{"id":"dl2","webhook_id":"ops","event":{"id":"e2","type":"auction.sold"},"attempts":3,"last_error":"HTTP 500"}]`
+	if err := os.WriteFile(Config.WebhookDLQFile, []byte(dlq), 0600); err != nil {
+		t.Fatal(err)
+	}
+	webhookManager = events.NewWebhookManager(events.NewEventBus(1), webhookServerID)
+	webhookManager.Load(Config.WebhooksFile, Config.WebhookDLQFile)
+	webhookManager.AddWebhook(&events.WebhookConfig{ID: "ops", URL: endpoint.URL, Enabled: true})
+
+	w := adminPost(adminWebhookReplay, url.Values{"id": {"dl1"}})
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "delivered") {
+		t.Errorf("Replay: status %d", w.Code)
+	}
+	if w := adminPost(adminWebhookDiscard, url.Values{"id": {"dl2"}}); w.Code != http.StatusSeeOther {
+		t.Errorf("Discard: status %d", w.Code)
+	}
+	if len(webhookManager.DeadLetters()) != 0 {
+		t.Error("Queue should be empty")
+	}
+	if w := adminPost(adminWebhookReplay, url.Values{"id": {"dl1"}}); w.Code != http.StatusNotFound {
+		t.Errorf("Replay of missing dead letter: status %d, want 404", w.Code)
+	}
+	if data, _ := os.ReadFile(Config.WebhookDLQFile); strings.TrimSpace(string(data)) != "[]" {
+		t.Errorf("Persisted queue = %s", data)
+	}
+}
@@ -5,15 +5,22 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math"
-	"math/rand"
 	"os"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/yourusername/matrix-mud/pkg/db"
+	"github.com/yourusername/matrix-mud/pkg/effects"
+	"github.com/yourusername/matrix-mud/pkg/game"
 	"github.com/yourusername/matrix-mud/pkg/logging"
+	"github.com/yourusername/matrix-mud/pkg/party"
+	"github.com/yourusername/matrix-mud/pkg/sim"
+	"github.com/yourusername/matrix-mud/pkg/watchdog"
+	"github.com/yourusername/matrix-mud/pkg/world"
 )
 
 // --- Structs ---
@@ -28,9 +35,35 @@ type Item struct {
 	Value, Price          int
 	// Rarity (0=Common, 1=Uncommon, 2=Rare, 3=Legendary)
 	Rarity int `json:"rarity"`
+	// The template an instance was made from, and the size of a stack, see items.go
+	Template string `json:"template,omitempty"`
+	Quantity int    `json:"quantity,omitempty"`
+	// Weapon speed (0 = normal, see game.AttackInterval)
+	Speed int `json:"speed,omitempty"`
+	// Damage type dealt (weapons) and resistances granted (armor), see pkg/game
+	DamageType string         `json:"damage_type,omitempty"`
+	Resist     map[string]int `json:"resist,omitempty"`
 	// Durability system (0 = unbreakable, >0 = current durability)
 	Durability    int `json:"durability,omitempty"`
 	MaxDurability int `json:"max_durability,omitempty"`
+	// Builder scripts keyed by trigger (use, wear), see scripting.go
+	Scripts map[string]string `json:"scripts,omitempty"`
+	// Weight of one item; items without one weigh 1, see containers.go
+	Weight int `json:"weight,omitempty"`
+	// Lights dark rooms when carried or worn, see room_flags.go
+	Light bool `json:"light,omitempty"`
+	// Containers (Type "container", and corpses) hold Contents, see containers.go
+	Contents  []*Item `json:"contents,omitempty"`
+	Capacity  int     `json:"capacity,omitempty"`   // Stacks held, 0 = no limit
+	MaxWeight int     `json:"max_weight,omitempty"` // Weight held, 0 = no limit
+	Fixed     bool    `json:"fixed,omitempty"`      // Cannot be picked up, like a room chest
+	Closable  bool    `json:"closable,omitempty"`
+	Closed    bool    `json:"closed,omitempty"`
+	Locked    bool    `json:"locked,omitempty"`
+	Key       string  `json:"key,omitempty"` // Template ID of the key that locks it
+	// Corpses (Type "corpse") hold what the dead carried until they decay, see death.go
+	Owner  string    `json:"owner,omitempty"` // Player whose corpse this is; only they may loot it
+	Decays time.Time `json:"decays,omitzero"`
 }
 
 // Quest represents an NPC quest that rewards the player for delivering a specific item.
@@ -56,8 +89,17 @@ type NPC struct {
 	OriginalRoom                         string
 	DeathTime                            time.Time
 	IsDead                               bool
-	IsAgent                              bool   `json:"is_agent,omitempty"` // Agent NPCs hunt awakened players
-	TargetPlayer                         string `json:"-"`                  // Player being hunted (runtime only)
+	IsAgent                              bool              `json:"is_agent,omitempty"`    // Agent NPCs hunt awakened players
+	TargetPlayer                         string            `json:"-"`                     // Player being hunted (runtime only)
+	Scripts                              map[string]string `json:"scripts,omitempty"`     // Builder scripts: greet, death, tick, give
+	Spawned                              bool              `json:"spawned,omitempty"`     // Created by a script; does not respawn
+	Effects                              effects.Set       `json:"-"`                     // Active status effects
+	Threat                               map[string]int    `json:"-"`                     // Player name -> threat
+	CombatTarget                         string            `json:"-"`                     // Player the NPC is attacking
+	LastAttack                           time.Time         `json:"-"`                     // Time of the NPC's last combat round
+	Kind                                 string            `json:"kind,omitempty"`        // agent, program, machine, bluepill; "" is human
+	DamageType                           string            `json:"damage_type,omitempty"` // Type of the NPC's attacks, kinetic by default
+	Resist                               map[string]int    `json:"resist,omitempty"`      // Resistances on top of the kind's
 }
 
 // Room represents a location in the game world with connections to other rooms.
@@ -65,13 +107,17 @@ type NPC struct {
 // The ItemMap and NPCMap provide fast lookups for entities in the room.
 type Room struct {
 	ID, Description string
-	Exits           map[string]string
+	Exits           map[string]*Exit // Direction -> exit, see doors.go
 	Symbol, Color   string
 	Items           []*Item
 	NPCs            []*NPC
 	ItemMap         map[string]*Item
 	NPCMap          map[string]*NPC
-	HasPhone        bool `json:"has_phone,omitempty"` // Room has a phone booth for fast travel
+	HasPhone        bool              `json:"has_phone,omitempty"`   // Room has a phone booth for fast travel
+	Respawn         bool              `json:"respawn,omitempty"`     // Released ghosts return to life here
+	Scripts         map[string]string `json:"scripts,omitempty"`     // Builder scripts: enter, say, say:<keyword>
+	Flags           []string          `json:"flags,omitempty"`       // safe, dark, pvp..., see room_flags.go
+	Environment     string            `json:"environment,omitempty"` // street, indoors, ship..., see pkg/world
 }
 
 // WorldData is a container for serializing the world state to JSON.
@@ -94,12 +140,20 @@ type Player struct {
 	XP, Level                   int
 	Class                       string
 	Money                       int
-	CraftingSkill               int      `json:"crafting_skill,omitempty"`
-	Awakened                    bool     `json:"awakened,omitempty"`          // True if player took the red pill
-	Heat                        int      `json:"heat,omitempty"`              // Agent aggro level (0-100)
-	DiscoveredPhones            []string `json:"discovered_phones,omitempty"` // Phone booth IDs player can call
-	BriefMode                   bool     `json:"brief_mode,omitempty"`        // Show short room descriptions
-	ColorTheme                  string   `json:"color_theme,omitempty"`       // green, amber, white, none
+	CraftingSkill               int               `json:"crafting_skill,omitempty"`
+	Awakened                    bool              `json:"awakened,omitempty"`          // True if player took the red pill
+	Heat                        int               `json:"heat,omitempty"`              // Agent aggro level (0-100)
+	DiscoveredPhones            []string          `json:"discovered_phones,omitempty"` // Phone booth IDs player can call
+	BriefMode                   bool              `json:"brief_mode,omitempty"`        // Show short room descriptions
+	ColorTheme                  string            `json:"color_theme,omitempty"`       // green, amber, white, none
+	RecordSessions              bool              `json:"record_sessions,omitempty"`   // Opted in to session recording
+	Flags                       map[string]string `json:"flags,omitempty"`             // Set by builder scripts
+	Skills                      map[string]int    `json:"skills,omitempty"`            // Learned skill -> proficiency
+	Practices                   int               `json:"practices,omitempty"`         // Unspent practice sessions
+	Ghost                       bool              `json:"ghost,omitempty"`             // Dead and awaiting resurrection
+	XPDebt                      int               `json:"xp_debt,omitempty"`           // XP owed from dying, repaid from XP gains
+	RevealedExits               []string          `json:"revealed_exits,omitempty"`    // Hidden exits found, as "room:direction"
+	Effects                     effects.Set       `json:"-"`                           // Active status effects
 }
 
 // World represents the entire game state including all rooms, players, NPCs, and items.
@@ -111,10 +165,40 @@ type World struct {
 	Dialogue      map[string]map[string]string
 	DeadNPCs      []*NPC
 	ItemTemplates map[string]*Item
-	MOTD          []string // Message of the Day
+	MOTD          []string  // Message of the Day
+	Clock         sim.Clock // Game time; nil means the wall clock
+	RNG           sim.RNG   // Combat, loot and generation rolls; nil means a shared random source
+	scripts       scriptQueue
 	mutex         sync.RWMutex
 }
 
+// defaultRNG serves worlds built without an RNG, such as test literals
+var defaultRNG = sim.NewRand(sim.RandomSeed())
+
+// now returns the world's game time
+func (w *World) now() time.Time {
+	if w.Clock == nil {
+		return time.Now()
+	}
+	return w.Clock.Now()
+}
+
+// rng returns the world's random source
+func (w *World) rng() sim.RNG {
+	if w.RNG == nil {
+		return defaultRNG
+	}
+	return w.RNG
+}
+
+// Seed returns the seed of the world's random source, if it has one
+func (w *World) Seed() (int64, bool) {
+	if r, ok := w.rng().(*sim.Rand); ok {
+		return r.Seed(), true
+	}
+	return 0, false
+}
+
 // --- Init ---
 
 // NewWorld creates and initializes a new game world.
@@ -122,10 +206,12 @@ type World struct {
 // loads NPC dialogue, and sets up room connections. If no world file exists,
 // default rooms are created.
 func NewWorld() *World {
-	w := &World{Rooms: make(map[string]*Room), Players: make(map[*Client]*Player), Dialogue: make(map[string]map[string]string), DeadNPCs: make([]*NPC, 0), ItemTemplates: make(map[string]*Item)}
+	w := &World{Rooms: make(map[string]*Room), Players: make(map[*Client]*Player), Dialogue: make(map[string]map[string]string), DeadNPCs: make([]*NPC, 0), ItemTemplates: make(map[string]*Item), Clock: sim.WallClock{}, RNG: sim.NewRand(simSeed())}
 	w.loadWorldData()
 	w.loadDialogue()
 	w.loadMOTD()
+	w.checkScripts()
+	w.checkSkills()
 	return w
 }
 func (w *World) loadWorldData() {
@@ -157,6 +243,7 @@ func (w *World) loadWorldData() {
 		room.ItemMap = make(map[string]*Item)
 		room.NPCMap = make(map[string]*NPC)
 		for _, item := range room.Items {
+			w.adoptItem(item)
 			room.ItemMap[item.ID] = item
 		}
 		for _, npc := range room.NPCs {
@@ -172,6 +259,10 @@ func (w *World) loadWorldData() {
 				npc.MaxHP = npc.HP
 				logging.Debug().Str("npc", npc.ID).Str("room", roomID).Int("max_hp", npc.MaxHP).Msg("NPC had invalid MaxHP, corrected")
 			}
+			if !game.ValidKind(npc.Kind) {
+				logging.Warn().Str("npc", npc.ID).Str("kind", npc.Kind).Msg("NPC has unknown kind, treating as human")
+				npc.Kind = ""
+			}
 
 			room.NPCMap[npc.ID] = npc
 		}
@@ -193,7 +284,7 @@ func (w *World) createDefaultWorld() {
 		Color:       "white",
 		ItemMap:     make(map[string]*Item),
 		NPCMap:      make(map[string]*NPC),
-		Exits:       make(map[string]string),
+		Exits:       make(map[string]*Exit),
 	}
 }
 
@@ -232,6 +323,19 @@ func (w *World) loadItemTemplates() {
 			Value:       item.Value,
 			Price:       item.Price,
 			Rarity:      item.Rarity,
+			Speed:       item.Speed,
+			DamageType:  item.DamageType,
+			Resist:      item.Resist,
+			Scripts:     item.Scripts,
+			Weight:      item.Weight,
+			Capacity:    item.Capacity,
+			MaxWeight:   item.MaxWeight,
+			Fixed:       item.Fixed,
+			Closable:    item.Closable,
+			Closed:      item.Closed,
+			Locked:      item.Locked,
+			Key:         item.Key,
+			Light:       item.Light,
 		}
 	}
 
@@ -248,7 +352,7 @@ func (w *World) loadDefaultItemTemplates() {
 	w.ItemTemplates["deck"] = &Item{ID: "deck", Name: "Cyberdeck", Description: "A portable hacking unit.", Slot: "hand", Damage: 2, Price: 150}
 	w.ItemTemplates["boots"] = &Item{ID: "boots", Name: "Combat Boots", Description: "Heavy boots.", Slot: "body", AC: 2, Price: 80}
 	w.ItemTemplates["shades"] = &Item{ID: "shades", Name: "Pilot Shades", Description: "Cool sunglasses.", Slot: "head", AC: 1, Price: 50}
-	w.ItemTemplates["trash"] = &Item{ID: "trash", Name: "Digital Trash", Description: "Useless data.", Price: 1}
+	w.ItemTemplates["trash"] = &Item{ID: "trash", Name: "Digital Trash", Description: "Useless data.", Type: "material", Price: 1}
 	w.ItemTemplates["baton"] = &Item{ID: "baton", Name: "Police Baton", Description: "Standard issue.", Damage: 3, Slot: "hand", Price: 20}
 	logging.Info().Msg("Loaded default item templates (items.json not available)")
 }
@@ -295,6 +399,7 @@ func (w *World) GetMOTD() string {
 // This is called on disconnect and periodically during gameplay.
 // Uses RLock to allow concurrent saves while preventing data corruption.
 func (w *World) SavePlayer(p *Player) {
+	defer observeSave("player", time.Now())
 	w.mutex.RLock()
 	defer w.mutex.RUnlock()
 	data, _ := json.MarshalIndent(p, "", "  ")
@@ -316,8 +421,10 @@ func (w *World) LoadPlayer(name string, client *Client) *Player {
 	if p.Equipment == nil {
 		p.Equipment = make(map[string]*Item)
 	}
-	if p.Bank == nil {
-		p.Bank = make([]*Item, 0)
+	p.Inventory = w.adoptItems(p.Inventory)
+	p.Bank = w.adoptItems(p.Bank)
+	for _, item := range p.Equipment {
+		w.adoptItem(item)
 	}
 	if p.Level == 0 {
 		p.Level = 1
@@ -347,6 +454,7 @@ func (w *World) Broadcast(roomID string, exclude *Player, msg string) {
 // Clears maps in output to avoid duplicate data in JSON file.
 // Uses RLock to prevent modifications during save.
 func (w *World) SaveWorld() {
+	defer observeSave("world", time.Now())
 	w.mutex.RLock()
 	defer w.mutex.RUnlock()
 
@@ -390,30 +498,32 @@ func (w *World) SaveWorld() {
 // GenerateLoot creates a randomized item instance from a template.
 // Items are rolled for rarity (Common, Uncommon, Rare, Legendary) with
 // higher rarities providing stat bonuses and increased value.
-// Each generated item receives a unique ID to prevent stack conflicts.
+// Rare and Legendary items also roll a damage type affinity: untyped
+// weapons deal code, EMP or psychic damage, and armor gains resistance.
 func (w *World) GenerateLoot(templateID string) *Item {
 	tmpl, ok := w.ItemTemplates[templateID]
 	if !ok {
 		return nil
 	}
 
-	// Copy Item
-	item := *tmpl
+	item := *w.newItem(tmpl, 1)
 
 	// Roll Rarity
-	roll := rand.Intn(100)
+	roll := w.rng().Intn(100)
 	if roll > 98 {
 		item.Rarity = 3 // Legendary
 		item.Name = "Legendary " + item.Name
 		item.Damage += 4
 		item.AC += 4
 		item.Price *= 10
+		w.rollAffinity(&item, LegendaryResist)
 	} else if roll > 90 {
 		item.Rarity = 2 // Rare
 		item.Name = "Rare " + item.Name
 		item.Damage += 2
 		item.AC += 2
 		item.Price *= 5
+		w.rollAffinity(&item, RareResist)
 	} else if roll > 75 {
 		item.Rarity = 1 // Uncommon
 		item.Name = "Uncommon " + item.Name
@@ -423,24 +533,45 @@ func (w *World) GenerateLoot(templateID string) *Item {
 	} else {
 		item.Rarity = 0 // Common
 	}
-
-	// Unique ID for this instance
-	item.ID = fmt.Sprintf("%s_%d", item.ID, rand.Intn(100000))
 	return &item
 }
 
+// Resistance rolled onto Rare and Legendary armor (percent)
+const (
+	RareResist      = 10
+	LegendaryResist = 25
+)
+
+// rollAffinity gives an untyped weapon a random non-kinetic damage type, or
+// armor resist percent against a random damage type
+func (w *World) rollAffinity(item *Item, resist int) {
+	switch {
+	case item.Slot == "hand" && item.Damage > 0 && item.DamageType == "":
+		types := game.DamageTypes[1:] // Everything but kinetic
+		item.DamageType = types[w.rng().Intn(len(types))]
+	case item.Slot != "" && item.Slot != "hand":
+		dt := game.DamageTypes[w.rng().Intn(len(game.DamageTypes))]
+		item.Resist = game.MergeResist(item.Resist, map[string]int{dt: resist})
+	}
+}
+
 // ColorizeItem returns the item name with ANSI color codes based on rarity.
 // Common items are white, Uncommon are bright green, Rare are cyan, and Legendary are magenta.
+// Stacks of more than one show their size.
 func ColorizeItem(i *Item) string {
+	name := i.Name
+	if i.Count() > 1 {
+		name += fmt.Sprintf(" (x%d)", i.Count())
+	}
 	switch i.Rarity {
 	case 1:
-		return ColorUncommon + i.Name + Reset
+		return ColorUncommon + name + Reset
 	case 2:
-		return ColorRare + i.Name + Reset
+		return ColorRare + name + Reset
 	case 3:
-		return ColorEpic + i.Name + Reset
+		return ColorEpic + name + Reset
 	default:
-		return White + i.Name + Reset
+		return White + name + Reset
 	}
 }
 
@@ -455,27 +586,25 @@ func (w *World) GenerateCity(p *Player, rows, cols int) string {
 	defer w.mutex.Unlock()
 	startRoom := w.Rooms[p.RoomID]
 	descriptions := []string{"A rain-slicked city street.", "A dark alleyway.", "A busy intersection.", "The base of a skyscraper.", "A subway entrance.", "A quiet park."}
-	baseID := "city_" + fmt.Sprintf("%d", time.Now().Unix())
+	baseID := "city_" + fmt.Sprintf("%d", w.now().Unix())
 	gridIDs := make([][]string, rows)
 	for r := 0; r < rows; r++ {
 		gridIDs[r] = make([]string, cols)
 		for c := 0; c < cols; c++ {
 			id := fmt.Sprintf("%s_%d_%d", baseID, r, c)
 			gridIDs[r][c] = id
-			desc := descriptions[rand.Intn(len(descriptions))]
-			newRoom := &Room{ID: id, Description: desc, Symbol: ".", Color: "white", Exits: make(map[string]string), ItemMap: make(map[string]*Item), NPCMap: make(map[string]*NPC)}
-			roll := rand.Intn(100)
+			desc := descriptions[w.rng().Intn(len(descriptions))]
+			newRoom := &Room{ID: id, Description: desc, Symbol: ".", Color: "white", Exits: make(map[string]*Exit), ItemMap: make(map[string]*Item), NPCMap: make(map[string]*NPC)}
+			roll := w.rng().Intn(100)
 			if roll < 10 {
 				npcID := fmt.Sprintf("cop_%d_%d", r, c)
-				newRoom.NPCMap[npcID] = &NPC{ID: npcID, Name: "Riot Cop", Description: "Armored police unit.", HP: 25, MaxHP: 25, Damage: 3, AC: 11, State: "IDLE", XP: 50, DropMoney: 10, RoomID: id, OriginalRoom: id, Loot: []string{"baton"}}
+				newRoom.NPCMap[npcID] = &NPC{ID: npcID, Name: "Riot Cop", Description: "Armored police unit.", HP: 25, MaxHP: 25, Damage: 3, AC: 11, State: "IDLE", XP: 50, DropMoney: 10, RoomID: id, OriginalRoom: id, Loot: []string{"baton"}, Kind: game.KindBluepill}
 				newRoom.Symbol = "!"
 				newRoom.Color = "red"
 			} else if roll < 30 {
-				itemID := fmt.Sprintf("trash_%d_%d", r, c)
 				if tmpl, ok := w.ItemTemplates["trash"]; ok {
-					item := *tmpl
-					item.ID = itemID
-					newRoom.ItemMap[itemID] = &item
+					item := w.newItem(tmpl, 1)
+					newRoom.ItemMap[item.ID] = item
 				}
 			}
 			w.Rooms[id] = newRoom
@@ -485,54 +614,72 @@ func (w *World) GenerateCity(p *Player, rows, cols int) string {
 		for c := 0; c < cols; c++ {
 			room := w.Rooms[gridIDs[r][c]]
 			if r > 0 {
-				room.Exits["north"] = gridIDs[r-1][c]
+				room.Exits["north"] = &Exit{To: gridIDs[r-1][c]}
 			}
 			if r < rows-1 {
-				room.Exits["south"] = gridIDs[r+1][c]
+				room.Exits["south"] = &Exit{To: gridIDs[r+1][c]}
 			}
 			if c > 0 {
-				room.Exits["west"] = gridIDs[r][c-1]
+				room.Exits["west"] = &Exit{To: gridIDs[r][c-1]}
 			}
 			if c < cols-1 {
-				room.Exits["east"] = gridIDs[r][c+1]
+				room.Exits["east"] = &Exit{To: gridIDs[r][c+1]}
 			}
 		}
 	}
-	startRoom.Exits["south"] = gridIDs[0][0]
-	w.Rooms[gridIDs[0][0]].Exits["north"] = startRoom.ID
+	startRoom.Exits["south"] = &Exit{To: gridIDs[0][0]}
+	w.Rooms[gridIDs[0][0]].Exits["north"] = &Exit{To: startRoom.ID}
 	return fmt.Sprintf("Generated %dx%d City Grid.", rows, cols)
 }
 
 // --- BANKING SYSTEM ---
+
+// DepositItem uploads items to the player's Archive storage, merging stacks
 func (w *World) DepositItem(p *Player, itemName string) string {
 	w.mutex.Lock()
 	defer w.mutex.Unlock()
 	if p.RoomID != "construct_archive" {
 		return "You must be in The Archive to access storage."
 	}
-	for i, item := range p.Inventory {
-		if strings.Contains(strings.ToLower(item.Name), itemName) || item.ID == itemName {
-			p.Bank = append(p.Bank, item)
-			p.Inventory = append(p.Inventory[:i], p.Inventory[i+1:]...)
-			return fmt.Sprintf("You upload %s to the Archive.", ColorizeItem(item))
-		}
+	picks, ok := pickItems(p.Inventory, itemName)
+	if len(picks) == 0 {
+		return "You don't have that."
 	}
-	return "You don't have that."
+	if !ok {
+		return "You don't have that many."
+	}
+	moved := w.moveItems(picks, nil,
+		func(item *Item) { p.Inventory = removeItem(p.Inventory, item) },
+		func(item *Item) { p.Bank = addItem(p.Bank, item) })
+	return fmt.Sprintf("You upload %s to the Archive.", itemList(moved))
 }
+
+// WithdrawItem downloads items from the player's Archive storage, as many
+// as fit in their inventory
 func (w *World) WithdrawItem(p *Player, itemName string) string {
 	w.mutex.Lock()
 	defer w.mutex.Unlock()
 	if p.RoomID != "construct_archive" {
 		return "You must be in The Archive to access storage."
 	}
-	for i, item := range p.Bank {
-		if strings.Contains(strings.ToLower(item.Name), itemName) || item.ID == itemName {
-			p.Inventory = append(p.Inventory, item)
-			p.Bank = append(p.Bank[:i], p.Bank[i+1:]...)
-			return fmt.Sprintf("You download %s from the Archive.", ColorizeItem(item))
-		}
+	picks, ok := pickItems(p.Bank, itemName)
+	if len(picks) == 0 {
+		return "Item not found in Archive."
 	}
-	return "Item not found in Archive."
+	if !ok {
+		return "The Archive doesn't hold that many."
+	}
+	moved := w.moveItems(picks, func(pk pick) bool { return canCarry(p, pk.item) },
+		func(item *Item) { p.Bank = removeItem(p.Bank, item) },
+		func(item *Item) { p.Inventory = addItem(p.Inventory, item) })
+	if len(moved) == 0 {
+		return fmt.Sprintf("Your inventory is full (max %d items).", MaxInventorySize)
+	}
+	msg := fmt.Sprintf("You download %s from the Archive.", itemList(moved))
+	if len(moved) < len(picks) {
+		msg += " Your inventory is full."
+	}
+	return msg
 }
 func (w *World) ShowStorage(p *Player) string {
 	w.mutex.RLock()
@@ -557,7 +704,19 @@ func (w *World) EditRoom(p *Player, field string, value string) string {
 		room.Description = value
 		return "Room description updated."
 	}
-	return "Usage: edit desc [text]"
+	if field == "flag" {
+		flag, val, _ := strings.Cut(value, " ")
+		return w.EditFlag(p, flag, val)
+	}
+	if field == "env" || field == "environment" {
+		return w.EditEnvironment(p, value)
+	}
+	if field == "exit" {
+		dir, rest, _ := strings.Cut(value, " ")
+		field, val, _ := strings.Cut(rest, " ")
+		return w.EditExit(p, dir, field, val)
+	}
+	return "Usage: edit desc [text] | edit exit [dir] [field] [value] | edit flag [flag] [on|off] | edit env [type]"
 }
 
 // --- Mapping ---
@@ -597,8 +756,12 @@ func (w *World) GenerateAutomapInternal(p *Player, radius int) string {
 				colorCode = White
 			}
 			grid[Coord{curr.X, curr.Y}] = colorCode + symbol + Reset
-			for dir, nextID := range room.Exits {
-				if !visited[nextID] {
+			for dir, exit := range room.Exits {
+				// Hidden exits stay off the map, and closed doors hide what is behind them
+				if !seesExit(p, room.ID, dir, exit) || exit.Closed {
+					continue
+				}
+				if nextID := exit.To; !visited[nextID] {
 					visited[nextID] = true
 					nx, ny := curr.X, curr.Y
 					switch dir {
@@ -639,9 +802,16 @@ func (w *World) GenerateAutomapInternal(p *Player, radius int) string {
 // Handles NPC respawning (30 second timer), aggressive NPC attacks, MP regeneration,
 // and automatic combat round resolution.
 func (w *World) Update() {
+	w.UpdateTick(nil)
+}
+
+// UpdateTick runs one game tick, marking each phase on tick for the
+// watchdog's slow-tick breakdown. tick may be nil.
+func (w *World) UpdateTick(tick *watchdog.Tick) {
 	w.mutex.Lock()
 	defer w.mutex.Unlock()
-	now := time.Now()
+	tick.Mark("lock")
+	now := w.now()
 	activeDead := make([]*NPC, 0)
 	for _, npc := range w.DeadNPCs {
 		if now.Sub(npc.DeathTime) > 30*time.Second {
@@ -657,146 +827,183 @@ func (w *World) Update() {
 		}
 	}
 	w.DeadNPCs = activeDead
+	tick.Mark("respawn")
 	for _, p := range w.Players {
-		if p.State == "IDLE" {
-			room := w.Rooms[p.RoomID]
+		if room := w.Rooms[p.RoomID]; p.State == "IDLE" && !p.Ghost && peaceBlocks(room) == "" {
 			for _, npc := range room.NPCMap {
 				if npc.Aggro && npc.State == "IDLE" {
 					p.State = "COMBAT"
 					p.Target = npc.ID
 					p.LastAttack = now.Add(-1 * time.Second)
 					npc.State = "COMBAT"
+					w.addThreat(npc, p, 0)
 					p.Conn.Write(Matrixify(fmt.Sprintf("\r\n%s%s spots you and ATTACKS!%s\r\n> ", Red, npc.Name, Green)))
 					break
 				}
 			}
 		}
-		if rand.Intn(6) == 0 {
+		if w.rng().Intn(6) == 0 {
 			if p.MP < p.MaxMP {
 				p.MP++
 			}
 		}
+	}
+	tick.Mark("aggro")
+	for _, p := range w.playersByName() {
 		if p.State == "COMBAT" {
-			if now.Sub(p.LastAttack) > 1500*time.Millisecond {
+			if now.Sub(p.LastAttack) > game.AttackInterval(weaponSpeed(p)) {
 				w.ResolveCombatRound(p)
 				p.LastAttack = now
 			}
 		}
 	}
+	w.npcCombatRounds(now)
+	tick.Mark("combat")
+	w.updateEffects()
+	tick.Mark("effects")
+	w.decayCorpses(now)
+	tick.Mark("corpses")
 
 	// Phase 1: Decay heat and run Agent AI (every ~30 seconds via counter)
 	w.DecayHeat()
+	tick.Mark("heat_decay")
 	w.AgentAI()
+	tick.Mark("agent_ai")
+	w.runScripts()
+	tick.Mark("scripts")
 }
 
 // --- Skills & Combat ---
 
-// CastSkill allows players to use class-specific abilities.
-// Hacker: "glitch" - logic bomb attack
-// Rebel: "smash" - powerful melee strike
-// Operator: "patch" - self-healing ability
-// Skills cost MP and some can target NPCs to deal damage.
-func (w *World) CastSkill(p *Player, skillName string, targetName string) string {
-	w.mutex.Lock()
-	defer w.mutex.Unlock()
-	cost := 5
-	if p.MP < cost {
-		return "Not enough MP."
+// gainXP awards XP and levels the player up if they cross the threshold,
+// returning the level-up message or ""
+func (w *World) gainXP(p *Player, xp int) string {
+	xp, debtMsg := repayXPDebt(p, xp)
+	p.XP += xp
+	if p.XP < p.Level*XPPerLevel {
+		return debtMsg
 	}
-	valid := false
-	if p.Class == "Hacker" && skillName == "glitch" {
-		valid = true
-	}
-	if p.Class == "Rebel" && skillName == "smash" {
-		valid = true
-	}
-	if p.Class == "Operator" && skillName == "patch" {
-		valid = true
-	}
-	if !valid {
-		return "You don't know that skill."
-	}
-	room := w.Rooms[p.RoomID]
-	if skillName == "patch" {
-		p.MP -= cost
-		p.HP += 10
-		if p.HP > p.MaxHP {
-			p.HP = p.MaxHP
+	return debtMsg + levelUp(p)
+}
+
+// levelUp raises a player one level: more HP, MP and strength, a full heal
+// and practice sessions. It returns the message to show the player.
+func levelUp(p *Player) string {
+	p.Level++
+	p.MaxHP += 10
+	p.MaxMP += 5
+	p.HP = p.MaxHP
+	p.MP = p.MaxMP
+	p.Strength += 1
+	p.Practices += PracticesPerLevel
+	publishLevelUp(p)
+	msg := fmt.Sprintf("\r\n%s*** LEVEL UP! ***%s", White, Reset)
+	if ids := newSkillsAt(p.Class, p.Level); len(ids) > 0 {
+		msg += fmt.Sprintf("\r\nNew skills to learn: %s.", strings.Join(ids, ", "))
+	}
+	return msg
+}
+
+// killNPC is the shared reward path for a player killing an NPC, by melee or
+// skill: XP, money, level-up, loot, heat, respawn bookkeeping and death
+// scripts. Party members in the room who fought the NPC share the kill (see
+// creditGroup): XP is split between them by party.SplitXP, money evenly and
+// loot in turn, into inventories. Loot nobody takes stays on the NPC's
+// corpse. It returns the messages for the killer.
+func (w *World) killNPC(p *Player, npc *NPC, room *Room) string {
+	group := w.creditGroup(p, npc)
+	xp := party.SplitXP(npc.XP, len(group))
+	money := npc.DropMoney / len(group)
+	messages := make([]string, len(group))
+	for i, member := range group {
+		cut := money
+		if i == 0 {
+			cut += npc.DropMoney % len(group) // The killer keeps the remainder
 		}
-		return "Healed 10 HP."
-	}
-	if targetName == "" && p.State == "COMBAT" {
-		targetName = p.Target
-	}
-	var targetNPC *NPC
-	for _, npc := range room.NPCMap {
-		if strings.Contains(strings.ToLower(npc.Name), targetName) || npc.ID == targetName {
-			targetNPC = npc
-			break
+		messages[i] = fmt.Sprintf("%s collapses.", npc.Name)
+		messages[i] += fmt.Sprintf("\r\n%sYou gain %d XP and %d Fragments.%s", Green, xp, cut, Reset)
+		member.Money += cut
+		messages[i] += w.gainXP(member, xp)
+	}
+
+	// LOOT GENERATION: shared into inventories, or left on the corpse
+	corpse := w.makeCorpse(npc.ID, npc.Name, nil, NPCCorpseDecay)
+	next := 0
+	for _, itemID := range npc.Loot {
+		drop := w.GenerateLoot(itemID)
+		if drop == nil {
+			continue
 		}
-	}
-	if targetNPC == nil && targetName != "" {
-		if npc, ok := room.NPCMap[targetName]; ok {
-			targetNPC = npc
+		taker := group[next%len(group)]
+		next++
+		if len(group) > 1 && canCarry(taker, drop) {
+			taker.Inventory = addItem(taker.Inventory, drop)
+			for i, member := range group {
+				if member == taker {
+					messages[i] += fmt.Sprintf("\r\nYou receive %s.", ColorizeItem(drop))
+				} else {
+					messages[i] += fmt.Sprintf("\r\n%s receives %s.", taker.Name, ColorizeItem(drop))
+				}
+			}
+			continue
+		}
+		corpse.Contents = addItem(corpse.Contents, drop)
+		for i := range group {
+			messages[i] += fmt.Sprintf("\r\n%s dropped %s.", npc.Name, ColorizeItem(drop))
 		}
 	}
-	if targetNPC == nil {
-		return "Cast at whom?"
-	}
-	p.MP -= cost
-	dmg := 0
-	desc := ""
-	if skillName == "glitch" {
-		dmg = rand.Intn(10) + 5
-		desc = fmt.Sprintf("Logic bomb hits %s for %d damage!", targetNPC.Name, dmg)
-	} else if skillName == "smash" {
-		dmg = rand.Intn(8) + p.Strength
-		desc = fmt.Sprintf("Smash hits %s for %d damage!", targetNPC.Name, dmg)
-	}
-	targetNPC.HP -= dmg
-	if p.State != "COMBAT" {
-		p.State = "COMBAT"
-		p.Target = targetNPC.ID
-		p.LastAttack = time.Now()
-	}
-	if targetNPC.HP <= 0 {
-		desc += fmt.Sprintf("\r\n%s collapses.", targetNPC.Name)
-		p.XP += targetNPC.XP
-		p.Money += targetNPC.DropMoney
-		threshold := p.Level * 1000
-		if p.XP >= threshold {
-			p.Level++
-			p.MaxHP += 10
-			p.MaxMP += 5
-			p.HP = p.MaxHP
-			p.MP = p.MaxMP
-			p.Strength += 1
-			desc += fmt.Sprintf("\r\n%s*** LEVEL UP! ***%s", White, Reset)
-		}
-
-		// LOOT GENERATION
-		for _, itemID := range targetNPC.Loot {
-			drop := w.GenerateLoot(itemID)
-			if drop != nil {
-				room.ItemMap[drop.ID] = drop
-				desc += fmt.Sprintf("\r\n%s dropped %s.", targetNPC.Name, ColorizeItem(drop))
-			}
+	room.ItemMap[corpse.ID] = corpse
+
+	// Add heat for the kill (Agents attract attention)
+	w.AddHeat(p, HeatPerKill)
+
+	w.removeDeadNPC(npc, room)
+	for i, member := range group {
+		if member.Target == npc.ID {
+			member.State = "IDLE"
+		}
+		publishNPCKill(member, npc)
+		if i > 0 && member.Conn != nil {
+			member.Conn.Write(Matrixify("\r\n" + messages[i] + "\r\n> "))
 		}
-		targetNPC.IsDead = true
-		targetNPC.DeathTime = time.Now()
-		w.DeadNPCs = append(w.DeadNPCs, targetNPC)
-		delete(room.NPCMap, targetNPC.ID)
-		p.State = "IDLE"
 	}
-	return desc
+	w.fireNPCScripts(npc, "death", p, "")
+	return messages[0]
 }
 
-// ResolveCombatRound processes one round of combat for a player and their target NPC.
-// Calculates attack rolls vs AC (d20 + modifiers), applies damage, checks for death,
-// awards XP and level-ups, generates loot drops, and handles player death (respawn).
-// Combat rounds occur automatically every 1.5 seconds when player State is COMBAT.
+// removeDeadNPC takes a dead NPC out of its room and queues it to respawn
+// unless a script spawned it
+func (w *World) removeDeadNPC(npc *NPC, room *Room) {
+	npc.IsDead = true
+	npc.DeathTime = w.now()
+	npc.Effects.Clear()
+	npc.Threat = nil
+	npc.CombatTarget = ""
+	if !npc.Spawned {
+		w.DeadNPCs = append(w.DeadNPCs, npc)
+	}
+	delete(room.NPCMap, npc.ID)
+}
+
+// ResolveCombatRound processes a player's attack on their target NPC.
+// The attack is resolved by the shared combat engine (game.Attack): hit
+// roll against AC, criticals, resistances and damage bonuses. It applies
+// damage and threat, and hands kills to the shared reward path. The NPC
+// strikes back in its own round (npcCombatRound) at whoever tops its
+// threat table. Combat rounds occur automatically at the speed of the
+// player's weapon, every 1.5 seconds at normal speed, when player State is COMBAT.
 func (w *World) ResolveCombatRound(p *Player) {
+	if strings.HasPrefix(p.Target, pvpPrefix) {
+		w.resolvePvPRound(p)
+		return
+	}
 	room := w.Rooms[p.RoomID]
+	if peaceBlocks(room) != "" {
+		p.State = "IDLE"
+		w.dropThreat(p)
+		p.Conn.Write(Matrixify("\r\nThe fight breaks off.\r\n> "))
+		return
+	}
 	targetNPC, ok := room.NPCMap[p.Target]
 	if !ok {
 		p.State = "IDLE"
@@ -804,91 +1011,52 @@ func (w *World) ResolveCombatRound(p *Player) {
 		return
 	}
 	output := ""
-	damage := 1 + (p.Strength-10)/2
 	weaponName := "fists"
 	if weapon, ok := p.Equipment["hand"]; ok {
-		damage += weapon.Damage
 		weaponName = ColorizeItem(weapon)
 	}
-	if damage < 1 {
-		damage = 1
-	}
+	now := w.now()
 	// Focus (bullet time) doubles damage
-	if p.State == "focused" || p.State == "COMBAT" && p.Target != "" {
-		if p.State == "focused" {
+	focused := p.State == "focused"
+	if focused {
+		output += fmt.Sprintf("%s[BULLET TIME]%s ", Cyan, Reset)
+		p.State = "COMBAT" // Reset state after use
+	}
+	w.addThreat(targetNPC, p, 0) // Any attack puts the player on the NPC's threat table
+	if p.Effects.Controlled(effects.Stun, now) {
+		output += "\r\nYou are stunned and cannot attack!"
+		p.Conn.Write(Matrixify(output + "\r\n"))
+		return
+	}
+	result := game.Attack(w.rng(), w.playerCombatant(p, now), w.npcCombatant(targetNPC, now))
+	if result.Hit {
+		damage := result.Damage
+		if focused {
 			damage *= 2
-			output += fmt.Sprintf("%s[BULLET TIME]%s ", Cyan, Reset)
-			p.State = "COMBAT" // Reset state after use
 		}
-	}
-	roll := rand.Intn(20) + 1
-	if roll >= targetNPC.AC {
 		targetNPC.HP -= damage
 		targetNPC.State = "COMBAT"
-		output += fmt.Sprintf("\r\nYou hit %s with %s for %d damage!", targetNPC.Name, weaponName, damage)
-		if targetNPC.HP <= 0 {
-			output += fmt.Sprintf("\r\n%s collapses.", targetNPC.Name)
-			p.XP += targetNPC.XP
-			p.Money += targetNPC.DropMoney
-			output += fmt.Sprintf("\r\n%sYou gain %d XP and %d Fragments.%s", Green, targetNPC.XP, targetNPC.DropMoney, Reset)
-			threshold := p.Level * 1000
-			if p.XP >= threshold {
-				p.Level++
-				p.MaxHP += 10
-				p.MaxMP += 5
-				p.HP = p.MaxHP
-				p.MP = p.MaxMP
-				p.Strength += 1
-				output += fmt.Sprintf("\r\n%s*** LEVEL UP! ***%s", White, Reset)
-			}
-
-			// LOOT GENERATION
-			for _, itemID := range targetNPC.Loot {
-				drop := w.GenerateLoot(itemID)
-				if drop != nil {
-					room.ItemMap[drop.ID] = drop
-					output += fmt.Sprintf("\r\n%s dropped %s.", targetNPC.Name, ColorizeItem(drop))
-				}
+		w.addThreat(targetNPC, p, damage)
+		if result.Critical {
+			output += fmt.Sprintf("\r\n%sCRITICAL HIT!%s You strike %s with %s for %d damage!", Yellow, Reset, targetNPC.Name, weaponName, damage)
+		} else {
+			output += fmt.Sprintf("\r\nYou hit %s with %s for %d damage!", targetNPC.Name, weaponName, damage)
+		}
+		if weapon, ok := p.Equipment["hand"]; ok && targetNPC.HP > 0 {
+			if def := effects.GlobalEffects.Get(weapon.Effect); def != nil {
+				output += " " + w.afflictNPC(targetNPC, def, now, p.Name)
 			}
-
-			// Add heat for the kill (Agents attract attention)
-			w.AddHeat(p, HeatPerKill)
-
-			targetNPC.IsDead = true
-			targetNPC.DeathTime = time.Now()
-			w.DeadNPCs = append(w.DeadNPCs, targetNPC)
-			delete(room.NPCMap, targetNPC.ID)
-			p.State = "IDLE"
+		}
+		if disabled := w.disableNPC(targetNPC, result.DamageType, now, p.Name); disabled != "" {
+			output += " " + disabled
+		}
+		if targetNPC.HP <= 0 {
+			output += "\r\n" + w.killNPC(p, targetNPC, room)
 			p.Conn.Write(Matrixify(output + "\r\n> "))
 			return
 		}
 	} else {
-		output += fmt.Sprintf("\r\nYou swing at %s but miss.", targetNPC.Name)
-	}
-	playerAC := p.BaseAC
-	if armor, ok := p.Equipment["body"]; ok {
-		playerAC += armor.AC
-	}
-	if armor, ok := p.Equipment["head"]; ok {
-		playerAC += armor.AC
-	}
-	// Awakened players have a chance to dodge (bonus AC)
-	if p.Awakened {
-		playerAC += 3
-	}
-	npcRoll := rand.Intn(20) + 1
-	if npcRoll >= playerAC {
-		npcDmg := rand.Intn(targetNPC.Damage) + 1
-		p.HP -= npcDmg
-		output += fmt.Sprintf("\r\n%s hits you for %d damage!", targetNPC.Name, npcDmg)
-		if p.HP <= 0 {
-			p.HP = p.MaxHP
-			p.RoomID = "loading_program"
-			p.State = "IDLE"
-			output += "\r\n*** YOU HAVE DIED ***\r\nRestoring backup..."
-		}
-	} else {
-		output += fmt.Sprintf("\r\n%s attacks you but misses.", targetNPC.Name)
+		output += "\r\n" + missMessage(result, "You", targetNPC.Name)
 	}
 	p.Conn.Write(Matrixify(output + "\r\n"))
 }
@@ -896,23 +1064,20 @@ func (w *World) ResolveCombatRound(p *Player) {
 // --- Standard Actions ---
 
 // GiveItem allows players to give items from their inventory to NPCs.
-// If the NPC has a quest for that item, completes the quest and awards XP.
+// If the NPC has a quest for that item, completes the quest with one of them
+// and awards XP. Scripted NPCs take any quantity, e.g. "give 3 trash to x".
 // Handles fuzzy item ID matching for generated items with random suffixes.
 func (w *World) GiveItem(p *Player, itemName string, targetName string) string {
 	w.mutex.Lock()
 	defer w.mutex.Unlock()
-	var itemToGive *Item
-	itemIdx := -1
-	for i, item := range p.Inventory {
-		if strings.Contains(strings.ToLower(item.Name), itemName) || item.ID == itemName {
-			itemToGive = item
-			itemIdx = i
-			break
-		}
-	}
-	if itemToGive == nil {
+	picks, ok := pickItems(p.Inventory, itemName)
+	if len(picks) == 0 {
 		return "You don't have that."
 	}
+	if !ok {
+		return "You don't have that many."
+	}
+	itemToGive := picks[0].item
 	room := w.Rooms[p.RoomID]
 	var targetNPC *NPC
 	for _, npc := range room.NPCMap {
@@ -924,21 +1089,19 @@ func (w *World) GiveItem(p *Player, itemName string, targetName string) string {
 	if targetNPC == nil {
 		return "They aren't here."
 	}
-	if targetNPC.Quest.WantedItem == itemToGive.ID || strings.Contains(itemToGive.ID, targetNPC.Quest.WantedItem) { // Fuzzy ID check for generated items
-		p.Inventory = append(p.Inventory[:itemIdx], p.Inventory[itemIdx+1:]...)
-		p.XP += targetNPC.Quest.RewardXP
-		threshold := p.Level * 1000
-		levelMsg := ""
-		if p.XP >= threshold {
-			p.Level++
-			p.MaxHP += 10
-			p.MaxMP += 5
-			p.HP = p.MaxHP
-			p.MP = p.MaxMP
-			p.Strength += 1
-			levelMsg = fmt.Sprintf("\r\n%s*** LEVEL UP! ***%s", White, Reset)
-		}
-		return fmt.Sprintf("You give %s to %s.\r\n%s%s%s\r\n(Gained %d XP)%s", ColorizeItem(itemToGive), targetNPC.Name, Green, targetNPC.Quest.RewardMsg, Reset, targetNPC.Quest.RewardXP, levelMsg)
+	wanted := targetNPC.Quest.WantedItem
+	questItem := itemToGive.TemplateID() == wanted || strings.Contains(itemToGive.ID, wanted) // Fuzzy ID check for generated items
+	giveAway := func(item *Item) { p.Inventory = removeItem(p.Inventory, item) }
+	// Scripted NPCs take anything that is not their quest item
+	if _, ok := targetNPC.Scripts["give"]; ok && (wanted == "" || !questItem) {
+		given := w.moveItems(picks, nil, giveAway, func(*Item) {})
+		w.fireNPCScripts(targetNPC, "give", p, itemToGive.TemplateID())
+		return fmt.Sprintf("You give %s to %s.", itemList(given), targetNPC.Name)
+	}
+	if questItem {
+		given := w.moveItems([]pick{{itemToGive, 1}}, nil, giveAway, func(*Item) {})
+		levelMsg := w.gainXP(p, targetNPC.Quest.RewardXP)
+		return fmt.Sprintf("You give %s to %s.\r\n%s%s%s\r\n(Gained %d XP)%s", itemList(given), targetNPC.Name, Green, targetNPC.Quest.RewardMsg, Reset, targetNPC.Quest.RewardXP, levelMsg)
 	}
 	return fmt.Sprintf("%s doesn't seem interested in %s.", targetNPC.Name, itemToGive.Name)
 }
@@ -967,6 +1130,9 @@ func (w *World) Look(p *Player, target string) string {
 		return fmt.Sprintf("%sError: You are in the void (room %s not found). Use 'recall' to return to safety.%s\r\n", Red, p.RoomID, Reset)
 	}
 
+	if !canSee(p, room) {
+		return darkLook(p, room, target)
+	}
 	if target == "" {
 		automap := w.GenerateAutomapInternal(p, 2)
 
@@ -981,9 +1147,13 @@ func (w *World) Look(p *Player, target string) string {
 			}
 		}
 
-		desc := fmt.Sprintf("%s\r\n%s*** %s ***%s\r\n%s\r\nExits: ", automap, White, room.ID, Green, roomDesc)
-		for dir := range room.Exits {
-			desc += fmt.Sprintf("[%s] ", dir)
+		desc := fmt.Sprintf("%s\r\n%s*** %s ***%s%s\r\n%s", automap, White, room.ID, roomTags(room), Green, roomDesc)
+		if isBuilder(p) {
+			desc += builderDetails(room)
+		}
+		desc += "\r\nExits: "
+		for _, dir := range visibleExits(p, room) {
+			desc += fmt.Sprintf("[%s] ", exitLabel(dir, room.Exits[dir]))
 		}
 		if len(room.ItemMap) > 0 {
 			desc += "\r\nVisible Items: "
@@ -1008,7 +1178,11 @@ func (w *World) Look(p *Player, target string) string {
 		found := false
 		for _, other := range w.Players {
 			if other.RoomID == p.RoomID && other != p {
-				desc += other.Name + " "
+				if other.Ghost {
+					desc += other.Name + " (ghost) "
+				} else {
+					desc += other.Name + " "
+				}
 				found = true
 			}
 		}
@@ -1017,21 +1191,65 @@ func (w *World) Look(p *Player, target string) string {
 		}
 		return desc + "\r\n"
 	}
+	if name, ok := strings.CutPrefix(target, "in "); ok {
+		return w.lookIn(p, name)
+	}
+	if dir, door := w.findDoor(p, target); door != nil {
+		return doorDetails(dir, door)
+	}
 	for _, npc := range room.NPCMap {
 		if strings.Contains(strings.ToLower(npc.Name), target) || npc.ID == target {
-			return fmt.Sprintf("\r\n%s\r\nState: %s | HP: %d/%d\r\n", npc.Description, npc.State, npc.HP, npc.MaxHP)
+			desc := fmt.Sprintf("\r\n%s\r\nState: %s | HP: %d/%d\r\n", npc.Description, npc.State, npc.HP, npc.MaxHP)
+			if active := npc.Effects.String(w.now()); active != "" {
+				desc += "Effects: " + active + "\r\n"
+			}
+			if npc.Kind != "" {
+				desc += "Kind: " + npc.Kind + "\r\n"
+			}
+			if resist := game.DescribeResist(npcResist(npc)); resist != "" {
+				desc += "Resists: " + resist + "\r\n"
+			}
+			if threat := threatString(npc); threat != "" {
+				desc += "Threat: " + threat + "\r\n"
+			}
+			return desc
+		}
+	}
+	for _, other := range w.Players {
+		if other.RoomID == p.RoomID && strings.HasPrefix(strings.ToLower(other.Name), target) {
+			desc := fmt.Sprintf("\r\n%s, a level %d %s.\r\nHP: %d/%d\r\n", other.Name, other.Level, other.Class, other.HP, other.MaxHP)
+			if active := other.Effects.String(w.now()); active != "" {
+				desc += "Effects: " + active + "\r\n"
+			}
+			return desc
 		}
 	}
 	if item := findItemInMap(room.ItemMap, target); item != nil {
-		return fmt.Sprintf("\r\n%s (Damage: %d, AC: %d, Value: %d)\r\n", ColorizeItem(item), item.Damage, item.AC, item.Price)
+		return itemDetails(item)
 	}
 	for _, item := range p.Inventory {
-		if strings.Contains(strings.ToLower(item.Name), target) || item.ID == target {
-			return fmt.Sprintf("\r\n%s (Damage: %d, AC: %d, Value: %d)\r\n", ColorizeItem(item), item.Damage, item.AC, item.Price)
+		if item.matches(target) {
+			return itemDetails(item)
 		}
 	}
 	return "You don't see that here."
 }
+
+// itemDetails describes an item for look: its stats, damage type and the
+// resistances it grants
+func itemDetails(item *Item) string {
+	desc := fmt.Sprintf("\r\n%s (Damage: %d, AC: %d, Value: %d)\r\n", ColorizeItem(item), item.Damage, item.AC, item.Price)
+	if item.DamageType != "" {
+		desc += "Damage type: " + item.DamageType + "\r\n"
+	}
+	if resist := game.DescribeResist(item.Resist); resist != "" {
+		desc += "Resists: " + resist + "\r\n"
+	}
+	if item.IsContainer() {
+		desc += containerDetails(item)
+	}
+	return desc
+}
 func (w *World) ListGoods(p *Player) string {
 	w.mutex.RLock()
 	defer w.mutex.RUnlock()
@@ -1054,6 +1272,9 @@ func (w *World) ListGoods(p *Player) string {
 	}
 	return s
 }
+
+// BuyItem buys items from the room's merchant, e.g. "buy 3 health vial".
+// Stackable goods arrive as one stack; gear needs a free slot per item.
 func (w *World) BuyItem(p *Player, itemName string) string {
 	w.mutex.Lock()
 	defer w.mutex.Unlock()
@@ -1068,22 +1289,47 @@ func (w *World) BuyItem(p *Player, itemName string) string {
 	if vendor == nil {
 		return "No merchant."
 	}
+	n, all, itemName := game.ParseQuantity(itemName)
+	if all {
+		return "Buy how many?"
+	}
 	for _, itemID := range vendor.Inventory {
 		if tmpl, ok := w.ItemTemplates[itemID]; ok {
 			if strings.Contains(strings.ToLower(tmpl.Name), itemName) || tmpl.ID == itemName {
-				if p.Money >= tmpl.Price {
-					p.Money -= tmpl.Price
-					newItem := *tmpl
-					p.Inventory = append(p.Inventory, &newItem)
-					return fmt.Sprintf("Bought %s.", ColorizeItem(&newItem))
-				} else {
+				// Compare by division so a huge count can't overflow the cost
+				if tmpl.Price > 0 && n > p.Money/tmpl.Price {
 					return "Not enough Fragments."
 				}
+				if !tmpl.Stackable() && n > 1 && len(p.Inventory)+n > MaxInventorySize {
+					return fmt.Sprintf("Your inventory is full (max %d items).", MaxInventorySize)
+				}
+				var bought []*Item
+				if tmpl.Stackable() {
+					bought = append(bought, w.newItem(tmpl, n))
+				} else {
+					for range n {
+						bought = append(bought, w.newItem(tmpl, 1))
+					}
+				}
+				if !canCarry(p, bought[0]) {
+					return fmt.Sprintf("Your inventory is full (max %d items).", MaxInventorySize)
+				}
+				p.Money -= tmpl.Price * n
+				for _, item := range bought {
+					p.Inventory = addItem(p.Inventory, item)
+				}
+				if len(bought) > 1 {
+					return fmt.Sprintf("Bought %dx %s.", n, ColorizeItem(tmpl))
+				}
+				return fmt.Sprintf("Bought %s.", ColorizeItem(bought[0]))
 			}
 		}
 	}
 	return "Merchant doesn't have that."
 }
+
+// SellItem sells items to the room's merchant for half their price, e.g.
+// "sell all.trash"
 func (w *World) SellItem(p *Player, itemName string) string {
 	w.mutex.Lock()
 	defer w.mutex.Unlock()
@@ -1098,18 +1344,37 @@ func (w *World) SellItem(p *Player, itemName string) string {
 	if vendor == nil {
 		return "No merchant."
 	}
-	for i, item := range p.Inventory {
-		if strings.Contains(strings.ToLower(item.Name), itemName) || item.ID == itemName {
+	picks, ok := pickItems(p.Inventory, itemName)
+	if len(picks) == 0 {
+		return "You don't have that."
+	}
+	if !ok {
+		return "You don't have that many."
+	}
+	total, refused := 0, ""
+	sold := w.moveItems(picks, func(pk pick) bool {
+		if len(pk.item.Contents) > 0 {
+			refused = fmt.Sprintf("Empty the %s first.", pk.item.Name)
+		}
+		return refused == ""
+	},
+		func(item *Item) { p.Inventory = removeItem(p.Inventory, item) },
+		func(item *Item) {
 			val := item.Price / 2
 			if val < 1 {
 				val = 1
 			}
-			p.Money += val
-			p.Inventory = append(p.Inventory[:i], p.Inventory[i+1:]...)
-			return fmt.Sprintf("Sold %s for %d.", ColorizeItem(item), val)
-		}
+			total += val * item.Count()
+		})
+	p.Money += total
+	if len(sold) == 0 {
+		return refused
+	}
+	msg := fmt.Sprintf("Sold %s for %d.", itemList(sold), total)
+	if refused != "" {
+		msg += " " + refused
 	}
-	return "You don't have that."
+	return msg
 }
 
 // StartCombat initiates combat between a player and an NPC.
@@ -1120,6 +1385,9 @@ func (w *World) StartCombat(p *Player, targetName string) string {
 	w.mutex.Lock()
 	defer w.mutex.Unlock()
 	room := w.Rooms[p.RoomID]
+	if msg := peaceBlocks(room); msg != "" {
+		return msg
+	}
 	var targetNPC *NPC
 	for _, npc := range room.NPCMap {
 		if strings.Contains(strings.ToLower(npc.Name), targetName) || npc.ID == targetName {
@@ -1128,6 +1396,9 @@ func (w *World) StartCombat(p *Player, targetName string) string {
 		}
 	}
 	if targetNPC == nil {
+		if other := w.findPlayerInRoom(room.ID, targetName); other != nil && targetName != "" && room.HasFlag(world.FlagPvP) {
+			return w.startPvP(p, other)
+		}
 		return "Not here."
 	}
 	if targetNPC.Vendor {
@@ -1135,27 +1406,55 @@ func (w *World) StartCombat(p *Player, targetName string) string {
 	}
 	p.State = "COMBAT"
 	p.Target = targetNPC.ID
-	p.LastAttack = time.Now().Add(-2 * time.Second)
+	p.LastAttack = w.now().Add(-2 * time.Second)
+	w.addThreat(targetNPC, p, 0)
 	return fmt.Sprintf("Engaging %s!", targetNPC.Name)
 }
 func (w *World) StopCombat(p *Player) string {
 	w.mutex.Lock()
 	defer w.mutex.Unlock()
+	if p.State == "COMBAT" {
+		if msg := w.controlBlocked(p, "flee"); msg != "" {
+			return msg
+		}
+		// Breaking away from an NPC that is attacking you can fail
+		if npc := w.attackerOf(p); npc != nil && !game.TryFlee(w.rng()) {
+			return fmt.Sprintf("You try to break away but %s cuts you off!", npc.Name)
+		}
+	}
+	w.dropThreat(p)
 	p.State = "IDLE"
 	return "Stopped."
 }
 
 // UseItem consumes a consumable item from inventory.
 // Supports healing items and stat buff items (like red pill for STR).
-// Removes the item from inventory after use.
+// Uses one item from a stack, removing the item once none are left.
 func (w *World) UseItem(p *Player, itemName string) string {
 	w.mutex.Lock()
 	defer w.mutex.Unlock()
-	for i, item := range p.Inventory {
-		if strings.Contains(strings.ToLower(item.Name), itemName) || item.ID == itemName {
-			if item.Type != "consumable" {
+	for _, stack := range p.Inventory {
+		if stack.matches(itemName) {
+			if stack.Type != "consumable" {
+				if _, ok := stack.Scripts["use"]; ok {
+					w.fireItemScripts(stack, "use", p)
+					return fmt.Sprintf("You use %s.", ColorizeItem(stack))
+				}
 				return "Can't use."
 			}
+			room := w.Rooms[p.RoomID]
+			var npc *NPC
+			if stack.Effect == "damage" {
+				target, ok := room.NPCMap[p.Target]
+				if p.State != "COMBAT" || !ok || target.IsDead {
+					return fmt.Sprintf("You need a target for %s. Attack something first.", ColorizeItem(stack))
+				}
+				npc = target
+			}
+			item := w.take(pick{stack, 1})
+			if item == stack {
+				p.Inventory = removeItem(p.Inventory, stack)
+			}
 			msg := ""
 			if item.Effect == "heal" {
 				p.HP += item.Value
@@ -1167,51 +1466,79 @@ func (w *World) UseItem(p *Player, itemName string) string {
 				p.Strength += item.Value
 				p.HP = p.MaxHP
 				msg = fmt.Sprintf("Swallowed %s. Str +%d!", ColorizeItem(item), item.Value)
+			} else if item.Effect == "damage" {
+				msg = w.throwItem(p, item, npc, room)
+			} else if applied := w.applyItemEffect(p, item); applied != "" {
+				msg = fmt.Sprintf("Used %s. %s", ColorizeItem(item), applied)
 			}
-			p.Inventory = append(p.Inventory[:i], p.Inventory[i+1:]...)
+			w.fireItemScripts(item, "use", p)
 			return msg
 		}
 	}
 	return "Don't have."
 }
 
-// GetItem picks up an item from the current room and adds it to player inventory.
-// Uses fuzzy name matching to find items by partial name or exact ID.
+// GetItem picks up items from the current room and adds them to player inventory.
+// Uses fuzzy name matching to find items by partial name or exact ID, and
+// takes quantities such as "5 trash" or "all.trash". Corpses stay put.
 func (w *World) GetItem(p *Player, itemName string) string {
 	w.mutex.Lock()
 	defer w.mutex.Unlock()
 
-	// Issue #8 fix: Check inventory size limit
-	if len(p.Inventory) >= MaxInventorySize {
-		return fmt.Sprintf("Your inventory is full (max %d items). Drop something first.", MaxInventorySize)
-	}
-
 	room := w.Rooms[p.RoomID]
 	if room == nil {
 		return "You cannot pick up items here."
 	}
 
-	item := findItemInMap(room.ItemMap, itemName)
-	if item != nil {
-		delete(room.ItemMap, item.ID)
-		p.Inventory = append(p.Inventory, item)
-		return fmt.Sprintf("Got %s.", ColorizeItem(item))
+	var items []*Item
+	for _, item := range roomItems(room) {
+		if item.Type != CorpseType && !item.Fixed {
+			items = append(items, item)
+		}
 	}
-	return "Not here."
+	picks, ok := pickItems(items, itemName)
+	if len(picks) == 0 {
+		if item := findItemInMap(room.ItemMap, itemName); item != nil && item.Type == CorpseType {
+			return fmt.Sprintf("You can't carry the %s. Use 'loot' to search it.", item.Name)
+		} else if item != nil && item.Fixed {
+			return fmt.Sprintf("The %s won't budge.", item.Name)
+		}
+		return "Not here."
+	}
+	if !ok {
+		return "There aren't that many here."
+	}
+
+	// Issue #8 fix: Check inventory size limit
+	moved := w.moveItems(picks, func(pk pick) bool { return canCarry(p, pk.item) },
+		func(item *Item) { delete(room.ItemMap, item.ID) },
+		func(item *Item) { p.Inventory = addItem(p.Inventory, item) })
+	if len(moved) == 0 {
+		return fmt.Sprintf("Your inventory is full (max %d items). Drop something first.", MaxInventorySize)
+	}
+	msg := fmt.Sprintf("Got %s.", itemList(moved))
+	if len(moved) < len(picks) {
+		msg += " Your inventory is full."
+	}
+	return msg
 }
 
-// DropItem removes an item from player inventory and places it in the current room.
+// DropItem removes items from player inventory and places them in the current room.
 func (w *World) DropItem(p *Player, itemName string) string {
 	w.mutex.Lock()
 	defer w.mutex.Unlock()
-	for i, item := range p.Inventory {
-		if strings.Contains(strings.ToLower(item.Name), itemName) || item.ID == itemName {
-			p.Inventory = append(p.Inventory[:i], p.Inventory[i+1:]...)
-			w.Rooms[p.RoomID].ItemMap[item.ID] = item
-			return fmt.Sprintf("Dropped %s.", ColorizeItem(item))
-		}
+	room := w.Rooms[p.RoomID]
+	picks, ok := pickItems(p.Inventory, itemName)
+	if len(picks) == 0 || room == nil {
+		return "Don't have."
 	}
-	return "Don't have."
+	if !ok {
+		return "You don't have that many."
+	}
+	moved := w.moveItems(picks, nil,
+		func(item *Item) { p.Inventory = removeItem(p.Inventory, item) },
+		func(item *Item) { addToRoom(room, item) })
+	return fmt.Sprintf("Dropped %s.", itemList(moved))
 }
 
 // WearItem equips an item from inventory to its designated slot (hand, body, or head).
@@ -1220,7 +1547,7 @@ func (w *World) WearItem(p *Player, itemName string) string {
 	w.mutex.Lock()
 	defer w.mutex.Unlock()
 	for i, item := range p.Inventory {
-		if strings.Contains(strings.ToLower(item.Name), itemName) || item.ID == itemName {
+		if item.matches(itemName) {
 			if item.Slot == "" {
 				return "Can't wear."
 			}
@@ -1229,6 +1556,7 @@ func (w *World) WearItem(p *Player, itemName string) string {
 			}
 			p.Equipment[item.Slot] = item
 			p.Inventory = append(p.Inventory[:i], p.Inventory[i+1:]...)
+			w.fireItemScripts(item, "wear", p)
 			return fmt.Sprintf("Equipped %s.", ColorizeItem(item))
 		}
 	}
@@ -1253,11 +1581,20 @@ func (w *World) RemoveItem(p *Player, slot string) string {
 func (w *World) MovePlayer(p *Player, direction string) string {
 	w.mutex.Lock()
 	defer w.mutex.Unlock()
+	if msg := w.controlBlocked(p, "move"); msg != "" {
+		return msg
+	}
 	p.State = "IDLE"
-	if next, ok := w.Rooms[p.RoomID].Exits[direction]; ok {
+	room := w.Rooms[p.RoomID]
+	if exit, ok := room.Exits[direction]; ok && seesExit(p, room.ID, direction, exit) {
+		if exit.Closed {
+			return fmt.Sprintf("The %s is closed.", exit.Door)
+		}
+		from, next := p.RoomID, exit.To
 		p.RoomID = next
 		// Check for phone booth discovery
 		w.CheckPhoneDiscovery(p)
+		w.playerEntered(p, from)
 		return fmt.Sprintf("You move to %s.", next)
 	}
 	return "No exit."
@@ -1278,6 +1615,10 @@ func (w *World) Recall(p *Player) string {
 		return "You are already at the recall point.\r\n"
 	}
 
+	if w.Rooms[p.RoomID].HasFlag(world.FlagNoRecall) {
+		return "Something in this place blocks your recall.\r\n"
+	}
+
 	// Check if recall location exists
 	if _, ok := w.Rooms[recallRoom]; !ok {
 		return fmt.Sprintf("%sError: Recall location not found. Contact an admin.%s\r\n", Red, Reset)
@@ -1290,6 +1631,7 @@ func (w *World) Recall(p *Player) string {
 	// Teleport player
 	oldRoom := p.RoomID
 	p.RoomID = recallRoom
+	w.playerEntered(p, oldRoom)
 
 	return fmt.Sprintf("%sYou close your eyes and focus on the safe house...%s\r\n"+
 		"%sReality bends around you. When you open your eyes, you're in the dojo.%s\r\n"+
@@ -1321,7 +1663,7 @@ func (w *World) ShowInventory(p *Player) string {
 	} else {
 		s += "  Head: <empty>\r\n"
 	}
-	s += "[ BACKPACK ]\r\n"
+	s += fmt.Sprintf("[ BACKPACK ] %d/%d slots\r\n", len(p.Inventory), MaxInventorySize)
 	if len(p.Inventory) == 0 {
 		s += "  Empty.\r\n"
 	}
@@ -1337,12 +1679,23 @@ func (w *World) ShowScore(p *Player) string {
 	w.mutex.RLock()
 	defer w.mutex.RUnlock()
 	nextLevel := p.Level * 1000
-	return fmt.Sprintf("\r\n%s=== %s ===%s\r\nClass: %s\r\nLevel: %d\r\nXP:    %d / %d\r\nFragments: %d\r\nHP:    %d / %d\r\nMP:    %d / %d\r\nSTR:   %d\r\n", Green, p.Name, Reset, p.Class, p.Level, p.XP, nextLevel, p.Money, p.HP, p.MaxHP, p.MP, p.MaxMP, p.Strength)
+	score := fmt.Sprintf("\r\n%s=== %s ===%s\r\nClass: %s\r\nLevel: %d\r\nXP:    %d / %d\r\nFragments: %d\r\nHP:    %d / %d\r\nMP:    %d / %d\r\nSTR:   %d\r\n", Green, p.Name, Reset, p.Class, p.Level, p.XP, nextLevel, p.Money, p.HP, p.MaxHP, p.MP, p.MaxMP, p.Strength)
+	if p.XPDebt > 0 {
+		score += fmt.Sprintf("XP debt: %d\r\n", p.XPDebt)
+	}
+	if p.Ghost {
+		score += Cyan + "You are a ghost." + Reset + "\r\n"
+	}
+	if active := p.Effects.String(w.now()); active != "" {
+		score += "Effects: " + active + "\r\n"
+	}
+	return score
 }
 func (w *World) HandleSay(p *Player, msg string) string {
 	w.mutex.RLock()
 	defer w.mutex.RUnlock()
 	room := w.Rooms[p.RoomID]
+	w.fireRoomScripts(room, "say", p, msg)
 	response := ""
 	for _, npc := range room.NPCMap {
 		if dialogue, ok := w.Dialogue[npc.ID]; ok {
@@ -1364,14 +1717,29 @@ func (w *World) ListPlayers() string {
 	}
 	return s
 }
-func (w *World) Teleport(p *Player, dest string) string {
+
+// Teleport errors
+var (
+	errUnknownRoom = errors.New("no such room")
+	errNoTeleport  = errors.New("something blocks teleporting there")
+)
+
+// Teleport moves a player straight to a room. Neither the room they leave
+// nor the one they arrive in may be no_teleport.
+func (w *World) Teleport(p *Player, dest string) error {
 	w.mutex.Lock()
 	defer w.mutex.Unlock()
-	if _, ok := w.Rooms[dest]; ok {
-		p.RoomID = dest
-		return "Teleported."
+	room, ok := w.Rooms[dest]
+	if !ok {
+		return errUnknownRoom
+	}
+	if room.HasFlag(world.FlagNoTeleport) || w.Rooms[p.RoomID].HasFlag(world.FlagNoTeleport) {
+		return errNoTeleport
 	}
-	return "Invalid destination."
+	from := p.RoomID
+	p.RoomID = dest
+	w.playerEntered(p, from)
+	return nil
 }
 func (w *World) Gossip(p *Player, msg string) {
 	w.mutex.RLock()
@@ -1406,12 +1774,12 @@ func (w *World) Dig(p *Player, direction string, roomName string) string {
 	}
 	newID := strings.ToLower(strings.ReplaceAll(roomName, " ", "_"))
 	if _, exists := w.Rooms[newID]; exists {
-		newID += "_" + fmt.Sprintf("%d", rand.Intn(999))
+		newID += "_" + fmt.Sprintf("%d", w.rng().Intn(999))
 	}
-	newRoom := &Room{ID: newID, Description: roomName, Exits: make(map[string]string), ItemMap: make(map[string]*Item), NPCMap: make(map[string]*NPC), Symbol: ".", Color: "white"}
+	newRoom := &Room{ID: newID, Description: roomName, Exits: make(map[string]*Exit), ItemMap: make(map[string]*Item), NPCMap: make(map[string]*NPC), Symbol: ".", Color: "white"}
 	reverseDir := getReverseDir(direction)
-	currentRoom.Exits[direction] = newID
-	newRoom.Exits[reverseDir] = p.RoomID
+	currentRoom.Exits[direction] = &Exit{To: newID}
+	newRoom.Exits[reverseDir] = &Exit{To: p.RoomID}
 	w.Rooms[newID] = newRoom
 	return fmt.Sprintf("Created room '%s'.", roomName)
 }
@@ -1429,6 +1797,18 @@ func getReverseDir(dir string) string {
 		return "down"
 	case "down":
 		return "up"
+	case "fore":
+		return "aft"
+	case "aft":
+		return "fore"
+	case "port":
+		return "starboard"
+	case "starboard":
+		return "port"
+	case "in":
+		return "out"
+	case "out":
+		return "in"
 	}
 	return "back"
 }
@@ -1535,7 +1915,11 @@ func (w *World) Craft(p *Player, recipeName string) string {
 		"operator_coat": {"Operator's Coat", []ingredient{{"coat", 1}, {"red_pill", 2}, {"trash", 15}}, "operator_coat", 5, 150},
 	}
 
-	r, ok := recipes[strings.ToLower(recipeName)]
+	n, all, recipeName := game.ParseQuantity(recipeName)
+	if all {
+		return "Craft how many?"
+	}
+	r, ok := recipes[recipeName]
 	if !ok {
 		return "Unknown recipe. Type 'recipes' to see available recipes."
 	}
@@ -1545,60 +1929,81 @@ func (w *World) Craft(p *Player, recipeName string) string {
 		return fmt.Sprintf("You need Crafting Skill %d to craft %s. (You have %d)", r.skill, r.name, p.CraftingSkill)
 	}
 
-	// Count inventory items
+	// Count inventory items, stacks by their size
 	invCount := make(map[string]int)
 	for _, item := range p.Inventory {
-		invCount[item.ID]++
+		invCount[item.TemplateID()] += item.Count()
 	}
 
-	// Check ingredients
+	// Check ingredients for n crafts, by division so a huge count can't
+	// overflow
 	var missing []string
 	for _, ing := range r.ingredients {
-		if invCount[ing.id] < ing.qty {
-			missing = append(missing, fmt.Sprintf("%dx %s", ing.qty-invCount[ing.id], ing.id))
+		if have := invCount[ing.id]; have/ing.qty < n {
+			need := math.MaxInt
+			if n <= math.MaxInt/ing.qty {
+				need = ing.qty * n
+			}
+			missing = append(missing, fmt.Sprintf("%dx %s", need-have, ing.id))
 		}
 	}
 	if len(missing) > 0 {
 		return fmt.Sprintf("Missing materials: %s", strings.Join(missing, ", "))
 	}
 
-	// Remove ingredients
+	// Create result item
+	tmpl, ok := w.ItemTemplates[r.resultID]
+	if !ok {
+		return "Error: Result item template not found."
+	}
+	if !tmpl.Stackable() && n > 1 && len(p.Inventory)+n > MaxInventorySize {
+		return fmt.Sprintf("Your inventory is full (max %d items).", MaxInventorySize)
+	}
+
+	// Remove ingredients, taking from stacks
 	for _, ing := range r.ingredients {
-		removed := 0
-		newInv := make([]*Item, 0, len(p.Inventory))
+		var picks []pick
+		need := ing.qty * n
 		for _, item := range p.Inventory {
-			if item.ID == ing.id && removed < ing.qty {
-				removed++
-				continue // Don't add to new inventory
+			if need > 0 && item.TemplateID() == ing.id {
+				picks = append(picks, pick{item, min(need, item.Count())})
+				need -= item.Count()
 			}
-			newInv = append(newInv, item)
 		}
-		p.Inventory = newInv
+		w.moveItems(picks, nil, func(item *Item) { p.Inventory = removeItem(p.Inventory, item) }, func(*Item) {})
 	}
 
-	// Create result item
-	tmpl, ok := w.ItemTemplates[r.resultID]
-	if !ok {
-		return "Error: Result item template not found."
+	var made []*Item
+	if tmpl.Stackable() {
+		made = append(made, w.newItem(tmpl, n))
+	} else {
+		for range n {
+			made = append(made, w.newItem(tmpl, 1))
+		}
 	}
-	newItem := *tmpl
-	// Set durability for equipment items
-	if newItem.Slot != "" {
-		newItem.MaxDurability = 100
-		newItem.Durability = 100
+	for _, newItem := range made {
+		// Set durability for equipment items
+		if newItem.Slot != "" {
+			newItem.MaxDurability = 100
+			newItem.Durability = 100
+		}
+		p.Inventory = addItem(p.Inventory, newItem)
 	}
-	p.Inventory = append(p.Inventory, &newItem)
 
 	// Award XP
-	p.XP += r.xp
+	levelMsg := w.gainXP(p, r.xp*n)
+	crafted := tmpl.Name
+	if n > 1 {
+		crafted = fmt.Sprintf("%dx %s", n, tmpl.Name)
+	}
 
 	// Small chance to increase crafting skill
-	if rand.Intn(100) < 20 { // 20% chance
+	if w.rng().Intn(100) < 20 { // 20% chance
 		p.CraftingSkill++
-		return fmt.Sprintf("%sYou crafted %s! (+%d XP) Your crafting skill increased to %d!%s", Green, newItem.Name, r.xp, p.CraftingSkill, Reset)
+		return fmt.Sprintf("%sYou crafted %s! (+%d XP) Your crafting skill increased to %d!%s", Green, crafted, r.xp*n, p.CraftingSkill, Reset) + levelMsg
 	}
 
-	return fmt.Sprintf("%sYou crafted %s! (+%d XP)%s", Green, newItem.Name, r.xp, Reset)
+	return fmt.Sprintf("%sYou crafted %s! (+%d XP)%s", Green, crafted, r.xp*n, Reset) + levelMsg
 }
 
 // RepairItem repairs an equipped item using a repair kit
@@ -1607,14 +2012,14 @@ func (w *World) RepairItem(p *Player, targetSlot string) string {
 	defer w.mutex.Unlock()
 
 	// Find repair kit
-	kitIdx := -1
-	for i, item := range p.Inventory {
-		if item.ID == "repair_kit" {
-			kitIdx = i
+	var kit *Item
+	for _, item := range p.Inventory {
+		if item.TemplateID() == "repair_kit" {
+			kit = item
 			break
 		}
 	}
-	if kitIdx == -1 {
+	if kit == nil {
 		return "You need a Repair Kit to repair items. Craft one with 'craft repair_kit'."
 	}
 
@@ -1631,7 +2036,9 @@ func (w *World) RepairItem(p *Player, targetSlot string) string {
 	}
 
 	// Use repair kit
-	p.Inventory = append(p.Inventory[:kitIdx], p.Inventory[kitIdx+1:]...)
+	if w.take(pick{kit, 1}) == kit {
+		p.Inventory = removeItem(p.Inventory, kit)
+	}
 
 	// Repair item
 	repaired := 25
@@ -1645,10 +2052,19 @@ func (w *World) RepairItem(p *Player, targetSlot string) string {
 
 // DegradeEquipment reduces durability of equipped items after combat
 func (w *World) DegradeEquipment(p *Player) {
+	w.degradeEquipment(p, 1)
+}
+
+// degradeEquipment reduces the durability of each equipped item by amount
+func (w *World) degradeEquipment(p *Player, amount int) {
+	if amount <= 0 {
+		return
+	}
 	for _, item := range p.Equipment {
 		if item.MaxDurability > 0 && item.Durability > 0 {
-			item.Durability--
+			item.Durability = max(0, item.Durability-amount)
 			if item.Durability == 0 {
+				recordAudit(p.Name, db.AuditDelete, fmt.Sprintf("item broken: %s (%s)", item.Name, item.ID), "")
 				if p.Conn != nil {
 					p.Conn.Write(fmt.Sprintf("\r\n%sYour %s has broken!%s\r\n", Red, item.Name, Reset))
 				}
@@ -41,7 +41,8 @@ func TestWorldRoomsHaveExits(t *testing.T) {
 			continue
 		}
 
-		for dir, targetID := range room.Exits {
+		for dir, exit := range room.Exits {
+			targetID := exit.To
 			if targetID == "" {
 				t.Errorf("Room %s has empty exit target for %s", roomID, dir)
 			}